@@ -0,0 +1,14 @@
+// Package api содержит контракты API: спецификацию OpenAPI для HTTP и proto-файлы для gRPC.
+package api
+
+import _ "embed"
+
+// OpenAPISpec — спецификация HTTP API в формате OpenAPI 3
+//
+//go:embed openapi.json
+var OpenAPISpec []byte
+
+// DocsPage — HTML-страница, отображающая OpenAPISpec с помощью Redoc
+//
+//go:embed docs.html
+var DocsPage []byte
@@ -1,8 +1,15 @@
 package main
 
-import "github.com/VadimBorzenkov/WalletAPI/internal/app"
+import (
+	"os"
 
-// Запуск проекта в пакете main
+	"github.com/VadimBorzenkov/WalletAPI/internal/app"
+)
+
+// Запуск проекта в пакете main. Команда migrate управляет миграциями без запуска серверов
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(app.Migrate(os.Args[2:]))
+	}
 	app.Run()
 }
@@ -0,0 +1,12 @@
+package main
+
+import (
+	"os"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/app"
+)
+
+// Утилита операторов для обслуживания кошельков: создание, корректировки, заморозка, сверка и выписки
+func main() {
+	os.Exit(app.Walletctl(os.Args[1:]))
+}
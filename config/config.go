@@ -1,19 +1,107 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
+	// Хранилище кошельков: postgres, sqlite (локальный файл SQLitePath) или memory (без базы данных,
+	// с кошельками из MemoryWallets)
+	Storage       string
+	SQLitePath    string
+	MemoryWallets []string
+
 	Port           string
+	GRPCPort       string
 	DBHost         string
 	DBPort         string
 	DBUser         string
 	DBPass         string
 	DBName         string
 	ExternalApiURL string
+
+	// Настройки подключения к базе данных: режим и сертификаты SSL, ограничение времени запроса,
+	// размер пула и время ожидания базы при запуске
+	DBSSLMode          string
+	DBSSLRootCert      string
+	DBSSLCert          string
+	DBSSLKey           string
+	DBStatementTimeout time.Duration
+	DBMaxConns         int
+	DBMinConns         int
+	DBConnMaxLifetime  time.Duration
+	DBConnMaxIdleTime  time.Duration
+	DBConnectTimeout   time.Duration
+
+	// Применять ли миграции при запуске. Если выключено, миграции применяются командой migrate up
+	DBAutoMigrate bool
+
+	// Настройки доставки доменных событий из outbox
+	OutboxPublisher    string
+	OutboxFilePath     string
+	OutboxWebhookURL   string
+	OutboxPollInterval time.Duration
+	OutboxBatchSize    int
+
+	// Настройки доставки вебхуков
+	WebhookPollInterval time.Duration
+	WebhookBatchSize    int
+	WebhookMaxAttempts  int
+	WebhookBackoffBase  time.Duration
+	WebhookBackoffMax   time.Duration
+	WebhookTimeout      time.Duration
+
+	// Настройки потоков Server-Sent Events
+	StreamHeartbeat    time.Duration
+	StreamMaxPerClient int
+
+	// Настройки обработки заданий на массовые выплаты
+	PayoutWorkers       int
+	PayoutBatchSize     int
+	PayoutPollInterval  time.Duration
+	PayoutLease         time.Duration
+	PayoutMaxUploadSize int
+
+	// Настройки кэша балансов: размер LRU (0 отключает кэш), время жизни записи и адрес общего Redis
+	CacheSize     int
+	CacheTTL      time.Duration
+	CacheRedisURL string
+
+	// Реплика для чтений с допустимым отставанием: строка подключения (пустая отключает реплику),
+	// максимальное отставание и период его проверки
+	DBReplicaDSN         string
+	ReplicaMaxLag        time.Duration
+	ReplicaCheckInterval time.Duration
+
+	// Горячие кошельки, пополнения которых буферизуются, и параметры переноса буфера в баланс
+	HotWallets            []string
+	HotWalletFoldInterval time.Duration
+	HotWalletFoldBatch    int
+
+	// Токен доступа к административному API
+	AdminAPIToken string
+
+	// Ключ подписи контрольных точек журнала аудита (seed Ed25519 в base64) и период их создания
+	AuditSigningKey         string
+	AuditCheckpointInterval time.Duration
+	// Период запечатывания новых записей журнала аудита в цепочку
+	AuditSealInterval time.Duration
+
+	// Период плановой сверки балансов с журналом операций (0 — только по запросу) и публикация расхождений событиями
+	ReconcileInterval time.Duration
+	ReconcileEvents   bool
+
+	// Период снимков балансов, по которым вычисляются исторические балансы (0 — снимки не создаются)
+	BalanceSnapshotInterval time.Duration
+
+	// Закрытие суток UTC дневными отчетами по кошелькам
+	DailyReports bool
 }
 
 func LoadConfig() (*Config, error) {
@@ -22,13 +110,147 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
-	return &Config{
+	config := &Config{
+		Storage:       getString("STORAGE", "postgres"),
+		SQLitePath:    getString("SQLITE_PATH", "wallets.db"),
+		MemoryWallets: getList("MEMORY_WALLETS"),
+
 		Port:           ":" + os.Getenv("PORT"),
+		GRPCPort:       ":" + getString("GRPC_PORT", "9090"),
 		DBHost:         os.Getenv("DB_HOST"),
 		DBPort:         os.Getenv("DB_PORT"),
 		DBUser:         os.Getenv("DB_USER"),
 		DBPass:         os.Getenv("DB_PASSWORD"),
 		DBName:         os.Getenv("DB_NAME"),
 		ExternalApiURL: os.Getenv("EXTERNAL_API_URL"),
-	}, nil
+
+		DBSSLMode:          getString("DB_SSLMODE", "disable"),
+		DBSSLRootCert:      os.Getenv("DB_SSLROOTCERT"),
+		DBSSLCert:          os.Getenv("DB_SSLCERT"),
+		DBSSLKey:           os.Getenv("DB_SSLKEY"),
+		DBStatementTimeout: getDuration("DB_STATEMENT_TIMEOUT", 0),
+		DBMaxConns:         getInt("DB_MAX_CONNS", 25),
+		DBMinConns:         getInt("DB_MIN_CONNS", 0),
+		DBConnMaxLifetime:  getDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		DBConnMaxIdleTime:  getDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+		DBConnectTimeout:   getDuration("DB_CONNECT_TIMEOUT", 30*time.Second),
+		DBAutoMigrate:      getBool("DB_AUTO_MIGRATE", true),
+
+		OutboxPublisher:    os.Getenv("OUTBOX_PUBLISHER"),
+		OutboxFilePath:     os.Getenv("OUTBOX_FILE_PATH"),
+		OutboxWebhookURL:   os.Getenv("OUTBOX_WEBHOOK_URL"),
+		OutboxPollInterval: getDuration("OUTBOX_POLL_INTERVAL", time.Second),
+		OutboxBatchSize:    getInt("OUTBOX_BATCH_SIZE", 100),
+
+		WebhookPollInterval: getDuration("WEBHOOK_POLL_INTERVAL", time.Second),
+		WebhookBatchSize:    getInt("WEBHOOK_BATCH_SIZE", 50),
+		WebhookMaxAttempts:  getInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookBackoffBase:  getDuration("WEBHOOK_BACKOFF_BASE", 10*time.Second),
+		WebhookBackoffMax:   getDuration("WEBHOOK_BACKOFF_MAX", time.Hour),
+		WebhookTimeout:      getDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+
+		StreamHeartbeat:    getDuration("SSE_HEARTBEAT_INTERVAL", 15*time.Second),
+		StreamMaxPerClient: getInt("SSE_MAX_STREAMS_PER_CLIENT", 5),
+
+		PayoutWorkers:       getInt("PAYOUT_WORKERS", 8),
+		PayoutBatchSize:     getInt("PAYOUT_BATCH_SIZE", 500),
+		PayoutPollInterval:  getDuration("PAYOUT_POLL_INTERVAL", time.Second),
+		PayoutLease:         getDuration("PAYOUT_LEASE", 5*time.Minute),
+		PayoutMaxUploadSize: getInt("PAYOUT_MAX_UPLOAD_SIZE", 64<<20),
+
+		CacheSize:     getInt("CACHE_SIZE", 10000),
+		CacheTTL:      getDuration("CACHE_TTL", 30*time.Second),
+		CacheRedisURL: os.Getenv("CACHE_REDIS_URL"),
+
+		DBReplicaDSN:         os.Getenv("DB_REPLICA_DSN"),
+		ReplicaMaxLag:        getDuration("REPLICA_MAX_LAG", 5*time.Second),
+		ReplicaCheckInterval: getDuration("REPLICA_CHECK_INTERVAL", time.Second),
+
+		HotWallets:            getList("HOT_WALLETS"),
+		HotWalletFoldInterval: getDuration("HOT_WALLET_FOLD_INTERVAL", 200*time.Millisecond),
+		HotWalletFoldBatch:    getInt("HOT_WALLET_FOLD_BATCH", 10000),
+
+		AdminAPIToken: os.Getenv("ADMIN_API_TOKEN"),
+
+		AuditSigningKey:         os.Getenv("AUDIT_SIGNING_KEY"),
+		AuditCheckpointInterval: getDuration("AUDIT_CHECKPOINT_INTERVAL", time.Hour),
+		AuditSealInterval:       getDuration("AUDIT_SEAL_INTERVAL", time.Second),
+
+		ReconcileInterval: getDuration("RECONCILE_INTERVAL", time.Hour),
+		ReconcileEvents:   getBool("RECONCILE_EVENTS", false),
+
+		BalanceSnapshotInterval: getDuration("BALANCE_SNAPSHOT_INTERVAL", 24*time.Hour),
+
+		DailyReports: getBool("DAILY_REPORTS", true),
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// validate проверяет размеры порций фоновых процессов: при нулевом или отрицательном размере процесс
+// забирал бы порции без остановки
+func (c *Config) validate() error {
+	batches := []struct {
+		key   string
+		value int
+	}{
+		{"OUTBOX_BATCH_SIZE", c.OutboxBatchSize},
+		{"WEBHOOK_BATCH_SIZE", c.WebhookBatchSize},
+		{"PAYOUT_BATCH_SIZE", c.PayoutBatchSize},
+		{"HOT_WALLET_FOLD_BATCH", c.HotWalletFoldBatch},
+	}
+	for _, batch := range batches {
+		if batch.value <= 0 {
+			return fmt.Errorf("%s must be positive, got %d", batch.key, batch.value)
+		}
+	}
+	return nil
+}
+
+// getString читает строку из переменной окружения, возвращая значение по умолчанию, если она не задана
+func getString(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
+
+// getList читает список значений, разделенных запятыми, пропуская пустые
+func getList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getDuration читает длительность из переменной окружения, возвращая значение по умолчанию, если она не задана или некорректна
+func getDuration(key string, def time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// getBool читает флаг из переменной окружения, возвращая значение по умолчанию, если она не задана или некорректна
+func getBool(key string, def bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// getInt читает целое число из переменной окружения, возвращая значение по умолчанию, если она не задана или некорректна
+func getInt(key string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return value
 }
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// validConfig возвращает конфигурацию с допустимыми размерами порций
+func validConfig() Config {
+	return Config{
+		OutboxBatchSize:    100,
+		WebhookBatchSize:   50,
+		PayoutBatchSize:    500,
+		HotWalletFoldBatch: 10000,
+	}
+}
+
+// TestConfigValidate проверяет отказ от нулевых и отрицательных размеров порций фоновых процессов
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name     string        // Название теста
+		modify   func(*Config) // Изменение допустимой конфигурации
+		expected string        // Ожидаемый фрагмент ошибки; пустой — ошибки нет
+	}{
+		{name: "Valid", modify: func(c *Config) {}},
+		{name: "Zero Outbox Batch", modify: func(c *Config) { c.OutboxBatchSize = 0 }, expected: "OUTBOX_BATCH_SIZE"},
+		{name: "Negative Outbox Batch", modify: func(c *Config) { c.OutboxBatchSize = -1 }, expected: "OUTBOX_BATCH_SIZE"},
+		{name: "Zero Webhook Batch", modify: func(c *Config) { c.WebhookBatchSize = 0 }, expected: "WEBHOOK_BATCH_SIZE"},
+		{name: "Negative Webhook Batch", modify: func(c *Config) { c.WebhookBatchSize = -1 }, expected: "WEBHOOK_BATCH_SIZE"},
+		{name: "Zero Payout Batch", modify: func(c *Config) { c.PayoutBatchSize = 0 }, expected: "PAYOUT_BATCH_SIZE"},
+		{name: "Negative Payout Batch", modify: func(c *Config) { c.PayoutBatchSize = -1 }, expected: "PAYOUT_BATCH_SIZE"},
+		{name: "Zero Hot Wallet Fold Batch", modify: func(c *Config) { c.HotWalletFoldBatch = 0 }, expected: "HOT_WALLET_FOLD_BATCH"},
+		{name: "Negative Hot Wallet Fold Batch", modify: func(c *Config) { c.HotWalletFoldBatch = -1 }, expected: "HOT_WALLET_FOLD_BATCH"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := validConfig()
+			tt.modify(&config)
+
+			err := config.validate()
+			if tt.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
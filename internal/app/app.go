@@ -1,15 +1,36 @@
 package app
 
 import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"github.com/VadimBorzenkov/WalletAPI/config"
+	"github.com/VadimBorzenkov/WalletAPI/internal/audit"
+	"github.com/VadimBorzenkov/WalletAPI/internal/cache"
 	"github.com/VadimBorzenkov/WalletAPI/internal/db"
+	"github.com/VadimBorzenkov/WalletAPI/internal/delivery/grpcserver"
 	"github.com/VadimBorzenkov/WalletAPI/internal/delivery/handler"
 	"github.com/VadimBorzenkov/WalletAPI/internal/delivery/routes"
+	"github.com/VadimBorzenkov/WalletAPI/internal/hotwallet"
+	"github.com/VadimBorzenkov/WalletAPI/internal/outbox"
+	"github.com/VadimBorzenkov/WalletAPI/internal/payout"
+	"github.com/VadimBorzenkov/WalletAPI/internal/reconciliation"
+	"github.com/VadimBorzenkov/WalletAPI/internal/report"
 	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
 	"github.com/VadimBorzenkov/WalletAPI/internal/service"
+	"github.com/VadimBorzenkov/WalletAPI/internal/stream"
+	"github.com/VadimBorzenkov/WalletAPI/internal/webhook"
 	"github.com/VadimBorzenkov/WalletAPI/pkg/logger"
 	"github.com/VadimBorzenkov/WalletAPI/pkg/migrator"
 	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 )
 
 // Run инициализирует и запускает сервер приложения
@@ -23,38 +44,286 @@ func Run() {
 		logger.Fatalf("Ошибка загрузки конфигурации: %v", err)
 	}
 
+	// Без PostgreSQL приложение запускается с локальным хранилищем кошельков: SQLite или памятью процесса
+	if config.Storage != "postgres" {
+		runLocal(config, logger)
+		return
+	}
+
 	// Инициализация подключения к базе данных
-	dbase := db.Init(config)
-	defer func() {
-		// Закрытие подключения к базе данных при завершении работы приложения
-		if err := db.Close(dbase); err != nil {
-			logger.Errorf("Ошибка закрытия базы данных: %v", err)
-		}
-	}()
+	dbase, err := db.Init(config, logger)
+	if err != nil {
+		logger.Fatalf("Ошибка подключения к базе данных: %v", err)
+	}
+	// Закрытие пула подключений к базе данных при завершении работы приложения
+	defer dbase.Close()
 
-	// Выполнение миграций базы данных для настройки необходимых таблиц
-	if err := migrator.RunDatabaseMigrations(dbase); err != nil {
+	// Выполнение миграций базы данных для настройки необходимых таблиц, если они не применяются отдельно
+	m, err := migrator.NewPostgres(dbase)
+	if err != nil {
+		logger.Fatalf("Ошибка подготовки миграций: %v", err)
+	}
+	if err := migrateOnStartup(m, config.DBAutoMigrate, logger); err != nil {
 		logger.Fatalf("Ошибка выполнения миграций: %v", err)
 	}
 
-	// Создание нового репозитория для работы с базой данных
-	repo := repository.NewApiWalletRepository(dbase, logger)
+	// Создание репозиториев для работы с базой данных
+	repo := repository.NewApiWalletRepository(dbase, logger).WithHotWallets(config.HotWallets)
+	webhookRepo := repository.NewApiWebhookRepository(dbase, logger)
+
+	// Кэш балансов перед репозиторием кошельков; горячие кошельки не кэшируются
+	var walletRepo repository.WalletRepository = repo
+	var operationSink stream.Sink
+	if config.CacheSize > 0 {
+		walletCache, err := newWalletCache(config)
+		if err != nil {
+			logger.Fatalf("Ошибка настройки кэша балансов: %v", err)
+		}
+		cachedRepo := cache.NewWalletRepository(repo, walletCache, logger).WithUncachedWallets(config.HotWallets)
+		walletRepo, operationSink = cachedRepo, cachedRepo
+	}
+
+	// Запуск фоновой доставки доменных событий из outbox: во внешний публикатор и в очередь вебхуков
+	publisher, err := outbox.NewPublisher(config)
+	if err != nil {
+		logger.Fatalf("Ошибка настройки публикации событий: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	relay := outbox.NewRelay(
+		repository.NewApiOutboxRepository(dbase, logger),
+		outbox.MultiPublisher{publisher, webhook.NewDispatcher(webhookRepo)},
+		logger,
+		config.OutboxPollInterval,
+		config.OutboxBatchSize,
+	)
+	go relay.Run(ctx)
+
+	// Запуск отправки вебхуков с повторными попытками
+	webhookWorker := webhook.NewWorker(webhookRepo, &http.Client{Timeout: config.WebhookTimeout}, logger, webhook.Options{
+		PollInterval: config.WebhookPollInterval,
+		BatchSize:    config.WebhookBatchSize,
+		MaxAttempts:  config.WebhookMaxAttempts,
+		BackoffBase:  config.WebhookBackoffBase,
+		BackoffMax:   config.WebhookBackoffMax,
+	})
+	go webhookWorker.Run(ctx)
+
+	// Подписка на уведомления об операциях для потоков Server-Sent Events и инвалидации кэша
+	hub := stream.NewHub()
+	sinks := stream.Sinks{hub}
+	if operationSink != nil {
+		sinks = append(sinks, operationSink)
+	}
+	listener := stream.NewListener(db.DSN(config), sinks, logger)
+	go func() {
+		if err := listener.Run(ctx); err != nil {
+			logger.Errorf("Ошибка подписки на уведомления об операциях: %v", err)
+		}
+	}()
+
+	// Запуск переноса буферизованных пополнений горячих кошельков в их балансы. Перенос выполняется и без
+	// горячих кошельков, чтобы пополнения кошелька, исключенного из HOT_WALLETS, не остались в буфере
+	go hotwallet.NewFolder(repo, logger, config.HotWalletFoldInterval, config.HotWalletFoldBatch).Run(ctx)
 
 	// Инициализация сервисного уровня с репозиторием и логгером
-	svc := service.NewApiWalletService(repo, logger)
+	svc := service.NewApiWalletService(walletRepo, logger)
+
+	// Чтения с заголовком Consistency: eventual направляются на реплику, пока она не отстает
+	if config.DBReplicaDSN != "" {
+		replicaDB, err := db.Open(config.DBReplicaDSN, config)
+		if err != nil {
+			logger.Fatalf("Ошибка подключения к реплике: %v", err)
+		}
+		defer replicaDB.Close()
+		replica := repository.NewReplicaWalletReader(replicaDB, dbase, walletRepo, config.ReplicaMaxLag, logger)
+		go replica.Run(ctx, config.ReplicaCheckInterval)
+		svc.WithReplica(replica)
+	}
+
+	// Запуск обработки заданий на массовые выплаты; после перезапуска невыполненные строки подхватываются заново
+	payoutRepo := repository.NewApiPayoutRepository(dbase, logger)
+	payoutProcessor := payout.NewProcessor(payoutRepo, svc, logger, payout.Options{
+		Workers:      config.PayoutWorkers,
+		BatchSize:    config.PayoutBatchSize,
+		PollInterval: config.PayoutPollInterval,
+		Lease:        config.PayoutLease,
+	})
+	go payoutProcessor.Run(ctx)
+
+	// Запечатывание записей журнала аудита в цепочку, ее проверка и периодические подписанные
+	// контрольные точки, если задан ключ подписи
+	adminRepo := repository.NewApiAdminRepository(repo, logger)
+	go audit.NewSealer(adminRepo, logger, config.AuditSealInterval).Run(ctx)
+	adminSvc, err := newAdminService(adminRepo, walletRepo, config, logger)
+	if err != nil {
+		logger.Fatalf("Ошибка настройки журнала аудита: %v", err)
+	}
+	if config.AuditSigningKey != "" {
+		go audit.NewCheckpointer(adminSvc, logger, config.AuditCheckpointInterval).Run(ctx)
+	}
+
+	// Периодические снимки балансов для запросов баланса на момент времени
+	reportRepo := repository.NewApiReportRepository(dbase, logger)
+	if config.BalanceSnapshotInterval > 0 {
+		go report.NewSnapshotter(reportRepo, logger, config.BalanceSnapshotInterval).Run(ctx)
+	}
+
+	// Дневные отчеты по кошелькам за каждые окончившиеся сутки UTC
+	if config.DailyReports {
+		go report.NewEndOfDay(reportRepo, logger).Run(ctx)
+	}
+
+	// Плановая сверка балансов всех кошельков с журналом операций
+	if config.ReconcileInterval > 0 {
+		go reconciliation.NewJob(adminSvc, logger, config.ReconcileInterval).Run(ctx)
+	}
 
 	// Настройка обработчиков API для обработки запросов
-	handler := handler.NewApiWalletHandler(svc, logger)
+	handlers := routes.Handlers{
+		Wallet:         handler.NewApiWalletHandler(svc, logger),
+		Stream:         handler.NewApiStreamHandler(svc, hub, logger, config.StreamHeartbeat, config.StreamMaxPerClient),
+		Webhook:        handler.NewApiWebhookHandler(service.NewApiWebhookService(webhookRepo, logger), logger),
+		Payout:         handler.NewApiPayoutHandler(service.NewApiPayoutService(payoutRepo, logger), logger),
+		Audit:          handler.NewApiAuditHandler(adminSvc, logger),
+		Reconciliation: handler.NewApiReconciliationHandler(adminSvc, logger),
+		Report:         handler.NewApiReportHandler(service.NewApiReportService(reportRepo, logger), logger),
+	}
+
+	serve(config, handlers, svc, hub, logger)
+}
+
+// runLocal запускает приложение на одном узле без PostgreSQL. С хранилищем sqlite кошельки хранятся в файле
+// SQLITE_PATH, с хранилищем memory кошельки из MEMORY_WALLETS хранятся в памяти процесса и теряются при остановке.
+// Операции передаются потокам напрямую из репозитория. Вебхуки, массовые выплаты и публикация событий
+// в этих режимах недоступны
+func runLocal(config *config.Config, logger *logrus.Logger) {
+	hub := stream.NewHub()
+
+	var repo repository.WalletRepository
+	switch config.Storage {
+	case "sqlite":
+		sqliteDB, err := db.OpenSQLite(config.SQLitePath)
+		if err != nil {
+			logger.Fatalf("Ошибка открытия базы SQLite %s: %v", config.SQLitePath, err)
+		}
+		defer sqliteDB.Close()
+		m, err := migrator.NewSQLite(sqliteDB)
+		if err != nil {
+			logger.Fatalf("Ошибка подготовки миграций: %v", err)
+		}
+		if err := migrateOnStartup(m, config.DBAutoMigrate, logger); err != nil {
+			logger.Fatalf("Ошибка выполнения миграций: %v", err)
+		}
+		repo = repository.NewSQLiteWalletRepository(sqliteDB, logger).WithNotify(hub.Publish)
+	case "memory":
+		memoryRepo := repository.NewMemoryWalletRepository(logger).WithNotify(hub.Publish)
+		for _, walletID := range config.MemoryWallets {
+			memoryRepo.CreateWallet(walletID, 0)
+		}
+		logger.Warnf("Хранилище в памяти: %d кошельков, данные не сохраняются между запусками", len(config.MemoryWallets))
+		repo = memoryRepo
+	default:
+		logger.Fatalf("Неизвестное хранилище кошельков %q", config.Storage)
+	}
+
+	svc := service.NewApiWalletService(repo, logger)
+	handlers := routes.Handlers{
+		Wallet: handler.NewApiWalletHandler(svc, logger),
+		Stream: handler.NewApiStreamHandler(svc, hub, logger, config.StreamHeartbeat, config.StreamMaxPerClient),
+	}
+	serve(config, handlers, svc, hub, logger)
+}
+
+// serve запускает HTTP- и gRPC-серверы и останавливает их по сигналу завершения или ошибке любого из них
+func serve(config *config.Config, handlers routes.Handlers, svc service.WalletService, hub *stream.Hub, logger *logrus.Logger) {
+	// Инициализация приложения Fiber для маршрутизации; лимиты тела запроса задают маршруты
+	app := fiber.New(routes.AppConfig())
+
+	// Регистрация маршрутов API в приложении; большое тело принимает только загрузка файла выплат
+	handlers.PayoutMaxUploadSize = config.PayoutMaxUploadSize
+	routes.SetupRoutes(app, handlers, config.AdminAPIToken)
+
+	// Инициализация gRPC-сервера поверх того же сервисного уровня
+	grpcServer := grpcserver.NewServer(grpcserver.NewWalletServer(svc, hub, logger))
+	grpcListener, err := net.Listen("tcp", config.GRPCPort)
+	if err != nil {
+		logger.Fatalf("Ошибка открытия порта gRPC %s: %v", config.GRPCPort, err)
+	}
+
+	// Запуск HTTP- и gRPC-серверов; остановка любого из них или сигнал завершения останавливают оба
+	serveErr := make(chan error, 2)
+	go func() {
+		logger.Infof("Запуск сервера на порту %s", config.Port)
+		serveErr <- app.Listen(config.Port)
+	}()
+	go func() {
+		logger.Infof("Запуск gRPC-сервера на порту %s", config.GRPCPort)
+		serveErr <- grpcServer.Serve(grpcListener)
+	}()
 
-	// Инициализация приложения Fiber для маршрутизации
-	app := fiber.New()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case sig := <-signals:
+		logger.Infof("Получен сигнал %s, завершение работы", sig)
+	case err := <-serveErr:
+		logger.Errorf("Ошибка работы сервера: %v", err)
+	}
 
-	// Регистрация маршрутов API в приложении
-	routes.SetupRoutes(app, handler)
+	shutdown(app, grpcServer, hub, logger)
+}
+
+// newAdminService создает сервис операций операторов. С ключом AUDIT_SIGNING_KEY сервис подписывает
+// контрольные точки журнала аудита и проверяет по ним подписи, с RECONCILE_EVENTS публикует расхождения сверки
+func newAdminService(adminRepo repository.AdminRepository, wallets repository.WalletRepository, config *config.Config, logger *logrus.Logger) (*service.ApiAdminService, error) {
+	svc := service.NewApiAdminService(adminRepo, wallets, logger)
+	if config.ReconcileEvents {
+		svc.WithDiscrepancyEvents()
+	}
+	if config.AuditSigningKey == "" {
+		return svc, nil
+	}
+	signer, err := audit.NewSigner(config.AuditSigningKey)
+	if err != nil {
+		return nil, err
+	}
+	return svc.WithCheckpointSigner(signer), nil
+}
 
-	// Запуск сервера на указанном порту из конфигурации
-	logger.Infof("Запуск сервера на порту %s", config.Port)
-	if err := app.Listen(config.Port); err != nil {
-		logger.Fatalf("Ошибка запуска сервера: %v", err)
+// newWalletCache создает общий кэш в Redis, если он настроен, иначе LRU в памяти процесса
+func newWalletCache(config *config.Config) (cache.Cache, error) {
+	if config.CacheRedisURL == "" {
+		return cache.NewLRU(config.CacheSize, config.CacheTTL), nil
+	}
+	options, err := redis.ParseURL(config.CacheRedisURL)
+	if err != nil {
+		return nil, err
+	}
+	return cache.NewRedis(redis.NewClient(options), "wallet:", config.CacheTTL), nil
+}
+
+// shutdownTimeout ограничивает время на завершение активных запросов при остановке
+const shutdownTimeout = 10 * time.Second
+
+// shutdown останавливает оба сервера, давая активным запросам завершиться
+func shutdown(app *fiber.App, grpcServer *grpc.Server, hub *stream.Hub, logger *logrus.Logger) {
+	// Потоки SSE и WatchBalance бесконечны, поэтому завершаем подписки заранее
+	hub.Reset()
+
+	if err := app.ShutdownWithTimeout(shutdownTimeout); err != nil {
+		logger.Errorf("Ошибка остановки HTTP-сервера: %v", err)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		grpcServer.Stop()
 	}
+	logger.Info("Серверы остановлены")
 }
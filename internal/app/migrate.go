@@ -0,0 +1,173 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/VadimBorzenkov/WalletAPI/config"
+	"github.com/VadimBorzenkov/WalletAPI/internal/db"
+	"github.com/VadimBorzenkov/WalletAPI/pkg/logger"
+	"github.com/VadimBorzenkov/WalletAPI/pkg/migrator"
+	"github.com/sirupsen/logrus"
+)
+
+const migrateUsage = `Usage: wallet-api migrate <command>
+
+Commands:
+  up         apply all pending migrations
+  down N     roll back the last N applied migrations
+  status     show the schema version and pending migrations
+  force V    set the schema version to V without running migrations (V = -1 for none)
+`
+
+// Migrate выполняет команду управления миграциями хранилища из конфигурации (postgres или sqlite)
+// и возвращает код завершения процесса
+func Migrate(args []string) int {
+	logger := logger.InitLogger()
+
+	run, err := parseMigrateCommand(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n\n%s", err, migrateUsage)
+		return 2
+	}
+
+	config, err := config.LoadConfig()
+	if err != nil {
+		logger.Errorf("Ошибка загрузки конфигурации: %v", err)
+		return 1
+	}
+
+	m, closeDB, err := openMigrator(config, logger)
+	if err != nil {
+		logger.Errorf("Ошибка подключения к базе данных: %v", err)
+		return 1
+	}
+	defer closeDB()
+	defer m.Close()
+
+	if err := run(m, os.Stdout); err != nil {
+		logger.Errorf("Ошибка выполнения миграций: %v", err)
+		return 1
+	}
+	return 0
+}
+
+// parseMigrateCommand разбирает аргументы команды migrate и возвращает ее выполнение.
+// Изменяющие команды после выполнения выводят состояние миграций
+func parseMigrateCommand(args []string) (func(m *migrator.Migrator, out io.Writer) error, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("missing command")
+	}
+
+	switch {
+	case args[0] == "status" && len(args) == 1:
+		return printStatus, nil
+	case args[0] == "up" && len(args) == 1:
+		return thenStatus(func(m *migrator.Migrator) error { return m.Up() }), nil
+	case args[0] == "down" && len(args) == 2:
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("down: N must be a positive number, got %q", args[1])
+		}
+		return thenStatus(func(m *migrator.Migrator) error { return m.Down(n) }), nil
+	case args[0] == "force" && len(args) == 2:
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < -1 {
+			return nil, fmt.Errorf("force: V must be a version number or -1, got %q", args[1])
+		}
+		return thenStatus(func(m *migrator.Migrator) error { return m.Force(version) }), nil
+	}
+	return nil, fmt.Errorf("invalid command %q", strings.Join(args, " "))
+}
+
+// thenStatus выполняет команду и выводит состояние миграций после нее
+func thenStatus(command func(m *migrator.Migrator) error) func(m *migrator.Migrator, out io.Writer) error {
+	return func(m *migrator.Migrator, out io.Writer) error {
+		if err := command(m); err != nil {
+			return err
+		}
+		return printStatus(m, out)
+	}
+}
+
+// printStatus выводит версию схемы и список миграций с отметкой о применении
+func printStatus(m *migrator.Migrator, out io.Writer) error {
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+
+	version := "none"
+	if status.Version > 0 {
+		version = strconv.FormatUint(uint64(status.Version), 10)
+	}
+	if status.Dirty {
+		version += " (dirty: fix the schema and run force)"
+	}
+	fmt.Fprintf(out, "Schema version: %s\nPending migrations: %d\n\n", version, status.Pending())
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, migration := range status.Migrations {
+		state := "pending"
+		if migration.Applied {
+			state = "applied"
+		}
+		fmt.Fprintf(w, "%06d\t%s\t%s\n", migration.Version, migration.Name, state)
+	}
+	return w.Flush()
+}
+
+// openMigrator подключается к хранилищу из конфигурации и создает для него мигратор.
+// Возвращает функцию, закрывающую подключение
+func openMigrator(config *config.Config, logger *logrus.Logger) (*migrator.Migrator, func(), error) {
+	switch config.Storage {
+	case "postgres":
+		pool, err := db.Init(config, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		m, err := migrator.NewPostgres(pool)
+		if err != nil {
+			pool.Close()
+			return nil, nil, err
+		}
+		return m, pool.Close, nil
+	case "sqlite":
+		sqliteDB, err := db.OpenSQLite(config.SQLitePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		m, err := migrator.NewSQLite(sqliteDB)
+		if err != nil {
+			sqliteDB.Close()
+			return nil, nil, err
+		}
+		return m, func() { sqliteDB.Close() }, nil
+	}
+	return nil, nil, fmt.Errorf("storage %q has no migrations", config.Storage)
+}
+
+// migrateOnStartup применяет миграции при запуске, если это разрешено DB_AUTO_MIGRATE. Иначе только проверяет,
+// что схема не отстает от исполняемого файла: миграции применяются отдельно командой migrate up
+func migrateOnStartup(m *migrator.Migrator, autoMigrate bool, logger *logrus.Logger) error {
+	defer m.Close()
+	if autoMigrate {
+		return m.Up()
+	}
+
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	if status.Dirty {
+		return fmt.Errorf("schema version %d is dirty, fix the schema and run migrate force", status.Version)
+	}
+	if pending := status.Pending(); pending > 0 {
+		logger.Warnf("Автоматические миграции отключены, не применено миграций: %d. Выполните migrate up", pending)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/VadimBorzenkov/WalletAPI/config"
+	"github.com/VadimBorzenkov/WalletAPI/internal/db"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service"
+	"github.com/VadimBorzenkov/WalletAPI/internal/walletctl"
+	"github.com/VadimBorzenkov/WalletAPI/pkg/logger"
+	"github.com/VadimBorzenkov/WalletAPI/pkg/migrator"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Walletctl выполняет команду утилиты операторов walletctl и возвращает код завершения процесса:
+// 2 — неверные аргументы, 1 — ошибка выполнения, расхождения при сверке или нарушение цепочки журнала аудита
+func Walletctl(args []string) int {
+	logger := logger.InitLogger()
+	// Журнал утилиты не смешивается с ее выводом: без явного LOG_LEVEL выводятся только предупреждения и ошибки
+	if os.Getenv("LOG_LEVEL") == "" {
+		logger.SetLevel(logrus.WarnLevel)
+	}
+
+	err := walletctl.Run(args, os.Stdout, func() (service.AdminService, func(), error) {
+		return connectAdmin(logger)
+	})
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, walletctl.ErrUsage):
+		fmt.Fprintf(os.Stderr, "%v\n\n%s", err, walletctl.Usage())
+		return 2
+	default:
+		fmt.Fprintf(os.Stderr, "walletctl: %v\n", err)
+		return 1
+	}
+}
+
+// connectAdmin подключается к PostgreSQL из конфигурации и создает сервис операций операторов.
+// Схема должна быть актуальной: утилита не применяет миграции
+func connectAdmin(logger *logrus.Logger) (service.AdminService, func(), error) {
+	config, err := config.LoadConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("load config: %w", err)
+	}
+	if config.Storage != "postgres" {
+		return nil, nil, fmt.Errorf("walletctl requires postgres storage, got %q", config.Storage)
+	}
+
+	dbase, err := db.Init(config, logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connect to database: %w", err)
+	}
+	m, err := migrator.NewPostgres(dbase)
+	if err != nil {
+		dbase.Close()
+		return nil, nil, err
+	}
+	status, err := m.Status()
+	m.Close()
+	if err != nil {
+		dbase.Close()
+		return nil, nil, err
+	}
+	if status.Dirty || status.Pending() > 0 {
+		dbase.Close()
+		return nil, nil, fmt.Errorf("database schema is not up to date (version %d, %d pending), run wallet-api migrate up", status.Version, status.Pending())
+	}
+
+	// Действия одного запуска утилиты связаны в журнале аудита общим ID запроса
+	wallets := repository.NewApiWalletRepository(dbase, logger).WithHotWallets(config.HotWallets)
+	adminRepo := repository.NewApiAdminRepository(wallets, logger).WithRequestID("walletctl:" + uuid.NewString())
+	svc, err := newAdminService(adminRepo, wallets, config, logger)
+	if err != nil {
+		dbase.Close()
+		return nil, nil, fmt.Errorf("configure audit checkpoints: %w", err)
+	}
+	return svc, dbase.Close, nil
+}
@@ -0,0 +1,248 @@
+package audit
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSeed = base64.StdEncoding.EncodeToString(make([]byte, ed25519.SeedSize))
+
+// chain строит цепочку из n записей
+func chain(t *testing.T, n int) []models.AuditEntry {
+	t.Helper()
+	entries := make([]models.AuditEntry, n)
+	prev := ""
+	now := time.Date(2024, 5, 1, 10, 0, 0, 123456789, time.UTC)
+	for i := range entries {
+		before, after := float64(i)*10, float64(i+1)*10
+		entries[i] = models.AuditEntry{
+			ID:            int64(100 + i),
+			Actor:         "api:127.0.0.1",
+			Action:        models.AuditOperationApplied,
+			WalletID:      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+			RequestID:     "req-" + string(rune('a'+i)),
+			OperationID:   int64(i + 1),
+			BalanceBefore: &before,
+			BalanceAfter:  &after,
+			Details:       map[string]interface{}{"operationType": models.OperationDeposit, "amount": 10, "at": now},
+			CreatedAt:     now.Add(time.Duration(i) * time.Second),
+		}
+		require.NoError(t, Seal(&entries[i], int64(i+1), prev))
+		prev = entries[i].Hash
+	}
+	return entries
+}
+
+// verify проверяет записи и голову цепочки
+func verify(entries []models.AuditEntry, checkpoints []models.AuditCheckpoint, key ed25519.PublicKey, headSeq int64, headHash string) models.AuditVerification {
+	v := NewVerifier(checkpoints, key)
+	for _, entry := range entries {
+		if !v.Add(entry) {
+			break
+		}
+	}
+	return v.Finish(headSeq, headHash)
+}
+
+// TestSeal проверяет, что хэш записи не меняется после чтения из базы и зависит от содержимого
+func TestSeal(t *testing.T) {
+	entries := chain(t, 2)
+	assert.Equal(t, "", entries[0].PrevHash)
+	assert.Equal(t, entries[0].Hash, entries[1].PrevHash)
+	assert.Len(t, entries[0].Hash, 64)
+	assert.Equal(t, 123456000, entries[0].CreatedAt.Nanosecond())
+
+	// Запись, прочитанная из базы: время в другом часовом поясе, числа в деталях — float64
+	stored := entries[0]
+	stored.CreatedAt = stored.CreatedAt.In(time.FixedZone("MSK", 3*3600))
+	stored.Details = map[string]interface{}{"operationType": "DEPOSIT", "amount": 10.0, "at": "2024-05-01T10:00:00.123456789Z"}
+	hash, err := Hash(stored)
+	require.NoError(t, err)
+	assert.Equal(t, entries[0].Hash, hash)
+
+	after := 10.01
+	stored.BalanceAfter = &after
+	hash, err = Hash(stored)
+	require.NoError(t, err)
+	assert.NotEqual(t, entries[0].Hash, hash)
+}
+
+// TestVerifier проверяет обнаружение первого нарушения цепочки
+func TestVerifier(t *testing.T) {
+	signer, err := NewSigner(testSeed)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string                                                                            // Название теста
+		tamper        func(entries []models.AuditEntry) ([]models.AuditEntry, []models.AuditCheckpoint) // Изменение журнала
+		expectedBreak int64                                                                             // Номер записи, на которой нарушена цепочка; 0 — цепочка цела
+		expectedText  string                                                                            // Фрагмент описания нарушения
+	}{
+		{
+			name: "Intact Chain",
+			tamper: func(entries []models.AuditEntry) ([]models.AuditEntry, []models.AuditCheckpoint) {
+				return entries, nil
+			},
+		},
+		{
+			name: "Edited Balance",
+			tamper: func(entries []models.AuditEntry) ([]models.AuditEntry, []models.AuditCheckpoint) {
+				after := 1000.0
+				entries[2].BalanceAfter = &after
+				return entries, nil
+			},
+			expectedBreak: 3,
+			expectedText:  "content does not match",
+		},
+		{
+			name: "Deleted Entry",
+			tamper: func(entries []models.AuditEntry) ([]models.AuditEntry, []models.AuditCheckpoint) {
+				return append(entries[:1], entries[2:]...), nil
+			},
+			expectedBreak: 2,
+			expectedText:  "expected entry 2, found 3",
+		},
+		{
+			name: "Rehashed Entry",
+			tamper: func(entries []models.AuditEntry) ([]models.AuditEntry, []models.AuditCheckpoint) {
+				entries[1].Actor = "someone-else"
+				entries[1].Hash, _ = Hash(entries[1])
+				return entries, nil
+			},
+			expectedBreak: 3,
+			expectedText:  "previous hash",
+		},
+		{
+			name: "Truncated Tail",
+			tamper: func(entries []models.AuditEntry) ([]models.AuditEntry, []models.AuditCheckpoint) {
+				return entries[:3], nil
+			},
+			expectedBreak: 4,
+			expectedText:  "chain head is entry 4",
+		},
+		{
+			name: "Rewritten Chain Behind Checkpoint",
+			tamper: func(entries []models.AuditEntry) ([]models.AuditEntry, []models.AuditCheckpoint) {
+				checkpoint := models.AuditCheckpoint{ID: 1, Seq: 2, Hash: entries[1].Hash}
+				signer.Sign(&checkpoint, time.Now())
+				prev := ""
+				for i := range entries {
+					entries[i].Actor = "rewritten"
+					require.NoError(t, Seal(&entries[i], entries[i].Seq, prev))
+					prev = entries[i].Hash
+				}
+				return entries, []models.AuditCheckpoint{checkpoint}
+			},
+			expectedBreak: 2,
+			expectedText:  "does not match checkpoint 1",
+		},
+		{
+			name: "Forged Checkpoint",
+			tamper: func(entries []models.AuditEntry) ([]models.AuditEntry, []models.AuditCheckpoint) {
+				checkpoint := models.AuditCheckpoint{ID: 1, Seq: 2, Hash: entries[1].Hash}
+				signer.Sign(&checkpoint, time.Now())
+				checkpoint.Seq = 3
+				checkpoint.Hash = entries[2].Hash
+				return entries, []models.AuditCheckpoint{checkpoint}
+			},
+			expectedBreak: 3,
+			expectedText:  "invalid signature",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := chain(t, 4)
+			head := entries[3]
+			tampered, checkpoints := tt.tamper(entries)
+			if tt.name == "Rewritten Chain Behind Checkpoint" {
+				head = tampered[3]
+			}
+
+			result := verify(tampered, checkpoints, signer.PublicKey(), head.Seq, head.Hash)
+			if tt.expectedBreak == 0 {
+				assert.True(t, result.Valid)
+				assert.Nil(t, result.Break)
+				assert.Equal(t, int64(4), result.Checked)
+				return
+			}
+			assert.False(t, result.Valid)
+			require.NotNil(t, result.Break)
+			assert.Equal(t, tt.expectedBreak, result.Break.Seq)
+			assert.True(t, strings.Contains(result.Break.Reason, tt.expectedText), result.Break.Reason)
+		})
+	}
+}
+
+// TestSigner проверяет подпись контрольных точек и ее проверку
+func TestSigner(t *testing.T) {
+	signer, err := NewSigner(testSeed)
+	require.NoError(t, err)
+
+	checkpoint := models.AuditCheckpoint{Seq: 42, Hash: strings.Repeat("ab", 32)}
+	signer.Sign(&checkpoint, time.Now())
+	assert.Equal(t, signer.KeyID(), checkpoint.KeyID)
+	assert.True(t, VerifyCheckpoint(signer.PublicKey(), checkpoint))
+
+	checkpoint.Seq = 43
+	assert.False(t, VerifyCheckpoint(signer.PublicKey(), checkpoint))
+
+	_, err = NewSigner("c2hvcnQ=")
+	assert.Error(t, err)
+}
+
+// fakeSealer запечатывает по limit записей, пока не закончатся pending
+type fakeSealer struct {
+	pending int
+	calls   int
+	err     error
+}
+
+func (f *fakeSealer) SealAudit(limit int) (int, error) {
+	f.calls++
+	if f.err != nil {
+		return 0, f.err
+	}
+	n := min(limit, f.pending)
+	f.pending -= n
+	return n, nil
+}
+
+// TestSealPending проверяет, что записи запечатываются пачками до последней неполной пачки
+func TestSealPending(t *testing.T) {
+	tests := []struct {
+		name    string
+		pending int   // Незапечатанных записей
+		err     error // Ошибка запечатывания
+		sealed  int   // Ожидаемое количество запечатанных записей
+		calls   int   // Ожидаемое количество вызовов SealAudit
+	}{
+		{name: "Nothing Pending", pending: 0, sealed: 0, calls: 1},
+		{name: "Single Batch", pending: 10, sealed: 10, calls: 1},
+		{name: "Full Batches", pending: 2 * SealBatch, sealed: 2 * SealBatch, calls: 3},
+		{name: "Partial Last Batch", pending: SealBatch + 1, sealed: SealBatch + 1, calls: 2},
+		{name: "Error", pending: 10, err: errors.New("db down"), sealed: 0, calls: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sealer := &fakeSealer{pending: tc.pending, err: tc.err}
+			sealed, err := SealPending(sealer)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.sealed, sealed)
+			assert.Equal(t, tc.calls, sealer.calls)
+		})
+	}
+}
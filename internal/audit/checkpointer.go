@@ -0,0 +1,53 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// CheckpointCreator создает подписанную контрольную точку по текущей голове цепочки.
+// Возвращает false, если с предыдущей контрольной точки цепочка не продвинулась
+type CheckpointCreator interface {
+	CreateCheckpoint() (models.AuditCheckpoint, bool, error)
+}
+
+// Checkpointer периодически создает контрольные точки журнала аудита
+type Checkpointer struct {
+	creator  CheckpointCreator
+	logger   *logrus.Logger
+	interval time.Duration
+}
+
+func NewCheckpointer(creator CheckpointCreator, logger *logrus.Logger, interval time.Duration) *Checkpointer {
+	return &Checkpointer{
+		creator:  creator,
+		logger:   logger,
+		interval: interval,
+	}
+}
+
+// Run создает контрольные точки до отмены контекста
+func (c *Checkpointer) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		checkpoint, created, err := c.creator.CreateCheckpoint()
+		if err != nil {
+			c.logger.Errorf("Failed to create audit checkpoint: %v", err)
+			continue
+		}
+		if created {
+			c.logger.Infof("Created audit checkpoint %d at entry %d", checkpoint.ID, checkpoint.Seq)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package audit реализует цепочку хэшей журнала аудита: вычисление хэша записи, проверку цепочки
+// и подписанные контрольные точки
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+)
+
+// record — содержимое записи, по которому вычисляется хэш. Суммы записываются в копейках, время — в UTC
+// с точностью до микросекунды, как его хранит PostgreSQL
+type record struct {
+	Seq           int64           `json:"seq"`
+	PrevHash      string          `json:"prevHash"`
+	Actor         string          `json:"actor"`
+	Action        string          `json:"action"`
+	WalletID      string          `json:"walletId"`
+	RequestID     string          `json:"requestId"`
+	OperationID   int64           `json:"operationId"`
+	BalanceBefore *int64          `json:"balanceBefore"`
+	BalanceAfter  *int64          `json:"balanceAfter"`
+	Details       json.RawMessage `json:"details"`
+	CreatedAt     string          `json:"createdAt"`
+}
+
+// Seal делает запись звеном цепочки после записи с хэшем prevHash: заполняет номер, предыдущий хэш и хэш записи.
+// Время и детали приводятся к виду, в котором они хранятся в базе, чтобы хэш можно было проверить
+func Seal(entry *models.AuditEntry, seq int64, prevHash string) error {
+	details, err := canonicalDetails(entry.Details)
+	if err != nil {
+		return err
+	}
+	entry.Seq = seq
+	entry.PrevHash = prevHash
+	entry.CreatedAt = entry.CreatedAt.UTC().Truncate(time.Microsecond)
+	entry.Details = details
+	entry.Hash, err = Hash(*entry)
+	return err
+}
+
+// Hash вычисляет хэш SHA-256 записи в шестнадцатеричном виде
+func Hash(entry models.AuditEntry) (string, error) {
+	details, err := canonicalDetails(entry.Details)
+	if err != nil {
+		return "", err
+	}
+	rawDetails, err := json.Marshal(details)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(record{
+		Seq:           entry.Seq,
+		PrevHash:      entry.PrevHash,
+		Actor:         entry.Actor,
+		Action:        entry.Action,
+		WalletID:      entry.WalletID,
+		RequestID:     entry.RequestID,
+		OperationID:   entry.OperationID,
+		BalanceBefore: cents(entry.BalanceBefore),
+		BalanceAfter:  cents(entry.BalanceAfter),
+		Details:       rawDetails,
+		CreatedAt:     entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// canonicalDetails возвращает детали в том виде, в каком их вернет JSONB: числа — float64,
+// время — строки RFC 3339. Пустые детали хранятся как пустой объект
+func canonicalDetails(details map[string]interface{}) (map[string]interface{}, error) {
+	canonical := map[string]interface{}{}
+	if len(details) == 0 {
+		return canonical, nil
+	}
+	data, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &canonical); err != nil {
+		return nil, err
+	}
+	return canonical, nil
+}
+
+func cents(amount *float64) *int64 {
+	if amount == nil {
+		return nil
+	}
+	c := int64(math.Round(*amount * 100))
+	return &c
+}
@@ -0,0 +1,67 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SealBatch — сколько записей журнала запечатывается за один вызов AuditSealer
+const SealBatch = 500
+
+// AuditSealer выстраивает в цепочку до limit незапечатанных записей журнала аудита в порядке их ID.
+// Возвращает количество запечатанных записей
+type AuditSealer interface {
+	SealAudit(limit int) (int, error)
+}
+
+// SealPending запечатывает все записи, зафиксированные к моменту вызова, и возвращает их количество
+func SealPending(sealer AuditSealer) (int, error) {
+	var total int
+	for {
+		n, err := sealer.SealAudit(SealBatch)
+		total += n
+		if err != nil || n < SealBatch {
+			return total, err
+		}
+	}
+}
+
+// Sealer периодически запечатывает новые записи журнала аудита
+type Sealer struct {
+	sealer   AuditSealer
+	logger   *logrus.Logger
+	interval time.Duration
+}
+
+func NewSealer(sealer AuditSealer, logger *logrus.Logger, interval time.Duration) *Sealer {
+	return &Sealer{
+		sealer:   sealer,
+		logger:   logger,
+		interval: interval,
+	}
+}
+
+// Run запечатывает записи до отмены контекста
+func (s *Sealer) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		sealed, err := SealPending(s.sealer)
+		if err != nil {
+			s.logger.Errorf("Failed to seal audit log: %v", err)
+			continue
+		}
+		if sealed > 0 {
+			s.logger.Debugf("Sealed %d audit entries", sealed)
+		}
+	}
+}
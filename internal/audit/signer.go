@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+)
+
+// Signer подписывает контрольные точки журнала аудита ключом Ed25519
+type Signer struct {
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+// NewSigner создает подписывающего по seed ключа Ed25519 (32 байта в base64)
+func NewSigner(seed string) (*Signer, error) {
+	raw, err := base64.StdEncoding.DecodeString(seed)
+	if err != nil {
+		return nil, fmt.Errorf("decode signing key: %w", err)
+	}
+	if len(raw) != ed25519.SeedSize {
+		return nil, fmt.Errorf("signing key must be a %d-byte Ed25519 seed, got %d bytes", ed25519.SeedSize, len(raw))
+	}
+	key := ed25519.NewKeyFromSeed(raw)
+	return &Signer{key: key, keyID: KeyID(key.Public().(ed25519.PublicKey))}, nil
+}
+
+// PublicKey возвращает открытый ключ для проверки подписей
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// KeyID возвращает идентификатор ключа, которым подписаны контрольные точки
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// Sign подписывает контрольную точку: заполняет ее время, идентификатор ключа и подпись
+func (s *Signer) Sign(checkpoint *models.AuditCheckpoint, now time.Time) {
+	checkpoint.CreatedAt = now.UTC().Truncate(time.Microsecond)
+	checkpoint.KeyID = s.keyID
+	checkpoint.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, checkpointMessage(*checkpoint)))
+}
+
+// VerifyCheckpoint проверяет подпись контрольной точки открытым ключом
+func VerifyCheckpoint(publicKey ed25519.PublicKey, checkpoint models.AuditCheckpoint) bool {
+	signature, err := base64.StdEncoding.DecodeString(checkpoint.Signature)
+	if err != nil {
+		return false
+	}
+	return checkpoint.KeyID == KeyID(publicKey) && ed25519.Verify(publicKey, checkpointMessage(checkpoint), signature)
+}
+
+// KeyID возвращает идентификатор открытого ключа: первые 8 байт его SHA-256 в шестнадцатеричном виде
+func KeyID(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(publicKey)
+	return hex.EncodeToString(sum[:8])
+}
+
+// checkpointMessage возвращает подписываемое содержимое контрольной точки
+func checkpointMessage(checkpoint models.AuditCheckpoint) []byte {
+	return []byte("wallet-audit-checkpoint\n" + strconv.FormatInt(checkpoint.Seq, 10) + "\n" + checkpoint.Hash + "\n" +
+		checkpoint.CreatedAt.UTC().Format(time.RFC3339Nano) + "\n" + checkpoint.KeyID)
+}
@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+)
+
+// Verifier проходит цепочку журнала аудита по порядку и находит первое нарушение: пропуск или перестановку записей,
+// несовпадение предыдущего хэша, измененное содержимое или расхождение с контрольной точкой
+type Verifier struct {
+	result      models.AuditVerification
+	lastHash    string
+	checkpoints map[int64]models.AuditCheckpoint
+	publicKey   ed25519.PublicKey // Ключ проверки подписей контрольных точек; nil — подписи не проверяются
+}
+
+// NewVerifier создает проверку цепочки, сверяющую записи с контрольными точками checkpoints
+func NewVerifier(checkpoints []models.AuditCheckpoint, publicKey ed25519.PublicKey) *Verifier {
+	v := &Verifier{
+		checkpoints: make(map[int64]models.AuditCheckpoint, len(checkpoints)),
+		publicKey:   publicKey,
+	}
+	for _, checkpoint := range checkpoints {
+		v.checkpoints[checkpoint.Seq] = checkpoint
+	}
+	return v
+}
+
+// Add проверяет очередную запись цепочки. Возвращает false, если цепочка нарушена и дальнейшие записи проверять не нужно
+func (v *Verifier) Add(entry models.AuditEntry) bool {
+	if v.result.Break != nil {
+		return false
+	}
+	seq := v.result.HeadSeq + 1
+	switch {
+	case entry.Seq != seq:
+		return v.fail(seq, entry.ID, fmt.Sprintf("expected entry %d, found %d", seq, entry.Seq))
+	case entry.PrevHash != v.lastHash:
+		return v.fail(seq, entry.ID, "previous hash does not match the preceding entry")
+	}
+	hash, err := Hash(entry)
+	if err != nil {
+		return v.fail(seq, entry.ID, fmt.Sprintf("cannot hash entry: %v", err))
+	}
+	if hash != entry.Hash {
+		return v.fail(seq, entry.ID, "entry content does not match its hash")
+	}
+
+	if checkpoint, ok := v.checkpoints[seq]; ok {
+		if !v.checkCheckpoint(checkpoint, entry.ID, hash) {
+			return false
+		}
+		v.result.Checkpoints++
+	}
+
+	v.result.Checked++
+	v.result.HeadSeq, v.result.HeadHash, v.lastHash = seq, hash, hash
+	return true
+}
+
+// Finish завершает проверку: последняя запись должна совпадать с головой цепочки, а контрольные точки
+// не должны указывать за ее конец, иначе записи в конце цепочки были удалены
+func (v *Verifier) Finish(headSeq int64, headHash string) models.AuditVerification {
+	if v.result.Break == nil {
+		if headSeq != v.result.HeadSeq || headHash != v.result.HeadHash {
+			v.fail(v.result.HeadSeq+1, 0, fmt.Sprintf("chain head is entry %d, but the log ends at entry %d", headSeq, v.result.HeadSeq))
+		}
+	}
+	if v.result.Break == nil {
+		for seq := range v.checkpoints {
+			if seq > v.result.HeadSeq {
+				v.fail(v.result.HeadSeq+1, 0, fmt.Sprintf("checkpoint at entry %d is beyond the end of the log", seq))
+				break
+			}
+		}
+	}
+	v.result.Valid = v.result.Break == nil
+	return v.result
+}
+
+func (v *Verifier) checkCheckpoint(checkpoint models.AuditCheckpoint, auditID int64, hash string) bool {
+	if v.publicKey != nil && !VerifyCheckpoint(v.publicKey, checkpoint) {
+		return v.fail(checkpoint.Seq, auditID, fmt.Sprintf("checkpoint %d has an invalid signature", checkpoint.ID))
+	}
+	if checkpoint.Hash != hash {
+		return v.fail(checkpoint.Seq, auditID, fmt.Sprintf("entry hash does not match checkpoint %d", checkpoint.ID))
+	}
+	return true
+}
+
+func (v *Verifier) fail(seq, auditID int64, reason string) bool {
+	v.result.Break = &models.AuditChainBreak{Seq: seq, AuditID: auditID, Reason: reason}
+	return false
+}
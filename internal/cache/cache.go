@@ -0,0 +1,20 @@
+package cache
+
+import (
+	"errors"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+)
+
+// ErrUnavailable сообщает, что общее хранилище кэша недоступно
+var ErrUnavailable = errors.New("cache store unavailable")
+
+// Cache хранит баланс и версию кошельков. Реализации: LRU в памяти процесса и Redis, общий для реплик
+type Cache interface {
+	// Get возвращает кошелек из кэша и признак его наличия
+	Get(walletID string) (models.Wallet, bool, error)
+	Set(wallet models.Wallet) error
+	Delete(walletID string) error
+	// Purge очищает кэш, например после потери уведомлений об изменениях
+	Purge() error
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+)
+
+// LRU — кэш в памяти процесса, вытесняющий давно не использованные кошельки.
+// Записи старше ttl считаются отсутствующими
+type LRU struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	items    map[string]*list.Element
+	order    *list.List // Начало списка — последний использованный кошелек
+	now      func() time.Time
+}
+
+type lruEntry struct {
+	wallet    models.Wallet
+	expiresAt time.Time
+}
+
+func NewLRU(capacity int, ttl time.Duration) *LRU {
+	return &LRU{
+		capacity: capacity,
+		ttl:      ttl,
+		items:    make(map[string]*list.Element, capacity),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+func (c *LRU) Get(walletID string) (models.Wallet, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[strings.ToLower(walletID)]
+	if !ok {
+		return models.Wallet{}, false, nil
+	}
+	entry := element.Value.(*lruEntry)
+	if c.now().After(entry.expiresAt) {
+		c.remove(element)
+		return models.Wallet{}, false, nil
+	}
+	c.order.MoveToFront(element)
+	return entry.wallet, true, nil
+}
+
+func (c *LRU) Set(wallet models.Wallet) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := strings.ToLower(wallet.ID)
+	entry := &lruEntry{wallet: wallet, expiresAt: c.now().Add(c.ttl)}
+	if element, ok := c.items[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return nil
+	}
+	c.items[key] = c.order.PushFront(entry)
+	if c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+func (c *LRU) Delete(walletID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[strings.ToLower(walletID)]; ok {
+		c.remove(element)
+	}
+	return nil
+}
+
+func (c *LRU) Purge() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[string]*list.Element, c.capacity)
+	c.order.Init()
+	return nil
+}
+
+// Len возвращает количество кошельков в кэше
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, strings.ToLower(element.Value.(*lruEntry).wallet.ID))
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLRU_Eviction проверяет вытеснение давно не использованного кошелька при переполнении
+func TestLRU_Eviction(t *testing.T) {
+	c := NewLRU(2, time.Minute)
+	c.Set(models.Wallet{ID: "wallet-1", Balance: 1})
+	c.Set(models.Wallet{ID: "wallet-2", Balance: 2})
+
+	// Чтение делает wallet-1 последним использованным, поэтому вытесняется wallet-2
+	_, ok, _ := c.Get("wallet-1")
+	assert.True(t, ok)
+	c.Set(models.Wallet{ID: "wallet-3", Balance: 3})
+
+	_, ok, _ = c.Get("wallet-2")
+	assert.False(t, ok)
+	wallet, ok, _ := c.Get("WALLET-1")
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, wallet.Balance)
+	assert.Equal(t, 2, c.Len())
+}
+
+// TestLRU_Expiration проверяет, что устаревшие записи не возвращаются
+func TestLRU_Expiration(t *testing.T) {
+	now := time.Now()
+	c := NewLRU(10, time.Minute)
+	c.now = func() time.Time { return now }
+	c.Set(models.Wallet{ID: "wallet-1", Balance: 1})
+
+	now = now.Add(2 * time.Minute)
+	_, ok, _ := c.Get("wallet-1")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+// TestLRU_DeleteAndPurge проверяет удаление одного кошелька и очистку кэша
+func TestLRU_DeleteAndPurge(t *testing.T) {
+	c := NewLRU(10, time.Minute)
+	c.Set(models.Wallet{ID: "wallet-1"})
+	c.Set(models.Wallet{ID: "wallet-2"})
+
+	assert.NoError(t, c.Delete("wallet-1"))
+	_, ok, _ := c.Get("wallet-1")
+	assert.False(t, ok)
+
+	assert.NoError(t, c.Purge())
+	assert.Equal(t, 0, c.Len())
+}
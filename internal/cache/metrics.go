@@ -0,0 +1,27 @@
+package cache
+
+import "expvar"
+
+// Счетчики кэша публикуются через expvar в переменной wallet_cache и доступны по /debug/vars
+var (
+	hits        = new(expvar.Int)
+	misses      = new(expvar.Int)
+	storeErrors = new(expvar.Int)
+)
+
+func init() {
+	metrics := expvar.NewMap("wallet_cache")
+	metrics.Set("hits", hits)
+	metrics.Set("misses", misses)
+	metrics.Set("errors", storeErrors)
+	metrics.Set("hit_ratio", expvar.Func(func() interface{} { return HitRatio() }))
+}
+
+// HitRatio возвращает долю чтений, обслуженных кэшем, с момента запуска
+func HitRatio() float64 {
+	h, m := hits.Value(), misses.Value()
+	if h+m == 0 {
+		return 0
+	}
+	return float64(h) / float64(h+m)
+}
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// Таймаут одного запроса к Redis: медленный кэш не должен задерживать чтение баланса из базы
+const redisTimeout = 100 * time.Millisecond
+
+// Redis — кэш, общий для всех реплик приложения. Ключи кошельков имеют префикс prefix и живут не дольше ttl
+type Redis struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+func NewRedis(client *redis.Client, prefix string, ttl time.Duration) *Redis {
+	return &Redis{
+		client: client,
+		prefix: prefix,
+		ttl:    ttl,
+	}
+}
+
+func (c *Redis) Get(walletID string) (models.Wallet, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	data, err := c.client.Get(ctx, c.key(walletID)).Bytes()
+	if err == redis.Nil {
+		return models.Wallet{}, false, nil
+	}
+	if err != nil {
+		return models.Wallet{}, false, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	var wallet models.Wallet
+	if err := json.Unmarshal(data, &wallet); err != nil {
+		return models.Wallet{}, false, err
+	}
+	return wallet, true, nil
+}
+
+func (c *Redis) Set(wallet models.Wallet) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	data, err := json.Marshal(wallet)
+	if err != nil {
+		return err
+	}
+	if err := c.client.Set(ctx, c.key(wallet.ID), data, c.ttl).Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return nil
+}
+
+func (c *Redis) Delete(walletID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	if err := c.client.Del(ctx, c.key(walletID)).Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return nil
+}
+
+// Purge удаляет все ключи кошельков с префиксом кэша
+func (c *Redis) Purge() error {
+	ctx := context.Background()
+	iter := c.client.Scan(ctx, 0, c.prefix+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		if err := c.client.Del(ctx, iter.Val()).Err(); err != nil {
+			return fmt.Errorf("%w: %v", ErrUnavailable, err)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return nil
+}
+
+func (c *Redis) key(walletID string) string {
+	return c.prefix + strings.ToLower(walletID)
+}
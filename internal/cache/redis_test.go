@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRedis проверяет хранение кошельков в Redis с временем жизни и очистку только своих ключей
+func TestRedis(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	c := NewRedis(client, "wallet:", time.Minute)
+
+	require.NoError(t, c.Set(models.Wallet{ID: "Wallet-1", Balance: 10.5, Version: 3}))
+	wallet, ok, err := c.Get("wallet-1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, models.Wallet{ID: "Wallet-1", Balance: 10.5, Version: 3}, wallet)
+
+	// Запись истекает вместе с ttl
+	server.FastForward(2 * time.Minute)
+	_, ok, err = c.Get("wallet-1")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// Очистка не затрагивает чужие ключи
+	require.NoError(t, c.Set(models.Wallet{ID: "wallet-2"}))
+	server.Set("other", "value")
+	require.NoError(t, c.Purge())
+	assert.False(t, server.Exists("wallet:wallet-2"))
+	assert.True(t, server.Exists("other"))
+}
+
+// TestRedis_Unavailable проверяет, что недоступность Redis возвращается как ErrUnavailable
+func TestRedis_Unavailable(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	c := NewRedis(client, "wallet:", time.Minute)
+	server.Close()
+
+	_, _, err := c.Get("wallet-1")
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.ErrorIs(t, c.Delete("wallet-1"), ErrUnavailable)
+}
@@ -0,0 +1,169 @@
+package cache
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// WalletRepository кэширует баланс и версию кошельков перед другим репозиторием.
+// Изменения в этом процессе инвалидируют кэш сразу после выполнения операции, изменения других реплик —
+// по уведомлениям об операциях: для этого репозиторий подключается к stream.Listener как получатель
+type WalletRepository struct {
+	repository.WalletRepository
+	cache    Cache
+	logger   *logrus.Logger
+	uncached map[string]bool // Кошельки, которые всегда читаются из базы
+
+	state *invalidationState // Общее для копий, созданных WithOrigin
+}
+
+// invalidationState — счетчик инвалидаций: кошелек, прочитанный из базы, попадает в кэш, только если за время чтения
+// не было инвалидаций, иначе в кэш могло бы попасть состояние до параллельной операции.
+// Заполнение кэша выполняется под блокировкой чтения, поэтому инвалидация не может вклиниться между проверкой и записью
+type invalidationState struct {
+	mu            sync.RWMutex
+	invalidations uint64
+}
+
+func NewWalletRepository(repo repository.WalletRepository, cache Cache, logger *logrus.Logger) *WalletRepository {
+	return &WalletRepository{
+		WalletRepository: repo,
+		cache:            cache,
+		logger:           logger,
+		state:            &invalidationState{},
+	}
+}
+
+// WithOrigin возвращает копию репозитория с тем же кэшем, изменения которой записываются
+// в журнал аудита от имени origin
+func (r *WalletRepository) WithOrigin(origin models.Origin) repository.WalletRepository {
+	scoped := *r
+	scoped.WalletRepository = repository.ScopeOrigin(r.WalletRepository, origin)
+	return &scoped
+}
+
+// WithUncachedWallets исключает кошельки из кэширования и возвращает репозиторий. Пополнения горячих кошельков
+// записываются в буфер без уведомлений, поэтому другие реплики не узнали бы о них до переноса буфера
+func (r *WalletRepository) WithUncachedWallets(walletIDs []string) *WalletRepository {
+	r.uncached = make(map[string]bool, len(walletIDs))
+	for _, id := range walletIDs {
+		r.uncached[strings.ToLower(id)] = true
+	}
+	return r
+}
+
+// Получение баланса кошелька из кэша или базы
+func (r *WalletRepository) GetWalletBalance(walletID string) (float64, error) {
+	wallet, err := r.GetWallet(walletID)
+	if err != nil {
+		return 0, err
+	}
+	return wallet.Balance, nil
+}
+
+// Получение баланса и версии кошелька из кэша или базы
+func (r *WalletRepository) GetWallet(walletID string) (models.Wallet, error) {
+	if r.uncached[strings.ToLower(walletID)] {
+		return r.WalletRepository.GetWallet(walletID)
+	}
+
+	wallet, ok, err := r.cache.Get(walletID)
+	if err != nil {
+		storeErrors.Add(1)
+		r.logger.Warnf("Failed to read wallet %s from cache: %v", walletID, err)
+	}
+	if ok {
+		hits.Add(1)
+		return wallet, nil
+	}
+	misses.Add(1)
+
+	r.state.mu.RLock()
+	seen := r.state.invalidations
+	r.state.mu.RUnlock()
+
+	wallet, err = r.WalletRepository.GetWallet(walletID)
+	if err != nil {
+		return models.Wallet{}, err
+	}
+
+	r.state.mu.RLock()
+	defer r.state.mu.RUnlock()
+	if r.state.invalidations == seen {
+		if err := r.cache.Set(wallet); err != nil {
+			storeErrors.Add(1)
+			r.logger.Warnf("Failed to cache wallet %s: %v", walletID, err)
+		}
+	}
+	return wallet, nil
+}
+
+func (r *WalletRepository) Deposit(walletID string, amount float64) error {
+	defer r.Invalidate(walletID)
+	return r.WalletRepository.Deposit(walletID, amount)
+}
+
+func (r *WalletRepository) Withdraw(walletID string, amount float64) error {
+	defer r.Invalidate(walletID)
+	return r.WalletRepository.Withdraw(walletID, amount)
+}
+
+func (r *WalletRepository) DepositIfVersion(walletID string, amount float64, version int64) (int64, error) {
+	defer r.Invalidate(walletID)
+	return r.WalletRepository.DepositIfVersion(walletID, amount, version)
+}
+
+func (r *WalletRepository) WithdrawIfVersion(walletID string, amount float64, version int64) (int64, error) {
+	defer r.Invalidate(walletID)
+	return r.WalletRepository.WithdrawIfVersion(walletID, amount, version)
+}
+
+func (r *WalletRepository) DepositIdempotent(walletID string, amount float64, key string) (bool, error) {
+	defer r.Invalidate(walletID)
+	return r.WalletRepository.DepositIdempotent(walletID, amount, key)
+}
+
+func (r *WalletRepository) ApplyBatch(items []models.BatchItem, atomic bool) ([]models.BatchItemResult, error) {
+	defer func() {
+		for _, item := range items {
+			r.Invalidate(item.WalletID)
+			if item.ToWalletID != "" {
+				r.Invalidate(item.ToWalletID)
+			}
+		}
+	}()
+	return r.WalletRepository.ApplyBatch(items, atomic)
+}
+
+// Invalidate удаляет кошелек из кэша
+func (r *WalletRepository) Invalidate(walletID string) {
+	r.state.mu.Lock()
+	r.state.invalidations++
+	r.state.mu.Unlock()
+
+	if err := r.cache.Delete(walletID); err != nil {
+		storeErrors.Add(1)
+		r.logger.Errorf("Failed to invalidate cached wallet %s: %v", walletID, err)
+	}
+}
+
+// Publish инвалидирует кошелек операции из уведомления, полученного stream.Listener
+func (r *WalletRepository) Publish(op models.Operation) {
+	r.Invalidate(op.WalletID)
+}
+
+// Reset очищает кэш после переподключения stream.Listener: уведомления за время разрыва потеряны
+func (r *WalletRepository) Reset() {
+	r.state.mu.Lock()
+	r.state.invalidations++
+	r.state.mu.Unlock()
+
+	if err := r.cache.Purge(); err != nil {
+		storeErrors.Add(1)
+		r.logger.Errorf("Failed to purge wallet cache: %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package cache
+
+import (
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+// memoryWalletRepository хранит кошельки в памяти. Чтение выполняется с задержкой, чтобы операции
+// успевали выполниться между чтением из базы и записью результата в кэш
+type memoryWalletRepository struct {
+	repository.WalletRepository
+	mu      sync.Mutex
+	wallets map[string]models.Wallet
+}
+
+func (r *memoryWalletRepository) GetWallet(walletID string) (models.Wallet, error) {
+	r.mu.Lock()
+	wallet, ok := r.wallets[walletID]
+	r.mu.Unlock()
+	time.Sleep(100 * time.Microsecond)
+	if !ok {
+		return models.Wallet{}, repository.ErrWalletNotFound
+	}
+	return wallet, nil
+}
+
+func (r *memoryWalletRepository) Deposit(walletID string, amount float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	wallet := r.wallets[walletID]
+	wallet.Balance += amount
+	wallet.Version++
+	r.wallets[walletID] = wallet
+	return nil
+}
+
+// TestWalletRepository_Cache проверяет попадания в кэш и инвалидацию при пополнении
+func TestWalletRepository_Cache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockWalletRepository(ctrl)
+	repo := NewWalletRepository(mockRepo, NewLRU(10, time.Minute), newTestLogger())
+
+	// Повторное чтение обслуживается кэшем, после пополнения кошелек читается из базы заново
+	gomock.InOrder(
+		mockRepo.EXPECT().GetWallet("wallet-1").Return(models.Wallet{ID: "wallet-1", Balance: 10, Version: 1}, nil),
+		mockRepo.EXPECT().Deposit("wallet-1", 5.0).Return(nil),
+		mockRepo.EXPECT().GetWallet("wallet-1").Return(models.Wallet{ID: "wallet-1", Balance: 15, Version: 2}, nil),
+	)
+
+	hitsBefore, missesBefore := hits.Value(), misses.Value()
+	for i := 0; i < 3; i++ {
+		balance, err := repo.GetWalletBalance("wallet-1")
+		require.NoError(t, err)
+		assert.Equal(t, 10.0, balance)
+	}
+	require.NoError(t, repo.Deposit("wallet-1", 5))
+	wallet, err := repo.GetWallet("wallet-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), wallet.Version)
+
+	assert.Equal(t, int64(2), hits.Value()-hitsBefore)
+	assert.Equal(t, int64(2), misses.Value()-missesBefore)
+}
+
+// TestWalletRepository_Uncached проверяет, что исключенные кошельки всегда читаются из базы
+func TestWalletRepository_Uncached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockWalletRepository(ctrl)
+	repo := NewWalletRepository(mockRepo, NewLRU(10, time.Minute), newTestLogger()).WithUncachedWallets([]string{"HOT-WALLET"})
+
+	mockRepo.EXPECT().GetWallet("hot-wallet").Return(models.Wallet{ID: "hot-wallet", Balance: 1}, nil).Times(2)
+	for i := 0; i < 2; i++ {
+		_, err := repo.GetWallet("hot-wallet")
+		assert.NoError(t, err)
+	}
+}
+
+// scopedWalletRepository запоминает источник, от имени которого создана копия репозитория
+type scopedWalletRepository struct {
+	*mock.MockWalletRepository
+	origin models.Origin
+}
+
+func (r *scopedWalletRepository) WithOrigin(origin models.Origin) repository.WalletRepository {
+	return &scopedWalletRepository{MockWalletRepository: r.MockWalletRepository, origin: origin}
+}
+
+// TestWalletRepository_WithOrigin проверяет, что копия с источником изменений передает его репозиторию
+// и инвалидирует общий кэш
+func TestWalletRepository_WithOrigin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockWalletRepository(ctrl)
+	lru := NewLRU(10, time.Minute)
+	repo := NewWalletRepository(&scopedWalletRepository{MockWalletRepository: mockRepo}, lru, newTestLogger())
+
+	mockRepo.EXPECT().GetWallet("wallet-1").Return(models.Wallet{ID: "wallet-1", Balance: 10, Version: 1}, nil)
+	mockRepo.EXPECT().Deposit("wallet-1", 5.0).Return(nil)
+	_, err := repo.GetWallet("wallet-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, lru.Len())
+
+	origin := models.Origin{Actor: "api:10.0.0.1", RequestID: "req-1"}
+	scoped := repository.ScopeOrigin(repo, origin).(*WalletRepository)
+	assert.Equal(t, origin, scoped.WalletRepository.(*scopedWalletRepository).origin)
+	require.NoError(t, scoped.Deposit("wallet-1", 5))
+	assert.Equal(t, 0, lru.Len())
+}
+
+// TestWalletRepository_RemoteInvalidation проверяет инвалидацию по уведомлению об операции другой реплики
+// и очистку кэша после потери уведомлений
+func TestWalletRepository_RemoteInvalidation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockWalletRepository(ctrl)
+	lru := NewLRU(10, time.Minute)
+	repo := NewWalletRepository(mockRepo, lru, newTestLogger())
+
+	mockRepo.EXPECT().GetWallet("wallet-1").Return(models.Wallet{ID: "wallet-1", Balance: 10, Version: 1}, nil).Times(2)
+	mockRepo.EXPECT().GetWallet("wallet-2").Return(models.Wallet{ID: "wallet-2", Balance: 20, Version: 1}, nil)
+
+	repo.GetWallet("wallet-1")
+	repo.Publish(models.Operation{WalletID: "wallet-1", Type: models.OperationDeposit, Amount: 1, Version: 2})
+	repo.GetWallet("wallet-1")
+
+	repo.GetWallet("wallet-2")
+	assert.Equal(t, 2, lru.Len())
+	repo.Reset()
+	assert.Equal(t, 0, lru.Len())
+}
+
+// TestWalletRepository_Consistency проверяет, что при параллельных чтениях и пополнениях чтение,
+// начатое после завершения пополнения, всегда видит его результат
+func TestWalletRepository_Consistency(t *testing.T) {
+	const (
+		writers  = 4
+		deposits = 200
+		readers  = 8
+	)
+	store := &memoryWalletRepository{wallets: map[string]models.Wallet{"wallet-1": {ID: "wallet-1"}}}
+	repo := NewWalletRepository(store, NewLRU(10, time.Minute), newTestLogger())
+
+	var (
+		mu        sync.Mutex
+		committed int64 // Версия, до которой пополнения гарантированно завершены
+		wg        sync.WaitGroup
+		done      = make(chan struct{})
+	)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < deposits; i++ {
+				require.NoError(t, repo.Deposit("wallet-1", 1))
+				mu.Lock()
+				committed++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var readersWG sync.WaitGroup
+	for r := 0; r < readers; r++ {
+		readersWG.Add(1)
+		go func() {
+			defer readersWG.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				mu.Lock()
+				floor := committed
+				mu.Unlock()
+
+				wallet, err := repo.GetWallet("wallet-1")
+				require.NoError(t, err)
+				if wallet.Version < floor {
+					t.Errorf("stale read: version %d, at least %d deposits completed before the read", wallet.Version, floor)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(done)
+	readersWG.Wait()
+
+	wallet, err := repo.GetWallet("wallet-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(writers*deposits), wallet.Version)
+	assert.Equal(t, float64(writers*deposits), wallet.Balance)
+}
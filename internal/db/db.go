@@ -1,30 +1,107 @@
 package db
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
-	"log"
+	"strings"
+	"time"
 
 	"github.com/VadimBorzenkov/WalletAPI/config"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
 )
 
-// Init инициализирует подключение к базе данных на основе конфигурации и возвращает объект базы данных.
-func Init(cfg *config.Config) *sql.DB {
-	url := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPass, cfg.DBName)
+// Границы паузы между попытками подключения при запуске
+const (
+	pingBackoffBase = 250 * time.Millisecond
+	pingBackoffMax  = 5 * time.Second
+)
+
+// Init открывает пул подключений к базе данных и ждет ее доступности не дольше DBConnectTimeout.
+// Пока база недоступна, подключение повторяется с экспоненциально растущей паузой
+func Init(cfg *config.Config, logger *logrus.Logger) (*pgxpool.Pool, error) {
+	pool, err := Open(DSN(cfg), cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	db, err := sql.Open("postgres", url)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DBConnectTimeout)
+	defer cancel()
+	if err := waitReady(ctx, pool.Ping, logger, pingBackoffBase, pingBackoffMax); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return pool, nil
+}
 
+// Open создает пул подключений pgx по строке подключения с ограничениями пула из конфигурации.
+// Подключения открываются по мере необходимости, доступность базы не проверяется.
+// Подготовленные выражения кэшируются в каждом подключении пула
+func Open(dsn string, cfg *config.Config) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
-		log.Fatalln(err)
+		return nil, err
+	}
+	if cfg.DBMaxConns > 0 {
+		poolConfig.MaxConns = int32(cfg.DBMaxConns)
+	}
+	poolConfig.MinConns = int32(cfg.DBMinConns)
+	poolConfig.MaxConnLifetime = cfg.DBConnMaxLifetime
+	poolConfig.MaxConnIdleTime = cfg.DBConnMaxIdleTime
+	return pgxpool.NewWithConfig(context.Background(), poolConfig)
+}
+
+// waitReady вызывает ping, пока он не завершится успешно или не истечет контекст
+func waitReady(ctx context.Context, ping func(context.Context) error, logger *logrus.Logger, base, max time.Duration) error {
+	backoff := base
+	for attempt := 1; ; attempt++ {
+		err := ping(ctx)
+		if err == nil {
+			return nil
+		}
+		logger.Warnf("Database is not available (attempt %d), retrying in %s: %v", attempt, backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("database is not available after %d attempts: %w", attempt, err)
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > max {
+			backoff = max
+		}
+	}
+}
+
+// DSN формирует строку подключения к базе данных из конфигурации
+func DSN(cfg *config.Config) string {
+	params := [][2]string{
+		{"host", cfg.DBHost},
+		{"port", cfg.DBPort},
+		{"user", cfg.DBUser},
+		{"password", cfg.DBPass},
+		{"dbname", cfg.DBName},
+		{"sslmode", cfg.DBSSLMode},
+		{"sslrootcert", cfg.DBSSLRootCert},
+		{"sslcert", cfg.DBSSLCert},
+		{"sslkey", cfg.DBSSLKey},
+	}
+	if cfg.DBStatementTimeout > 0 {
+		params = append(params, [2]string{"statement_timeout", fmt.Sprint(cfg.DBStatementTimeout.Milliseconds())})
 	}
 
-	return db
+	var parts []string
+	for _, p := range params {
+		if p[1] != "" {
+			parts = append(parts, p[0]+"="+quote(p[1]))
+		}
+	}
+	return strings.Join(parts, " ")
 }
 
-// Close закрывает соединение с базой данных и возвращает ошибку, если возникла проблема при закрытии.
-func Close(db *sql.DB) error {
-	if err := db.Close(); err != nil {
-		return err
+// quote экранирует значение параметра строки подключения, если в нем есть пробелы, кавычки или обратная косая черта
+func quote(value string) string {
+	if !strings.ContainsAny(value, ` '\`) {
+		return value
 	}
-	return nil
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
 }
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/config"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDSN проверяет, что пустые параметры пропускаются, а значения с пробелами и кавычками экранируются
+func TestDSN(t *testing.T) {
+	cfg := &config.Config{
+		DBHost:             "db",
+		DBPort:             "5432",
+		DBUser:             "wallet",
+		DBPass:             `p@ss word's\`,
+		DBName:             "wallets",
+		DBSSLMode:          "verify-full",
+		DBSSLRootCert:      "/certs/ca.pem",
+		DBStatementTimeout: 30 * time.Second,
+	}
+
+	assert.Equal(t,
+		`host=db port=5432 user=wallet password='p@ss word\'s\\' dbname=wallets sslmode=verify-full sslrootcert=/certs/ca.pem statement_timeout=30000`,
+		DSN(cfg))
+}
+
+// TestWaitReady проверяет повторные попытки подключения с растущей паузой и отказ по истечении срока
+func TestWaitReady(t *testing.T) {
+	tests := []struct {
+		name          string        // Название теста
+		failures      int           // Сколько первых попыток завершаются ошибкой
+		timeout       time.Duration // Срок ожидания базы
+		expectedCalls int           // Ожидаемое количество попыток
+		expectErr     bool          // Ожидается ли ошибка
+	}{
+		{name: "Available Immediately", timeout: time.Second, expectedCalls: 1},
+		{name: "Available After Retries", failures: 3, timeout: time.Second, expectedCalls: 4},
+		{name: "Deadline Exceeded", failures: 1000, timeout: 50 * time.Millisecond, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			var calls int
+			ping := func(context.Context) error {
+				calls++
+				if calls <= tt.failures {
+					return errors.New("connection refused")
+				}
+				return nil
+			}
+
+			err := waitReady(ctx, ping, logrus.New(), time.Millisecond, 10*time.Millisecond)
+			if tt.expectErr {
+				assert.ErrorContains(t, err, "connection refused")
+				assert.Greater(t, calls, 1)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCalls, calls)
+		})
+	}
+}
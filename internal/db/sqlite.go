@@ -0,0 +1,34 @@
+package db
+
+import (
+	"database/sql"
+	"net/url"
+	"strconv"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteBusyTimeout ограничивает ожидание блокировки записи, которую держит другая транзакция
+const sqliteBusyTimeout = 5 * time.Second
+
+// OpenSQLite открывает файл базы SQLite, создавая его при необходимости. Каждая транзакция начинается
+// с BEGIN IMMEDIATE и сразу получает блокировку записи: транзакции с записью выполняются по очереди,
+// и проверка средств не может устареть до изменения баланса. Журнал WAL позволяет читать во время записи
+func OpenSQLite(path string) (*sql.DB, error) {
+	params := url.Values{}
+	params.Set("_txlock", "immediate")
+	params.Set("_journal_mode", "WAL")
+	params.Set("_foreign_keys", "on")
+	params.Set("_busy_timeout", strconv.FormatInt(sqliteBusyTimeout.Milliseconds(), 10))
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
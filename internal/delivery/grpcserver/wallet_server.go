@@ -0,0 +1,265 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service"
+	"github.com/VadimBorzenkov/WalletAPI/internal/stream"
+	"github.com/VadimBorzenkov/WalletAPI/internal/validation"
+	walletv1 "github.com/VadimBorzenkov/WalletAPI/pkg/api/wallet/v1"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Количество операций, дочитываемых из журнала за один запрос при возобновлении наблюдения
+const resumeBatchSize = 500
+
+// WalletServer реализует gRPC API кошельков поверх того же сервисного уровня, что и HTTP API
+type WalletServer struct {
+	walletv1.UnimplementedWalletServiceServer
+
+	walletService service.WalletService
+	hub           *stream.Hub
+	logger        *logrus.Logger
+}
+
+func NewWalletServer(walletService service.WalletService, hub *stream.Hub, logger *logrus.Logger) *WalletServer {
+	return &WalletServer{
+		walletService: walletService,
+		hub:           hub,
+		logger:        logger,
+	}
+}
+
+// NewServer создает gRPC-сервер с зарегистрированным API кошельков и reflection для инструментов вроде grpcurl
+func NewServer(walletServer *WalletServer) *grpc.Server {
+	server := grpc.NewServer()
+	walletv1.RegisterWalletServiceServer(server, walletServer)
+	reflection.Register(server)
+	return server
+}
+
+// ConsistencyMetadata — ключ метаданных вызова, значением eventual которого клиент разрешает читать с реплики
+const ConsistencyMetadata = "consistency"
+
+// eventualRead сообщает, допускает ли клиент чтение с реплики
+func eventualRead(ctx context.Context) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(ConsistencyMetadata)
+	return len(values) > 0 && strings.EqualFold(values[0], "eventual")
+}
+
+// RequestIDMetadata — ключ метаданных вызова с ID запроса, который записывается в журнал аудита вместе с изменениями.
+// Без него ID запроса генерируется
+const RequestIDMetadata = "x-request-id"
+
+// callOrigin возвращает источник изменений вызова для журнала аудита: адрес клиента и ID запроса
+func callOrigin(ctx context.Context) models.Origin {
+	origin := models.Origin{Actor: "grpc"}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		origin.Actor = "grpc:" + p.Addr.String()
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(RequestIDMetadata); len(values) > 0 && values[0] != "" {
+		origin.RequestID = values[0]
+	} else {
+		origin.RequestID = uuid.NewString()
+	}
+	return origin
+}
+
+// service возвращает сервис, изменения которого записываются в журнал аудита от имени вызова
+func (s *WalletServer) service(ctx context.Context) service.WalletService {
+	return service.ScopeOrigin(s.walletService, callOrigin(ctx))
+}
+
+// GetBalance возвращает баланс и версию кошелька. С метаданными consistency: eventual баланс может быть прочитан с реплики
+func (s *WalletServer) GetBalance(ctx context.Context, req *walletv1.GetBalanceRequest) (*walletv1.GetBalanceResponse, error) {
+	if err := validateWalletID(req.GetWalletId()); err != nil {
+		return nil, err
+	}
+
+	getWallet := s.walletService.GetWallet
+	if eventualRead(ctx) {
+		getWallet = s.walletService.GetWalletEventual
+	}
+	wallet, err := getWallet(req.GetWalletId())
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	return &walletv1.GetBalanceResponse{WalletId: wallet.ID, Balance: wallet.Balance, Version: wallet.Version}, nil
+}
+
+// Deposit зачисляет средства, при наличии expected_version — только для указанной версии кошелька
+func (s *WalletServer) Deposit(ctx context.Context, req *walletv1.DepositRequest) (*walletv1.OperationResponse, error) {
+	if err := validateOperation(req.GetWalletId(), req.GetAmount()); err != nil {
+		return nil, err
+	}
+
+	if req.ExpectedVersion != nil {
+		version, err := s.service(ctx).DepositIfVersion(req.GetWalletId(), req.GetAmount(), req.GetExpectedVersion())
+		if err != nil {
+			return nil, s.toStatus(err)
+		}
+		return &walletv1.OperationResponse{Version: version}, nil
+	}
+
+	if err := s.service(ctx).Deposit(req.GetWalletId(), req.GetAmount()); err != nil {
+		return nil, s.toStatus(err)
+	}
+	return &walletv1.OperationResponse{}, nil
+}
+
+// Withdraw списывает средства, при наличии expected_version — только для указанной версии кошелька
+func (s *WalletServer) Withdraw(ctx context.Context, req *walletv1.WithdrawRequest) (*walletv1.OperationResponse, error) {
+	if err := validateOperation(req.GetWalletId(), req.GetAmount()); err != nil {
+		return nil, err
+	}
+
+	if req.ExpectedVersion != nil {
+		version, err := s.service(ctx).WithdrawIfVersion(req.GetWalletId(), req.GetAmount(), req.GetExpectedVersion())
+		if err != nil {
+			return nil, s.toStatus(err)
+		}
+		return &walletv1.OperationResponse{Version: version}, nil
+	}
+
+	if err := s.service(ctx).Withdraw(req.GetWalletId(), req.GetAmount()); err != nil {
+		return nil, s.toStatus(err)
+	}
+	return &walletv1.OperationResponse{}, nil
+}
+
+// WatchBalance передает операции кошелька, начиная с after_operation_id, до отмены вызова клиентом.
+// Если сервер прервал поток, клиент может продолжить его с ID последней полученной операции.
+// С метаданными consistency: eventual пропущенные операции дочитываются в основном с реплики
+func (s *WalletServer) WatchBalance(req *walletv1.WatchBalanceRequest, srv walletv1.WalletService_WatchBalanceServer) error {
+	walletID := req.GetWalletId()
+	if err := validateWalletID(walletID); err != nil {
+		return err
+	}
+
+	// Подписка оформляется до чтения журнала, чтобы не пропустить операции между ними
+	sub := s.hub.Subscribe(walletID)
+	defer sub.Close()
+
+	wallet, err := s.walletService.GetWallet(walletID)
+	if err != nil {
+		return s.toStatus(err)
+	}
+
+	lastID := req.GetAfterOperationId()
+	var baseVersion int64
+	if lastID > 0 {
+		// Журнал читается с реплики, если клиент это допускает, а операции, которые до нее еще не дошли, — с основной базы
+		sources := []func(walletID string, afterID int64, limit int) ([]models.Operation, error){s.walletService.GetOperationsAfter}
+		if eventualRead(srv.Context()) {
+			sources = append([]func(string, int64, int) ([]models.Operation, error){s.walletService.GetOperationsAfterEventual}, sources...)
+		}
+		for _, getOperations := range sources {
+			if lastID, err = s.replay(srv, walletID, lastID, getOperations); err != nil {
+				return err
+			}
+		}
+	} else {
+		// Без after_operation_id клиент получает только операции новее текущей версии
+		baseVersion = wallet.Version
+	}
+
+	for {
+		select {
+		case <-srv.Context().Done():
+			return nil
+		case op, ok := <-sub.Events():
+			if !ok {
+				return status.Error(codes.Unavailable, "watch interrupted, resume from the last received operation")
+			}
+			if op.ID <= lastID || op.Version <= baseVersion {
+				continue
+			}
+			if err := srv.Send(toBalanceUpdate(op)); err != nil {
+				return err
+			}
+			lastID = op.ID
+		}
+	}
+}
+
+// replay отправляет операции журнала после lastID и возвращает ID последней отправленной
+func (s *WalletServer) replay(srv walletv1.WalletService_WatchBalanceServer, walletID string, lastID int64, getOperations func(walletID string, afterID int64, limit int) ([]models.Operation, error)) (int64, error) {
+	for {
+		operations, err := getOperations(walletID, lastID, resumeBatchSize)
+		if err != nil {
+			return lastID, s.toStatus(err)
+		}
+		for _, op := range operations {
+			if err := srv.Send(toBalanceUpdate(op)); err != nil {
+				return lastID, err
+			}
+			lastID = op.ID
+		}
+		if len(operations) < resumeBatchSize {
+			return lastID, nil
+		}
+	}
+}
+
+// validateWalletID проверяет ID кошелька по тем же правилам, что и HTTP API
+func validateWalletID(walletID string) error {
+	if err := validation.UUID(walletID); err != nil {
+		return status.Error(codes.InvalidArgument, "wallet_id "+err.Error())
+	}
+	return nil
+}
+
+// validateOperation проверяет параметры операции до обращения к сервису по тем же правилам, что и HTTP API
+func validateOperation(walletID string, amount float64) error {
+	if err := validateWalletID(walletID); err != nil {
+		return err
+	}
+	if err := validation.Amount(amount); err != nil {
+		return status.Error(codes.InvalidArgument, "amount "+err.Error())
+	}
+	return nil
+}
+
+// toStatus сопоставляет доменные ошибки с кодами gRPC. Текст внутренней ошибки только логируется
+func (s *WalletServer) toStatus(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrWalletNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, repository.ErrInsufficientFunds), errors.Is(err, repository.ErrWalletFrozen):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, repository.ErrVersionMismatch):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, service.ErrInvalidArgument):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		s.logger.Errorf("gRPC request failed: %v", err)
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+// toBalanceUpdate преобразует операцию журнала в сообщение потока
+func toBalanceUpdate(op models.Operation) *walletv1.BalanceUpdate {
+	return &walletv1.BalanceUpdate{
+		OperationId:   op.ID,
+		WalletId:      op.WalletID,
+		OperationType: op.Type,
+		Amount:        op.Amount,
+		Balance:       op.BalanceAfter,
+		Version:       op.Version,
+		OccurredAt:    timestamppb.New(op.CreatedAt),
+	}
+}
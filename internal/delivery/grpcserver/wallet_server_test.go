@@ -0,0 +1,238 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service/mock"
+	"github.com/VadimBorzenkov/WalletAPI/internal/stream"
+	walletv1 "github.com/VadimBorzenkov/WalletAPI/pkg/api/wallet/v1"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	testWalletID    = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	missingWalletID = "9b2d7c1e-0f4a-4e8b-a1c3-5d6e7f809a1b"
+)
+
+// newTestClient запускает gRPC-сервер в памяти и возвращает клиента к нему
+func newTestClient(t *testing.T, svc *mock.MockWalletService, hub *stream.Hub) walletv1.WalletServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewServer(NewWalletServer(svc, hub, logrus.New()))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return walletv1.NewWalletServiceClient(conn)
+}
+
+// TestWalletServer_ErrorCodes проверяет сопоставление доменных ошибок с кодами gRPC
+func TestWalletServer_ErrorCodes(t *testing.T) {
+	tests := []struct {
+		name         string                          // Название теста
+		request      *walletv1.WithdrawRequest       // Запрос на списание
+		mockService  func(s *mock.MockWalletService) // Настройка мок сервиса
+		expectedCode codes.Code                      // Ожидаемый код gRPC
+	}{
+		{
+			name:    "Withdraw Success",
+			request: &walletv1.WithdrawRequest{WalletId: testWalletID, Amount: 50},
+			mockService: func(s *mock.MockWalletService) {
+				s.EXPECT().Withdraw(testWalletID, 50.0).Return(nil)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name:    "Insufficient Funds",
+			request: &walletv1.WithdrawRequest{WalletId: testWalletID, Amount: 500},
+			mockService: func(s *mock.MockWalletService) {
+				s.EXPECT().Withdraw(testWalletID, 500.0).Return(fmt.Errorf("could not withdraw amount: %w", repository.ErrInsufficientFunds))
+			},
+			expectedCode: codes.FailedPrecondition,
+		},
+		{
+			name:    "Wallet Not Found",
+			request: &walletv1.WithdrawRequest{WalletId: missingWalletID, Amount: 50},
+			mockService: func(s *mock.MockWalletService) {
+				s.EXPECT().Withdraw(missingWalletID, 50.0).Return(fmt.Errorf("could not withdraw amount: %w", repository.ErrWalletNotFound))
+			},
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:    "Version Mismatch",
+			request: &walletv1.WithdrawRequest{WalletId: testWalletID, Amount: 50, ExpectedVersion: proto.Int64(3)},
+			mockService: func(s *mock.MockWalletService) {
+				s.EXPECT().WithdrawIfVersion(testWalletID, 50.0, int64(3)).Return(int64(0), fmt.Errorf("could not withdraw amount: %w", repository.ErrVersionMismatch))
+			},
+			expectedCode: codes.Aborted,
+		},
+		{
+			// Внутренняя ошибка не раскрывается клиенту
+			name:    "Internal Error",
+			request: &walletv1.WithdrawRequest{WalletId: testWalletID, Amount: 50},
+			mockService: func(s *mock.MockWalletService) {
+				s.EXPECT().Withdraw(testWalletID, 50.0).Return(fmt.Errorf("could not withdraw amount: dial tcp 10.0.0.5:5432: connection refused"))
+			},
+			expectedCode: codes.Internal,
+		},
+		{
+			name:         "Negative Amount",
+			request:      &walletv1.WithdrawRequest{WalletId: testWalletID, Amount: -5},
+			mockService:  func(s *mock.MockWalletService) {},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "NaN Amount",
+			request:      &walletv1.WithdrawRequest{WalletId: testWalletID, Amount: math.NaN()},
+			mockService:  func(s *mock.MockWalletService) {},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "Positive Infinity Amount",
+			request:      &walletv1.WithdrawRequest{WalletId: testWalletID, Amount: math.Inf(1)},
+			mockService:  func(s *mock.MockWalletService) {},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "Negative Infinity Amount",
+			request:      &walletv1.WithdrawRequest{WalletId: testWalletID, Amount: math.Inf(-1)},
+			mockService:  func(s *mock.MockWalletService) {},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "Too Many Decimal Places",
+			request:      &walletv1.WithdrawRequest{WalletId: testWalletID, Amount: 10.005},
+			mockService:  func(s *mock.MockWalletService) {},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "Amount Above Maximum",
+			request:      &walletv1.WithdrawRequest{WalletId: testWalletID, Amount: 1_000_000_000.01},
+			mockService:  func(s *mock.MockWalletService) {},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "Malformed Wallet ID",
+			request:      &walletv1.WithdrawRequest{WalletId: "wallet-123", Amount: 50},
+			mockService:  func(s *mock.MockWalletService) {},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := mock.NewMockWalletService(ctrl)
+			tt.mockService(svc)
+			client := newTestClient(t, svc, stream.NewHub())
+
+			_, err := client.Withdraw(context.Background(), tt.request)
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			assert.NotContains(t, status.Convert(err).Message(), "10.0.0.5")
+		})
+	}
+}
+
+// TestWalletServer_WatchBalance проверяет возобновление наблюдения из журнала и доставку новых операций
+func TestWalletServer_WatchBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := mock.NewMockWalletService(ctrl)
+	svc.EXPECT().GetWallet(testWalletID).Return(models.Wallet{ID: testWalletID, Balance: 70, Version: 4}, nil)
+	svc.EXPECT().GetOperationsAfter(testWalletID, int64(5), resumeBatchSize).
+		Return([]models.Operation{{ID: 6, WalletID: testWalletID, BalanceAfter: 70, Version: 4}}, nil)
+
+	hub := stream.NewHub()
+	client := newTestClient(t, svc, hub)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	watch, err := client.WatchBalance(ctx, &walletv1.WatchBalanceRequest{WalletId: testWalletID, AfterOperationId: 5})
+	assert.NoError(t, err)
+
+	// Операция из журнала
+	update, err := watch.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), update.GetOperationId())
+
+	// Новая операция из уведомлений
+	hub.Publish(models.Operation{ID: 7, WalletID: testWalletID, BalanceAfter: 60, Version: 5})
+	update, err = watch.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), update.GetOperationId())
+	assert.Equal(t, 60.0, update.GetBalance())
+}
+
+// TestWalletServer_WatchBalanceUppercaseID проверяет, что наблюдение с ID кошелька в верхнем регистре получает
+// операции из уведомлений, в которых Postgres передает ID в нижнем регистре
+func TestWalletServer_WatchBalanceUppercaseID(t *testing.T) {
+	const (
+		requestedID = "3FA85F64-5717-4562-B3FC-2C963F66AFA6"
+		storedID    = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := mock.NewMockWalletService(ctrl)
+	svc.EXPECT().GetWallet(requestedID).Return(models.Wallet{ID: storedID, Balance: 70, Version: 4}, nil)
+	svc.EXPECT().GetOperationsAfter(requestedID, int64(5), resumeBatchSize).
+		Return([]models.Operation{{ID: 6, WalletID: storedID, BalanceAfter: 70, Version: 4}}, nil)
+
+	hub := stream.NewHub()
+	client := newTestClient(t, svc, hub)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	watch, err := client.WatchBalance(ctx, &walletv1.WatchBalanceRequest{WalletId: requestedID, AfterOperationId: 5})
+	assert.NoError(t, err)
+
+	// Операция из журнала означает, что подписка уже оформлена
+	update, err := watch.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), update.GetOperationId())
+
+	hub.Publish(models.Operation{ID: 7, WalletID: storedID, BalanceAfter: 60, Version: 5})
+	update, err = watch.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), update.GetOperationId())
+	assert.Equal(t, 60.0, update.GetBalance())
+}
+
+// TestWalletServer_MalformedWalletID проверяет, что чтение баланса и наблюдение с ID кошелька не в формате UUID
+// отклоняются с InvalidArgument до обращения к сервису
+func TestWalletServer_MalformedWalletID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := newTestClient(t, mock.NewMockWalletService(ctrl), stream.NewHub())
+
+	_, err := client.GetBalance(context.Background(), &walletv1.GetBalanceRequest{WalletId: "wallet-123"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	watch, err := client.WatchBalance(context.Background(), &walletv1.WatchBalanceRequest{WalletId: "wallet-123"})
+	assert.NoError(t, err)
+	_, err = watch.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
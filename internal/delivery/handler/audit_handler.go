@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type AuditHandler interface {
+	HandleVerify(c *fiber.Ctx) error
+	HandleListCheckpoints(c *fiber.Ctx) error
+	HandleCreateCheckpoint(c *fiber.Ctx) error
+}
+
+type ApiAuditHandler struct {
+	adminService service.AdminService
+	logger       *logrus.Logger
+}
+
+func NewApiAuditHandler(adminService service.AdminService, logger *logrus.Logger) *ApiAuditHandler {
+	return &ApiAuditHandler{
+		adminService: adminService,
+		logger:       logger,
+	}
+}
+
+// adminActor возвращает имя, от которого запрос к административному API записывается в журнал аудита.
+// Токен администратора общий, поэтому запрос различается по адресу клиента
+func adminActor(c *fiber.Ctx) string {
+	return "admin-api:" + c.IP()
+}
+
+type CheckpointResponse struct {
+	Created    bool                    `json:"created"`
+	Checkpoint *models.AuditCheckpoint `json:"checkpoint,omitempty"`
+}
+
+// HandleVerify проверяет цепочку журнала аудита и возвращает первое нарушенное звено, если оно есть
+func (h *ApiAuditHandler) HandleVerify(c *fiber.Ctx) error {
+	result, err := h.adminService.VerifyAuditChain(adminActor(c))
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(result)
+}
+
+// HandleListCheckpoints выгружает подписанные контрольные точки журнала аудита вместе с открытым ключом
+func (h *ApiAuditHandler) HandleListCheckpoints(c *fiber.Ctx) error {
+	export, err := h.adminService.Checkpoints(adminActor(c), int64(c.QueryInt("after")), c.QueryInt("limit"))
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(export)
+}
+
+// HandleCreateCheckpoint создает контрольную точку по текущей голове цепочки, не дожидаясь периодической
+func (h *ApiAuditHandler) HandleCreateCheckpoint(c *fiber.Ctx) error {
+	checkpoint, created, err := h.adminService.CreateCheckpoint()
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	if !created {
+		return c.JSON(CheckpointResponse{})
+	}
+	return c.Status(fiber.StatusCreated).JSON(CheckpointResponse{Created: true, Checkpoint: &checkpoint})
+}
+
+// errorResponse сопоставляет ошибку сервиса с HTTP-статусом
+func (h *ApiAuditHandler) errorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidArgument):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrCheckpointsDisabled):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	default:
+		h.logger.Errorf("Audit admin request failed: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
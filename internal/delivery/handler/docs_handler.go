@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"github.com/VadimBorzenkov/WalletAPI/api"
+	"github.com/gofiber/fiber/v2"
+)
+
+// HandleOpenAPISpec отдает спецификацию OpenAPI
+func HandleOpenAPISpec(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	return c.Send(api.OpenAPISpec)
+}
+
+// HandleDocs отдает страницу документации, построенную по спецификации OpenAPI
+func HandleDocs(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.Send(api.DocsPage)
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// Количество строк с ошибками, возвращаемых вместе с прогрессом задания
+const payoutFailuresPreview = 100
+
+// Количество строк, читаемых за один запрос при выгрузке отчета об ошибках
+const payoutReportPageSize = 1000
+
+type PayoutHandler interface {
+	HandleCreatePayoutJob(c *fiber.Ctx) error
+	HandleGetPayoutJob(c *fiber.Ctx) error
+	HandlePayoutErrorReport(c *fiber.Ctx) error
+}
+
+type ApiPayoutHandler struct {
+	payoutService service.PayoutService
+	logger        *logrus.Logger
+}
+
+func NewApiPayoutHandler(payoutService service.PayoutService, logger *logrus.Logger) *ApiPayoutHandler {
+	return &ApiPayoutHandler{
+		payoutService: payoutService,
+		logger:        logger,
+	}
+}
+
+type PayoutJobResponse struct {
+	models.PayoutJob
+	Progress       float64            `json:"progress"` // Доля обработанных строк, от 0 до 1
+	Failures       []models.PayoutRow `json:"failures,omitempty"`
+	ErrorReportURL string             `json:"errorReportUrl"`
+}
+
+// HandleCreatePayoutJob принимает файл выплат в формате CSV или JSONL и сразу возвращает ID задания.
+// Файл передается телом запроса либо полем file в multipart/form-data
+func (h *ApiPayoutHandler) HandleCreatePayoutJob(c *fiber.Ctx) error {
+	var (
+		reader      io.Reader
+		contentType = c.Get(fiber.HeaderContentType)
+		filename    string
+	)
+	if strings.HasPrefix(contentType, fiber.MIMEMultipartForm) {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "file is required"})
+		}
+		file, err := header.Open()
+		if err != nil {
+			h.logger.Errorf("Failed to open uploaded payout file: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "could not read payout file"})
+		}
+		defer file.Close()
+		reader, contentType, filename = file, header.Header.Get(fiber.HeaderContentType), header.Filename
+	} else {
+		reader = bytes.NewReader(c.Body())
+	}
+
+	format, err := payoutFormat(contentType, filename)
+	if err != nil {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{"error": err.Error()})
+	}
+	rows, err := parsePayoutFile(reader, format)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	job, err := h.payoutService.CreateJob(rows)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	c.Location(payoutJobURL(job.ID))
+	return c.Status(fiber.StatusAccepted).JSON(newPayoutJobResponse(job, nil))
+}
+
+// HandleGetPayoutJob возвращает прогресс задания и первые строки, завершившиеся ошибкой
+func (h *ApiPayoutHandler) HandleGetPayoutJob(c *fiber.Ctx) error {
+	jobID := c.Params("jobID")
+	var v ValidationError
+	if validateUUID(&v, "jobID", jobID); v.err() != nil {
+		return validationFailed(c, &v)
+	}
+
+	job, err := h.payoutService.GetJob(jobID)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	var failures []models.PayoutRow
+	if job.FailedRows > 0 {
+		if failures, err = h.payoutService.ListFailedRows(jobID, 0, payoutFailuresPreview); err != nil {
+			return h.errorResponse(c, err)
+		}
+	}
+	return c.JSON(newPayoutJobResponse(job, failures))
+}
+
+// HandlePayoutErrorReport выгружает все строки задания, завершившиеся ошибкой, в формате CSV
+func (h *ApiPayoutHandler) HandlePayoutErrorReport(c *fiber.Ctx) error {
+	jobID := c.Params("jobID")
+	var v ValidationError
+	if validateUUID(&v, "jobID", jobID); v.err() != nil {
+		return validationFailed(c, &v)
+	}
+	if _, err := h.payoutService.GetJob(jobID); err != nil {
+		return h.errorResponse(c, err)
+	}
+
+	var report bytes.Buffer
+	w := csv.NewWriter(&report)
+	w.Write([]string{"row", "wallet_id", "amount", "error"})
+	for afterRow := 0; ; {
+		rows, err := h.payoutService.ListFailedRows(jobID, afterRow, payoutReportPageSize)
+		if err != nil {
+			return h.errorResponse(c, err)
+		}
+		for _, row := range rows {
+			amount := ""
+			if row.Amount != nil {
+				amount = strconv.FormatFloat(*row.Amount, 'f', 2, 64)
+			}
+			w.Write([]string{strconv.Itoa(row.Row), row.WalletID, amount, row.Error})
+			afterRow = row.Row
+		}
+		if len(rows) < payoutReportPageSize {
+			break
+		}
+	}
+	w.Flush()
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="payout-`+jobID+`-errors.csv"`)
+	return c.Send(report.Bytes())
+}
+
+// errorResponse сопоставляет ошибку сервиса с HTTP-статусом. Текст внутренней ошибки только логируется
+func (h *ApiPayoutHandler) errorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidArgument):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, repository.ErrJobNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": repository.ErrJobNotFound.Error()})
+	default:
+		h.logger.Errorf("Payout job request failed: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "could not process payout job"})
+	}
+}
+
+func newPayoutJobResponse(job models.PayoutJob, failures []models.PayoutRow) PayoutJobResponse {
+	progress := 1.0
+	if job.TotalRows > 0 {
+		progress = float64(job.ProcessedRows) / float64(job.TotalRows)
+	}
+	return PayoutJobResponse{
+		PayoutJob:      job,
+		Progress:       progress,
+		Failures:       failures,
+		ErrorReportURL: payoutJobURL(job.ID) + "/errors",
+	}
+}
+
+func payoutJobURL(jobID string) string {
+	return "/api/v1/jobs/" + jobID
+}
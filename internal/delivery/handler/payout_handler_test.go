@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service/mock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+const payoutJobID = "c1d2e3f4-5a6b-4c7d-8e9f-0a1b2c3d4e5f"
+
+// multipartPayoutFile формирует тело multipart/form-data с файлом выплат в поле file
+func multipartPayoutFile(filename, content string) (io.Reader, string) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, _ := w.CreateFormFile("file", filename)
+	part.Write([]byte(content))
+	w.Close()
+	return &body, w.FormDataContentType()
+}
+
+// TestHandleCreatePayoutJob проверяет прием файла выплат
+func TestHandleCreatePayoutJob(t *testing.T) {
+	csvFile := "wallet_id,amount\n" + payoutWalletID + ",10\n" + payoutWalletID + ",-5\n"
+
+	tests := []struct {
+		name         string                                                // Название теста
+		request      func() (io.Reader, string)                            // Тело запроса и его тип содержимого
+		mockService  func(ctrl *gomock.Controller) *mock.MockPayoutService // Мок сервис для тестирования
+		expectedCode int                                                   // Ожидаемый HTTP-код ответа
+	}{
+		{
+			name:    "CSV Body",
+			request: func() (io.Reader, string) { return strings.NewReader(csvFile), "text/csv" },
+			mockService: func(ctrl *gomock.Controller) *mock.MockPayoutService {
+				s := mock.NewMockPayoutService(ctrl)
+				s.EXPECT().CreateJob(gomock.Len(2)).Return(models.PayoutJob{ID: payoutJobID, Status: models.PayoutJobRunning, TotalRows: 2, ProcessedRows: 1, FailedRows: 1}, nil)
+				return s
+			},
+			expectedCode: http.StatusAccepted,
+		},
+		{
+			name:    "Multipart File",
+			request: func() (io.Reader, string) { return multipartPayoutFile("payouts.csv", csvFile) },
+			mockService: func(ctrl *gomock.Controller) *mock.MockPayoutService {
+				s := mock.NewMockPayoutService(ctrl)
+				s.EXPECT().CreateJob(gomock.Len(2)).Return(models.PayoutJob{ID: payoutJobID, Status: models.PayoutJobRunning, TotalRows: 2}, nil)
+				return s
+			},
+			expectedCode: http.StatusAccepted,
+		},
+		{
+			name:    "Unsupported Format",
+			request: func() (io.Reader, string) { return strings.NewReader(`[]`), "application/json" },
+			mockService: func(ctrl *gomock.Controller) *mock.MockPayoutService {
+				return mock.NewMockPayoutService(ctrl)
+			},
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:    "Invalid Header",
+			request: func() (io.Reader, string) { return strings.NewReader("id,sum\n1,2\n"), "text/csv" },
+			mockService: func(ctrl *gomock.Controller) *mock.MockPayoutService {
+				return mock.NewMockPayoutService(ctrl)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:    "Service Error",
+			request: func() (io.Reader, string) { return strings.NewReader(csvFile), "text/csv" },
+			mockService: func(ctrl *gomock.Controller) *mock.MockPayoutService {
+				s := mock.NewMockPayoutService(ctrl)
+				s.EXPECT().CreateJob(gomock.Any()).Return(models.PayoutJob{}, fmt.Errorf("could not create payout job"))
+				return s
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			app := fiber.New()
+			apiHandler := NewApiPayoutHandler(tt.mockService(ctrl), logrus.New())
+			app.Post("/api/v1/jobs/payouts", apiHandler.HandleCreatePayoutJob)
+
+			body, contentType := tt.request()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/jobs/payouts", body)
+			req.Header.Set("Content-Type", contentType)
+
+			resp, _ := app.Test(req)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+			if resp.StatusCode == http.StatusAccepted {
+				assert.Equal(t, "/api/v1/jobs/"+payoutJobID, resp.Header.Get("Location"))
+			}
+		})
+	}
+}
+
+// TestHandleGetPayoutJob проверяет получение прогресса задания
+func TestHandleGetPayoutJob(t *testing.T) {
+	tests := []struct {
+		name         string                                                // Название теста
+		jobID        string                                                // ID задания в пути запроса
+		mockService  func(ctrl *gomock.Controller) *mock.MockPayoutService // Мок сервис для тестирования
+		expectedCode int                                                   // Ожидаемый HTTP-код ответа
+		expectedBody string                                                // Фрагмент ожидаемого тела ответа
+	}{
+		{
+			name:  "Job With Failures",
+			jobID: payoutJobID,
+			mockService: func(ctrl *gomock.Controller) *mock.MockPayoutService {
+				s := mock.NewMockPayoutService(ctrl)
+				s.EXPECT().GetJob(payoutJobID).Return(models.PayoutJob{ID: payoutJobID, Status: models.PayoutJobRunning, TotalRows: 4, ProcessedRows: 2, FailedRows: 1}, nil)
+				s.EXPECT().ListFailedRows(payoutJobID, 0, payoutFailuresPreview).
+					Return([]models.PayoutRow{{Row: 2, WalletID: "wallet-1", Status: models.PayoutRowFailed, Error: "wallet_id: must be a valid UUID"}}, nil)
+				return s
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"progress":0.5`,
+		},
+		{
+			name:  "Job Not Found",
+			jobID: payoutJobID,
+			mockService: func(ctrl *gomock.Controller) *mock.MockPayoutService {
+				s := mock.NewMockPayoutService(ctrl)
+				s.EXPECT().GetJob(payoutJobID).Return(models.PayoutJob{}, fmt.Errorf("could not retrieve payout job: %w", repository.ErrJobNotFound))
+				return s
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			// Внутренняя ошибка не раскрывается клиенту
+			name:  "Internal Error",
+			jobID: payoutJobID,
+			mockService: func(ctrl *gomock.Controller) *mock.MockPayoutService {
+				s := mock.NewMockPayoutService(ctrl)
+				s.EXPECT().GetJob(payoutJobID).Return(models.PayoutJob{}, fmt.Errorf("could not retrieve payout job: dial tcp 10.0.0.5:5432: connection refused"))
+				return s
+			},
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"error":"could not process payout job"}`,
+		},
+		{
+			name:  "Invalid Job ID",
+			jobID: "job-1",
+			mockService: func(ctrl *gomock.Controller) *mock.MockPayoutService {
+				return mock.NewMockPayoutService(ctrl)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			app := fiber.New()
+			apiHandler := NewApiPayoutHandler(tt.mockService(ctrl), logrus.New())
+			app.Get("/api/v1/jobs/:jobID", apiHandler.HandleGetPayoutJob)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+tt.jobID, nil)
+			resp, _ := app.Test(req)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+
+			body, _ := io.ReadAll(resp.Body)
+			assert.Contains(t, string(body), tt.expectedBody)
+		})
+	}
+}
+
+// TestHandlePayoutErrorReport проверяет постраничную выгрузку всех строк с ошибками в CSV
+func TestHandlePayoutErrorReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	amount := 7.5
+	firstPage := make([]models.PayoutRow, payoutReportPageSize)
+	for i := range firstPage {
+		firstPage[i] = models.PayoutRow{Row: i + 1, WalletID: "wallet-1", Status: models.PayoutRowFailed, Error: "wallet not found"}
+	}
+	lastPage := []models.PayoutRow{{Row: 1500, WalletID: "wallet-2", Amount: &amount, Status: models.PayoutRowFailed, Error: "wallet not found"}}
+
+	s := mock.NewMockPayoutService(ctrl)
+	s.EXPECT().GetJob(payoutJobID).Return(models.PayoutJob{ID: payoutJobID, Status: models.PayoutJobCompleted}, nil)
+	gomock.InOrder(
+		s.EXPECT().ListFailedRows(payoutJobID, 0, payoutReportPageSize).Return(firstPage, nil),
+		s.EXPECT().ListFailedRows(payoutJobID, payoutReportPageSize, payoutReportPageSize).Return(lastPage, nil),
+	)
+
+	app := fiber.New()
+	apiHandler := NewApiPayoutHandler(s, logrus.New())
+	app.Get("/api/v1/jobs/:jobID/errors", apiHandler.HandlePayoutErrorReport)
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+payoutJobID+"/errors", nil))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), "attachment")
+
+	body, _ := io.ReadAll(resp.Body)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	assert.Len(t, lines, payoutReportPageSize+2)
+	assert.Equal(t, "row,wallet_id,amount,error", lines[0])
+	assert.Equal(t, "1500,wallet-2,7.50,wallet not found", lines[len(lines)-1])
+}
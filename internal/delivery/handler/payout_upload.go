@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+)
+
+// Форматы файлов выплат
+const (
+	payoutFormatCSV   = "csv"
+	payoutFormatJSONL = "jsonl"
+)
+
+// Максимальная длина строки JSONL в файле выплат
+const maxPayoutLineLength = 64 * 1024
+
+var (
+	errUnsupportedPayoutFormat = errors.New("payout file must be CSV (text/csv) or JSONL (application/x-ndjson)")
+
+	payoutJSONFields = []string{"walletId", "amount"}
+	payoutCSVColumns = []string{"wallet_id", "amount"}
+)
+
+// payoutFormat определяет формат файла выплат по типу содержимого, а если он не указан явно — по расширению файла
+func payoutFormat(contentType, filename string) (string, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return payoutFormatCSV, nil
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return payoutFormatJSONL, nil
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return payoutFormatCSV, nil
+	case ".jsonl", ".ndjson":
+		return payoutFormatJSONL, nil
+	}
+	return "", errUnsupportedPayoutFormat
+}
+
+// parsePayoutFile читает строки выплат. Строки, не прошедшие проверку, возвращаются со статусом FAILED
+// и причиной, чтобы попасть в отчет об ошибках. Ошибка возвращается, только если файл нельзя разобрать целиком
+func parsePayoutFile(r io.Reader, format string) ([]models.PayoutRow, error) {
+	if format == payoutFormatCSV {
+		return parsePayoutCSV(r)
+	}
+	return parsePayoutJSONL(r)
+}
+
+// parsePayoutCSV читает CSV с заголовком, содержащим колонки wallet_id и amount в любом порядке
+func parsePayoutCSV(r io.Reader) ([]models.PayoutRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !contains(payoutCSVColumns, name) {
+			return nil, fmt.Errorf("unknown CSV column %q, expected wallet_id and amount", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range payoutCSVColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV header must contain column %q", name)
+		}
+	}
+
+	var rows []models.PayoutRow
+	for n := 1; ; n++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV at row %d: %w", n, err)
+		}
+
+		var v ValidationError
+		if len(record) != len(header) {
+			v.add("row", "expected "+strconv.Itoa(len(header))+" fields")
+			rows = append(rows, newPayoutRow(n, "", nil, &v))
+			continue
+		}
+
+		walletID := strings.TrimSpace(record[columns["wallet_id"]])
+		validateUUID(&v, "wallet_id", walletID)
+
+		var amount *float64
+		text := strings.TrimSpace(record[columns["amount"]])
+		if text == "" {
+			v.add("amount", "is required")
+		} else if value, err := strconv.ParseFloat(text, 64); err != nil {
+			v.add("amount", "must be a number")
+		} else {
+			amount = checkedAmount(&v, "amount", value)
+		}
+		rows = append(rows, newPayoutRow(n, walletID, amount, &v))
+	}
+}
+
+// parsePayoutJSONL читает JSONL, в котором каждая непустая строка — объект с полями walletId и amount
+func parsePayoutJSONL(r io.Reader) ([]models.PayoutRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4096), maxPayoutLineLength)
+
+	var rows []models.PayoutRow
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		n := len(rows) + 1
+
+		var (
+			v   ValidationError
+			raw map[string]json.RawMessage
+		)
+		if err := json.Unmarshal(line, &raw); err != nil || raw == nil {
+			v.add("row", "must be a JSON object")
+			rows = append(rows, newPayoutRow(n, "", nil, &v))
+			continue
+		}
+		checkUnknownFields(&v, raw, "", payoutJSONFields)
+
+		var walletID string
+		if decodeField(&v, raw, "", "walletId", &walletID, "must be a string") {
+			validateUUID(&v, "walletId", walletID)
+		}
+
+		var (
+			amount *float64
+			value  float64
+		)
+		if decodeField(&v, raw, "", "amount", &value, "must be a number") {
+			amount = checkedAmount(&v, "amount", value)
+		}
+		rows = append(rows, newPayoutRow(n, walletID, amount, &v))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid JSONL at row %d: %w", len(rows)+1, err)
+	}
+	return rows, nil
+}
+
+// checkedAmount возвращает сумму, только если она прошла проверку
+func checkedAmount(v *ValidationError, field string, amount float64) *float64 {
+	errorsBefore := len(v.Fields)
+	validateAmount(v, field, amount)
+	if len(v.Fields) > errorsBefore {
+		return nil
+	}
+	return &amount
+}
+
+// newPayoutRow создает строку выплаты; строка с ошибками проверки сразу получает статус FAILED
+func newPayoutRow(n int, walletID string, amount *float64, v *ValidationError) models.PayoutRow {
+	row := models.PayoutRow{Row: n, WalletID: walletID, Amount: amount, Status: models.PayoutRowPending}
+	if v.err() != nil {
+		row.Status = models.PayoutRowFailed
+		row.Error = v.summary()
+	}
+	return row
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const payoutWalletID = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+
+// TestPayoutFormat проверяет определение формата файла выплат по типу содержимого и расширению
+func TestPayoutFormat(t *testing.T) {
+	tests := []struct {
+		name           string // Название теста
+		contentType    string // Тип содержимого
+		filename       string // Имя загруженного файла
+		expectedFormat string // Ожидаемый формат, пустой при ошибке
+	}{
+		{"CSV Content Type", "text/csv; charset=utf-8", "", payoutFormatCSV},
+		{"NDJSON Content Type", "application/x-ndjson", "", payoutFormatJSONL},
+		{"CSV Extension", "application/octet-stream", "payouts.CSV", payoutFormatCSV},
+		{"JSONL Extension", "", "payouts.jsonl", payoutFormatJSONL},
+		{"Unsupported", "application/json", "payouts.json", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := payoutFormat(tt.contentType, tt.filename)
+			if tt.expectedFormat == "" {
+				assert.ErrorIs(t, err, errUnsupportedPayoutFormat)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedFormat, format)
+		})
+	}
+}
+
+// TestParsePayoutCSV проверяет, что некорректные строки не прерывают разбор, а попадают в отчет об ошибках
+func TestParsePayoutCSV(t *testing.T) {
+	file := "\ufeffamount,wallet_id\n" +
+		"10.50," + payoutWalletID + "\n" +
+		"abc," + payoutWalletID + "\n" +
+		"5,wallet-1\n" +
+		"1\n"
+
+	rows, err := parsePayoutFile(strings.NewReader(file), payoutFormatCSV)
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+
+	assert.Equal(t, models.PayoutRowPending, rows[0].Status)
+	assert.Equal(t, payoutWalletID, rows[0].WalletID)
+	assert.Equal(t, 10.5, *rows[0].Amount)
+
+	assert.Equal(t, models.PayoutRowFailed, rows[1].Status)
+	assert.Nil(t, rows[1].Amount)
+	assert.Contains(t, rows[1].Error, "amount")
+
+	assert.Equal(t, models.PayoutRowFailed, rows[2].Status)
+	assert.Contains(t, rows[2].Error, "wallet_id")
+	assert.Equal(t, 3, rows[2].Row)
+
+	assert.Equal(t, models.PayoutRowFailed, rows[3].Status)
+}
+
+// TestParsePayoutCSV_InvalidHeader проверяет отклонение файла с неверным заголовком целиком
+func TestParsePayoutCSV_InvalidHeader(t *testing.T) {
+	for _, header := range []string{"wallet_id\n", "wallet_id,amount,comment\n", "\"wallet_id,amount\n"} {
+		_, err := parsePayoutFile(strings.NewReader(header), payoutFormatCSV)
+		assert.Error(t, err, header)
+	}
+}
+
+// TestParsePayoutJSONL проверяет разбор JSONL с пропуском пустых строк
+func TestParsePayoutJSONL(t *testing.T) {
+	file := `{"walletId":"` + payoutWalletID + `","amount":3}` + "\n\n" +
+		`{"walletId":"` + payoutWalletID + `","amount":-1}` + "\n" +
+		`{"walletId":"` + payoutWalletID + `","amount":1,"note":"x"}` + "\n" +
+		`not json` + "\n"
+
+	rows, err := parsePayoutFile(strings.NewReader(file), payoutFormatJSONL)
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+
+	assert.Equal(t, models.PayoutRowPending, rows[0].Status)
+	assert.Equal(t, 3.0, *rows[0].Amount)
+
+	assert.Equal(t, models.PayoutRowFailed, rows[1].Status)
+	assert.Equal(t, 2, rows[1].Row)
+
+	assert.Equal(t, models.PayoutRowFailed, rows[2].Status)
+	assert.Contains(t, rows[2].Error, "note")
+
+	assert.Equal(t, models.PayoutRowFailed, rows[3].Status)
+	assert.Empty(t, rows[3].WalletID)
+}
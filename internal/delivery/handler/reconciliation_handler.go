@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ReconciliationHandler interface {
+	HandleReconcile(c *fiber.Ctx) error
+}
+
+type ApiReconciliationHandler struct {
+	adminService service.AdminService
+	logger       *logrus.Logger
+}
+
+func NewApiReconciliationHandler(adminService service.AdminService, logger *logrus.Logger) *ApiReconciliationHandler {
+	return &ApiReconciliationHandler{
+		adminService: adminService,
+		logger:       logger,
+	}
+}
+
+type ReconciliationRequest struct {
+	WalletIDs []string `json:"walletIds"`
+}
+
+// HandleReconcile сверяет балансы кошельков с журналом операций. Без тела запроса или с пустым списком
+// сверяются все кошельки и проверяется глобальный инвариант
+func (h *ApiReconciliationHandler) HandleReconcile(c *fiber.Ctx) error {
+	var req ReconciliationRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request payload"})
+		}
+	}
+
+	report, err := h.adminService.Reconcile(adminActor(c), req.WalletIDs)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidArgument) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		h.logger.Errorf("Reconciliation request failed: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(report)
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service"
+	"github.com/VadimBorzenkov/WalletAPI/internal/statement"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ReportHandler interface {
+	HandleBalanceAt(c *fiber.Ctx) error
+	HandleStatement(c *fiber.Ctx) error
+	HandleDailyReport(c *fiber.Ctx) error
+	HandleWalletDailyReport(c *fiber.Ctx) error
+}
+
+type ApiReportHandler struct {
+	reportService service.ReportService
+	logger        *logrus.Logger
+}
+
+func NewApiReportHandler(reportService service.ReportService, logger *logrus.Logger) *ApiReportHandler {
+	return &ApiReportHandler{
+		reportService: reportService,
+		logger:        logger,
+	}
+}
+
+// HandleBalanceAt возвращает баланс кошелька на прошедший момент, заданный параметром at в формате RFC 3339
+func (h *ApiReportHandler) HandleBalanceAt(c *fiber.Ctx) error {
+	walletID := c.Params("walletID")
+	var v ValidationError
+	validateUUID(&v, "walletID", walletID)
+	at := validateTime(&v, "at", c.Query("at"))
+	if v.err() != nil {
+		return validationFailed(c, &v)
+	}
+
+	balance, err := h.reportService.BalanceAt(walletID, at)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(balance)
+}
+
+// HandleStatement выгружает выписку по кошельку за период [from, to) в формате csv, json или pdf.
+// Выписка передается клиенту по мере чтения операций, поэтому статус и заголовки ответа отправляются,
+// когда известен баланс на начало периода: ошибки до этого момента возвращаются обычным ответом,
+// ошибка после него обрывает передачу
+func (h *ApiReportHandler) HandleStatement(c *fiber.Ctx) error {
+	walletID := c.Params("walletID")
+	var v ValidationError
+	validateUUID(&v, "walletID", walletID)
+	from := validateTime(&v, "from", c.Query("from"))
+	to := validateTime(&v, "to", c.Query("to"))
+	format := c.Query("format", statement.FormatJSON)
+	switch format {
+	case statement.FormatCSV, statement.FormatJSON, statement.FormatPDF:
+	default:
+		v.add("format", "must be one of csv, json, pdf")
+	}
+	if v.err() != nil {
+		return validationFailed(c, &v)
+	}
+
+	header := statement.Header{WalletID: walletID, From: from, To: to}
+	pr, pw := io.Pipe()
+	w, err := statement.NewWriter(format, pw, header)
+	if err != nil {
+		return h.statementError(c, walletID, err)
+	}
+	stream := &startedWriter{Writer: w, started: make(chan struct{})}
+	done := make(chan error, 1)
+	go func() {
+		err := h.reportService.Statement(walletID, from, to, stream)
+		done <- err
+		pw.CloseWithError(err)
+	}()
+
+	select {
+	case <-stream.started:
+	case err := <-done:
+		if err != nil {
+			return h.statementError(c, walletID, err)
+		}
+	}
+	c.Set(fiber.HeaderContentType, statement.ContentType(format))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, statement.Filename(format, header)))
+	c.Context().SetBodyStream(pr, -1)
+	return nil
+}
+
+// HandleDailyReport возвращает итоги дневных отчетов всех кошельков за дни с from по to включительно,
+// сгруппированные по groupBy
+func (h *ApiReportHandler) HandleDailyReport(c *fiber.Ctx) error {
+	return h.dailyReport(c, "", &ValidationError{})
+}
+
+// HandleWalletDailyReport возвращает итоги дневных отчетов кошелька за дни с from по to включительно,
+// сгруппированные по groupBy
+func (h *ApiReportHandler) HandleWalletDailyReport(c *fiber.Ctx) error {
+	walletID := c.Params("walletID")
+	var v ValidationError
+	validateUUID(&v, "walletID", walletID)
+	return h.dailyReport(c, walletID, &v)
+}
+
+// dailyReport проверяет параметры периода и группировки, дополняя ошибки v, и возвращает дневной отчет
+// по всем кошелькам (walletID пустой) или по одному
+func (h *ApiReportHandler) dailyReport(c *fiber.Ctx, walletID string, v *ValidationError) error {
+	from := validateDate(v, "from", c.Query("from"))
+	to := validateDate(v, "to", c.Query("to"))
+	groupBy := c.Query("groupBy", models.ReportGroupDay)
+	switch groupBy {
+	case models.ReportGroupDay, models.ReportGroupWeek, models.ReportGroupMonth:
+	default:
+		v.add("groupBy", "must be one of day, week, month")
+	}
+	if v.err() != nil {
+		return validationFailed(c, v)
+	}
+
+	report, err := h.reportService.DailyReport(walletID, from, to, groupBy)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(report)
+}
+
+// startedWriter сообщает о начале выписки закрытием started перед записью баланса на начало периода
+type startedWriter struct {
+	statement.Writer
+	started chan struct{}
+	once    sync.Once
+}
+
+func (w *startedWriter) Opening(balance float64) error {
+	w.once.Do(func() { close(w.started) })
+	return w.Writer.Opening(balance)
+}
+
+// statementError отвечает на ошибку выписки до начала передачи. Внутренняя ошибка только логируется
+func (h *ApiReportHandler) statementError(c *fiber.Ctx, walletID string, err error) error {
+	if errors.Is(err, service.ErrInvalidArgument) || errors.Is(err, repository.ErrWalletNotFound) {
+		return h.errorResponse(c, err)
+	}
+	h.logger.Errorf("Statement for wallet %s failed: %v", walletID, err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "could not build statement"})
+}
+
+// errorResponse сопоставляет ошибку сервиса с HTTP-статусом. Текст внутренней ошибки только логируется
+func (h *ApiReportHandler) errorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidArgument):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, repository.ErrWalletNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": repository.ErrWalletNotFound.Error()})
+	default:
+		h.logger.Errorf("Report request failed: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "could not build report"})
+	}
+}
@@ -0,0 +1,354 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service/mock"
+	"github.com/VadimBorzenkov/WalletAPI/internal/statement"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHandleBalanceAt проверяет получение баланса на момент времени
+func TestHandleBalanceAt(t *testing.T) {
+	const walletID = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	at := time.Date(2026, 9, 30, 23, 59, 59, 0, time.UTC)
+
+	tests := []struct {
+		name         string                                                // Название теста
+		path         string                                                // Путь запроса
+		mockService  func(ctrl *gomock.Controller) *mock.MockReportService // Мок сервис для тестирования
+		expectedCode int                                                   // Ожидаемый HTTP-код ответа
+		expectedBody string                                                // Ожидаемое тело ответа, если проверяется
+	}{
+		{
+			name: "Balance Success",
+			path: "/api/v1/wallets/" + walletID + "/balance?at=2026-09-30T23:59:59Z",
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				s := mock.NewMockReportService(ctrl)
+				s.EXPECT().BalanceAt(walletID, at).Return(models.BalanceAt{WalletID: walletID, At: at, Balance: 42}, nil)
+				return s
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Missing Time",
+			path: "/api/v1/wallets/" + walletID + "/balance",
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				return mock.NewMockReportService(ctrl)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Invalid Time",
+			path: "/api/v1/wallets/" + walletID + "/balance?at=30.09.2026",
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				return mock.NewMockReportService(ctrl)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Future Time",
+			path: "/api/v1/wallets/" + walletID + "/balance?at=2026-09-30T23:59:59Z",
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				s := mock.NewMockReportService(ctrl)
+				s.EXPECT().BalanceAt(walletID, at).Return(models.BalanceAt{}, fmt.Errorf("%w: balance time must not be in the future", service.ErrInvalidArgument))
+				return s
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Wallet Not Found",
+			path: "/api/v1/wallets/" + walletID + "/balance?at=2026-09-30T23:59:59Z",
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				s := mock.NewMockReportService(ctrl)
+				s.EXPECT().BalanceAt(walletID, at).Return(models.BalanceAt{}, fmt.Errorf("could not retrieve balance: %w", repository.ErrWalletNotFound))
+				return s
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			// Внутренняя ошибка не раскрывается клиенту
+			name: "Internal Error",
+			path: "/api/v1/wallets/" + walletID + "/balance?at=2026-09-30T23:59:59Z",
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				s := mock.NewMockReportService(ctrl)
+				s.EXPECT().BalanceAt(walletID, at).Return(models.BalanceAt{}, fmt.Errorf("could not retrieve balance: dial tcp 10.0.0.5:5432: connection refused"))
+				return s
+			},
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"error":"could not build report"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			app := fiber.New()
+			apiHandler := NewApiReportHandler(tt.mockService(ctrl), logrus.New())
+			app.Get("/api/v1/wallets/:walletID/balance", apiHandler.HandleBalanceAt)
+
+			resp, _ := app.Test(httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+			if tt.expectedBody != "" {
+				body, _ := io.ReadAll(resp.Body)
+				assert.Equal(t, tt.expectedBody, string(body))
+			}
+		})
+	}
+}
+
+// TestHandleStatement проверяет выгрузку выписки и ответы на ошибки до начала передачи
+func TestHandleStatement(t *testing.T) {
+	const walletID = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	query := "?from=2026-09-01T00:00:00Z&to=2026-10-01T00:00:00Z"
+	streamed := func(walletID string, from, to time.Time, w statement.Writer) error {
+		if err := w.Opening(100); err != nil {
+			return err
+		}
+		op := models.Operation{ID: 7, WalletID: walletID, Type: models.OperationWithdraw, Amount: 40, BalanceAfter: 60, CreatedAt: from.Add(time.Hour)}
+		if err := w.Operation(op); err != nil {
+			return err
+		}
+		return w.Closing(60)
+	}
+
+	tests := []struct {
+		name         string                                                // Название теста
+		path         string                                                // Путь запроса
+		mockService  func(ctrl *gomock.Controller) *mock.MockReportService // Мок сервис для тестирования
+		expectedCode int                                                   // Ожидаемый HTTP-код ответа
+		expectedType string                                                // Ожидаемый Content-Type
+		expectedBody string                                                // Ожидаемое тело ответа, если проверяется
+	}{
+		{
+			name: "CSV Statement",
+			path: "/api/v1/wallets/" + walletID + "/statement" + query + "&format=csv",
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				s := mock.NewMockReportService(ctrl)
+				s.EXPECT().Statement(walletID, from, to, gomock.Any()).DoAndReturn(streamed)
+				return s
+			},
+			expectedCode: http.StatusOK,
+			expectedType: "text/csv; charset=utf-8",
+			expectedBody: "operation_id,occurred_at,operation_type,amount,balance\n" +
+				",2026-09-01T00:00:00Z,OPENING_BALANCE,,100.00\n" +
+				"7,2026-09-01T01:00:00Z,WITHDRAW,-40.00,60.00\n" +
+				",2026-10-01T00:00:00Z,CLOSING_BALANCE,,60.00\n",
+		},
+		{
+			name: "JSON Statement By Default",
+			path: "/api/v1/wallets/" + walletID + "/statement" + query,
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				s := mock.NewMockReportService(ctrl)
+				s.EXPECT().Statement(walletID, from, to, gomock.Any()).DoAndReturn(streamed)
+				return s
+			},
+			expectedCode: http.StatusOK,
+			expectedType: "application/json",
+		},
+		{
+			name: "PDF Statement",
+			path: "/api/v1/wallets/" + walletID + "/statement" + query + "&format=pdf",
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				s := mock.NewMockReportService(ctrl)
+				s.EXPECT().Statement(walletID, from, to, gomock.Any()).DoAndReturn(streamed)
+				return s
+			},
+			expectedCode: http.StatusOK,
+			expectedType: "application/pdf",
+		},
+		{
+			name: "Unknown Format",
+			path: "/api/v1/wallets/" + walletID + "/statement" + query + "&format=xlsx",
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				return mock.NewMockReportService(ctrl)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Missing Period",
+			path: "/api/v1/wallets/" + walletID + "/statement?from=2026-09-01T00:00:00Z",
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				return mock.NewMockReportService(ctrl)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Reversed Period",
+			path: "/api/v1/wallets/" + walletID + "/statement?from=2026-10-01T00:00:00Z&to=2026-09-01T00:00:00Z",
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				s := mock.NewMockReportService(ctrl)
+				s.EXPECT().Statement(walletID, to, from, gomock.Any()).Return(fmt.Errorf("%w: statement period start must be before its end", service.ErrInvalidArgument))
+				return s
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Wallet Not Found",
+			path: "/api/v1/wallets/" + walletID + "/statement" + query,
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				s := mock.NewMockReportService(ctrl)
+				s.EXPECT().Statement(walletID, from, to, gomock.Any()).Return(fmt.Errorf("could not build statement: %w", repository.ErrWalletNotFound))
+				return s
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			// Внутренняя ошибка до начала передачи не раскрывается клиенту
+			name: "Internal Error",
+			path: "/api/v1/wallets/" + walletID + "/statement" + query,
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				s := mock.NewMockReportService(ctrl)
+				s.EXPECT().Statement(walletID, from, to, gomock.Any()).Return(fmt.Errorf("could not build statement: dial tcp 10.0.0.5:5432: connection refused"))
+				return s
+			},
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"error":"could not build statement"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			app := fiber.New()
+			apiHandler := NewApiReportHandler(tt.mockService(ctrl), logrus.New())
+			app.Get("/api/v1/wallets/:walletID/statement", apiHandler.HandleStatement)
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			if tt.expectedCode != http.StatusOK {
+				if tt.expectedBody != "" {
+					assert.Equal(t, tt.expectedBody, string(body))
+				}
+				return
+			}
+			assert.Equal(t, tt.expectedType, resp.Header.Get(fiber.HeaderContentType))
+			assert.Contains(t, resp.Header.Get(fiber.HeaderContentDisposition), "statement-"+walletID+"-20260901-20261001.")
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, string(body))
+			}
+		})
+	}
+}
+
+// TestHandleDailyReport проверяет параметры дневных отчетов по всем кошелькам и по кошельку
+func TestHandleDailyReport(t *testing.T) {
+	const walletID = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string                                                // Название теста
+		path         string                                                // Путь запроса
+		mockService  func(ctrl *gomock.Controller) *mock.MockReportService // Мок сервис для тестирования
+		expectedCode int                                                   // Ожидаемый HTTP-код ответа
+	}{
+		{
+			name: "All Wallets By Day",
+			path: "/api/v1/reports/daily?from=2026-09-01&to=2026-09-30",
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				s := mock.NewMockReportService(ctrl)
+				s.EXPECT().DailyReport("", from, to, models.ReportGroupDay).Return(models.DailyReport{From: "2026-09-01", To: "2026-09-30", GroupBy: models.ReportGroupDay}, nil)
+				return s
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Wallet By Month",
+			path: "/api/v1/reports/daily/wallets/" + walletID + "?from=2026-09-01&to=2026-09-30&groupBy=month",
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				s := mock.NewMockReportService(ctrl)
+				s.EXPECT().DailyReport(walletID, from, to, models.ReportGroupMonth).Return(models.DailyReport{WalletID: walletID, GroupBy: models.ReportGroupMonth}, nil)
+				return s
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Invalid Date",
+			path: "/api/v1/reports/daily?from=2026-09-01T00:00:00Z&to=2026-09-30",
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				return mock.NewMockReportService(ctrl)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Missing Dates",
+			path: "/api/v1/reports/daily",
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				return mock.NewMockReportService(ctrl)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Unknown Grouping",
+			path: "/api/v1/reports/daily?from=2026-09-01&to=2026-09-30&groupBy=year",
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				return mock.NewMockReportService(ctrl)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Invalid Wallet ID",
+			path: "/api/v1/reports/daily/wallets/not-a-uuid?from=2026-09-01&to=2026-09-30",
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				return mock.NewMockReportService(ctrl)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Reversed Dates",
+			path: "/api/v1/reports/daily?from=2026-09-30&to=2026-09-01",
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				s := mock.NewMockReportService(ctrl)
+				s.EXPECT().DailyReport("", to, from, models.ReportGroupDay).Return(models.DailyReport{}, fmt.Errorf("%w: report start date must not be after its end date", service.ErrInvalidArgument))
+				return s
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Wallet Not Found",
+			path: "/api/v1/reports/daily/wallets/" + walletID + "?from=2026-09-01&to=2026-09-30",
+			mockService: func(ctrl *gomock.Controller) *mock.MockReportService {
+				s := mock.NewMockReportService(ctrl)
+				s.EXPECT().DailyReport(walletID, from, to, models.ReportGroupDay).Return(models.DailyReport{}, fmt.Errorf("could not build daily report: %w", repository.ErrWalletNotFound))
+				return s
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			app := fiber.New()
+			apiHandler := NewApiReportHandler(tt.mockService(ctrl), logrus.New())
+			app.Get("/api/v1/reports/daily", apiHandler.HandleDailyReport)
+			app.Get("/api/v1/reports/daily/wallets/:walletID", apiHandler.HandleWalletDailyReport)
+
+			resp, _ := app.Test(httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service"
+	"github.com/VadimBorzenkov/WalletAPI/internal/stream"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// Количество операций, дочитываемых из журнала за один запрос при возобновлении потока
+const resumeBatchSize = 500
+
+type StreamHandler interface {
+	HandleStream(c *fiber.Ctx) error
+}
+
+type ApiStreamHandler struct {
+	walletService service.WalletService
+	hub           *stream.Hub
+	logger        *logrus.Logger
+	heartbeat     time.Duration
+	maxPerClient  int
+
+	mu     sync.Mutex
+	active map[string]int
+}
+
+func NewApiStreamHandler(walletService service.WalletService, hub *stream.Hub, logger *logrus.Logger, heartbeat time.Duration, maxPerClient int) *ApiStreamHandler {
+	return &ApiStreamHandler{
+		walletService: walletService,
+		hub:           hub,
+		logger:        logger,
+		heartbeat:     heartbeat,
+		maxPerClient:  maxPerClient,
+		active:        make(map[string]int),
+	}
+}
+
+// HandleStream отправляет клиенту операции кошелька в формате Server-Sent Events.
+// С заголовком Last-Event-ID поток продолжается с операции, следующей за указанной; с заголовком
+// Consistency: eventual пропущенные операции дочитываются в основном с реплики
+func (h *ApiStreamHandler) HandleStream(c *fiber.Ctx) error {
+	walletID := c.Params("walletID")
+	var v ValidationError
+	if validateUUID(&v, "walletID", walletID); v.err() != nil {
+		return validationFailed(c, &v)
+	}
+
+	lastEventID := c.Get("Last-Event-ID", c.Query("lastEventId"))
+	var resumeAfter int64
+	if lastEventID != "" {
+		id, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || id < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid Last-Event-ID"})
+		}
+		resumeAfter = id
+	}
+
+	eventual := eventualRead(c)
+	client := c.IP()
+	if !h.acquire(client) {
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": "too many concurrent streams"})
+	}
+
+	// Подписка оформляется до чтения состояния кошелька, чтобы не пропустить операции между ними
+	sub := h.hub.Subscribe(walletID)
+	wallet, err := h.walletService.GetWallet(walletID)
+	if err != nil {
+		sub.Close()
+		h.release(client)
+		if errors.Is(err, repository.ErrWalletNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "wallet not found"})
+		}
+		h.logger.Errorf("Failed to open stream for wallet %s: %v", walletID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "could not open stream"})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer h.release(client)
+		defer sub.Close()
+
+		sent := streamState{lastID: resumeAfter}
+		fmt.Fprintf(w, "retry: %d\n\n", (3 * time.Second).Milliseconds())
+		if lastEventID != "" {
+			if err := h.resume(w, walletID, &sent, eventual); err != nil {
+				h.logger.Errorf("Failed to resume stream for wallet %s after %d: %v", walletID, resumeAfter, err)
+				return
+			}
+		} else {
+			// Без Last-Event-ID клиент получает текущий баланс, а затем только более новые операции
+			sent.baseVersion = wallet.Version
+			writeEvent(w, "balance", "", fiber.Map{"walletId": walletID, "balance": wallet.Balance, "version": wallet.Version})
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(h.heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case op, ok := <-sub.Events():
+				if !ok {
+					// Хаб завершил подписку; клиент переподключится с Last-Event-ID
+					return
+				}
+				if sent.seen(op) {
+					continue
+				}
+				writeOperation(w, op)
+				sent.lastID = op.ID
+			case <-ticker.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+			}
+			if err := w.Flush(); err != nil {
+				// Клиент отключился
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// resume дочитывает из журнала операции, пропущенные клиентом. Если клиент допускает чтение с реплики,
+// журнал читается с нее, а операции, которые до реплики еще не дошли, — с основной базы
+func (h *ApiStreamHandler) resume(w *bufio.Writer, walletID string, sent *streamState, eventual bool) error {
+	if eventual {
+		if err := h.replay(w, walletID, sent, h.walletService.GetOperationsAfterEventual); err != nil {
+			return err
+		}
+	}
+	return h.replay(w, walletID, sent, h.walletService.GetOperationsAfter)
+}
+
+// replay отправляет клиенту операции журнала после последней отправленной
+func (h *ApiStreamHandler) replay(w *bufio.Writer, walletID string, sent *streamState, getOperations func(walletID string, afterID int64, limit int) ([]models.Operation, error)) error {
+	for {
+		operations, err := getOperations(walletID, sent.lastID, resumeBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, op := range operations {
+			writeOperation(w, op)
+			sent.lastID = op.ID
+		}
+		if len(operations) < resumeBatchSize {
+			return nil
+		}
+	}
+}
+
+// acquire учитывает новый поток клиента, если лимит одновременных потоков не превышен
+func (h *ApiStreamHandler) acquire(client string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.active[client] >= h.maxPerClient {
+		return false
+	}
+	h.active[client]++
+	return true
+}
+
+// release освобождает место под поток клиента
+func (h *ApiStreamHandler) release(client string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.active[client]--
+	if h.active[client] <= 0 {
+		delete(h.active, client)
+	}
+}
+
+// streamState хранит границу уже отправленных клиенту операций
+type streamState struct {
+	lastID      int64
+	baseVersion int64
+}
+
+// seen сообщает, что операция уже отправлена или учтена в начальном балансе
+func (s streamState) seen(op models.Operation) bool {
+	return op.ID <= s.lastID || op.Version <= s.baseVersion
+}
+
+// writeOperation записывает операцию как событие с ее ID, чтобы клиент мог возобновить поток
+func writeOperation(w *bufio.Writer, op models.Operation) {
+	writeEvent(w, "operation", strconv.FormatInt(op.ID, 10), op)
+}
+
+// writeEvent записывает событие в формате text/event-stream
+func writeEvent(w *bufio.Writer, event, id string, data interface{}) {
+	payload, _ := json.Marshal(data)
+	if id != "" {
+		fmt.Fprintf(w, "id: %s\n", id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service/mock"
+	"github.com/VadimBorzenkov/WalletAPI/internal/stream"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHandleStream_Resume проверяет возобновление потока по Last-Event-ID и доставку новых операций
+func TestHandleStream_Resume(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Клиент уже получил операцию 5; из журнала дочитывается операция 6
+	s := mock.NewMockWalletService(ctrl)
+	s.EXPECT().GetWallet("3fa85f64-5717-4562-b3fc-2c963f66afa6").Return(models.Wallet{ID: "3fa85f64-5717-4562-b3fc-2c963f66afa6", Balance: 70, Version: 4}, nil)
+	s.EXPECT().GetOperationsAfter("3fa85f64-5717-4562-b3fc-2c963f66afa6", int64(5), resumeBatchSize).
+		Return([]models.Operation{{ID: 6, WalletID: "3fa85f64-5717-4562-b3fc-2c963f66afa6", Type: models.OperationDeposit, Amount: 20, BalanceAfter: 70, Version: 4}}, nil)
+
+	hub := stream.NewHub()
+	app := fiber.New()
+	apiHandler := NewApiStreamHandler(s, hub, logrus.New(), time.Minute, 1)
+	app.Get("/api/v1/wallets/:walletID/stream", apiHandler.HandleStream)
+
+	// Публикуем повтор операции 6 и новую операцию 7, затем завершаем подписку
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		hub.Publish(models.Operation{ID: 6, WalletID: "3fa85f64-5717-4562-b3fc-2c963f66afa6", Version: 4})
+		hub.Publish(models.Operation{ID: 7, WalletID: "3fa85f64-5717-4562-b3fc-2c963f66afa6", Type: models.OperationWithdraw, Amount: 10, BalanceAfter: 60, Version: 5})
+		time.Sleep(100 * time.Millisecond)
+		hub.Reset()
+	}()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/3fa85f64-5717-4562-b3fc-2c963f66afa6/stream", nil)
+	req.Header.Set("Last-Event-ID", "5")
+	resp, err := app.Test(req, 2000)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "id: 6\nevent: operation\n")
+	assert.Contains(t, string(body), "id: 7\nevent: operation\n")
+	// Повтор операции 6 из уведомлений не отправляется второй раз
+	assert.Equal(t, 1, strings.Count(string(body), "id: 6\n"))
+}
+
+// TestHandleStream_TooManyStreams проверяет ограничение одновременных потоков одного клиента
+func TestHandleStream_TooManyStreams(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := fiber.New()
+	apiHandler := NewApiStreamHandler(mock.NewMockWalletService(ctrl), stream.NewHub(), logrus.New(), time.Minute, 1)
+	app.Get("/api/v1/wallets/:walletID/stream", apiHandler.HandleStream)
+
+	// Единственное доступное место уже занято потоком этого клиента
+	apiHandler.acquire("0.0.0.0")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/3fa85f64-5717-4562-b3fc-2c963f66afa6/stream", nil)
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+}
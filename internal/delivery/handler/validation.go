@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/validation"
+	"github.com/gofiber/fiber/v2"
+)
+
+// MaxTransactionAmount ограничивает сумму одной операции
+const MaxTransactionAmount = validation.MaxTransactionAmount
+
+// MaxBatchSize ограничивает количество операций в одном пакете
+const MaxBatchSize = 1000
+
+var errInvalidPayload = errors.New("invalid request payload")
+
+// FieldError описывает ошибку в одном поле запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError содержит все ошибки полей запроса, найденные за одну проверку
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	return "validation failed: " + e.summary()
+}
+
+// summary перечисляет ошибки полей одной строкой
+func (e *ValidationError) summary() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		messages = append(messages, f.Field+": "+f.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// err возвращает nil, если ошибок не найдено
+func (e *ValidationError) err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// validationFailed отвечает клиенту списком ошибок полей
+func validationFailed(c *fiber.Ctx, err *ValidationError) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "validation failed", "details": err.Fields})
+}
+
+// validateUUID проверяет, что ID кошелька или задания является UUID
+func validateUUID(v *ValidationError, field, id string) {
+	if err := validation.UUID(id); err != nil {
+		v.add(field, err.Error())
+	}
+}
+
+// validateTime проверяет, что момент времени задан в формате RFC 3339, и возвращает его
+func validateTime(v *ValidationError, field, value string) time.Time {
+	if value == "" {
+		v.add(field, "is required")
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		v.add(field, "must be an RFC 3339 timestamp")
+	}
+	return t
+}
+
+// validateDate проверяет, что дата задана в формате YYYY-MM-DD, и возвращает начало дня в UTC
+func validateDate(v *ValidationError, field, value string) time.Time {
+	if value == "" {
+		v.add(field, "is required")
+		return time.Time{}
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		v.add(field, "must be a date in YYYY-MM-DD format")
+	}
+	return t
+}
+
+// transactionFields перечисляет поля TransactionRequest в порядке их проверки
+var transactionFields = []string{"walletId", "operationType", "amount"}
+
+// parseTransactionRequest разбирает и проверяет тело запроса на операцию.
+// В отличие от BodyParser, отклоняет неизвестные поля и сообщает обо всех ошибках сразу
+func parseTransactionRequest(body []byte) (TransactionRequest, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil || raw == nil {
+		return TransactionRequest{}, errInvalidPayload
+	}
+
+	var (
+		req TransactionRequest
+		v   ValidationError
+	)
+	checkUnknownFields(&v, raw, "", transactionFields)
+
+	if decodeField(&v, raw, "", "walletId", &req.WalletID, "must be a string") {
+		validateUUID(&v, "walletId", req.WalletID)
+	}
+
+	if decodeField(&v, raw, "", "operationType", &req.OperationType, "must be a string") {
+		if req.OperationType != "DEPOSIT" && req.OperationType != "WITHDRAW" {
+			v.add("operationType", "must be one of DEPOSIT, WITHDRAW")
+		}
+	}
+
+	if decodeField(&v, raw, "", "amount", &req.Amount, "must be a number") {
+		validateAmount(&v, "amount", req.Amount)
+	}
+
+	return req, v.err()
+}
+
+var (
+	batchFields          = []string{"mode", "operations"}
+	batchOperationFields = []string{"operationType", "walletId", "toWalletId", "amount"}
+)
+
+// parseBatchRequest разбирает и проверяет тело запроса на пакет операций.
+// Ошибки операций возвращаются с индексом операции в имени поля, например operations[3].amount
+func parseBatchRequest(body []byte) (BatchRequest, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil || raw == nil {
+		return BatchRequest{}, errInvalidPayload
+	}
+
+	req := BatchRequest{Mode: models.BatchAtomic}
+	var v ValidationError
+	checkUnknownFields(&v, raw, "", batchFields)
+
+	if value, ok := raw["mode"]; ok && !bytes.Equal(value, []byte("null")) {
+		if err := json.Unmarshal(value, &req.Mode); err != nil {
+			v.add("mode", "must be a string")
+		} else if req.Mode != models.BatchAtomic && req.Mode != models.BatchBestEffort {
+			v.add("mode", "must be one of ATOMIC, BEST_EFFORT")
+		}
+	}
+
+	var operations []map[string]json.RawMessage
+	if decodeField(&v, raw, "", "operations", &operations, "must be an array of objects") {
+		switch {
+		case len(operations) == 0:
+			v.add("operations", "must contain at least one operation")
+		case len(operations) > MaxBatchSize:
+			v.add("operations", "must contain at most "+strconv.Itoa(MaxBatchSize)+" operations")
+		}
+	}
+	if len(operations) > MaxBatchSize {
+		return req, v.err()
+	}
+
+	for i, op := range operations {
+		prefix := "operations[" + strconv.Itoa(i) + "]."
+		if op == nil {
+			v.add(strings.TrimSuffix(prefix, "."), "must be an object")
+			continue
+		}
+		req.Operations = append(req.Operations, parseBatchOperation(&v, op, prefix))
+	}
+	return req, v.err()
+}
+
+// parseBatchOperation проверяет одну операцию пакета
+func parseBatchOperation(v *ValidationError, raw map[string]json.RawMessage, prefix string) models.BatchItem {
+	var item models.BatchItem
+	checkUnknownFields(v, raw, prefix, batchOperationFields)
+
+	if decodeField(v, raw, prefix, "walletId", &item.WalletID, "must be a string") {
+		validateUUID(v, prefix+"walletId", item.WalletID)
+	}
+
+	if decodeField(v, raw, prefix, "operationType", &item.OperationType, "must be a string") {
+		switch item.OperationType {
+		case models.OperationDeposit, models.OperationWithdraw:
+			if _, ok := raw["toWalletId"]; ok {
+				v.add(prefix+"toWalletId", "is allowed only for TRANSFER")
+			}
+		case models.OperationTransfer:
+			if decodeField(v, raw, prefix, "toWalletId", &item.ToWalletID, "must be a string") {
+				validateUUID(v, prefix+"toWalletId", item.ToWalletID)
+				if strings.EqualFold(item.ToWalletID, item.WalletID) {
+					v.add(prefix+"toWalletId", "must differ from walletId")
+				}
+			}
+		default:
+			v.add(prefix+"operationType", "must be one of DEPOSIT, WITHDRAW, TRANSFER")
+		}
+	}
+
+	if decodeField(v, raw, prefix, "amount", &item.Amount, "must be a number") {
+		validateAmount(v, prefix+"amount", item.Amount)
+	}
+	return item
+}
+
+// checkUnknownFields добавляет ошибку для каждого поля, не входящего в known, в алфавитном порядке
+func checkUnknownFields(v *ValidationError, raw map[string]json.RawMessage, prefix string, known []string) {
+	var unknown []string
+	for field := range raw {
+		if !contains(known, field) {
+			unknown = append(unknown, field)
+		}
+	}
+	sort.Strings(unknown)
+	for _, field := range unknown {
+		v.add(prefix+field, "unknown field")
+	}
+}
+
+// decodeField читает обязательное поле запроса. Возвращает false, если поле отсутствует или имеет неверный тип
+func decodeField(v *ValidationError, raw map[string]json.RawMessage, prefix, field string, dst interface{}, typeMessage string) bool {
+	value, ok := raw[field]
+	if !ok || bytes.Equal(value, []byte("null")) {
+		v.add(prefix+field, "is required")
+		return false
+	}
+	if err := json.Unmarshal(value, dst); err != nil {
+		v.add(prefix+field, typeMessage)
+		return false
+	}
+	return true
+}
+
+// validateAmount проверяет знак, верхнюю границу и точность суммы операции
+func validateAmount(v *ValidationError, field string, amount float64) {
+	if err := validation.Amount(amount); err != nil {
+		v.add(field, err.Error())
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,12 @@
 package handler
 
 import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
 	"github.com/VadimBorzenkov/WalletAPI/internal/service"
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
@@ -9,6 +15,7 @@ import (
 type WalletHandler interface {
 	HandleBalance(c *fiber.Ctx) error
 	HandleTransaction(c *fiber.Ctx) error
+	HandleBatch(c *fiber.Ctx) error
 }
 
 type ApiWalletHandler struct {
@@ -23,20 +30,51 @@ func NewApiWalletHandler(walletService service.WalletService, logger *logrus.Log
 	}
 }
 
-// HandleBalance обрабатывает запрос на получение баланса кошелька
+// ConsistencyHeader — заголовок запроса, значением eventual которого клиент разрешает читать с реплики,
+// возможно отстающей от последних операций
+const ConsistencyHeader = "Consistency"
+
+// eventualRead сообщает, допускает ли клиент чтение с реплики
+func eventualRead(c *fiber.Ctx) bool {
+	return strings.EqualFold(c.Get(ConsistencyHeader), "eventual")
+}
+
+// requestOrigin возвращает источник изменений запроса для журнала аудита: адрес клиента и ID запроса,
+// который выдает промежуточный обработчик requestid
+func requestOrigin(c *fiber.Ctx) models.Origin {
+	return models.Origin{Actor: "api:" + c.IP(), RequestID: c.GetRespHeader(fiber.HeaderXRequestID)}
+}
+
+// service возвращает сервис, изменения которого записываются в журнал аудита от имени запроса
+func (h *ApiWalletHandler) service(c *fiber.Ctx) service.WalletService {
+	return service.ScopeOrigin(h.walletService, requestOrigin(c))
+}
+
+// HandleBalance обрабатывает запрос на получение баланса кошелька.
+// С заголовком Consistency: eventual баланс может быть прочитан с реплики
 func (h *ApiWalletHandler) HandleBalance(c *fiber.Ctx) error {
 	walletID := c.Params("walletID")
-	if walletID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "walletID is required"})
+	var v ValidationError
+	if validateUUID(&v, "walletID", walletID); v.err() != nil {
+		return validationFailed(c, &v)
 	}
 
-	balance, err := h.walletService.GetBalance(walletID)
+	getWallet := h.walletService.GetWallet
+	if eventualRead(c) {
+		getWallet = h.walletService.GetWalletEventual
+	}
+	wallet, err := getWallet(walletID)
+	if errors.Is(err, repository.ErrWalletNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": repository.ErrWalletNotFound.Error()})
+	}
 	if err != nil {
 		h.logger.Errorf("Failed to get balance for wallet %s: %v", walletID, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "could not retrieve balance"})
 	}
 
-	return c.JSON(fiber.Map{"balance": balance})
+	// Версия кошелька отдается как ETag, чтобы клиент мог передать ее обратно в If-Match
+	c.Set(fiber.HeaderETag, formatETag(wallet.Version))
+	return c.JSON(fiber.Map{"balance": wallet.Balance})
 }
 
 type TransactionRequest struct {
@@ -47,29 +85,146 @@ type TransactionRequest struct {
 
 // HandleTransaction обрабатывает запрос на выполнение операции с кошельком
 func (h *ApiWalletHandler) HandleTransaction(c *fiber.Ctx) error {
-	var req TransactionRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request payload"})
+	req, err := parseTransactionRequest(c.Body())
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationFailed(c, validationErr)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	if req.Amount <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "amount must be positive"})
+	// При наличии If-Match операция выполняется только для указанной версии кошелька
+	if ifMatch := c.Get(fiber.HeaderIfMatch); ifMatch != "" && ifMatch != "*" {
+		version, ok := parseETag(ifMatch)
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid If-Match header"})
+		}
+		return h.handleConditionalTransaction(c, req, version)
 	}
 
-	var err error
 	switch req.OperationType {
 	case "DEPOSIT":
-		err = h.walletService.Deposit(req.WalletID, req.Amount)
+		err = h.service(c).Deposit(req.WalletID, req.Amount)
+	case "WITHDRAW":
+		err = h.service(c).Withdraw(req.WalletID, req.Amount)
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid operation type"})
+	}
+
+	if err != nil {
+		return h.transactionError(c, req.WalletID, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "transaction successful"})
+}
+
+// handleConditionalTransaction выполняет операцию с проверкой версии кошелька
+func (h *ApiWalletHandler) handleConditionalTransaction(c *fiber.Ctx, req TransactionRequest, version int64) error {
+	var (
+		newVersion int64
+		err        error
+	)
+	switch req.OperationType {
+	case "DEPOSIT":
+		newVersion, err = h.service(c).DepositIfVersion(req.WalletID, req.Amount, version)
 	case "WITHDRAW":
-		err = h.walletService.Withdraw(req.WalletID, req.Amount)
+		newVersion, err = h.service(c).WithdrawIfVersion(req.WalletID, req.Amount, version)
 	default:
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid operation type"})
 	}
 
 	if err != nil {
-		h.logger.Errorf("Failed to process transaction for wallet %s: %v", req.WalletID, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return h.transactionError(c, req.WalletID, err)
 	}
 
+	c.Set(fiber.HeaderETag, formatETag(newVersion))
 	return c.JSON(fiber.Map{"message": "transaction successful"})
 }
+
+// transactionError сопоставляет ошибку операции с HTTP-статусом. Клиент получает фиксированное сообщение,
+// подробности ошибки записываются в журнал приложения
+func (h *ApiWalletHandler) transactionError(c *fiber.Ctx, walletID string, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrWalletNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": repository.ErrWalletNotFound.Error()})
+	case errors.Is(err, repository.ErrVersionMismatch):
+		h.logger.Warnf("Precondition failed for wallet %s: %v", walletID, err)
+		return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{"error": repository.ErrVersionMismatch.Error()})
+	case errors.Is(err, repository.ErrWalletFrozen):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": repository.ErrWalletFrozen.Error()})
+	case errors.Is(err, repository.ErrInsufficientFunds):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": repository.ErrInsufficientFunds.Error()})
+	default:
+		h.logger.Errorf("Failed to process transaction for wallet %s: %v", walletID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "could not process transaction"})
+	}
+}
+
+type BatchRequest struct {
+	Mode       string             `json:"mode"` // "ATOMIC" (по умолчанию) or "BEST_EFFORT"
+	Operations []models.BatchItem `json:"operations"`
+}
+
+type BatchResponse struct {
+	Mode    string                   `json:"mode"`
+	Applied int                      `json:"applied"`
+	Failed  int                      `json:"failed"`
+	Results []models.BatchItemResult `json:"results"`
+	Error   string                   `json:"error,omitempty"`
+}
+
+// HandleBatch обрабатывает запрос на выполнение пакета операций. В атомарном режиме ошибка любой
+// операции отменяет пакет целиком, в режиме BEST_EFFORT выполняются все операции, которые возможно выполнить
+func (h *ApiWalletHandler) HandleBatch(c *fiber.Ctx) error {
+	req, err := parseBatchRequest(c.Body())
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationFailed(c, validationErr)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	results, err := h.service(c).ExecuteBatch(req.Operations, req.Mode)
+	if err != nil && !errors.Is(err, repository.ErrBatchRejected) {
+		h.logger.Errorf("Failed to process batch of %d operations: %v", len(req.Operations), err)
+		if errors.Is(err, service.ErrInvalidArgument) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "could not execute batch"})
+	}
+
+	resp := BatchResponse{Mode: req.Mode, Results: results}
+	for _, result := range results {
+		if result.Status == models.BatchItemApplied {
+			resp.Applied++
+		} else if result.Status == models.BatchItemFailed {
+			resp.Failed++
+		}
+	}
+	if err != nil {
+		// Атомарный пакет отклонен: ни одна операция не выполнена
+		resp.Error = err.Error()
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(resp)
+	}
+	return c.JSON(resp)
+}
+
+// formatETag представляет версию кошелька в виде сильного ETag
+func formatETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseETag извлекает версию кошелька из сильного ETag. Слабые ETag для If-Match не подходят
+func parseETag(tag string) (int64, bool) {
+	tag = strings.TrimSpace(tag)
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+	if err != nil || version < 0 {
+		return 0, false
+	}
+	return version, true
+}
@@ -8,8 +8,12 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service"
 	"github.com/VadimBorzenkov/WalletAPI/internal/service/mock"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/golang/mock/gomock"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -21,6 +25,7 @@ func TestHandleTransaction(t *testing.T) {
 	tests := []struct {
 		name         string                                                // Название теста
 		requestBody  TransactionRequest                                    // Тело запроса транзакции
+		ifMatch      string                                                // Значение заголовка If-Match
 		mockService  func(ctrl *gomock.Controller) *mock.MockWalletService // Мок сервис для тестирования
 		expectedCode int                                                   // Ожидаемый HTTP-код ответа
 	}{
@@ -28,14 +33,14 @@ func TestHandleTransaction(t *testing.T) {
 			// Успешный сценарий депозита
 			name: "Deposit Success",
 			requestBody: TransactionRequest{
-				WalletID:      "wallet-123",
+				WalletID:      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
 				OperationType: "DEPOSIT",
 				Amount:        100.0,
 			},
 			// Настраиваем mock для успешного вызова Deposit
 			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
 				s := mock.NewMockWalletService(ctrl)
-				s.EXPECT().Deposit("wallet-123", 100.0).Return(nil)
+				s.EXPECT().Deposit("3fa85f64-5717-4562-b3fc-2c963f66afa6", 100.0).Return(nil)
 				return s
 			},
 			expectedCode: http.StatusOK,
@@ -44,14 +49,14 @@ func TestHandleTransaction(t *testing.T) {
 			// Успешный сценарий вывода средств
 			name: "Withdraw Success",
 			requestBody: TransactionRequest{
-				WalletID:      "wallet-123",
+				WalletID:      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
 				OperationType: "WITHDRAW",
 				Amount:        50.0,
 			},
 			// Настраиваем mock для успешного вызова Withdraw
 			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
 				s := mock.NewMockWalletService(ctrl)
-				s.EXPECT().Withdraw("wallet-123", 50.0).Return(nil)
+				s.EXPECT().Withdraw("3fa85f64-5717-4562-b3fc-2c963f66afa6", 50.0).Return(nil)
 				return s
 			},
 			expectedCode: http.StatusOK,
@@ -60,7 +65,7 @@ func TestHandleTransaction(t *testing.T) {
 			// Некорректный тип операции
 			name: "Invalid Operation Type",
 			requestBody: TransactionRequest{
-				WalletID:      "wallet-123",
+				WalletID:      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
 				OperationType: "TRANSFER",
 				Amount:        50.0,
 			},
@@ -74,18 +79,130 @@ func TestHandleTransaction(t *testing.T) {
 			// Ошибка из-за недостатка средств на счете
 			name: "Withdraw Insufficient Funds",
 			requestBody: TransactionRequest{
-				WalletID:      "wallet-123",
+				WalletID:      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
 				OperationType: "WITHDRAW",
 				Amount:        200.0,
 			},
 			// Настраиваем mock для вызова Withdraw, возвращающего ошибку
 			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
 				s := mock.NewMockWalletService(ctrl)
-				s.EXPECT().Withdraw("wallet-123", 200.0).Return(fmt.Errorf("insufficient funds"))
+				s.EXPECT().Withdraw("3fa85f64-5717-4562-b3fc-2c963f66afa6", 200.0).Return(fmt.Errorf("could not withdraw amount: %w", repository.ErrInsufficientFunds))
+				return s
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			// Операция с несуществующим кошельком
+			name: "Deposit Wallet Not Found",
+			requestBody: TransactionRequest{
+				WalletID:      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+				OperationType: "DEPOSIT",
+				Amount:        10.0,
+			},
+			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
+				s := mock.NewMockWalletService(ctrl)
+				s.EXPECT().Deposit("3fa85f64-5717-4562-b3fc-2c963f66afa6", 10.0).Return(fmt.Errorf("could not deposit amount: %w", repository.ErrWalletNotFound))
+				return s
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			// Операция с замороженным кошельком
+			name: "Deposit Wallet Frozen",
+			requestBody: TransactionRequest{
+				WalletID:      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+				OperationType: "DEPOSIT",
+				Amount:        10.0,
+			},
+			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
+				s := mock.NewMockWalletService(ctrl)
+				s.EXPECT().Deposit("3fa85f64-5717-4562-b3fc-2c963f66afa6", 10.0).Return(fmt.Errorf("could not deposit amount: %w", repository.ErrWalletFrozen))
+				return s
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			// Внутренняя ошибка не раскрывается клиенту
+			name: "Deposit Internal Error",
+			requestBody: TransactionRequest{
+				WalletID:      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+				OperationType: "DEPOSIT",
+				Amount:        10.0,
+			},
+			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
+				s := mock.NewMockWalletService(ctrl)
+				s.EXPECT().Deposit("3fa85f64-5717-4562-b3fc-2c963f66afa6", 10.0).Return(fmt.Errorf("could not deposit amount: dial tcp 10.0.0.5:5432: connection refused"))
 				return s
 			},
 			expectedCode: http.StatusInternalServerError,
 		},
+		{
+			// Условный вывод средств при совпадении версии
+			name: "Withdraw If-Match Success",
+			requestBody: TransactionRequest{
+				WalletID:      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+				OperationType: "WITHDRAW",
+				Amount:        50.0,
+			},
+			ifMatch: `"3"`,
+			// Настраиваем mock для успешного вызова WithdrawIfVersion
+			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
+				s := mock.NewMockWalletService(ctrl)
+				s.EXPECT().WithdrawIfVersion("3fa85f64-5717-4562-b3fc-2c963f66afa6", 50.0, int64(3)).Return(int64(4), nil)
+				return s
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			// Версия кошелька изменилась с момента чтения
+			name: "Withdraw If-Match Version Mismatch",
+			requestBody: TransactionRequest{
+				WalletID:      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+				OperationType: "WITHDRAW",
+				Amount:        50.0,
+			},
+			ifMatch: `"3"`,
+			// Настраиваем mock для вызова WithdrawIfVersion, возвращающего конфликт версий
+			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
+				s := mock.NewMockWalletService(ctrl)
+				s.EXPECT().WithdrawIfVersion("3fa85f64-5717-4562-b3fc-2c963f66afa6", 50.0, int64(3)).
+					Return(int64(0), fmt.Errorf("could not withdraw amount: %w", repository.ErrVersionMismatch))
+				return s
+			},
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{
+			// Некорректное значение If-Match
+			name: "Invalid If-Match",
+			requestBody: TransactionRequest{
+				WalletID:      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+				OperationType: "DEPOSIT",
+				Amount:        50.0,
+			},
+			ifMatch: `W/"3"`,
+			// Ожидаем, что сервис не будет вызван
+			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
+				return mock.NewMockWalletService(ctrl)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			// If-Match: * не накладывает условий на версию
+			name: "Deposit If-Match Any",
+			requestBody: TransactionRequest{
+				WalletID:      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+				OperationType: "DEPOSIT",
+				Amount:        50.0,
+			},
+			ifMatch: "*",
+			// Настраиваем mock для безусловного вызова Deposit
+			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
+				s := mock.NewMockWalletService(ctrl)
+				s.EXPECT().Deposit("3fa85f64-5717-4562-b3fc-2c963f66afa6", 50.0).Return(nil)
+				return s
+			},
+			expectedCode: http.StatusOK,
+		},
 	}
 
 	// Выполняем каждый тестовый случай
@@ -105,6 +222,9 @@ func TestHandleTransaction(t *testing.T) {
 			body, _ := json.Marshal(tt.requestBody)
 			req := httptest.NewRequest(http.MethodPost, "/api/v1/transaction", bytes.NewBuffer(body))
 			req.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
 
 			// Выполняем запрос и проверяем код ответа
 			resp, _ := app.Test(req)
@@ -113,42 +233,128 @@ func TestHandleTransaction(t *testing.T) {
 	}
 }
 
+// scopedWalletService запоминает источники, от имени которых обработчик выполняет изменения
+type scopedWalletService struct {
+	*mock.MockWalletService
+	origins []models.Origin
+}
+
+func (s *scopedWalletService) WithOrigin(origin models.Origin) service.WalletService {
+	s.origins = append(s.origins, origin)
+	return s
+}
+
+// TestHandleTransactionOrigin проверяет, что изменение выполняется от имени клиента с ID запроса из X-Request-ID
+func TestHandleTransactionOrigin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := &scopedWalletService{MockWalletService: mock.NewMockWalletService(ctrl)}
+	svc.EXPECT().Deposit("3fa85f64-5717-4562-b3fc-2c963f66afa6", 50.0).Return(nil)
+
+	app := fiber.New()
+	app.Use(requestid.New())
+	app.Post("/api/v1/transaction", NewApiWalletHandler(svc, logrus.New()).HandleTransaction)
+
+	body, _ := json.Marshal(TransactionRequest{WalletID: "3fa85f64-5717-4562-b3fc-2c963f66afa6", OperationType: "DEPOSIT", Amount: 50})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transaction", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(fiber.HeaderXRequestID, "req-42")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []models.Origin{{Actor: "api:0.0.0.0", RequestID: "req-42"}}, svc.origins)
+}
+
 // TestHandleBalance проверяет обработчик HandleBalance для разных случаев получения баланса.
 func TestHandleBalance(t *testing.T) {
 	// Определяем тестовые случаи для метода HandleBalance
 	tests := []struct {
 		name         string                                                // Название теста
 		walletID     string                                                // ID кошелька для запроса баланса
+		consistency  string                                                // Заголовок Consistency
 		mockService  func(ctrl *gomock.Controller) *mock.MockWalletService // Mock сервис для тестирования
 		expectedCode int                                                   // Ожидаемый HTTP-код ответа
 		expectedBody string                                                // Ожидаемое тело ответа
+		expectedETag string                                                // Ожидаемый заголовок ETag
 	}{
 		{
 			// Успешный сценарий получения баланса
 			name:     "Balance Success",
-			walletID: "wallet-123",
-			// Настраиваем mock для успешного вызова GetBalance
+			walletID: "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+			// Настраиваем mock для успешного вызова GetWallet
 			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
 				s := mock.NewMockWalletService(ctrl)
-				s.EXPECT().GetBalance("wallet-123").Return(100.0, nil)
+				s.EXPECT().GetWallet("3fa85f64-5717-4562-b3fc-2c963f66afa6").Return(models.Wallet{ID: "3fa85f64-5717-4562-b3fc-2c963f66afa6", Balance: 100.0, Version: 7}, nil)
 				return s
 			},
 			expectedCode: http.StatusOK,
 			expectedBody: `{"balance":100}`,
+			expectedETag: `"7"`,
+		},
+		{
+			// С заголовком Consistency: eventual баланс читается с реплики
+			name:        "Balance Eventual",
+			walletID:    "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+			consistency: "eventual",
+			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
+				s := mock.NewMockWalletService(ctrl)
+				s.EXPECT().GetWalletEventual("3fa85f64-5717-4562-b3fc-2c963f66afa6").Return(models.Wallet{ID: "3fa85f64-5717-4562-b3fc-2c963f66afa6", Balance: 100.0, Version: 6}, nil)
+				return s
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"balance":100}`,
+			expectedETag: `"6"`,
+		},
+		{
+			// Явная строгая согласованность читает баланс с основной базы
+			name:        "Balance Strong",
+			walletID:    "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+			consistency: "strong",
+			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
+				s := mock.NewMockWalletService(ctrl)
+				s.EXPECT().GetWallet("3fa85f64-5717-4562-b3fc-2c963f66afa6").Return(models.Wallet{ID: "3fa85f64-5717-4562-b3fc-2c963f66afa6", Balance: 100.0, Version: 7}, nil)
+				return s
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"balance":100}`,
+			expectedETag: `"7"`,
 		},
 		{
 			// Ошибка при получении баланса
 			name:     "Balance Error",
-			walletID: "wallet-123",
-			// Настраиваем mock для вызова GetBalance, возвращающего ошибку
+			walletID: "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+			// Настраиваем mock для вызова GetWallet, возвращающего ошибку
 			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
 				s := mock.NewMockWalletService(ctrl)
-				s.EXPECT().GetBalance("wallet-123").Return(0.0, fmt.Errorf("could not retrieve balance"))
+				s.EXPECT().GetWallet("3fa85f64-5717-4562-b3fc-2c963f66afa6").Return(models.Wallet{}, fmt.Errorf("could not retrieve balance"))
 				return s
 			},
 			expectedCode: http.StatusInternalServerError,
 			expectedBody: `{"error":"could not retrieve balance"}`,
 		},
+		{
+			// Кошелек не найден
+			name:     "Balance Wallet Not Found",
+			walletID: "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
+				s := mock.NewMockWalletService(ctrl)
+				s.EXPECT().GetWallet("3fa85f64-5717-4562-b3fc-2c963f66afa6").Return(models.Wallet{}, fmt.Errorf("could not retrieve balance: %w", repository.ErrWalletNotFound))
+				return s
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"error":"wallet not found"}`,
+		},
+		{
+			// ID кошелька не является UUID, запрос не доходит до сервиса
+			name:     "Balance Invalid Wallet ID",
+			walletID: "wallet-123",
+			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
+				return mock.NewMockWalletService(ctrl)
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"validation failed","details":[{"field":"walletID","message":"must be a valid UUID"}]}`,
+		},
 	}
 
 	// Выполняем каждый тестовый случай
@@ -166,10 +372,14 @@ func TestHandleBalance(t *testing.T) {
 
 			// Создаем GET запрос для проверки баланса
 			req := httptest.NewRequest(http.MethodGet, "/api/v1/wallet/"+tt.walletID, nil)
+			if tt.consistency != "" {
+				req.Header.Set(ConsistencyHeader, tt.consistency)
+			}
 			resp, _ := app.Test(req)
 
-			// Проверяем код ответа
+			// Проверяем код ответа и версию кошелька в ETag
 			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+			assert.Equal(t, tt.expectedETag, resp.Header.Get("ETag"))
 
 			// Декодируем тело ответа для проверки содержимого
 			var respBody map[string]interface{}
@@ -179,9 +389,240 @@ func TestHandleBalance(t *testing.T) {
 				// Проверяем баланс, если запрос успешен
 				assert.Equal(t, respBody["balance"], float64(100.0))
 			} else {
-				// Проверяем наличие ошибки в ответе, если запрос завершился ошибкой
-				assert.Contains(t, respBody["error"], "could not retrieve balance")
+				// Проверяем ошибку в ответе, если запрос завершился ошибкой
+				var expectedBody map[string]interface{}
+				json.Unmarshal([]byte(tt.expectedBody), &expectedBody)
+				assert.Equal(t, expectedBody, respBody)
+			}
+		})
+	}
+}
+
+// TestHandleTransactionValidation проверяет, что HandleTransaction возвращает все ошибки полей запроса сразу.
+func TestHandleTransactionValidation(t *testing.T) {
+	tests := []struct {
+		name            string       // Название теста
+		body            string       // Тело запроса
+		expectedCode    int          // Ожидаемый HTTP-код ответа
+		expectedDetails []FieldError // Ожидаемые ошибки полей
+	}{
+		{
+			// Тело запроса не является JSON-объектом
+			name:         "Malformed JSON",
+			body:         `{"walletId":`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			// Пустой объект: все поля обязательны
+			name:         "Missing Fields",
+			body:         `{}`,
+			expectedCode: http.StatusBadRequest,
+			expectedDetails: []FieldError{
+				{Field: "walletId", Message: "is required"},
+				{Field: "operationType", Message: "is required"},
+				{Field: "amount", Message: "is required"},
+			},
+		},
+		{
+			// Ошибки во всех полях и неизвестное поле возвращаются одним ответом
+			name:         "All Fields Invalid",
+			body:         `{"walletId":"","operationType":"deposit","amount":-5,"currency":"RUB"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedDetails: []FieldError{
+				{Field: "currency", Message: "unknown field"},
+				{Field: "walletId", Message: "is required"},
+				{Field: "operationType", Message: "must be one of DEPOSIT, WITHDRAW"},
+				{Field: "amount", Message: "must be positive"},
+			},
+		},
+		{
+			// Неверные типы полей
+			name:         "Wrong Types",
+			body:         `{"walletId":123,"operationType":"DEPOSIT","amount":"10"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedDetails: []FieldError{
+				{Field: "walletId", Message: "must be a string"},
+				{Field: "amount", Message: "must be a number"},
+			},
+		},
+		{
+			// ID кошелька не является UUID, сумма превышает максимум
+			name:         "Invalid UUID And Amount Too Large",
+			body:         `{"walletId":"wallet-123","operationType":"WITHDRAW","amount":1000000000.01}`,
+			expectedCode: http.StatusBadRequest,
+			expectedDetails: []FieldError{
+				{Field: "walletId", Message: "must be a valid UUID"},
+				{Field: "amount", Message: "must not exceed 1000000000"},
+			},
+		},
+		{
+			// Сумма с точностью больше двух знаков после запятой
+			name:         "Amount Precision",
+			body:         `{"walletId":"3fa85f64-5717-4562-b3fc-2c963f66afa6","operationType":"DEPOSIT","amount":10.005}`,
+			expectedCode: http.StatusBadRequest,
+			expectedDetails: []FieldError{
+				{Field: "amount", Message: "must have at most 2 decimal places"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Сервис не должен вызываться ни в одном из случаев
+			app := fiber.New()
+			apiHandler := NewApiWalletHandler(mock.NewMockWalletService(ctrl), logrus.New())
+			app.Post("/api/v1/transaction", apiHandler.HandleTransaction)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/transaction", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+
+			var respBody struct {
+				Error   string       `json:"error"`
+				Details []FieldError `json:"details"`
+			}
+			json.NewDecoder(resp.Body).Decode(&respBody)
+			assert.NotEmpty(t, respBody.Error)
+			assert.Equal(t, tt.expectedDetails, respBody.Details)
+		})
+	}
+}
+
+// TestValidateAmount проверяет допустимые суммы на границах точности и максимума.
+func TestValidateAmount(t *testing.T) {
+	for _, amount := range []float64{0.01, 0.29, 19.99, 1234567.89, MaxTransactionAmount} {
+		var v ValidationError
+		validateAmount(&v, "amount", amount)
+		assert.NoError(t, v.err(), "amount %v", amount)
+	}
+}
+
+// TestHandleBatch проверяет обработчик HandleBatch для атомарного режима, режима best-effort и ошибок проверки.
+func TestHandleBatch(t *testing.T) {
+	const (
+		walletA = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+		walletB = "9b2d7c1e-0f4a-4e8b-a1c3-5d6e7f809a1b"
+	)
+	items := []models.BatchItem{
+		{OperationType: "DEPOSIT", WalletID: walletA, Amount: 10},
+		{OperationType: "TRANSFER", WalletID: walletA, ToWalletID: walletB, Amount: 5},
+	}
+	body := `{"operations":[
+		{"operationType":"DEPOSIT","walletId":"` + walletA + `","amount":10},
+		{"operationType":"TRANSFER","walletId":"` + walletA + `","toWalletId":"` + walletB + `","amount":5}]`
+
+	tests := []struct {
+		name            string                                                // Название теста
+		body            string                                                // Тело запроса
+		mockService     func(ctrl *gomock.Controller) *mock.MockWalletService // Мок сервис для тестирования
+		expectedCode    int                                                   // Ожидаемый HTTP-код ответа
+		expectedApplied int                                                   // Ожидаемое количество выполненных операций
+		expectedFailed  int                                                   // Ожидаемое количество невыполненных операций
+		expectedDetails []FieldError                                          // Ожидаемые ошибки полей
+	}{
+		{
+			// По умолчанию пакет выполняется атомарно
+			name: "Atomic Success",
+			body: body + `}`,
+			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
+				s := mock.NewMockWalletService(ctrl)
+				s.EXPECT().ExecuteBatch(items, models.BatchAtomic).Return([]models.BatchItemResult{
+					{Index: 0, Status: models.BatchItemApplied},
+					{Index: 1, Status: models.BatchItemApplied},
+				}, nil)
+				return s
+			},
+			expectedCode:    http.StatusOK,
+			expectedApplied: 2,
+		},
+		{
+			// Отклоненный атомарный пакет
+			name: "Atomic Rejected",
+			body: body + `}`,
+			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
+				s := mock.NewMockWalletService(ctrl)
+				s.EXPECT().ExecuteBatch(items, models.BatchAtomic).Return([]models.BatchItemResult{
+					{Index: 0, Status: models.BatchItemRolledBack},
+					{Index: 1, Status: models.BatchItemFailed, Error: "insufficient funds"},
+				}, repository.ErrBatchRejected)
+				return s
+			},
+			expectedCode:   http.StatusUnprocessableEntity,
+			expectedFailed: 1,
+		},
+		{
+			// В режиме best-effort частичный успех не является ошибкой
+			name: "Best Effort Partial",
+			body: body + `,"mode":"BEST_EFFORT"}`,
+			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
+				s := mock.NewMockWalletService(ctrl)
+				s.EXPECT().ExecuteBatch(items, models.BatchBestEffort).Return([]models.BatchItemResult{
+					{Index: 0, Status: models.BatchItemApplied},
+					{Index: 1, Status: models.BatchItemFailed, Error: "insufficient funds"},
+				}, nil)
+				return s
+			},
+			expectedCode:    http.StatusOK,
+			expectedApplied: 1,
+			expectedFailed:  1,
+		},
+		{
+			// Ошибки операций возвращаются с индексом операции
+			name: "Validation Errors",
+			body: `{"mode":"PARTIAL","operations":[
+				{"operationType":"DEPOSIT","walletId":"` + walletA + `","toWalletId":"` + walletB + `","amount":1},
+				{"operationType":"TRANSFER","walletId":"` + walletA + `","toWalletId":"` + walletA + `","amount":0.001},
+				null]}`,
+			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
+				return mock.NewMockWalletService(ctrl)
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedDetails: []FieldError{
+				{Field: "mode", Message: "must be one of ATOMIC, BEST_EFFORT"},
+				{Field: "operations[0].toWalletId", Message: "is allowed only for TRANSFER"},
+				{Field: "operations[1].toWalletId", Message: "must differ from walletId"},
+				{Field: "operations[1].amount", Message: "must have at most 2 decimal places"},
+				{Field: "operations[2]", Message: "must be an object"},
+			},
+		},
+		{
+			// Пустой пакет
+			name: "Empty Batch",
+			body: `{"operations":[]}`,
+			mockService: func(ctrl *gomock.Controller) *mock.MockWalletService {
+				return mock.NewMockWalletService(ctrl)
+			},
+			expectedCode:    http.StatusBadRequest,
+			expectedDetails: []FieldError{{Field: "operations", Message: "must contain at least one operation"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			app := fiber.New()
+			apiHandler := NewApiWalletHandler(tt.mockService(ctrl), logrus.New())
+			app.Post("/api/v1/transactions/batch", apiHandler.HandleBatch)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions/batch", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+
+			var respBody struct {
+				BatchResponse
+				Details []FieldError `json:"details"`
 			}
+			json.NewDecoder(resp.Body).Decode(&respBody)
+			assert.Equal(t, tt.expectedApplied, respBody.Applied)
+			assert.Equal(t, tt.expectedFailed, respBody.Failed)
+			assert.Equal(t, tt.expectedDetails, respBody.Details)
 		})
 	}
 }
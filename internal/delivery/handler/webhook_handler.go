@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type WebhookHandler interface {
+	HandleCreateEndpoint(c *fiber.Ctx) error
+	HandleListEndpoints(c *fiber.Ctx) error
+	HandleDeleteEndpoint(c *fiber.Ctx) error
+	HandleListDeliveries(c *fiber.Ctx) error
+	HandleRedeliver(c *fiber.Ctx) error
+}
+
+type ApiWebhookHandler struct {
+	webhookService service.WebhookService
+	logger         *logrus.Logger
+}
+
+func NewApiWebhookHandler(webhookService service.WebhookService, logger *logrus.Logger) *ApiWebhookHandler {
+	return &ApiWebhookHandler{
+		webhookService: webhookService,
+		logger:         logger,
+	}
+}
+
+type WebhookEndpointRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"eventTypes"`
+}
+
+// HandleCreateEndpoint регистрирует получателя вебхуков
+func (h *ApiWebhookHandler) HandleCreateEndpoint(c *fiber.Ctx) error {
+	var req WebhookEndpointRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request payload"})
+	}
+
+	endpoint, err := h.webhookService.RegisterEndpoint(req.URL, req.Secret, req.EventTypes)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(endpoint)
+}
+
+// HandleListEndpoints возвращает зарегистрированных получателей вебхуков
+func (h *ApiWebhookHandler) HandleListEndpoints(c *fiber.Ctx) error {
+	endpoints, err := h.webhookService.ListEndpoints()
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"endpoints": endpoints})
+}
+
+// HandleDeleteEndpoint удаляет получателя вебхуков
+func (h *ApiWebhookHandler) HandleDeleteEndpoint(c *fiber.Ctx) error {
+	endpointID := c.Params("endpointID")
+	var v ValidationError
+	if validateUUID(&v, "endpointID", endpointID); v.err() != nil {
+		return validationFailed(c, &v)
+	}
+
+	if err := h.webhookService.DeleteEndpoint(endpointID); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleListDeliveries возвращает журнал доставок получателя с фильтром по статусу
+func (h *ApiWebhookHandler) HandleListDeliveries(c *fiber.Ctx) error {
+	endpointID := c.Params("endpointID")
+	var v ValidationError
+	if validateUUID(&v, "endpointID", endpointID); v.err() != nil {
+		return validationFailed(c, &v)
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(endpointID, c.Query("status"), c.QueryInt("limit"))
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"deliveries": deliveries})
+}
+
+// HandleRedeliver назначает немедленную повторную отправку доставки
+func (h *ApiWebhookHandler) HandleRedeliver(c *fiber.Ctx) error {
+	deliveryID, err := strconv.ParseInt(c.Params("deliveryID"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid deliveryID"})
+	}
+
+	delivery, err := h.webhookService.Redeliver(deliveryID)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.Status(fiber.StatusAccepted).JSON(delivery)
+}
+
+// errorResponse сопоставляет ошибку сервиса с HTTP-статусом
+func (h *ApiWebhookHandler) errorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidArgument):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, repository.ErrEndpointNotFound), errors.Is(err, repository.ErrDeliveryNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	default:
+		h.logger.Errorf("Webhook admin request failed: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service/mock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHandleCreateEndpoint проверяет регистрацию получателя вебхуков
+func TestHandleCreateEndpoint(t *testing.T) {
+	tests := []struct {
+		name         string                                                 // Название теста
+		requestBody  WebhookEndpointRequest                                 // Тело запроса
+		mockService  func(ctrl *gomock.Controller) *mock.MockWebhookService // Мок сервис для тестирования
+		expectedCode int                                                    // Ожидаемый HTTP-код ответа
+	}{
+		{
+			name:        "Create Success",
+			requestBody: WebhookEndpointRequest{URL: "https://merchant.example/hooks", EventTypes: []string{models.EventWalletDeposited}},
+			mockService: func(ctrl *gomock.Controller) *mock.MockWebhookService {
+				s := mock.NewMockWebhookService(ctrl)
+				s.EXPECT().RegisterEndpoint("https://merchant.example/hooks", "", []string{models.EventWalletDeposited}).
+					Return(models.WebhookEndpoint{ID: "endpoint-1"}, nil)
+				return s
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:        "Validation Error",
+			requestBody: WebhookEndpointRequest{URL: "not-a-url"},
+			mockService: func(ctrl *gomock.Controller) *mock.MockWebhookService {
+				s := mock.NewMockWebhookService(ctrl)
+				s.EXPECT().RegisterEndpoint("not-a-url", "", nil).
+					Return(models.WebhookEndpoint{}, fmt.Errorf("%w: url must be an absolute http(s) URL", service.ErrInvalidArgument))
+				return s
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			app := fiber.New()
+			apiHandler := NewApiWebhookHandler(tt.mockService(ctrl), logrus.New())
+			app.Post("/api/v1/admin/webhooks", apiHandler.HandleCreateEndpoint)
+
+			body, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/webhooks", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, _ := app.Test(req)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+		})
+	}
+}
+
+// TestHandleRedeliver проверяет ручную повторную отправку доставки
+func TestHandleRedeliver(t *testing.T) {
+	tests := []struct {
+		name         string                                                 // Название теста
+		deliveryID   string                                                 // ID доставки в пути запроса
+		mockService  func(ctrl *gomock.Controller) *mock.MockWebhookService // Мок сервис для тестирования
+		expectedCode int                                                    // Ожидаемый HTTP-код ответа
+	}{
+		{
+			name:       "Redeliver Success",
+			deliveryID: "42",
+			mockService: func(ctrl *gomock.Controller) *mock.MockWebhookService {
+				s := mock.NewMockWebhookService(ctrl)
+				s.EXPECT().Redeliver(int64(42)).Return(models.WebhookDelivery{ID: 42, Status: models.DeliveryPending}, nil)
+				return s
+			},
+			expectedCode: http.StatusAccepted,
+		},
+		{
+			name:       "Delivery Not Found",
+			deliveryID: "43",
+			mockService: func(ctrl *gomock.Controller) *mock.MockWebhookService {
+				s := mock.NewMockWebhookService(ctrl)
+				s.EXPECT().Redeliver(int64(43)).Return(models.WebhookDelivery{}, fmt.Errorf("could not redeliver webhook: %w", repository.ErrDeliveryNotFound))
+				return s
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:       "Invalid Delivery ID",
+			deliveryID: "abc",
+			mockService: func(ctrl *gomock.Controller) *mock.MockWebhookService {
+				return mock.NewMockWebhookService(ctrl)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			app := fiber.New()
+			apiHandler := NewApiWebhookHandler(tt.mockService(ctrl), logrus.New())
+			app.Post("/api/v1/admin/webhooks/deliveries/:deliveryID/redeliver", apiHandler.HandleRedeliver)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/webhooks/deliveries/"+tt.deliveryID+"/redeliver", nil)
+			resp, _ := app.Test(req)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+		})
+	}
+}
+
+// TestHandleDeleteEndpoint проверяет удаление получателя вебхуков
+func TestHandleDeleteEndpoint(t *testing.T) {
+	tests := []struct {
+		name         string                                                 // Название теста
+		endpointID   string                                                 // ID получателя в пути запроса
+		mockService  func(ctrl *gomock.Controller) *mock.MockWebhookService // Мок сервис для тестирования
+		expectedCode int                                                    // Ожидаемый HTTP-код ответа
+	}{
+		{
+			name:       "Delete Success",
+			endpointID: "5e0c1a2b-3d4f-4a6b-8c7d-9e0f1a2b3c4d",
+			mockService: func(ctrl *gomock.Controller) *mock.MockWebhookService {
+				s := mock.NewMockWebhookService(ctrl)
+				s.EXPECT().DeleteEndpoint("5e0c1a2b-3d4f-4a6b-8c7d-9e0f1a2b3c4d").Return(nil)
+				return s
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:       "Endpoint Not Found",
+			endpointID: "5e0c1a2b-3d4f-4a6b-8c7d-9e0f1a2b3c4d",
+			mockService: func(ctrl *gomock.Controller) *mock.MockWebhookService {
+				s := mock.NewMockWebhookService(ctrl)
+				s.EXPECT().DeleteEndpoint("5e0c1a2b-3d4f-4a6b-8c7d-9e0f1a2b3c4d").
+					Return(fmt.Errorf("could not delete webhook endpoint: %w", repository.ErrEndpointNotFound))
+				return s
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:       "Invalid Endpoint ID",
+			endpointID: "endpoint-1",
+			mockService: func(ctrl *gomock.Controller) *mock.MockWebhookService {
+				return mock.NewMockWebhookService(ctrl)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			app := fiber.New()
+			apiHandler := NewApiWebhookHandler(tt.mockService(ctrl), logrus.New())
+			app.Delete("/api/v1/admin/webhooks/:endpointID", apiHandler.HandleDeleteEndpoint)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/webhooks/"+tt.endpointID, nil)
+			resp, _ := app.Test(req)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+		})
+	}
+}
+
+// TestHandleListDeliveries проверяет журнал доставок получателя
+func TestHandleListDeliveries(t *testing.T) {
+	tests := []struct {
+		name         string                                                 // Название теста
+		endpointID   string                                                 // ID получателя в пути запроса
+		mockService  func(ctrl *gomock.Controller) *mock.MockWebhookService // Мок сервис для тестирования
+		expectedCode int                                                    // Ожидаемый HTTP-код ответа
+	}{
+		{
+			name:       "List Success",
+			endpointID: "5e0c1a2b-3d4f-4a6b-8c7d-9e0f1a2b3c4d",
+			mockService: func(ctrl *gomock.Controller) *mock.MockWebhookService {
+				s := mock.NewMockWebhookService(ctrl)
+				s.EXPECT().ListDeliveries("5e0c1a2b-3d4f-4a6b-8c7d-9e0f1a2b3c4d", models.DeliveryDead, 0).
+					Return([]models.WebhookDelivery{{ID: 1, Status: models.DeliveryDead}}, nil)
+				return s
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:       "Invalid Endpoint ID",
+			endpointID: "endpoint-1",
+			mockService: func(ctrl *gomock.Controller) *mock.MockWebhookService {
+				return mock.NewMockWebhookService(ctrl)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			app := fiber.New()
+			apiHandler := NewApiWebhookHandler(tt.mockService(ctrl), logrus.New())
+			app.Get("/api/v1/admin/webhooks/:endpointID/deliveries", apiHandler.HandleListDeliveries)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/webhooks/"+tt.endpointID+"/deliveries?status=DEAD", nil)
+			resp, _ := app.Test(req)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+		})
+	}
+}
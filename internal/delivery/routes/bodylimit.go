@@ -0,0 +1,64 @@
+package routes
+
+import (
+	"io"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// payoutUploadPath — маршрут загрузки файла выплат, единственный, которому разрешено тело больше общего лимита
+const payoutUploadPath = "/api/v1/jobs/payouts"
+
+// AppConfig возвращает настройки Fiber, при которых лимиты тела запроса задаются маршрутами. Тело читается
+// потоком: fasthttp не отклоняет большие запросы сам, а bodyLimit дочитывает тело не дальше лимита маршрута.
+// Multipart не разбирается заранее, чтобы файл не попадал во временные файлы до проверки размера
+func AppConfig() fiber.Config {
+	return fiber.Config{
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
+	}
+}
+
+// bodyLimit отвечает 413 на тело запроса больше limit байт, а меньшее дочитывает в память, чтобы обработчики
+// работали с ним как обычно. Запросы, для которых next возвращает true, пропускаются без проверки
+func bodyLimit(limit int, next func(c *fiber.Ctx) bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if next != nil && next(c) {
+			return c.Next()
+		}
+
+		req := c.Request()
+		if req.Header.ContentLength() > limit {
+			return bodyTooLarge(c)
+		}
+		if !req.IsBodyStream() {
+			if len(req.Body()) > limit {
+				return bodyTooLarge(c)
+			}
+			return c.Next()
+		}
+
+		body, err := io.ReadAll(io.LimitReader(req.BodyStream(), int64(limit)+1))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "could not read request body"})
+		}
+		if len(body) > limit {
+			return bodyTooLarge(c)
+		}
+		req.SetBody(body)
+		return c.Next()
+	}
+}
+
+func bodyTooLarge(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderConnection, "close")
+	return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": "request body is too large"})
+}
+
+// isPayoutUpload сообщает, что запрос загружает файл выплат. Маршруты Fiber по умолчанию не различают регистр
+// и завершающий слэш, поэтому путь сравнивается так же
+func isPayoutUpload(c *fiber.Ctx) bool {
+	return c.Method() == fiber.MethodPost &&
+		strings.EqualFold(strings.TrimSuffix(c.Path(), "/"), payoutUploadPath)
+}
@@ -0,0 +1,77 @@
+package routes
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBodyLimit проверяет, что большое тело принимает только загрузка файла выплат, в том числе без Content-Length
+func TestBodyLimit(t *testing.T) {
+	tests := []struct {
+		name     string // Название теста
+		method   string // HTTP-метод запроса
+		path     string // Путь запроса
+		size     int    // Размер тела в байтах
+		chunked  bool   // Передавать ли тело без Content-Length
+		expected int    // Ожидаемый HTTP-статус
+	}{
+		{name: "Transaction Over Default Limit", method: fiber.MethodPatch, path: "/api/v1/wallets/", size: fiber.DefaultBodyLimit + 1, expected: fiber.StatusRequestEntityTooLarge},
+		{name: "Chunked Transaction Over Default Limit", method: fiber.MethodPatch, path: "/api/v1/wallets/", size: fiber.DefaultBodyLimit + 1, chunked: true, expected: fiber.StatusRequestEntityTooLarge},
+		{name: "Batch Over Default Limit", method: fiber.MethodPost, path: "/api/v1/transactions/batch", size: fiber.DefaultBodyLimit + 1, expected: fiber.StatusRequestEntityTooLarge},
+		{name: "Transaction Within Default Limit", method: fiber.MethodPatch, path: "/api/v1/wallets/", size: 16, expected: fiber.StatusBadRequest},
+		{name: "Payout Upload Over Default Limit", method: fiber.MethodPost, path: "/api/v1/jobs/payouts", size: fiber.DefaultBodyLimit + 1, expected: fiber.StatusUnsupportedMediaType},
+		{name: "Chunked Payout Upload Over Default Limit", method: fiber.MethodPost, path: "/api/v1/jobs/payouts", size: fiber.DefaultBodyLimit + 1, chunked: true, expected: fiber.StatusUnsupportedMediaType},
+		{name: "Payout Upload Over Upload Limit", method: fiber.MethodPost, path: "/api/v1/jobs/payouts", size: testUploadLimit + 1, expected: fiber.StatusRequestEntityTooLarge},
+		{name: "Chunked Payout Upload Over Upload Limit", method: fiber.MethodPost, path: "/api/v1/jobs/payouts", size: testUploadLimit + 1, chunked: true, expected: fiber.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			app, _ := newTestApp(ctrl)
+
+			body := bytes.Repeat([]byte("x"), tt.size)
+			var (
+				resp *http.Response
+				err  error
+			)
+			if tt.chunked {
+				resp, err = sendChunked(t, app, tt.method, tt.path, body)
+			} else {
+				req := httptest.NewRequest(tt.method, tt.path, bytes.NewReader(body))
+				req.Header.Set(fiber.HeaderContentType, fiber.MIMEOctetStream)
+				resp, err = app.Test(req, -1)
+			}
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expected, resp.StatusCode)
+		})
+	}
+}
+
+// sendChunked отправляет тело с Transfer-Encoding: chunked через настоящее соединение: app.Test всегда
+// проставляет Content-Length
+func sendChunked(t *testing.T, app *fiber.App, method, path string, body []byte) (*http.Response, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = app.Listener(ln) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+
+	// MultiReader скрывает размер тела, и клиент не может проставить Content-Length
+	req, err := http.NewRequest(method, "http://"+ln.Addr().String()+path, io.MultiReader(bytes.NewReader(body)))
+	require.NoError(t, err)
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEOctetStream)
+	return http.DefaultClient.Do(req)
+}
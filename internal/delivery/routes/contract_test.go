@@ -0,0 +1,604 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/api"
+	"github.com/VadimBorzenkov/WalletAPI/internal/delivery/handler"
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service/mock"
+	"github.com/VadimBorzenkov/WalletAPI/internal/statement"
+	"github.com/VadimBorzenkov/WalletAPI/internal/stream"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAdminToken = "test-token"
+	testWalletID   = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	otherWalletID  = "9b2d7c1e-0f4a-4e8b-a1c3-5d6e7f809a1b"
+	testJobID      = "c1d2e3f4-5a6b-4c7d-8e9f-0a1b2c3d4e5f"
+	testEndpointID = "5e0c1a2b-3d4f-4a6b-8c7d-9e0f1a2b3c4d"
+
+	testUploadLimit = 2 * fiber.DefaultBodyLimit
+)
+
+// testServices объединяет мок сервисы, на которых строится приложение для контрактных тестов
+type testServices struct {
+	wallet  *mock.MockWalletService
+	webhook *mock.MockWebhookService
+	payout  *mock.MockPayoutService
+	admin   *mock.MockAdminService
+	report  *mock.MockReportService
+}
+
+// newTestApp регистрирует маршруты приложения с настоящими обработчиками поверх мок сервисов
+func newTestApp(ctrl *gomock.Controller) (*fiber.App, testServices) {
+	services := testServices{
+		wallet:  mock.NewMockWalletService(ctrl),
+		webhook: mock.NewMockWebhookService(ctrl),
+		payout:  mock.NewMockPayoutService(ctrl),
+		admin:   mock.NewMockAdminService(ctrl),
+		report:  mock.NewMockReportService(ctrl),
+	}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	app := fiber.New(AppConfig())
+	SetupRoutes(app, Handlers{
+		Wallet:         handler.NewApiWalletHandler(services.wallet, logger),
+		Stream:         handler.NewApiStreamHandler(services.wallet, stream.NewHub(), logger, time.Minute, 1),
+		Webhook:        handler.NewApiWebhookHandler(services.webhook, logger),
+		Payout:         handler.NewApiPayoutHandler(services.payout, logger),
+		Audit:          handler.NewApiAuditHandler(services.admin, logger),
+		Reconciliation: handler.NewApiReconciliationHandler(services.admin, logger),
+		Report:         handler.NewApiReportHandler(services.report, logger),
+
+		PayoutMaxUploadSize: testUploadLimit,
+	}, testAdminToken)
+	return app, services
+}
+
+// loadSpec загружает и проверяет встроенную спецификацию OpenAPI
+func loadSpec(t *testing.T) (*openapi3.T, routers.Router) {
+	doc, err := openapi3.NewLoader().LoadFromData(api.OpenAPISpec)
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(context.Background()))
+
+	router, err := legacy.NewRouter(doc)
+	require.NoError(t, err)
+	return doc, router
+}
+
+var fiberParam = regexp.MustCompile(`:(\w+)`)
+
+// requireBearer проверяет наличие токена для операций, защищенных схемой bearerAuth
+func requireBearer(_ context.Context, input *openapi3filter.AuthenticationInput) error {
+	if !strings.HasPrefix(input.RequestValidationInput.Request.Header.Get("Authorization"), "Bearer ") {
+		return errors.New("missing bearer token")
+	}
+	return nil
+}
+
+// TestSpecCoversRoutes проверяет, что спецификация описывает ровно те маршруты, которые регистрирует SetupRoutes
+func TestSpecCoversRoutes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	doc, _ := loadSpec(t)
+	app, _ := newTestApp(ctrl)
+
+	registered := make(map[string]bool)
+	for _, route := range app.GetRoutes(true) {
+		// HEAD Fiber добавляет к каждому GET автоматически
+		if route.Method == fiber.MethodHead {
+			continue
+		}
+		path := fiberParam.ReplaceAllString(route.Path, "{$1}")
+		registered[route.Method+" "+path] = true
+
+		item := doc.Paths.Find(path)
+		if assert.NotNil(t, item, "route %s %s is missing from openapi.json", route.Method, path) {
+			assert.NotNil(t, item.GetOperation(route.Method), "operation %s %s is missing from openapi.json", route.Method, path)
+		}
+	}
+
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			assert.True(t, registered[method+" "+path], "openapi.json describes %s %s, which is not registered", method, path)
+		}
+	}
+}
+
+// TestContract проверяет запросы и настоящие ответы обработчиков на соответствие спецификации
+func TestContract(t *testing.T) {
+	_, router := loadSpec(t)
+
+	tests := []struct {
+		name         string                      // Название теста
+		method       string                      // HTTP-метод
+		path         string                      // Путь запроса
+		body         interface{}                 // Тело запроса; строка передается как есть, остальное кодируется в JSON
+		headers      map[string]string           // Дополнительные заголовки
+		validRequest bool                        // Запрос должен соответствовать спецификации
+		mockServices func(services testServices) // Настройка мок сервисов
+		expectedCode int                         // Ожидаемый HTTP-код ответа
+	}{
+		{
+			name:         "Get Balance",
+			method:       http.MethodGet,
+			path:         "/api/v1/wallets/" + testWalletID,
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.wallet.EXPECT().GetWallet(testWalletID).Return(models.Wallet{ID: testWalletID, Balance: 100, Version: 2}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Get Balance Error",
+			method:       http.MethodGet,
+			path:         "/api/v1/wallets/" + testWalletID,
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.wallet.EXPECT().GetWallet(testWalletID).Return(models.Wallet{}, fmt.Errorf("could not retrieve balance"))
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:         "Get Balance At",
+			method:       http.MethodGet,
+			path:         "/api/v1/wallets/" + testWalletID + "/balance?at=2026-09-30T23:59:59Z",
+			validRequest: true,
+			mockServices: func(s testServices) {
+				at := time.Date(2026, 9, 30, 23, 59, 59, 0, time.UTC)
+				s.report.EXPECT().BalanceAt(testWalletID, at).Return(models.BalanceAt{WalletID: testWalletID, At: at, Balance: 75.5}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Get Balance At Not Found",
+			method:       http.MethodGet,
+			path:         "/api/v1/wallets/" + testWalletID + "/balance?at=2026-09-30T23:59:59Z",
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.report.EXPECT().BalanceAt(testWalletID, gomock.Any()).Return(models.BalanceAt{}, repository.ErrWalletNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Get Statement",
+			method:       http.MethodGet,
+			path:         "/api/v1/wallets/" + testWalletID + "/statement?from=2026-09-01T00:00:00Z&to=2026-10-01T00:00:00Z",
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.report.EXPECT().Statement(testWalletID, gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(walletID string, from, to time.Time, w statement.Writer) error {
+						if err := w.Opening(100); err != nil {
+							return err
+						}
+						op := models.Operation{ID: 1, WalletID: walletID, Type: models.OperationDeposit, Amount: 25, BalanceAfter: 125, Version: 3, CreatedAt: from.Add(time.Hour)}
+						if err := w.Operation(op); err != nil {
+							return err
+						}
+						return w.Closing(125)
+					})
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Get Statement CSV",
+			method:       http.MethodGet,
+			path:         "/api/v1/wallets/" + testWalletID + "/statement?from=2026-09-01T00:00:00Z&to=2026-10-01T00:00:00Z&format=csv",
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.report.EXPECT().Statement(testWalletID, gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(walletID string, from, to time.Time, w statement.Writer) error {
+						if err := w.Opening(100); err != nil {
+							return err
+						}
+						return w.Closing(100)
+					})
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Get Statement Invalid Format",
+			method:       http.MethodGet,
+			path:         "/api/v1/wallets/" + testWalletID + "/statement?from=2026-09-01T00:00:00Z&to=2026-10-01T00:00:00Z&format=xlsx",
+			mockServices: func(s testServices) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Deposit",
+			method:       http.MethodPatch,
+			path:         "/api/v1/wallets/",
+			body:         handler.TransactionRequest{WalletID: testWalletID, OperationType: "DEPOSIT", Amount: 10},
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.wallet.EXPECT().Deposit(testWalletID, 10.0).Return(nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Conditional Withdraw Precondition Failed",
+			method:       http.MethodPatch,
+			path:         "/api/v1/wallets/",
+			body:         handler.TransactionRequest{WalletID: testWalletID, OperationType: "WITHDRAW", Amount: 10},
+			headers:      map[string]string{"If-Match": `"2"`},
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.wallet.EXPECT().WithdrawIfVersion(testWalletID, 10.0, int64(2)).Return(int64(0), repository.ErrVersionMismatch)
+			},
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:         "Invalid Operation Type",
+			method:       http.MethodPatch,
+			path:         "/api/v1/wallets/",
+			body:         handler.TransactionRequest{WalletID: testWalletID, OperationType: "TRANSFER", Amount: 10},
+			mockServices: func(s testServices) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Batch Best Effort",
+			method: http.MethodPost,
+			path:   "/api/v1/transactions/batch",
+			body: handler.BatchRequest{Mode: models.BatchBestEffort, Operations: []models.BatchItem{
+				{OperationType: models.OperationTransfer, WalletID: testWalletID, ToWalletID: otherWalletID, Amount: 5},
+				{OperationType: models.OperationWithdraw, WalletID: otherWalletID, Amount: 100},
+			}},
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.wallet.EXPECT().ExecuteBatch(gomock.Len(2), models.BatchBestEffort).Return([]models.BatchItemResult{
+					{Index: 0, Status: models.BatchItemApplied, Operations: []models.Operation{
+						{ID: 1, WalletID: testWalletID, Type: models.OperationTransferOut, Amount: 5, BalanceAfter: 95, Version: 2, CreatedAt: time.Now()},
+						{ID: 2, WalletID: otherWalletID, Type: models.OperationTransferIn, Amount: 5, BalanceAfter: 5, Version: 2, CreatedAt: time.Now()},
+					}},
+					{Index: 1, Status: models.BatchItemFailed, Error: repository.ErrInsufficientFunds.Error()},
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "Batch Atomic Rejected",
+			method: http.MethodPost,
+			path:   "/api/v1/transactions/batch",
+			body: handler.BatchRequest{Mode: models.BatchAtomic, Operations: []models.BatchItem{
+				{OperationType: models.OperationWithdraw, WalletID: testWalletID, Amount: 100},
+			}},
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.wallet.EXPECT().ExecuteBatch(gomock.Len(1), models.BatchAtomic).Return([]models.BatchItemResult{
+					{Index: 0, Status: models.BatchItemFailed, Error: repository.ErrInsufficientFunds.Error()},
+				}, repository.ErrBatchRejected)
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "Create Payout Job",
+			method:       http.MethodPost,
+			path:         "/api/v1/jobs/payouts",
+			body:         "wallet_id,amount\n" + testWalletID + ",10\n",
+			headers:      map[string]string{"Content-Type": "text/csv"},
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.payout.EXPECT().CreateJob(gomock.Len(1)).Return(models.PayoutJob{ID: testJobID, Status: models.PayoutJobRunning, TotalRows: 1, CreatedAt: time.Now()}, nil)
+			},
+			expectedCode: http.StatusAccepted,
+		},
+		{
+			name:         "Get Payout Job",
+			method:       http.MethodGet,
+			path:         "/api/v1/jobs/" + testJobID,
+			validRequest: true,
+			mockServices: func(s testServices) {
+				completedAt := time.Now()
+				s.payout.EXPECT().GetJob(testJobID).Return(models.PayoutJob{
+					ID: testJobID, Status: models.PayoutJobCompleted, TotalRows: 2, ProcessedRows: 2, FailedRows: 1, CreatedAt: time.Now(), CompletedAt: &completedAt,
+				}, nil)
+				s.payout.EXPECT().ListFailedRows(testJobID, 0, 100).Return([]models.PayoutRow{
+					{Row: 2, WalletID: otherWalletID, Status: models.PayoutRowFailed, Error: repository.ErrWalletNotFound.Error()},
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Get Payout Job Not Found",
+			method:       http.MethodGet,
+			path:         "/api/v1/jobs/" + testJobID,
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.payout.EXPECT().GetJob(testJobID).Return(models.PayoutJob{}, repository.ErrJobNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Payout Error Report",
+			method:       http.MethodGet,
+			path:         "/api/v1/jobs/" + testJobID + "/errors",
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.payout.EXPECT().GetJob(testJobID).Return(models.PayoutJob{ID: testJobID, Status: models.PayoutJobCompleted}, nil)
+				s.payout.EXPECT().ListFailedRows(testJobID, 0, 1000).Return([]models.PayoutRow{}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Create Webhook Endpoint",
+			method:       http.MethodPost,
+			path:         "/api/v1/admin/webhooks",
+			body:         handler.WebhookEndpointRequest{URL: "https://merchant.example/hooks", EventTypes: []string{models.EventWalletDeposited}},
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.webhook.EXPECT().RegisterEndpoint("https://merchant.example/hooks", "", []string{models.EventWalletDeposited}).
+					Return(models.WebhookEndpoint{ID: testEndpointID, URL: "https://merchant.example/hooks", Secret: "secret", EventTypes: []string{models.EventWalletDeposited}, CreatedAt: time.Now()}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "List Webhook Endpoints",
+			method:       http.MethodGet,
+			path:         "/api/v1/admin/webhooks",
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.webhook.EXPECT().ListEndpoints().Return([]models.WebhookEndpoint{{ID: testEndpointID, URL: "https://merchant.example/hooks", EventTypes: []string{"*"}, CreatedAt: time.Now()}}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "List Webhook Deliveries",
+			method:       http.MethodGet,
+			path:         "/api/v1/admin/webhooks/" + testEndpointID + "/deliveries?status=DEAD",
+			validRequest: true,
+			mockServices: func(s testServices) {
+				code, reason := 500, "endpoint responded with status 500"
+				s.webhook.EXPECT().ListDeliveries(testEndpointID, models.DeliveryDead, 0).Return([]models.WebhookDelivery{{
+					ID: 1, EndpointID: testEndpointID, EventID: 10, EventType: models.EventWalletDeposited, Payload: json.RawMessage(`{"eventId":10}`),
+					Status: models.DeliveryDead, Attempts: 8, NextAttemptAt: time.Now(), LastStatusCode: &code, LastError: &reason, CreatedAt: time.Now(),
+				}}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Redeliver Webhook",
+			method:       http.MethodPost,
+			path:         "/api/v1/admin/webhooks/deliveries/1/redeliver",
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.webhook.EXPECT().Redeliver(int64(1)).Return(models.WebhookDelivery{
+					ID: 1, EndpointID: testEndpointID, EventID: 10, EventType: models.EventWalletDeposited, Payload: json.RawMessage(`{"eventId":10}`),
+					Status: models.DeliveryPending, NextAttemptAt: time.Now(), CreatedAt: time.Now(),
+				}, nil)
+			},
+			expectedCode: http.StatusAccepted,
+		},
+		{
+			name:         "Delete Webhook Endpoint",
+			method:       http.MethodDelete,
+			path:         "/api/v1/admin/webhooks/" + testEndpointID,
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.webhook.EXPECT().DeleteEndpoint(testEndpointID).Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "Verify Audit Chain Broken",
+			method:       http.MethodGet,
+			path:         "/api/v1/admin/audit/verify",
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.admin.EXPECT().VerifyAuditChain(gomock.Any()).Return(models.AuditVerification{
+					Checked: 4, HeadSeq: 4, HeadHash: "4f2a", Break: &models.AuditChainBreak{Seq: 5, AuditID: 12, Reason: "entry content does not match its hash"},
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "List Audit Checkpoints",
+			method:       http.MethodGet,
+			path:         "/api/v1/admin/audit/checkpoints?after=1&limit=10",
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.admin.EXPECT().Checkpoints(gomock.Any(), int64(1), 10).Return(models.AuditCheckpointExport{
+					KeyID: "0a1b2c3d4e5f6071", PublicKey: "key",
+					Checkpoints: []models.AuditCheckpoint{{ID: 2, Seq: 40, Hash: "4f2a", CreatedAt: time.Now(), KeyID: "0a1b2c3d4e5f6071", Signature: "sig"}},
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Create Audit Checkpoint",
+			method:       http.MethodPost,
+			path:         "/api/v1/admin/audit/checkpoints",
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.admin.EXPECT().CreateCheckpoint().Return(models.AuditCheckpoint{ID: 3, Seq: 41, Hash: "5b3c", CreatedAt: time.Now(), KeyID: "0a1b2c3d4e5f6071", Signature: "sig"}, true, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "Create Audit Checkpoint Disabled",
+			method:       http.MethodPost,
+			path:         "/api/v1/admin/audit/checkpoints",
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.admin.EXPECT().CreateCheckpoint().Return(models.AuditCheckpoint{}, false, service.ErrCheckpointsDisabled)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "Reconcile All Wallets",
+			method:       http.MethodPost,
+			path:         "/api/v1/admin/reconciliation",
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.admin.EXPECT().Reconcile(gomock.Any(), nil).Return(models.ReconciliationReport{
+					StartedAt: time.Now(), FinishedAt: time.Now(), Checked: 2,
+					Discrepancies: []models.WalletDiscrepancy{{WalletID: testWalletID, Balance: 51, LedgerBalance: 50, LastBalanceAfter: 50, Operations: 3}},
+					Invariant:     &models.LedgerInvariant{TotalBalance: 151, Credits: 150, Holds: false},
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Reconcile Selected Wallets",
+			method:       http.MethodPost,
+			path:         "/api/v1/admin/reconciliation",
+			body:         map[string]interface{}{"walletIds": []string{testWalletID}},
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.admin.EXPECT().Reconcile(gomock.Any(), []string{testWalletID}).Return(models.ReconciliationReport{
+					StartedAt: time.Now(), FinishedAt: time.Now(), Checked: 1, Discrepancies: []models.WalletDiscrepancy{},
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Daily Report",
+			method:       http.MethodGet,
+			path:         "/api/v1/reports/daily?from=2026-09-01&to=2026-09-30&groupBy=week",
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.report.EXPECT().DailyReport("", gomock.Any(), gomock.Any(), models.ReportGroupWeek).Return(models.DailyReport{
+					From: "2026-09-01", To: "2026-09-30", GroupBy: models.ReportGroupWeek,
+					Periods: []models.ReportPeriod{{Period: "2026-09-01", Wallets: 2, OpeningBalance: 100, ClosingBalance: 130, Deposits: 50, Withdrawals: 20, DepositCount: 3, WithdrawalCount: 1}},
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Wallet Daily Report",
+			method:       http.MethodGet,
+			path:         "/api/v1/reports/daily/wallets/" + testWalletID + "?from=2026-09-01&to=2026-09-30",
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.report.EXPECT().DailyReport(testWalletID, gomock.Any(), gomock.Any(), models.ReportGroupDay).Return(models.DailyReport{
+					WalletID: testWalletID, From: "2026-09-01", To: "2026-09-30", GroupBy: models.ReportGroupDay, Periods: []models.ReportPeriod{},
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Wallet Daily Report Not Found",
+			method:       http.MethodGet,
+			path:         "/api/v1/reports/daily/wallets/" + testWalletID + "?from=2026-09-01&to=2026-09-30",
+			validRequest: true,
+			mockServices: func(s testServices) {
+				s.report.EXPECT().DailyReport(testWalletID, gomock.Any(), gomock.Any(), models.ReportGroupDay).Return(models.DailyReport{}, repository.ErrWalletNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Daily Report Invalid Grouping",
+			method:       http.MethodGet,
+			path:         "/api/v1/reports/daily?from=2026-09-01&to=2026-09-30&groupBy=year",
+			mockServices: func(s testServices) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Daily Report Without Token",
+			method:       http.MethodGet,
+			path:         "/api/v1/reports/daily?from=2026-09-01&to=2026-09-30",
+			headers:      map[string]string{"Authorization": ""},
+			mockServices: func(s testServices) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "Admin Without Token",
+			method:       http.MethodGet,
+			path:         "/api/v1/admin/webhooks",
+			headers:      map[string]string{"Authorization": ""},
+			mockServices: func(s testServices) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "OpenAPI Spec",
+			method:       http.MethodGet,
+			path:         "/openapi.json",
+			validRequest: true,
+			mockServices: func(s testServices) {},
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			app, services := newTestApp(ctrl)
+			tt.mockServices(services)
+
+			var body []byte
+			switch b := tt.body.(type) {
+			case nil:
+			case string:
+				body = []byte(b)
+			default:
+				body, _ = json.Marshal(b)
+			}
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewReader(body))
+			if tt.body != nil {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if strings.HasPrefix(tt.path, "/api/v1/admin") || strings.HasPrefix(tt.path, "/api/v1/reports") {
+				req.Header.Set("Authorization", "Bearer "+testAdminToken)
+			}
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			// Проверка запроса по спецификации
+			route, pathParams, err := router.FindRoute(req)
+			require.NoError(t, err)
+			requestInput := &openapi3filter.RequestValidationInput{
+				Request:    req,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    &openapi3filter.Options{AuthenticationFunc: requireBearer},
+			}
+			requestErr := openapi3filter.ValidateRequest(context.Background(), requestInput)
+			if tt.validRequest {
+				assert.NoError(t, requestErr)
+			} else {
+				assert.Error(t, requestErr)
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			// Проверка настоящего ответа обработчика по спецификации
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+
+			respBody, _ := io.ReadAll(resp.Body)
+			responseInput := &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: requestInput,
+				Status:                 resp.StatusCode,
+				Header:                 resp.Header,
+				Body:                   io.NopCloser(bytes.NewReader(respBody)),
+			}
+			assert.NoError(t, openapi3filter.ValidateResponse(context.Background(), responseInput), string(respBody))
+		})
+	}
+}
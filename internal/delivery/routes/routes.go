@@ -4,21 +4,102 @@ import (
 	"github.com/VadimBorzenkov/WalletAPI/internal/delivery/handler"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/expvar"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 )
 
-// SetupRoutes регистрирует маршруты приложения.
-func SetupRoutes(app *fiber.App, h handler.WalletHandler) *fiber.App {
+// Handlers объединяет обработчики, маршруты которых регистрирует SetupRoutes
+type Handlers struct {
+	Wallet         handler.WalletHandler
+	Stream         handler.StreamHandler
+	Webhook        handler.WebhookHandler
+	Payout         handler.PayoutHandler
+	Audit          handler.AuditHandler
+	Reconciliation handler.ReconciliationHandler
+	Report         handler.ReportHandler
+
+	// PayoutMaxUploadSize — наибольший размер тела загрузки файла выплат в байтах. Остальные маршруты
+	// принимают тело не больше fiber.DefaultBodyLimit; ноль оставляет общий лимит и для загрузки
+	PayoutMaxUploadSize int
+}
+
+// SetupRoutes регистрирует маршруты приложения. Приложение создается с настройками AppConfig, иначе тело
+// запроса ограничено BodyLimit самого Fiber.
+func SetupRoutes(app *fiber.App, h Handlers, adminToken string) *fiber.App {
 	app.Use(logger.New())
 	app.Use(recover.New())
+	// ID запроса из X-Request-ID или сгенерированный; записывается в журнал аудита вместе с изменениями
+	app.Use(requestid.New())
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*", // Настройка CORS, чтобы разрешить доступ со всех доменов
 	}))
+	// Общий лимит тела запроса; загрузка файла выплат ограничивается отдельно на своем маршруте
+	app.Use(bodyLimit(fiber.DefaultBodyLimit, isPayoutUpload))
+
+	// Метрики expvar, в том числе доля попаданий в кэш балансов, доступны с токеном администратора
+	app.Use("/debug/vars", adminAuth(adminToken), expvar.New())
+
+	// Спецификация OpenAPI и страница документации
+	app.Get("/openapi.json", handler.HandleOpenAPISpec)
+	app.Get("/docs", handler.HandleDocs)
 
 	api := app.Group("/api/v1/wallets")
-	api.Get("/:walletID", h.HandleBalance)
-	api.Patch("/", h.HandleTransaction)
+	api.Get("/:walletID", h.Wallet.HandleBalance)
+	api.Get("/:walletID/stream", h.Stream.HandleStream)
+	api.Patch("/", h.Wallet.HandleTransaction)
+
+	// Исторические балансы вычисляются по снимкам балансов и журналу операций. Без хранилища снимков маршрут
+	// не регистрируется
+	if h.Report != nil {
+		api.Get("/:walletID/balance", h.Report.HandleBalanceAt)
+		api.Get("/:walletID/statement", h.Report.HandleStatement)
+	}
+
+	// Дневные отчеты содержат итоги по всем кошелькам и доступны только с токеном администратора
+	if h.Report != nil {
+		reports := app.Group("/api/v1/reports", adminAuth(adminToken))
+		reports.Get("/daily", h.Report.HandleDailyReport)
+		reports.Get("/daily/wallets/:walletID", h.Report.HandleWalletDailyReport)
+	}
+
+	transactions := app.Group("/api/v1/transactions")
+	transactions.Post("/batch", h.Wallet.HandleBatch)
+
+	// Задания на массовые выплаты выполняются в фоне. Без хранилища заданий маршруты не регистрируются
+	if h.Payout != nil {
+		jobs := app.Group("/api/v1/jobs")
+		uploadLimit := h.PayoutMaxUploadSize
+		if uploadLimit <= 0 {
+			uploadLimit = fiber.DefaultBodyLimit
+		}
+		jobs.Post("/payouts", bodyLimit(uploadLimit, nil), h.Payout.HandleCreatePayoutJob)
+		jobs.Get("/:jobID", h.Payout.HandleGetPayoutJob)
+		jobs.Get("/:jobID/errors", h.Payout.HandlePayoutErrorReport)
+	}
+
+	// Административное API доступно только с токеном администратора
+	if h.Webhook != nil || h.Audit != nil || h.Reconciliation != nil {
+		admin := app.Group("/api/v1/admin", adminAuth(adminToken))
+		if h.Webhook != nil {
+			admin.Post("/webhooks", h.Webhook.HandleCreateEndpoint)
+			admin.Get("/webhooks", h.Webhook.HandleListEndpoints)
+			admin.Delete("/webhooks/:endpointID", h.Webhook.HandleDeleteEndpoint)
+			admin.Get("/webhooks/:endpointID/deliveries", h.Webhook.HandleListDeliveries)
+			admin.Post("/webhooks/deliveries/:deliveryID/redeliver", h.Webhook.HandleRedeliver)
+		}
+		// Проверка цепочки журнала аудита и выгрузка контрольных точек
+		if h.Audit != nil {
+			admin.Get("/audit/verify", h.Audit.HandleVerify)
+			admin.Get("/audit/checkpoints", h.Audit.HandleListCheckpoints)
+			admin.Post("/audit/checkpoints", h.Audit.HandleCreateCheckpoint)
+		}
+		// Сверка балансов с журналом операций по запросу, в дополнение к плановой
+		if h.Reconciliation != nil {
+			admin.Post("/reconciliation", h.Reconciliation.HandleReconcile)
+		}
+	}
 
 	return app
 }
@@ -0,0 +1,49 @@
+package hotwallet
+
+import (
+	"context"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// Folder периодически переносит буферизованные пополнения горячих кошельков в их балансы.
+// Чтение баланса учитывает буфер, поэтому интервал влияет только на задержку записи операций в журнал и событий
+type Folder struct {
+	repo      repository.WalletRepository
+	logger    *logrus.Logger
+	interval  time.Duration
+	batchSize int
+}
+
+func NewFolder(repo repository.WalletRepository, logger *logrus.Logger, interval time.Duration, batchSize int) *Folder {
+	return &Folder{
+		repo:      repo,
+		logger:    logger,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Run переносит пополнения до отмены контекста
+func (f *Folder) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		// Пока буфер заполнен больше чем на порцию, переносим без ожидания следующего тика
+		for {
+			folded, err := f.repo.FoldPendingDeposits(f.batchSize)
+			if err != nil || folded < f.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package hotwallet
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+)
+
+// TestFolder_Run проверяет, что заполненный буфер переносится порциями без ожидания тика,
+// а ошибка переноса не останавливает обработку
+func TestFolder_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockWalletRepository(ctrl)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	last := repo.EXPECT().FoldPendingDeposits(10).DoAndReturn(func(int) (int, error) {
+		cancel()
+		return 0, nil
+	})
+	gomock.InOrder(
+		repo.EXPECT().FoldPendingDeposits(10).Return(10, nil),
+		repo.EXPECT().FoldPendingDeposits(10).Return(10, nil),
+		repo.EXPECT().FoldPendingDeposits(10).Return(3, nil),
+		repo.EXPECT().FoldPendingDeposits(10).Return(0, errors.New("connection reset")),
+		last,
+	)
+	// После отмены контекста возможен еще один тик
+	repo.EXPECT().FoldPendingDeposits(10).Return(0, nil).After(last).AnyTimes()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	done := make(chan struct{})
+	go func() {
+		NewFolder(repo, logger, time.Millisecond, 10).Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("folder did not stop after context cancellation")
+	}
+}
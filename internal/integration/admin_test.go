@@ -0,0 +1,191 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/audit"
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAdmin создает сервис операций операторов поверх базы окружения
+func (e *testEnv) newAdmin() *service.ApiAdminService {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return service.NewApiAdminService(repository.NewApiAdminRepository(e.repo, logger), e.repo, logger)
+}
+
+// TestAdmin_Lifecycle проверяет создание, корректировки, заморозку, сверку с глобальным инвариантом и выписку
+// с записью в журнал аудита
+func TestAdmin_Lifecycle(t *testing.T) {
+	env := newEnv(t)
+	admin := env.newAdmin()
+	start := time.Now().Add(-time.Minute)
+
+	info, err := admin.CreateWallet("alice", "", 100)
+	require.NoError(t, err)
+	walletID := info.ID
+	_, err = admin.CreateWallet("alice", walletID, 0)
+	assert.ErrorIs(t, err, repository.ErrWalletExists)
+
+	op, err := admin.Adjust("alice", walletID, -30, "duplicate payout")
+	require.NoError(t, err)
+	assert.Equal(t, models.OperationAdjustmentDebit, op.Type)
+	assert.Equal(t, 70.0, op.BalanceAfter)
+	_, err = admin.Adjust("alice", walletID, -500, "chargeback")
+	assert.ErrorIs(t, err, repository.ErrInsufficientFunds)
+
+	// Операции с замороженным кошельком отклоняются, корректировки оператора выполняются
+	info, err = admin.Freeze("bob", walletID, "fraud investigation")
+	require.NoError(t, err)
+	assert.True(t, info.Frozen)
+	status, _, body := env.transact(t, walletID, "DEPOSIT", 10, "")
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Contains(t, string(body), repository.ErrWalletFrozen.Error())
+	_, err = admin.Adjust("bob", walletID, 5, "compensation")
+	require.NoError(t, err)
+
+	_, err = admin.Unfreeze("bob", walletID, "cleared")
+	require.NoError(t, err)
+	status, _, body = env.transact(t, walletID, "WITHDRAW", 25, "")
+	require.Equal(t, http.StatusOK, status, string(body))
+
+	report, err := admin.Reconcile("carol", []string{walletID})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Checked)
+	assert.Empty(t, report.Discrepancies)
+	assert.Nil(t, report.Invariant)
+
+	// Баланс, измененный в обход журнала, обнаруживается сверкой
+	_, err = env.db.Exec(context.Background(), `UPDATE wallets SET balance = balance + 1 WHERE wallet_id = $1`, walletID)
+	require.NoError(t, err)
+	report, err = admin.Reconcile("carol", nil)
+	require.NoError(t, err)
+	require.Len(t, report.Discrepancies, 1)
+	assert.Equal(t, 51.0, report.Discrepancies[0].Balance)
+	assert.Equal(t, 50.0, report.Discrepancies[0].LedgerBalance)
+	require.NotNil(t, report.Invariant)
+	assert.False(t, report.Invariant.Holds)
+	assert.Equal(t, 51.0, report.Invariant.TotalBalance)
+	assert.Equal(t, 50.0, report.Invariant.Credits-report.Invariant.Debits)
+
+	statement, err := admin.Statement("carol", walletID, start, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, statement.OpeningBalance)
+	assert.Equal(t, 50.0, statement.ClosingBalance)
+	assert.Len(t, statement.Operations, 4)
+
+	entries, err := admin.AuditLog("carol", walletID, 0, 100)
+	require.NoError(t, err)
+	actions := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		actions = append(actions, entry.Actor+" "+entry.Action)
+	}
+	assert.Equal(t, []string{
+		"alice " + models.AuditWalletCreated,
+		"alice " + models.AuditWalletAdjusted,
+		"bob " + models.AuditWalletFrozen,
+		"bob " + models.AuditWalletAdjusted,
+		"bob " + models.AuditWalletUnfrozen,
+		"api:0.0.0.0 " + models.AuditOperationApplied,
+		"carol " + models.AuditStatementIssued,
+	}, actions)
+	assert.Equal(t, 2, env.count(t, `SELECT COUNT(*) FROM audit_log WHERE action = $1`, models.AuditReconciled))
+
+	// С включенной публикацией каждое расхождение сохраняется событием outbox
+	_, err = env.newAdmin().WithDiscrepancyEvents().Reconcile("reconciliation", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, env.count(t, `SELECT COUNT(*) FROM outbox_events WHERE event_type = $1 AND wallet_id = $2`,
+		models.EventBalanceDiscrepancy, walletID))
+
+	// Списание через API записано с балансом до и после операции и ID запроса
+	withdrawal := entries[5]
+	require.NotNil(t, withdrawal.BalanceBefore)
+	assert.Equal(t, 75.0, *withdrawal.BalanceBefore)
+	assert.Equal(t, 50.0, *withdrawal.BalanceAfter)
+	assert.NotEmpty(t, withdrawal.RequestID)
+	assert.NotZero(t, withdrawal.OperationID)
+}
+
+// TestAdmin_AuditChain проверяет цепочку журнала аудита: запрет изменения записей, контрольные точки
+// и обнаружение первой измененной записи
+func TestAdmin_AuditChain(t *testing.T) {
+	env := newEnv(t)
+	signer, err := audit.NewSigner(base64.StdEncoding.EncodeToString(make([]byte, ed25519.SeedSize)))
+	require.NoError(t, err)
+	admin := env.newAdmin().WithCheckpointSigner(signer)
+	ctx := context.Background()
+
+	info, err := admin.CreateWallet("alice", "", 100)
+	require.NoError(t, err)
+	walletID := info.ID
+	status, _, body := env.do(t, http.MethodPatch, "/api/v1/wallets/", map[string]interface{}{
+		"walletId": walletID, "operationType": "DEPOSIT", "amount": 10,
+	}, map[string]string{fiber.HeaderXRequestID: "req-chain"})
+	require.Equal(t, http.StatusOK, status, string(body))
+	_, resp := env.batch(t, models.BatchAtomic,
+		models.BatchItem{OperationType: models.OperationWithdraw, WalletID: walletID, Amount: 5},
+		models.BatchItem{OperationType: models.OperationDeposit, WalletID: walletID, Amount: 1})
+	require.Equal(t, 2, resp.Applied)
+
+	// Транзакции добавляют записи без номера в цепочке, запечатываются они отдельно в порядке audit_id
+	assert.Equal(t, 4, env.count(t, `SELECT COUNT(*) FROM audit_log WHERE chain_seq IS NULL`))
+	_, err = env.db.Exec(ctx, `UPDATE audit_log SET actor = 'mallory', chain_seq = 1, prev_hash = '', hash = 'ab'
+		WHERE audit_id = (SELECT MIN(audit_id) FROM audit_log)`)
+	assert.ErrorContains(t, err, "append-only")
+
+	checkpoint, created, err := admin.CreateCheckpoint()
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, int64(4), checkpoint.Seq)
+	_, created, err = admin.CreateCheckpoint()
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	result, err := admin.VerifyAuditChain("carol")
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, int64(4), result.Checked)
+	assert.Equal(t, 1, result.Checkpoints)
+	assert.Equal(t, 1, env.count(t, `SELECT COUNT(*) FROM audit_log WHERE request_id = 'req-chain'`))
+	assert.Equal(t, 0, env.count(t, `SELECT COUNT(*) FROM audit_log WHERE chain_seq IS NULL`))
+	assert.Equal(t, 0, env.count(t, `SELECT COUNT(*) FROM audit_log a JOIN audit_log b
+		ON a.audit_id < b.audit_id AND a.chain_seq > b.chain_seq`))
+
+	// Запечатанную запись нельзя запечатать заново
+	_, err = env.db.Exec(ctx, `UPDATE audit_log SET hash = 'ab' WHERE chain_seq = 2`)
+	assert.ErrorContains(t, err, "append-only")
+
+	// Записи журнала нельзя изменить или удалить
+	_, err = env.db.Exec(ctx, `UPDATE audit_log SET actor = 'mallory' WHERE chain_seq = 2`)
+	assert.ErrorContains(t, err, "append-only")
+	_, err = env.db.Exec(ctx, `DELETE FROM audit_log WHERE chain_seq = 2`)
+	assert.ErrorContains(t, err, "append-only")
+
+	// Изменение в обход триггера обнаруживается проверкой цепочки
+	_, err = env.db.Exec(ctx, `ALTER TABLE audit_log DISABLE TRIGGER audit_log_append_only`)
+	require.NoError(t, err)
+	_, err = env.db.Exec(ctx, `UPDATE audit_log SET balance_after = balance_after + 1 WHERE chain_seq = 2`)
+	require.NoError(t, err)
+	_, err = env.db.Exec(ctx, `ALTER TABLE audit_log ENABLE TRIGGER audit_log_append_only`)
+	require.NoError(t, err)
+
+	result, err = admin.VerifyAuditChain("carol")
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.NotNil(t, result.Break)
+	assert.Equal(t, int64(2), result.Break.Seq)
+}
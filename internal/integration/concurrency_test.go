@@ -0,0 +1,229 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Количество параллельных клиентов в сценариях конкуренции
+const clients = 50
+
+// parallel запускает fn в n горутинах одновременно и ждет их завершения
+func parallel(n int, fn func(i int)) {
+	var (
+		start sync.WaitGroup
+		done  sync.WaitGroup
+	)
+	start.Add(1)
+	for i := 0; i < n; i++ {
+		done.Add(1)
+		go func(i int) {
+			defer done.Done()
+			start.Wait()
+			fn(i)
+		}(i)
+	}
+	start.Done()
+	done.Wait()
+}
+
+// statusCounter считает коды ответов параллельных запросов
+type statusCounter struct {
+	mu     sync.Mutex
+	counts map[int]int
+}
+
+func (c *statusCounter) add(status int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[int]int)
+	}
+	c.counts[status]++
+}
+
+func TestConcurrentDeposits(t *testing.T) {
+	env := newEnv(t)
+	walletID := env.createWallet(t, 0)
+
+	var statuses statusCounter
+	parallel(clients, func(int) {
+		status, _, _ := env.transact(t, walletID, "DEPOSIT", 1.5, "")
+		statuses.add(status)
+	})
+	assert.Equal(t, map[int]int{http.StatusOK: clients}, statuses.counts)
+
+	balance, etag := env.balance(t, walletID)
+	assert.Equal(t, 1.5*clients, balance)
+	assert.Equal(t, fmt.Sprintf(`"%d"`, clients+1), etag)
+
+	// Ни одно пополнение не потеряно: версии в журнале идут подряд, балансы после операций возрастают
+	operations := env.operations(t, walletID)
+	require.Len(t, operations, clients)
+	for i, op := range operations {
+		assert.Equal(t, int64(i+2), op.Version)
+		assert.Equal(t, 1.5*float64(i+1), op.BalanceAfter)
+	}
+}
+
+func TestConcurrentWithdrawals(t *testing.T) {
+	env := newEnv(t)
+	walletID := env.createWallet(t, 20)
+
+	var statuses statusCounter
+	parallel(clients, func(int) {
+		status, _, _ := env.transact(t, walletID, "WITHDRAW", 1, "")
+		statuses.add(status)
+	})
+	assert.Equal(t, map[int]int{http.StatusOK: 20, http.StatusUnprocessableEntity: clients - 20}, statuses.counts)
+
+	balance, _ := env.balance(t, walletID)
+	assert.Equal(t, 0.0, balance)
+	assert.Len(t, env.operations(t, walletID), 20)
+	assert.Zero(t, env.count(t, `SELECT COUNT(*) FROM wallet_operations WHERE balance_after < 0`))
+}
+
+// Отклоненные списания определяют причину отказа в своей транзакции и не занимают второе подключение:
+// при числе клиентов больше размера пула они не должны ждать друг друга бесконечно
+func TestConcurrentRejectedWithdrawalsSmallPool(t *testing.T) {
+	env := newEnv(t)
+	walletID := env.createWallet(t, 0)
+
+	config := env.db.Config().Copy()
+	config.MaxConns = 2
+	db, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	repo := repository.NewApiWalletRepository(db, logger)
+
+	errs := make(chan error, clients)
+	done := make(chan struct{})
+	go func() {
+		parallel(clients, func(int) {
+			errs <- repo.Withdraw(walletID, 1)
+		})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("rejected withdrawals did not finish: connection pool is exhausted")
+	}
+	close(errs)
+	for err := range errs {
+		assert.ErrorIs(t, err, repository.ErrInsufficientFunds)
+	}
+}
+
+func TestConcurrentConditionalUpdates(t *testing.T) {
+	env := newEnv(t)
+	walletID := env.createWallet(t, 0)
+	_, etag := env.balance(t, walletID)
+
+	// Из операций с одной версией выполняется только одна, остальные получают 412
+	var statuses statusCounter
+	parallel(clients, func(int) {
+		status, _, _ := env.transact(t, walletID, "DEPOSIT", 10, etag)
+		statuses.add(status)
+	})
+	assert.Equal(t, map[int]int{http.StatusOK: 1, http.StatusPreconditionFailed: clients - 1}, statuses.counts)
+
+	balance, _ := env.balance(t, walletID)
+	assert.Equal(t, 10.0, balance)
+}
+
+func TestConcurrentOpposingTransfers(t *testing.T) {
+	env := newEnv(t)
+	a := env.createWallet(t, 1000)
+	b := env.createWallet(t, 1000)
+
+	// Встречные переводы блокируют кошельки в одном порядке, поэтому не взаимоблокируются,
+	// а сумма балансов сохраняется
+	var statuses statusCounter
+	parallel(clients, func(i int) {
+		from, to := a, b
+		if i%2 == 1 {
+			from, to = b, a
+		}
+		status, _ := env.batch(t, "ATOMIC", models.BatchItem{OperationType: models.OperationTransfer, WalletID: from, ToWalletID: to, Amount: 7})
+		statuses.add(status)
+	})
+	assert.Equal(t, map[int]int{http.StatusOK: clients}, statuses.counts)
+
+	balanceA, etagA := env.balance(t, a)
+	balanceB, etagB := env.balance(t, b)
+	assert.Equal(t, 1000.0, balanceA)
+	assert.Equal(t, 1000.0, balanceB)
+	assert.Equal(t, fmt.Sprintf(`"%d"`, clients+1), etagA)
+	assert.Equal(t, fmt.Sprintf(`"%d"`, clients+1), etagB)
+}
+
+func TestConcurrentIdempotentDeposits(t *testing.T) {
+	env := newEnv(t)
+	walletID := env.createWallet(t, 0)
+
+	// Параллельные повторы операции с одним ключом, как у строки выплаты после перезапуска, выполняют ее один раз
+	var (
+		mu      sync.Mutex
+		applied int
+	)
+	parallel(clients, func(int) {
+		ok, err := env.repo.DepositIdempotent(walletID, 25, "integration:"+walletID)
+		if !assert.NoError(t, err) || !ok {
+			return
+		}
+		mu.Lock()
+		applied++
+		mu.Unlock()
+	})
+	assert.Equal(t, 1, applied)
+
+	balance, _ := env.balance(t, walletID)
+	assert.Equal(t, 25.0, balance)
+	assert.Len(t, env.operations(t, walletID), 1)
+}
+
+func TestConcurrentIdempotentHotDeposits(t *testing.T) {
+	env := newEnv(t)
+	walletID := env.createWallet(t, 0)
+	env.repo.WithHotWallets([]string{walletID})
+
+	// Параллельные повторы с одним ключом сталкиваются на уникальном индексе буфера, а не на проверке журнала,
+	// и все, кроме одного, должны считаться уже выполненными, а не ошибкой
+	var (
+		mu      sync.Mutex
+		applied int
+	)
+	parallel(clients, func(int) {
+		ok, err := env.repo.DepositIdempotent(walletID, 25, "integration:hot:"+walletID)
+		if !assert.NoError(t, err) || !ok {
+			return
+		}
+		mu.Lock()
+		applied++
+		mu.Unlock()
+	})
+	assert.Equal(t, 1, applied)
+
+	_, err := env.repo.FoldPendingDeposits(100)
+	require.NoError(t, err)
+	balance, _ := env.balance(t, walletID)
+	assert.Equal(t, 25.0, balance)
+	assert.Len(t, env.operations(t, walletID), 1)
+}
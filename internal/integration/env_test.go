@@ -0,0 +1,193 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/delivery/handler"
+	"github.com/VadimBorzenkov/WalletAPI/internal/delivery/routes"
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service"
+	"github.com/VadimBorzenkov/WalletAPI/internal/stream"
+	"github.com/VadimBorzenkov/WalletAPI/pkg/migrator"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+// testEnv — приложение с настоящими обработчиками, сервисами и репозиториями поверх отдельной схемы базы
+type testEnv struct {
+	db   *pgxpool.Pool
+	repo *repository.ApiWalletRepository
+	app  *fiber.App
+}
+
+// newEnv создает схему со случайным именем, применяет к ней миграции и собирает приложение,
+// подключения которого работают только с этой схемой. Схема удаляется по завершении теста
+func newEnv(t *testing.T) *testEnv {
+	t.Helper()
+	dsn := os.Getenv("WALLET_TEST_DSN")
+	if dsn == "" {
+		t.Skip("WALLET_TEST_DSN is not set")
+	}
+	ctx := context.Background()
+
+	admin, err := pgxpool.New(ctx, dsn)
+	require.NoError(t, err)
+	t.Cleanup(admin.Close)
+	requireLocal(t, admin.Config())
+
+	schema := "it_" + randomHex(8)
+	_, err = admin.Exec(ctx, `CREATE SCHEMA `+schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if _, err := admin.Exec(context.Background(), `DROP SCHEMA `+schema+` CASCADE`); err != nil {
+			t.Errorf("drop schema %s: %v", schema, err)
+		}
+	})
+
+	config := admin.Config().Copy()
+	config.ConnConfig.RuntimeParams["search_path"] = schema
+	db, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	require.NoError(t, migrator.RunDatabaseMigrations(db))
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	repo := repository.NewApiWalletRepository(db, logger)
+	svc := service.NewApiWalletService(repo, logger)
+	app := fiber.New(routes.AppConfig())
+	routes.SetupRoutes(app, routes.Handlers{
+		Wallet:  handler.NewApiWalletHandler(svc, logger),
+		Stream:  handler.NewApiStreamHandler(svc, stream.NewHub(), logger, time.Minute, 1),
+		Webhook: handler.NewApiWebhookHandler(service.NewApiWebhookService(repository.NewApiWebhookRepository(db, logger), logger), logger),
+		Payout:  handler.NewApiPayoutHandler(service.NewApiPayoutService(repository.NewApiPayoutRepository(db, logger), logger), logger),
+		Report:  handler.NewApiReportHandler(service.NewApiReportService(repository.NewApiReportRepository(db, logger), logger), logger),
+	}, "integration-token")
+
+	return &testEnv{db: db, repo: repo, app: app}
+}
+
+// requireLocal останавливает тест, если база не на локальной машине: тесты не должны обращаться к сети
+func requireLocal(t *testing.T, config *pgxpool.Config) {
+	t.Helper()
+	hosts := []string{config.ConnConfig.Host}
+	for _, fallback := range config.ConnConfig.Fallbacks {
+		hosts = append(hosts, fallback.Host)
+	}
+	for _, host := range hosts {
+		switch {
+		case host == "localhost", host == "127.0.0.1", host == "::1", strings.HasPrefix(host, "/"):
+		default:
+			t.Fatalf("WALLET_TEST_DSN must point to a local database, got host %q", host)
+		}
+	}
+}
+
+// createWallet создает кошелек с начальным балансом и возвращает его ID
+func (e *testEnv) createWallet(t *testing.T, balance float64) string {
+	t.Helper()
+	var walletID string
+	err := e.db.QueryRow(context.Background(), `INSERT INTO wallets (wallet_id, balance) VALUES (gen_random_uuid(), $1) RETURNING wallet_id`, balance).Scan(&walletID)
+	require.NoError(t, err)
+	return walletID
+}
+
+// do выполняет запрос к приложению и возвращает код ответа, заголовки и тело
+func (e *testEnv) do(t *testing.T, method, path string, body interface{}, header map[string]string) (int, http.Header, []byte) {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := e.app.Test(req, -1)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, resp.Header, data
+}
+
+// balance запрашивает баланс кошелька через API и возвращает его вместе с ETag
+func (e *testEnv) balance(t *testing.T, walletID string) (float64, string) {
+	t.Helper()
+	status, header, body := e.do(t, http.MethodGet, "/api/v1/wallets/"+walletID, nil, nil)
+	require.Equal(t, http.StatusOK, status, string(body))
+
+	var resp struct {
+		Balance float64 `json:"balance"`
+	}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	return resp.Balance, header.Get(fiber.HeaderETag)
+}
+
+// transact выполняет операцию через PATCH /api/v1/wallets/. Непустой ifMatch передается в заголовке If-Match
+func (e *testEnv) transact(t *testing.T, walletID, opType string, amount float64, ifMatch string) (int, http.Header, []byte) {
+	t.Helper()
+	header := map[string]string{}
+	if ifMatch != "" {
+		header[fiber.HeaderIfMatch] = ifMatch
+	}
+	return e.do(t, http.MethodPatch, "/api/v1/wallets/", handler.TransactionRequest{
+		WalletID:      walletID,
+		OperationType: opType,
+		Amount:        amount,
+	}, header)
+}
+
+// batch выполняет пакет операций через POST /api/v1/transactions/batch
+func (e *testEnv) batch(t *testing.T, mode string, items ...models.BatchItem) (int, handler.BatchResponse) {
+	t.Helper()
+	status, _, body := e.do(t, http.MethodPost, "/api/v1/transactions/batch", handler.BatchRequest{Mode: mode, Operations: items}, nil)
+
+	var resp handler.BatchResponse
+	require.NoError(t, json.Unmarshal(body, &resp), string(body))
+	return status, resp
+}
+
+// operations читает журнал операций кошелька напрямую из базы
+func (e *testEnv) operations(t *testing.T, walletID string) []models.Operation {
+	t.Helper()
+	operations, err := e.repo.GetOperationsAfter(walletID, 0, 10000)
+	require.NoError(t, err)
+	return operations
+}
+
+// count возвращает количество строк, которые вернул запрос SELECT COUNT(*)
+func (e *testEnv) count(t *testing.T, query string, args ...interface{}) int {
+	t.Helper()
+	var n int
+	require.NoError(t, e.db.QueryRow(context.Background(), query, args...).Scan(&n))
+	return n
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,36 @@
+//go:build docker
+
+package integration
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Тег образа, собираемого для проверки
+const testImage = "wallet-api:image-test"
+
+// TestImage_SQLite собирает образ из Dockerfile и применяет в нем миграции SQLite собранным приложением.
+// Драйвер SQLite работает только в сборке с cgo, поэтому без компилятора C в образе команда завершится ошибкой
+//
+//	go test -tags docker -run TestImage ./internal/integration
+func TestImage_SQLite(t *testing.T) {
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("docker is not installed")
+	}
+
+	build := exec.Command("docker", "build", "-t", testImage, "../..")
+	out, err := build.CombinedOutput()
+	require.NoError(t, err, string(out))
+	t.Cleanup(func() { exec.Command("docker", "rmi", "-f", testImage).Run() })
+
+	// Конфигурация читается из .env, которого в образе может не быть; переменные окружения имеют приоритет
+	run := exec.Command("docker", "run", "--rm", "-e", "STORAGE=sqlite", "-e", "SQLITE_PATH=/tmp/wallets.db", testImage,
+		"sh", "-c", "touch .env && ./main migrate up && ./main migrate status")
+	out, err = run.CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.NotContains(t, string(out), "CGO_ENABLED=0")
+}
@@ -0,0 +1,63 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOutbox_Claims проверяет захват событий outbox: порядок событий кошелька сохраняется, отложенный кошелек
+// пропускается, захваченные события не выдаются повторно до завершения
+func TestOutbox_Claims(t *testing.T) {
+	env := newEnv(t)
+	first := env.createWallet(t, 0)
+	second := env.createWallet(t, 0)
+	for _, walletID := range []string{first, first, second} {
+		status, _, body := env.transact(t, walletID, "DEPOSIT", 1, "")
+		require.Equal(t, http.StatusOK, status, string(body))
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	outbox := repository.NewApiOutboxRepository(env.db, logger)
+
+	claimed, err := outbox.ClaimOutbox(2, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	assert.Equal(t, first, claimed[0].WalletID)
+	assert.Equal(t, first, claimed[1].WalletID)
+	assert.Less(t, claimed[0].ID, claimed[1].ID)
+
+	// Захваченные события не выдаются повторно, события другого кошелька — выдаются
+	next, err := outbox.ClaimOutbox(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, next, 1)
+	assert.Equal(t, second, next[0].WalletID)
+
+	// Первое событие не опубликовано и откладывается, второе освобождается: кошелек пропускается до повтора
+	require.NoError(t, outbox.CompleteOutbox(nil, []int64{claimed[0].ID}, []int64{claimed[1].ID}, time.Hour))
+	require.NoError(t, outbox.CompleteOutbox([]int64{next[0].ID}, nil, nil, time.Hour))
+	again, err := outbox.ClaimOutbox(10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+	assert.Equal(t, 1, env.count(t, `SELECT COUNT(*) FROM outbox_events WHERE published_at IS NOT NULL`))
+	assert.Equal(t, 1, env.count(t, `SELECT COUNT(*) FROM outbox_events WHERE attempts = 1 AND next_attempt_at > NOW() + INTERVAL '59 minutes'`))
+
+	// Когда время повтора наступило, события кошелька выдаются снова по порядку
+	_, err = env.db.Exec(context.Background(), `UPDATE outbox_events SET next_attempt_at = NOW() - INTERVAL '1 second' WHERE event_id = $1`, claimed[0].ID)
+	require.NoError(t, err)
+	again, err = outbox.ClaimOutbox(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, again, 2)
+	assert.Equal(t, claimed[0].ID, again[0].ID)
+	assert.Equal(t, claimed[1].ID, again[1].ID)
+}
@@ -0,0 +1,235 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// balanceAt запрашивает баланс кошелька на момент at через GET /api/v1/wallets/:walletID/balance
+func (e *testEnv) balanceAt(t *testing.T, walletID string, at time.Time) (int, float64) {
+	t.Helper()
+	status, _, body := e.do(t, http.MethodGet, "/api/v1/wallets/"+walletID+"/balance?at="+url.QueryEscape(at.Format(time.RFC3339)), nil, nil)
+	var resp models.BalanceAt
+	if status == http.StatusOK {
+		require.NoError(t, json.Unmarshal(body, &resp))
+	}
+	return status, resp.Balance
+}
+
+// TestReport_BalanceAt проверяет баланс на момент времени по снимкам и операциям после них
+func TestReport_BalanceAt(t *testing.T) {
+	env := newEnv(t)
+	ctx := context.Background()
+	walletID := env.createWallet(t, 0)
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	for _, op := range []struct {
+		opType string
+		amount float64
+	}{{"DEPOSIT", 100}, {"WITHDRAW", 30}, {"DEPOSIT", 50}} {
+		status, _, body := env.transact(t, walletID, op.opType, op.amount, "")
+		require.Equal(t, http.StatusOK, status, string(body))
+	}
+	// Операции переносятся в прошлое: по одной в середине 1, 2 и 3 января
+	for i, op := range env.operations(t, walletID) {
+		_, err := env.db.Exec(ctx, `UPDATE wallet_operations SET created_at = $2 WHERE operation_id = $1`, op.ID, day(i+1).Add(10*time.Hour))
+		require.NoError(t, err)
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	reports := repository.NewApiReportRepository(env.db, logger)
+	for _, at := range []time.Time{day(2), day(3)} {
+		taken, err := reports.TakeSnapshots(at)
+		require.NoError(t, err)
+		assert.Equal(t, 1, taken)
+	}
+	taken, err := reports.TakeSnapshots(day(3))
+	require.NoError(t, err)
+	assert.Zero(t, taken)
+	last, err := reports.LastSnapshot()
+	require.NoError(t, err)
+	assert.True(t, last.Equal(day(3)))
+
+	tests := []struct {
+		name string    // Название теста
+		at   time.Time // Момент времени
+		want float64   // Ожидаемый баланс
+	}{
+		{"Before First Operation", day(1), 0},
+		{"Without Snapshot", day(1).Add(12 * time.Hour), 100},
+		{"At Snapshot", day(2), 100},
+		{"After Snapshot", day(2).Add(12 * time.Hour), 70},
+		{"After Last Operation", day(3).Add(12 * time.Hour), 120},
+		{"Now", time.Now(), 120},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, balance := env.balanceAt(t, walletID, tt.at)
+			require.Equal(t, http.StatusOK, status)
+			assert.Equal(t, tt.want, balance)
+		})
+	}
+
+	// Баланс после снимка вычисляется от снимка, а не от начала журнала
+	_, err = env.db.Exec(ctx, `UPDATE wallet_balance_snapshots SET balance = 1000 WHERE wallet_id = $1 AND taken_at = $2`, walletID, day(3))
+	require.NoError(t, err)
+	_, balance := env.balanceAt(t, walletID, day(3).Add(12*time.Hour))
+	assert.Equal(t, 1050.0, balance)
+
+	status, _ := env.balanceAt(t, "00000000-0000-0000-0000-000000000000", day(2))
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _ = env.balanceAt(t, walletID, time.Now().Add(time.Hour))
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+// TestReport_Statement проверяет выписку за период в форматах JSON, CSV и PDF
+func TestReport_Statement(t *testing.T) {
+	env := newEnv(t)
+	ctx := context.Background()
+	walletID := env.createWallet(t, 0)
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	for _, op := range []struct {
+		opType string
+		amount float64
+	}{{"DEPOSIT", 100}, {"WITHDRAW", 30}, {"DEPOSIT", 50}} {
+		status, _, body := env.transact(t, walletID, op.opType, op.amount, "")
+		require.Equal(t, http.StatusOK, status, string(body))
+	}
+	ops := env.operations(t, walletID)
+	require.Len(t, ops, 3)
+	for i, op := range ops {
+		_, err := env.db.Exec(ctx, `UPDATE wallet_operations SET created_at = $2 WHERE operation_id = $1`, op.ID, day(i+1).Add(10*time.Hour))
+		require.NoError(t, err)
+	}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	_, err := repository.NewApiReportRepository(env.db, logger).TakeSnapshots(day(2))
+	require.NoError(t, err)
+
+	path := "/api/v1/wallets/" + walletID + "/statement?from=2025-01-02T00:00:00Z&to=2025-01-04T00:00:00Z"
+
+	status, header, body := env.do(t, http.MethodGet, path, nil, nil)
+	require.Equal(t, http.StatusOK, status, string(body))
+	assert.Equal(t, fmt.Sprintf(`attachment; filename="statement-%s-20250102-20250104.json"`, walletID), header.Get("Content-Disposition"))
+	var statement models.Statement
+	require.NoError(t, json.Unmarshal(body, &statement))
+	assert.Equal(t, 100.0, statement.OpeningBalance)
+	assert.Equal(t, 120.0, statement.ClosingBalance)
+	require.Len(t, statement.Operations, 2)
+	assert.Equal(t, ops[1].ID, statement.Operations[0].ID)
+	assert.Equal(t, 70.0, statement.Operations[0].BalanceAfter)
+	assert.Equal(t, 120.0, statement.Operations[1].BalanceAfter)
+
+	status, header, body = env.do(t, http.MethodGet, path+"&format=csv", nil, nil)
+	require.Equal(t, http.StatusOK, status, string(body))
+	assert.True(t, strings.HasPrefix(header.Get("Content-Type"), "text/csv"))
+	want := "operation_id,occurred_at,operation_type,amount,balance\n" +
+		",2025-01-02T00:00:00Z,OPENING_BALANCE,,100.00\n" +
+		fmt.Sprintf("%d,2025-01-02T10:00:00Z,WITHDRAW,-30.00,70.00\n", ops[1].ID) +
+		fmt.Sprintf("%d,2025-01-03T10:00:00Z,DEPOSIT,50.00,120.00\n", ops[2].ID) +
+		",2025-01-04T00:00:00Z,CLOSING_BALANCE,,120.00\n"
+	assert.Equal(t, want, string(body))
+
+	status, header, body = env.do(t, http.MethodGet, path+"&format=pdf", nil, nil)
+	require.Equal(t, http.StatusOK, status, string(body))
+	assert.Equal(t, "application/pdf", header.Get("Content-Type"))
+	assert.True(t, bytes.HasPrefix(body, []byte("%PDF-")))
+
+	status, _, _ = env.do(t, http.MethodGet, "/api/v1/wallets/00000000-0000-0000-0000-000000000000/statement?from=2025-01-02T00:00:00Z&to=2025-01-04T00:00:00Z", nil, nil)
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _, _ = env.do(t, http.MethodGet, "/api/v1/wallets/"+walletID+"/statement?from=2025-01-04T00:00:00Z&to=2025-01-02T00:00:00Z", nil, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+// dailyReport запрашивает дневной отчет с токеном администратора
+func (e *testEnv) dailyReport(t *testing.T, path string) (int, models.DailyReport) {
+	t.Helper()
+	status, _, body := e.do(t, http.MethodGet, path, nil, map[string]string{"Authorization": "Bearer integration-token"})
+	var report models.DailyReport
+	if status == http.StatusOK {
+		require.NoError(t, json.Unmarshal(body, &report))
+	}
+	return status, report
+}
+
+// TestReport_DailyReports проверяет закрытие суток дневными отчетами и их группировку по периодам
+func TestReport_DailyReports(t *testing.T) {
+	env := newEnv(t)
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	first := env.createWallet(t, 0)
+	second := env.createWallet(t, 0)
+
+	for _, op := range []struct {
+		walletID string
+		opType   string
+		amount   float64
+		day      int
+	}{{first, "DEPOSIT", 100, 1}, {first, "WITHDRAW", 30, 2}, {second, "DEPOSIT", 20, 2}, {first, "DEPOSIT", 50, 3}} {
+		status, _, body := env.transact(t, op.walletID, op.opType, op.amount, "")
+		require.Equal(t, http.StatusOK, status, string(body))
+		_, err := env.db.Exec(ctx, `UPDATE wallet_operations SET created_at = $2
+			WHERE operation_id = (SELECT MAX(operation_id) FROM wallet_operations WHERE wallet_id = $1)`, op.walletID, day(op.day).Add(10*time.Hour))
+		require.NoError(t, err)
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	reports := repository.NewApiReportRepository(env.db, logger)
+	for _, closing := range []struct {
+		day  int
+		want int
+	}{{1, 1}, {2, 2}, {3, 2}, {3, 0}} {
+		closed, err := reports.CloseDay(day(closing.day))
+		require.NoError(t, err)
+		assert.Equal(t, closing.want, closed, "day %d", closing.day)
+	}
+	last, err := reports.LastDailyReport()
+	require.NoError(t, err)
+	assert.True(t, last.Equal(day(3)))
+
+	status, report := env.dailyReport(t, "/api/v1/reports/daily?from=2025-01-01&to=2025-01-03")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []models.ReportPeriod{
+		{Period: "2025-01-01", Wallets: 1, OpeningBalance: 0, ClosingBalance: 100, Deposits: 100, DepositCount: 1},
+		{Period: "2025-01-02", Wallets: 2, OpeningBalance: 100, ClosingBalance: 90, Deposits: 20, Withdrawals: 30, DepositCount: 1, WithdrawalCount: 1},
+		{Period: "2025-01-03", Wallets: 2, OpeningBalance: 90, ClosingBalance: 140, Deposits: 50, DepositCount: 1},
+	}, report.Periods)
+
+	status, report = env.dailyReport(t, "/api/v1/reports/daily?from=2025-01-01&to=2025-01-31&groupBy=month")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []models.ReportPeriod{
+		{Period: "2025-01-01", Wallets: 2, OpeningBalance: 0, ClosingBalance: 140, Deposits: 170, Withdrawals: 30, DepositCount: 3, WithdrawalCount: 1},
+	}, report.Periods)
+
+	// 1 января 2025 года — среда, поэтому неделя с понедельника 30 декабря обрезается до первого дня отчета
+	status, report = env.dailyReport(t, "/api/v1/reports/daily/wallets/"+first+"?from=2025-01-01&to=2025-01-05&groupBy=week")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, first, report.WalletID)
+	assert.Equal(t, []models.ReportPeriod{
+		{Period: "2025-01-01", Wallets: 1, OpeningBalance: 0, ClosingBalance: 120, Deposits: 150, Withdrawals: 30, DepositCount: 2, WithdrawalCount: 1},
+	}, report.Periods)
+
+	status, _ = env.dailyReport(t, "/api/v1/reports/daily/wallets/00000000-0000-0000-0000-000000000000?from=2025-01-01&to=2025-01-03")
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _, _ = env.do(t, http.MethodGet, "/api/v1/reports/daily?from=2025-01-01&to=2025-01-03", nil, nil)
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
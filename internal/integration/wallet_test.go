@@ -0,0 +1,139 @@
+//go:build integration
+
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWallet_DepositAndWithdraw(t *testing.T) {
+	env := newEnv(t)
+	walletID := env.createWallet(t, 0)
+
+	status, _, body := env.transact(t, walletID, "DEPOSIT", 100, "")
+	require.Equal(t, http.StatusOK, status, string(body))
+	status, _, body = env.transact(t, walletID, "WITHDRAW", 30.55, "")
+	require.Equal(t, http.StatusOK, status, string(body))
+
+	balance, etag := env.balance(t, walletID)
+	assert.Equal(t, 69.45, balance)
+	assert.Equal(t, `"3"`, etag)
+
+	operations := env.operations(t, walletID)
+	require.Len(t, operations, 2)
+	assert.Equal(t, models.OperationDeposit, operations[0].Type)
+	assert.Equal(t, 100.0, operations[0].BalanceAfter)
+	assert.Equal(t, int64(2), operations[0].Version)
+	assert.Equal(t, models.OperationWithdraw, operations[1].Type)
+	assert.Equal(t, 30.55, operations[1].Amount)
+	assert.Equal(t, 69.45, operations[1].BalanceAfter)
+	assert.Equal(t, int64(3), operations[1].Version)
+
+	// Каждая операция записывает событие в outbox в той же транзакции
+	assert.Equal(t, 2, env.count(t, `SELECT COUNT(*) FROM outbox_events WHERE wallet_id = $1`, walletID))
+}
+
+func TestWallet_Errors(t *testing.T) {
+	env := newEnv(t)
+	walletID := env.createWallet(t, 10)
+	unknownID := "00000000-0000-4000-8000-" + randomHex(6)
+
+	tests := []struct {
+		name       string  // Название теста
+		walletID   string  // ID кошелька операции
+		opType     string  // Тип операции
+		amount     float64 // Сумма операции
+		wantStatus int     // Ожидаемый код ответа
+		wantError  string  // Ожидаемая ошибка в ответе
+	}{
+		{"Insufficient Funds", walletID, "WITHDRAW", 10.01, http.StatusUnprocessableEntity, "insufficient funds"},
+		{"Wallet Not Found", unknownID, "DEPOSIT", 1, http.StatusNotFound, "wallet not found"},
+		{"Negative Amount", walletID, "DEPOSIT", -1, http.StatusBadRequest, ""},
+		{"Invalid Operation Type", walletID, "STEAL", 1, http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _, body := env.transact(t, tt.walletID, tt.opType, tt.amount, "")
+			assert.Equal(t, tt.wantStatus, status, string(body))
+			if tt.wantError != "" {
+				assert.JSONEq(t, `{"error": "`+tt.wantError+`"}`, string(body))
+			}
+		})
+	}
+
+	// Отклоненные операции не меняют баланс и не попадают в журнал
+	balance, etag := env.balance(t, walletID)
+	assert.Equal(t, 10.0, balance)
+	assert.Equal(t, `"1"`, etag)
+	assert.Empty(t, env.operations(t, walletID))
+}
+
+func TestWallet_IfMatch(t *testing.T) {
+	env := newEnv(t)
+	walletID := env.createWallet(t, 50)
+
+	_, etag := env.balance(t, walletID)
+	status, header, body := env.transact(t, walletID, "WITHDRAW", 20, etag)
+	require.Equal(t, http.StatusOK, status, string(body))
+	assert.Equal(t, `"2"`, header.Get("ETag"))
+
+	// Повтор с устаревшей версией отклоняется, баланс не меняется
+	status, _, body = env.transact(t, walletID, "WITHDRAW", 20, etag)
+	assert.Equal(t, http.StatusPreconditionFailed, status, string(body))
+
+	balance, newETag := env.balance(t, walletID)
+	assert.Equal(t, 30.0, balance)
+	assert.Equal(t, `"2"`, newETag)
+}
+
+func TestBatch(t *testing.T) {
+	env := newEnv(t)
+	from := env.createWallet(t, 100)
+	to := env.createWallet(t, 0)
+
+	items := []models.BatchItem{
+		{OperationType: models.OperationTransfer, WalletID: from, ToWalletID: to, Amount: 60},
+		{OperationType: models.OperationWithdraw, WalletID: from, Amount: 50},
+		{OperationType: models.OperationDeposit, WalletID: to, Amount: 5},
+	}
+
+	// Атомарный пакет с невыполнимой операцией отклоняется целиком
+	status, resp := env.batch(t, "ATOMIC", items...)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.Equal(t, 0, resp.Applied)
+	assert.Equal(t, 1, resp.Failed)
+	balance, _ := env.balance(t, from)
+	assert.Equal(t, 100.0, balance)
+	assert.Empty(t, env.operations(t, from))
+
+	// В режиме BEST_EFFORT выполняются все выполнимые операции
+	status, resp = env.batch(t, "BEST_EFFORT", items...)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 2, resp.Applied)
+	assert.Equal(t, 1, resp.Failed)
+
+	fromBalance, fromETag := env.balance(t, from)
+	toBalance, toETag := env.balance(t, to)
+	assert.Equal(t, 40.0, fromBalance)
+	assert.Equal(t, `"2"`, fromETag)
+	assert.Equal(t, 65.0, toBalance)
+	assert.Equal(t, `"3"`, toETag)
+
+	// Результаты пакета содержат ID и версии записей журнала, сохраненных в базе
+	require.Len(t, resp.Results[0].Operations, 2)
+	debit := resp.Results[0].Operations[0]
+	stored := env.operations(t, from)
+	require.Len(t, stored, 1)
+	assert.Equal(t, stored[0].ID, debit.ID)
+	assert.Equal(t, models.OperationTransferOut, stored[0].Type)
+	assert.Equal(t, int64(2), debit.Version)
+
+	// Перевод и пополнение записывают по одному событию в outbox
+	assert.Equal(t, 2, env.count(t, `SELECT COUNT(*) FROM outbox_events`))
+}
@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// Действия, которые записываются в журнал аудита
+const (
+	AuditOperationApplied = "wallet.operation_applied"
+	AuditWalletCreated    = "wallet.created"
+	AuditWalletViewed     = "wallet.viewed"
+	AuditHistoryViewed    = "wallet.history_viewed"
+	AuditLogViewed        = "wallet.audit_viewed"
+	AuditWalletAdjusted   = "wallet.adjusted"
+	AuditWalletFrozen     = "wallet.frozen"
+	AuditWalletUnfrozen   = "wallet.unfrozen"
+	AuditReconciled       = "ledger.reconciled"
+	AuditStatementIssued  = "wallet.statement_issued"
+	AuditChainVerified    = "audit.verified"
+)
+
+// Origin описывает, от чьего имени и в рамках какого запроса выполняется изменение
+type Origin struct {
+	Actor     string `json:"actor"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// SystemOrigin — источник изменений, которые выполняет само приложение, например перенос буфера пополнений
+var SystemOrigin = Origin{Actor: "system"}
+
+// AuditEntry описывает запись журнала аудита: кто, что и когда сделал. Записи только добавляются
+// и образуют цепочку: Hash вычисляется по содержимому записи и Hash предыдущей записи (PrevHash)
+type AuditEntry struct {
+	ID            int64                  `json:"auditId"`
+	Seq           int64                  `json:"seq"` // Номер записи в цепочке, начиная с 1
+	Actor         string                 `json:"actor"`
+	Action        string                 `json:"action"`
+	WalletID      string                 `json:"walletId,omitempty"`
+	RequestID     string                 `json:"requestId,omitempty"`
+	OperationID   int64                  `json:"operationId,omitempty"`
+	BalanceBefore *float64               `json:"balanceBefore,omitempty"`
+	BalanceAfter  *float64               `json:"balanceAfter,omitempty"`
+	Details       map[string]interface{} `json:"details,omitempty"`
+	CreatedAt     time.Time              `json:"createdAt"`
+	PrevHash      string                 `json:"prevHash"`
+	Hash          string                 `json:"hash"`
+}
+
+// AuditCheckpoint — подписанная отметка состояния цепочки журнала аудита. Выгруженные контрольные точки
+// позволяют обнаружить переписывание журнала вместе с хэшами
+type AuditCheckpoint struct {
+	ID        int64     `json:"checkpointId"`
+	Seq       int64     `json:"seq"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"createdAt"`
+	KeyID     string    `json:"keyId"`
+	Signature string    `json:"signature"` // Подпись Ed25519 в base64
+}
+
+// AuditChainBreak описывает первое нарушение цепочки журнала аудита
+type AuditChainBreak struct {
+	Seq     int64  `json:"seq"`
+	AuditID int64  `json:"auditId,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// AuditVerification — результат проверки цепочки журнала аудита
+type AuditVerification struct {
+	Valid       bool             `json:"valid"`
+	Checked     int64            `json:"checked"`     // Количество проверенных записей
+	Checkpoints int              `json:"checkpoints"` // Количество сверенных контрольных точек
+	HeadSeq     int64            `json:"headSeq"`
+	HeadHash    string           `json:"headHash"`
+	Break       *AuditChainBreak `json:"break,omitempty"`
+}
+
+// AuditCheckpointExport — выгрузка контрольных точек вместе с открытым ключом, которым их можно проверить
+type AuditCheckpointExport struct {
+	KeyID       string            `json:"keyId,omitempty"`
+	PublicKey   string            `json:"publicKey,omitempty"` // Открытый ключ Ed25519 в base64; пустой, если подпись не настроена
+	Checkpoints []AuditCheckpoint `json:"checkpoints"`
+}
@@ -0,0 +1,44 @@
+package models
+
+// Режимы выполнения пакета операций
+const (
+	// BatchAtomic — все операции пакета выполняются в одной транзакции либо не выполняется ни одна
+	BatchAtomic = "ATOMIC"
+	// BatchBestEffort — выполняются все операции, которые возможно выполнить, остальные возвращаются с ошибкой
+	BatchBestEffort = "BEST_EFFORT"
+)
+
+// OperationTransfer — тип операции пакета для перевода между кошельками
+const OperationTransfer = "TRANSFER"
+
+// Статусы операции пакета
+const (
+	BatchItemApplied    = "APPLIED"
+	BatchItemFailed     = "FAILED"
+	BatchItemRolledBack = "ROLLED_BACK"
+)
+
+// BatchItem описывает одну операцию пакета. ToWalletID задается только для перевода
+type BatchItem struct {
+	OperationType string  `json:"operationType"`
+	WalletID      string  `json:"walletId"`
+	ToWalletID    string  `json:"toWalletId,omitempty"`
+	Amount        float64 `json:"amount"`
+}
+
+// BatchItemResult описывает результат операции пакета и записи журнала, которые она создала
+type BatchItemResult struct {
+	Index      int         `json:"index"`
+	Status     string      `json:"status"`
+	Error      string      `json:"error,omitempty"`
+	Operations []Operation `json:"operations,omitempty"`
+}
+
+// Transfer — содержимое события TransferCompleted
+type Transfer struct {
+	FromWalletID string    `json:"fromWalletId"`
+	ToWalletID   string    `json:"toWalletId"`
+	Amount       float64   `json:"amount"`
+	Debit        Operation `json:"debit"`
+	Credit       Operation `json:"credit"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Типы доменных событий кошелька
+const (
+	EventWalletDeposited   = "WalletDeposited"
+	EventWalletWithdrawn   = "WalletWithdrawn"
+	EventTransferCompleted = "TransferCompleted"
+	EventWalletAdjusted    = "WalletAdjusted"
+
+	// Сверка нашла кошелек, баланс которого не сходится с журналом операций
+	EventBalanceDiscrepancy = "BalanceDiscrepancyDetected"
+)
+
+// EventTypes перечисляет все типы событий, на которые можно подписаться
+var EventTypes = []string{
+	EventWalletDeposited,
+	EventWalletWithdrawn,
+	EventTransferCompleted,
+	EventWalletAdjusted,
+	EventBalanceDiscrepancy,
+}
+
+// Event описывает доменное событие, сохраненное в outbox
+type Event struct {
+	ID        int64           `json:"eventId"`
+	Type      string          `json:"eventType"`
+	WalletID  string          `json:"walletId"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Типы операций с кошельком
+const (
+	OperationOpening     = "OPENING"
+	OperationDeposit     = "DEPOSIT"
+	OperationWithdraw    = "WITHDRAW"
+	OperationTransferOut = "TRANSFER_OUT"
+	OperationTransferIn  = "TRANSFER_IN"
+
+	// Ручные корректировки баланса оператором
+	OperationAdjustmentCredit = "ADJUSTMENT_CREDIT"
+	OperationAdjustmentDebit  = "ADJUSTMENT_DEBIT"
+)
+
+// Operation описывает запись журнала операций кошелька
+type Operation struct {
+	ID           int64     `json:"operationId"`
+	WalletID     string    `json:"walletId"`
+	Type         string    `json:"operationType"`
+	Amount       float64   `json:"amount"`
+	BalanceAfter float64   `json:"balance"`
+	Version      int64     `json:"version"`
+	CreatedAt    time.Time `json:"occurredAt"`
+}
+
+// SignedAmount возвращает сумму операции со знаком: отрицательную для операций, уменьшающих баланс
+func (op Operation) SignedAmount() float64 {
+	switch op.Type {
+	case OperationWithdraw, OperationTransferOut, OperationAdjustmentDebit:
+		return -op.Amount
+	}
+	return op.Amount
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Статусы задания на выплаты
+const (
+	PayoutJobRunning   = "RUNNING"
+	PayoutJobCompleted = "COMPLETED"
+)
+
+// Статусы строки задания на выплаты
+const (
+	PayoutRowPending = "PENDING"
+	PayoutRowPaid    = "PAID"
+	PayoutRowFailed  = "FAILED"
+)
+
+// PayoutJob описывает задание на массовые выплаты и его прогресс
+type PayoutJob struct {
+	ID            string     `json:"jobId"`
+	Status        string     `json:"status"`
+	TotalRows     int        `json:"totalRows"`
+	ProcessedRows int        `json:"processedRows"`
+	FailedRows    int        `json:"failedRows"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	CompletedAt   *time.Time `json:"completedAt,omitempty"`
+}
+
+// PayoutRow описывает строку задания на выплаты. Row — номер строки данных в загруженном файле, начиная с 1.
+// Amount не задан, если сумму в строке не удалось разобрать
+type PayoutRow struct {
+	JobID    string   `json:"-"`
+	Row      int      `json:"row"`
+	WalletID string   `json:"walletId"`
+	Amount   *float64 `json:"amount,omitempty"`
+	Status   string   `json:"status"`
+	Error    string   `json:"error,omitempty"`
+}
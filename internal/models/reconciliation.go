@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// WalletDiscrepancy описывает кошелек, баланс которого не сходится с журналом операций
+type WalletDiscrepancy struct {
+	WalletID         string  `json:"walletId"`
+	Balance          float64 `json:"balance"`          // Баланс в таблице кошельков
+	LedgerBalance    float64 `json:"ledgerBalance"`    // Сумма операций журнала с учетом их знака
+	LastBalanceAfter float64 `json:"lastBalanceAfter"` // Баланс после последней операции журнала
+	Operations       int64   `json:"operations"`       // Количество операций в журнале
+}
+
+// LedgerInvariant — проверка глобального инварианта: сумма балансов всех кошельков равна сумме поступлений
+// за вычетом списаний. Переводы перемещают деньги между кошельками, поэтому входящие и исходящие переводы
+// должны совпадать
+type LedgerInvariant struct {
+	TotalBalance float64 `json:"totalBalance"` // Сумма балансов в таблице кошельков
+	Credits      float64 `json:"credits"`      // Начальные балансы, пополнения и корректировки в плюс
+	Debits       float64 `json:"debits"`       // Списания и корректировки в минус
+	TransfersIn  float64 `json:"transfersIn"`
+	TransfersOut float64 `json:"transfersOut"`
+	Holds        bool    `json:"holds"`
+}
+
+// ReconciliationReport — результат сверки балансов кошельков с журналом операций. Глобальный инвариант
+// проверяется только при сверке всех кошельков
+type ReconciliationReport struct {
+	StartedAt     time.Time           `json:"startedAt"`
+	FinishedAt    time.Time           `json:"finishedAt"`
+	Checked       int                 `json:"checked"`
+	Discrepancies []WalletDiscrepancy `json:"discrepancies"`
+	Invariant     *LedgerInvariant    `json:"invariant,omitempty"`
+}
+
+// Clean сообщает, что сверка не нашла расхождений и глобальный инвариант, если он проверялся, выполняется
+func (r ReconciliationReport) Clean() bool {
+	return len(r.Discrepancies) == 0 && (r.Invariant == nil || r.Invariant.Holds)
+}
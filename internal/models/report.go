@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// BalanceAt — баланс кошелька на момент At с учетом всех операций, совершенных не позже него
+type BalanceAt struct {
+	WalletID string    `json:"walletId"`
+	At       time.Time `json:"at"`
+	Balance  float64   `json:"balance"`
+}
+
+// Группировка дневных отчетов по периодам
+const (
+	ReportGroupDay   = "day"
+	ReportGroupWeek  = "week"
+	ReportGroupMonth = "month"
+)
+
+// ReportGroups перечисляет допустимые группировки дневных отчетов
+var ReportGroups = []string{ReportGroupDay, ReportGroupWeek, ReportGroupMonth}
+
+// ReportPeriod — итоги за период группировки. Period — первый день периода (неделя начинается с понедельника);
+// баланс на начало берется за первый день периода в пределах запрошенных дат, на конец — за последний.
+// Пополнения включают все операции, увеличивающие баланс, списания — все операции, уменьшающие его, поэтому
+// ClosingBalance = OpeningBalance + Deposits - Withdrawals
+type ReportPeriod struct {
+	Period          string  `json:"period"`
+	Wallets         int64   `json:"wallets"`
+	OpeningBalance  float64 `json:"openingBalance"`
+	ClosingBalance  float64 `json:"closingBalance"`
+	Deposits        float64 `json:"deposits"`
+	Withdrawals     float64 `json:"withdrawals"`
+	DepositCount    int64   `json:"depositCount"`
+	WithdrawalCount int64   `json:"withdrawalCount"`
+}
+
+// DailyReport — итоги по дневным отчетам за дни с From по To включительно, по всем кошелькам
+// или по кошельку WalletID
+type DailyReport struct {
+	WalletID string         `json:"walletId,omitempty"`
+	From     string         `json:"from"`
+	To       string         `json:"to"`
+	GroupBy  string         `json:"groupBy"`
+	Periods  []ReportPeriod `json:"periods"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Statement — выписка по кошельку за период [From, To): баланс на начало, операции с балансом после каждой
+// и баланс на конец периода
+type Statement struct {
+	WalletID       string      `json:"walletId"`
+	From           time.Time   `json:"from"`
+	To             time.Time   `json:"to"`
+	OpeningBalance float64     `json:"openingBalance"`
+	ClosingBalance float64     `json:"closingBalance"`
+	Operations     []Operation `json:"operations"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Wallet описывает состояние кошелька вместе с его версией
+type Wallet struct {
+	ID      string
+	Balance float64
+	Version int64
+}
+
+// WalletInfo описывает кошелек для операторов: помимо баланса и версии — заморозку и время создания
+type WalletInfo struct {
+	ID        string    `json:"walletId"`
+	Balance   float64   `json:"balance"`
+	Version   int64     `json:"version"`
+	Frozen    bool      `json:"frozen"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,144 @@
+package outbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/config"
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+)
+
+// Publisher доставляет доменные события во внешний мир. Ошибка означает, что событие нужно доставить повторно
+type Publisher interface {
+	Publish(event models.Event) error
+}
+
+// NewPublisher создает публикатор событий по настройкам из конфигурации
+func NewPublisher(cfg *config.Config) (Publisher, error) {
+	switch cfg.OutboxPublisher {
+	case "", "stdout":
+		return NewWriterPublisher(os.Stdout), nil
+	case "file":
+		return NewFilePublisher(cfg.OutboxFilePath)
+	case "http":
+		if cfg.OutboxWebhookURL == "" {
+			return nil, fmt.Errorf("OUTBOX_WEBHOOK_URL is required for http publisher")
+		}
+		return NewHTTPPublisher(cfg.OutboxWebhookURL, &http.Client{Timeout: 10 * time.Second}), nil
+	default:
+		return nil, fmt.Errorf("unknown outbox publisher %q", cfg.OutboxPublisher)
+	}
+}
+
+// WriterPublisher записывает события в формате JSON Lines в произвольный io.Writer
+type WriterPublisher struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterPublisher(w io.Writer) *WriterPublisher {
+	return &WriterPublisher{w: w}
+}
+
+// Публикация события отдельной строкой JSON
+func (p *WriterPublisher) Publish(event models.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err = p.w.Write(append(data, '\n'))
+	return err
+}
+
+// FilePublisher дописывает события в файл JSON Lines и сбрасывает их на диск после каждой записи
+type FilePublisher struct {
+	WriterPublisher
+	file *os.File
+}
+
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	if path == "" {
+		return nil, fmt.Errorf("OUTBOX_FILE_PATH is required for file publisher")
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FilePublisher{WriterPublisher: WriterPublisher{w: file}, file: file}, nil
+}
+
+// Публикация события с синхронизацией файла, чтобы событие не было помечено опубликованным раньше записи на диск
+func (p *FilePublisher) Publish(event models.Event) error {
+	if err := p.WriterPublisher.Publish(event); err != nil {
+		return err
+	}
+	return p.file.Sync()
+}
+
+// Close закрывает файл событий
+func (p *FilePublisher) Close() error {
+	return p.file.Close()
+}
+
+// HTTPPublisher отправляет каждое событие POST-запросом с телом в формате JSON
+type HTTPPublisher struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPPublisher(url string, client *http.Client) *HTTPPublisher {
+	return &HTTPPublisher{
+		url:    url,
+		client: client,
+	}
+}
+
+// Публикация события; любой ответ, кроме 2xx, считается неудачной доставкой
+func (p *HTTPPublisher) Publish(event models.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Type", event.Type)
+	req.Header.Set("X-Event-ID", fmt.Sprint(event.ID))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// MultiPublisher публикует событие во все публикаторы по очереди.
+// Ошибка любого из них приводит к повторной публикации события во все публикаторы
+type MultiPublisher []Publisher
+
+func (m MultiPublisher) Publish(event models.Event) error {
+	for _, publisher := range m {
+		if err := publisher.Publish(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
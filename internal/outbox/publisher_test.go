@@ -0,0 +1,67 @@
+package outbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWriterPublisher проверяет, что события записываются отдельными строками JSON
+func TestWriterPublisher(t *testing.T) {
+	var buf bytes.Buffer
+	publisher := NewWriterPublisher(&buf)
+
+	assert.NoError(t, publisher.Publish(models.Event{ID: 1, Type: models.EventWalletDeposited, WalletID: "wallet-123", Payload: json.RawMessage(`{}`)}))
+	assert.NoError(t, publisher.Publish(models.Event{ID: 2, Type: models.EventWalletWithdrawn, WalletID: "wallet-123", Payload: json.RawMessage(`{}`)}))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+
+	var event models.Event
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &event))
+	assert.Equal(t, models.EventWalletWithdrawn, event.Type)
+}
+
+// TestFilePublisher проверяет, что события дописываются в файл
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	publisher, err := NewFilePublisher(path)
+	assert.NoError(t, err)
+	defer publisher.Close()
+
+	assert.NoError(t, publisher.Publish(models.Event{ID: 1, Type: models.EventWalletDeposited, Payload: json.RawMessage(`{}`)}))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"eventType":"WalletDeposited"`)
+}
+
+// TestHTTPPublisher проверяет доставку события и обработку ответа с ошибкой
+func TestHTTPPublisher(t *testing.T) {
+	status := http.StatusOK
+	var received models.Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	publisher := NewHTTPPublisher(server.URL, server.Client())
+	event := models.Event{ID: 7, Type: models.EventWalletDeposited, WalletID: "wallet-123", Payload: json.RawMessage(`{}`)}
+
+	// Успешная доставка
+	assert.NoError(t, publisher.Publish(event))
+	assert.Equal(t, int64(7), received.ID)
+
+	// Ответ 5xx означает, что событие нужно доставить повторно
+	status = http.StatusServiceUnavailable
+	assert.Error(t, publisher.Publish(event))
+}
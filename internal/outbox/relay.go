@@ -0,0 +1,102 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// claimLease — время, на которое экземпляр захватывает порцию событий. Публикация порции прекращается
+// по истечении половины этого времени, чтобы события не захватил другой экземпляр, пока их еще публикуют
+const claimLease = 2 * time.Minute
+
+// retryDelay — задержка перед первой повторной публикацией события; каждая следующая неудача удваивает ее
+const retryDelay = 5 * time.Second
+
+// Relay периодически забирает события из outbox и передает их публикатору.
+// Доставка выполняется не менее одного раза: событие помечается опубликованным только после успешной публикации.
+// События захватываются в короткой транзакции и публикуются вне ее, поэтому медленный получатель
+// не удерживает соединение с базой данных и блокировку outbox
+type Relay struct {
+	repo      repository.OutboxRepository
+	publisher Publisher
+	logger    *logrus.Logger
+	interval  time.Duration
+	batchSize int
+}
+
+func NewRelay(repo repository.OutboxRepository, publisher Publisher, logger *logrus.Logger, interval time.Duration, batchSize int) *Relay {
+	return &Relay{
+		repo:      repo,
+		publisher: publisher,
+		logger:    logger,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Run обрабатывает outbox до отмены контекста
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		// Пока события есть, забираем их без ожидания следующего тика
+		for {
+			published, err := r.RelayOnce()
+			if err != nil || published < r.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce публикует одну порцию событий и возвращает количество опубликованных
+func (r *Relay) RelayOnce() (int, error) {
+	events, err := r.repo.ClaimOutbox(r.batchSize, claimLease)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+
+	published, failed, released := r.publishBatch(events, time.Now().Add(claimLease/2))
+	if err := r.repo.CompleteOutbox(published, failed, released, retryDelay); err != nil {
+		return 0, err
+	}
+	return len(published), nil
+}
+
+// publishBatch публикует события по порядку до наступления deadline. После первой неудачи события того же
+// кошелька в этой порции не публикуются, чтобы получатели не увидели их раньше неудавшегося: неудавшееся
+// событие откладывается, следующие за ним освобождаются вместе с событиями, до которых не дошла очередь
+func (r *Relay) publishBatch(events []models.Event, deadline time.Time) (published, failed, released []int64) {
+	blocked := make(map[string]bool)
+	published = make([]int64, 0, len(events))
+
+	for _, event := range events {
+		if blocked[event.WalletID] || time.Now().After(deadline) {
+			released = append(released, event.ID)
+			continue
+		}
+		if err := r.publisher.Publish(event); err != nil {
+			r.logger.Warnf("Failed to publish event %d (%s) for wallet %s: %v", event.ID, event.Type, event.WalletID, err)
+			blocked[event.WalletID] = true
+			failed = append(failed, event.ID)
+			continue
+		}
+		published = append(published, event.ID)
+	}
+
+	if len(published) > 0 {
+		r.logger.Debugf("Published %d of %d outbox events", len(published), len(events))
+	}
+	return published, failed, released
+}
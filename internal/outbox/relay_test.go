@@ -0,0 +1,158 @@
+package outbox
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOutboxRepository хранит события в памяти и повторяет правила захвата ApiOutboxRepository:
+// событие захватывается, если ни одно более раннее неопубликованное событие кошелька не захвачено и не отложено
+type fakeOutboxRepository struct {
+	events    []models.Event
+	published map[int64]bool
+	claimed   map[int64]bool
+	deferred  map[int64]bool
+	completed int
+}
+
+func newFakeOutboxRepository(events ...models.Event) *fakeOutboxRepository {
+	return &fakeOutboxRepository{
+		events:    events,
+		published: make(map[int64]bool),
+		claimed:   make(map[int64]bool),
+		deferred:  make(map[int64]bool),
+	}
+}
+
+func (r *fakeOutboxRepository) ClaimOutbox(limit int, lease time.Duration) ([]models.Event, error) {
+	blocked := make(map[string]bool)
+	var claimed []models.Event
+	for _, event := range r.events {
+		if r.published[event.ID] {
+			continue
+		}
+		if r.claimed[event.ID] || r.deferred[event.ID] {
+			blocked[event.WalletID] = true
+		}
+		if blocked[event.WalletID] || len(claimed) == limit {
+			continue
+		}
+		claimed = append(claimed, event)
+	}
+	for _, event := range claimed {
+		r.claimed[event.ID] = true
+	}
+	return claimed, nil
+}
+
+func (r *fakeOutboxRepository) CompleteOutbox(published, failed, released []int64, retryDelay time.Duration) error {
+	r.completed++
+	for _, id := range published {
+		r.published[id] = true
+		delete(r.claimed, id)
+	}
+	for _, id := range failed {
+		r.deferred[id] = true
+		delete(r.claimed, id)
+	}
+	for _, id := range released {
+		delete(r.claimed, id)
+	}
+	return nil
+}
+
+// retryDue имитирует наступление времени повторной публикации отложенных событий
+func (r *fakeOutboxRepository) retryDue() {
+	r.deferred = make(map[int64]bool)
+}
+
+// fakePublisher запоминает доставленные события и отказывает в доставке для указанных ID
+type fakePublisher struct {
+	delivered []int64
+	failIDs   map[int64]bool
+}
+
+func (p *fakePublisher) Publish(event models.Event) error {
+	if p.failIDs[event.ID] {
+		return errors.New("publish failed")
+	}
+	p.delivered = append(p.delivered, event.ID)
+	return nil
+}
+
+// TestRelay_PreservesOrderPerWallet проверяет, что после неудачной публикации события кошелька
+// следующие события этого кошелька не публикуются, а события других кошельков доставляются
+func TestRelay_PreservesOrderPerWallet(t *testing.T) {
+	repo := newFakeOutboxRepository(
+		models.Event{ID: 1, WalletID: "wallet-a"},
+		models.Event{ID: 2, WalletID: "wallet-b"},
+		models.Event{ID: 3, WalletID: "wallet-a"},
+		models.Event{ID: 4, WalletID: "wallet-b"},
+	)
+	publisher := &fakePublisher{failIDs: map[int64]bool{1: true}}
+	relay := NewRelay(repo, publisher, logrus.New(), 0, 10)
+
+	// Первое событие кошелька wallet-a не доставлено, поэтому событие 3 тоже откладывается
+	published, err := relay.RelayOnce()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, []int64{2, 4}, publisher.delivered)
+	assert.Empty(t, repo.claimed, "all claimed events must be completed or released")
+
+	// До наступления времени повтора события wallet-a не захватываются
+	published, err = relay.RelayOnce()
+	assert.NoError(t, err)
+	assert.Zero(t, published)
+
+	// После восстановления получателя события wallet-a доставляются в исходном порядке
+	publisher.failIDs = nil
+	repo.retryDue()
+	published, err = relay.RelayOnce()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, []int64{2, 4, 1, 3}, publisher.delivered)
+}
+
+// TestRelay_SkipsBlockedWallet проверяет, что события кошелька, публикация которых откладывается,
+// не занимают порции и не задерживают события других кошельков
+func TestRelay_SkipsBlockedWallet(t *testing.T) {
+	repo := newFakeOutboxRepository(
+		models.Event{ID: 1, WalletID: "wallet-a"},
+		models.Event{ID: 2, WalletID: "wallet-a"},
+		models.Event{ID: 3, WalletID: "wallet-a"},
+		models.Event{ID: 4, WalletID: "wallet-b"},
+		models.Event{ID: 5, WalletID: "wallet-c"},
+	)
+	publisher := &fakePublisher{failIDs: map[int64]bool{1: true}}
+	relay := NewRelay(repo, publisher, logrus.New(), 0, 2)
+
+	// Порция из событий 1 и 2: событие 1 откладывается, событие 2 освобождается
+	published, err := relay.RelayOnce()
+	require.NoError(t, err)
+	assert.Zero(t, published)
+
+	// Следующая порция пропускает кошелек wallet-a, а не читает снова его первые события
+	published, err = relay.RelayOnce()
+	require.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, []int64{4, 5}, publisher.delivered)
+	assert.Equal(t, 2, repo.completed)
+}
+
+// TestRelay_ReleasesAfterDeadline проверяет, что события, до которых не дошла очередь до истечения
+// времени публикации порции, освобождаются для следующей порции
+func TestRelay_ReleasesAfterDeadline(t *testing.T) {
+	relay := NewRelay(newFakeOutboxRepository(), &fakePublisher{}, logrus.New(), 0, 10)
+	events := []models.Event{{ID: 1, WalletID: "wallet-a"}, {ID: 2, WalletID: "wallet-b"}}
+
+	published, failed, released := relay.publishBatch(events, time.Now().Add(-time.Second))
+	assert.Empty(t, published)
+	assert.Empty(t, failed)
+	assert.Equal(t, []int64{1, 2}, released)
+}
@@ -0,0 +1,139 @@
+package payout
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service"
+	"github.com/sirupsen/logrus"
+)
+
+// Options задает параметры обработки заданий на выплаты
+type Options struct {
+	Workers      int           // Количество параллельных выплат
+	BatchSize    int           // Количество строк, захватываемых за один раз
+	PollInterval time.Duration // Период опроса необработанных строк
+	Lease        time.Duration // Время, на которое захватываются строки
+}
+
+// Processor выполняет строки заданий на выплаты пулом воркеров через WalletService.
+// Каждая выплата выполняется с ключом идемпотентности строки, поэтому строка, выплаченная
+// перед остановкой приложения, при повторном захвате не будет выплачена второй раз
+type Processor struct {
+	repo    repository.PayoutRepository
+	wallets service.WalletService
+	logger  *logrus.Logger
+	options Options
+}
+
+func NewProcessor(repo repository.PayoutRepository, wallets service.WalletService, logger *logrus.Logger, options Options) *Processor {
+	return &Processor{
+		repo:    repo,
+		wallets: wallets,
+		logger:  logger,
+		options: options,
+	}
+}
+
+// Run обрабатывает задания до отмены контекста
+func (p *Processor) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.options.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			processed, err := p.ProcessOnce()
+			if err != nil || processed < p.options.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessOnce захватывает и выплачивает одну порцию строк и возвращает их количество
+func (p *Processor) ProcessOnce() (int, error) {
+	rows, err := p.repo.ClaimPendingRows(p.options.BatchSize, p.options.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	queue := make(chan models.PayoutRow)
+	var wg sync.WaitGroup
+	for i := 0; i < p.options.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range queue {
+				p.pay(row)
+			}
+		}()
+	}
+	for _, row := range rows {
+		queue <- row
+	}
+	close(queue)
+	wg.Wait()
+
+	return len(rows), nil
+}
+
+// pay выполняет выплату по строке и сохраняет ее результат. При временной ошибке строка остается
+// необработанной и будет захвачена повторно после истечения захвата
+func (p *Processor) pay(row models.PayoutRow) {
+	if row.Amount == nil {
+		p.complete(row, "amount is missing")
+		return
+	}
+
+	key := IdempotencyKey(row)
+	wallets := service.ScopeOrigin(p.wallets, models.Origin{Actor: "payout", RequestID: key})
+	applied, err := wallets.DepositIdempotent(row.WalletID, *row.Amount, key)
+	if failure, ok := permanentFailure(err); ok {
+		p.complete(row, failure)
+		return
+	}
+	if err != nil {
+		p.logger.Warnf("Payout row %d of job %s failed, will retry: %v", row.Row, row.JobID, err)
+		return
+	}
+	if !applied {
+		p.logger.Infof("Payout row %d of job %s was already paid", row.Row, row.JobID)
+	}
+	p.complete(row, "")
+}
+
+// permanentFailure возвращает причину отказа в выплате, если повтор не изменит результат:
+// кошелек не найден или заморожен, сумма строки недопустима
+func permanentFailure(err error) (string, bool) {
+	switch {
+	case errors.Is(err, repository.ErrWalletNotFound):
+		return repository.ErrWalletNotFound.Error(), true
+	case errors.Is(err, repository.ErrWalletFrozen):
+		return repository.ErrWalletFrozen.Error(), true
+	case errors.Is(err, service.ErrInvalidArgument):
+		return err.Error(), true
+	}
+	return "", false
+}
+
+func (p *Processor) complete(row models.PayoutRow, failure string) {
+	if err := p.repo.CompleteRow(row.JobID, row.Row, failure); err != nil {
+		p.logger.Errorf("Failed to record result of payout row %d of job %s: %v", row.Row, row.JobID, err)
+	}
+}
+
+// IdempotencyKey возвращает ключ идемпотентности выплаты по строке задания
+func IdempotencyKey(row models.PayoutRow) string {
+	return "payout:" + row.JobID + ":" + strconv.Itoa(row.Row)
+}
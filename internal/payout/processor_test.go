@@ -0,0 +1,150 @@
+package payout
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	repomock "github.com/VadimBorzenkov/WalletAPI/internal/repository/mock"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service"
+	servicemock "github.com/VadimBorzenkov/WalletAPI/internal/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestProcessor_ProcessOnce проверяет результат строк выплат в зависимости от ответа сервиса кошельков
+func TestProcessor_ProcessOnce(t *testing.T) {
+	amount := 25.0
+	row := models.PayoutRow{JobID: "job-1", Row: 3, WalletID: "wallet-1", Amount: &amount, Status: models.PayoutRowPending}
+
+	tests := []struct {
+		name        string                                       // Название теста
+		row         models.PayoutRow                             // Захваченная строка
+		mockDeposit func(wallets *servicemock.MockWalletService) // Настройка мок сервиса кошельков
+		mockResult  func(repo *repomock.MockPayoutRepository)    // Ожидаемая фиксация результата строки
+	}{
+		{
+			name: "Paid",
+			row:  row,
+			mockDeposit: func(wallets *servicemock.MockWalletService) {
+				wallets.EXPECT().DepositIdempotent("wallet-1", 25.0, "payout:job-1:3").Return(true, nil)
+			},
+			mockResult: func(repo *repomock.MockPayoutRepository) {
+				repo.EXPECT().CompleteRow("job-1", 3, "").Return(nil)
+			},
+		},
+		{
+			// Строка была выплачена до остановки приложения, но результат не успел сохраниться
+			name: "Already Paid",
+			row:  row,
+			mockDeposit: func(wallets *servicemock.MockWalletService) {
+				wallets.EXPECT().DepositIdempotent("wallet-1", 25.0, "payout:job-1:3").Return(false, nil)
+			},
+			mockResult: func(repo *repomock.MockPayoutRepository) {
+				repo.EXPECT().CompleteRow("job-1", 3, "").Return(nil)
+			},
+		},
+		{
+			name: "Wallet Not Found",
+			row:  row,
+			mockDeposit: func(wallets *servicemock.MockWalletService) {
+				wallets.EXPECT().DepositIdempotent("wallet-1", 25.0, "payout:job-1:3").
+					Return(false, fmt.Errorf("could not deposit amount: %w", repository.ErrWalletNotFound))
+			},
+			mockResult: func(repo *repomock.MockPayoutRepository) {
+				repo.EXPECT().CompleteRow("job-1", 3, repository.ErrWalletNotFound.Error()).Return(nil)
+			},
+		},
+		{
+			// Замороженный кошелек не разморозится повтором: строка завершается с ошибкой
+			name: "Wallet Frozen",
+			row:  row,
+			mockDeposit: func(wallets *servicemock.MockWalletService) {
+				wallets.EXPECT().DepositIdempotent("wallet-1", 25.0, "payout:job-1:3").
+					Return(false, fmt.Errorf("could not deposit amount: %w", repository.ErrWalletFrozen))
+			},
+			mockResult: func(repo *repomock.MockPayoutRepository) {
+				repo.EXPECT().CompleteRow("job-1", 3, repository.ErrWalletFrozen.Error()).Return(nil)
+			},
+		},
+		{
+			name: "Invalid Amount",
+			row:  row,
+			mockDeposit: func(wallets *servicemock.MockWalletService) {
+				wallets.EXPECT().DepositIdempotent("wallet-1", 25.0, "payout:job-1:3").
+					Return(false, fmt.Errorf("%w: amount must be positive", service.ErrInvalidArgument))
+			},
+			mockResult: func(repo *repomock.MockPayoutRepository) {
+				repo.EXPECT().CompleteRow("job-1", 3, "invalid argument: amount must be positive").Return(nil)
+			},
+		},
+		{
+			// Временная ошибка оставляет строку необработанной до истечения захвата
+			name: "Transient Error",
+			row:  row,
+			mockDeposit: func(wallets *servicemock.MockWalletService) {
+				wallets.EXPECT().DepositIdempotent("wallet-1", 25.0, "payout:job-1:3").Return(false, fmt.Errorf("connection reset"))
+			},
+			mockResult: func(repo *repomock.MockPayoutRepository) {},
+		},
+		{
+			name:        "Missing Amount",
+			row:         models.PayoutRow{JobID: "job-1", Row: 4, WalletID: "wallet-1", Status: models.PayoutRowPending},
+			mockDeposit: func(wallets *servicemock.MockWalletService) {},
+			mockResult: func(repo *repomock.MockPayoutRepository) {
+				repo.EXPECT().CompleteRow("job-1", 4, "amount is missing").Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := repomock.NewMockPayoutRepository(ctrl)
+			wallets := servicemock.NewMockWalletService(ctrl)
+			repo.EXPECT().ClaimPendingRows(10, time.Minute).Return([]models.PayoutRow{tt.row}, nil)
+			tt.mockDeposit(wallets)
+			tt.mockResult(repo)
+
+			logger := logrus.New()
+			logger.SetOutput(io.Discard)
+			processor := NewProcessor(repo, wallets, logger, Options{Workers: 2, BatchSize: 10, Lease: time.Minute})
+
+			processed, err := processor.ProcessOnce()
+			assert.NoError(t, err)
+			assert.Equal(t, 1, processed)
+		})
+	}
+}
+
+// TestProcessor_ProcessOnce_Concurrent проверяет, что все захваченные строки выплачиваются пулом воркеров
+func TestProcessor_ProcessOnce_Concurrent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	amount := 1.0
+	rows := make([]models.PayoutRow, 100)
+	for i := range rows {
+		rows[i] = models.PayoutRow{JobID: "job-1", Row: i + 1, WalletID: "wallet-1", Amount: &amount, Status: models.PayoutRowPending}
+	}
+
+	repo := repomock.NewMockPayoutRepository(ctrl)
+	wallets := servicemock.NewMockWalletService(ctrl)
+	repo.EXPECT().ClaimPendingRows(len(rows), time.Minute).Return(rows, nil)
+	wallets.EXPECT().DepositIdempotent("wallet-1", 1.0, gomock.Any()).Return(true, nil).Times(len(rows))
+	repo.EXPECT().CompleteRow("job-1", gomock.Any(), "").Return(nil).Times(len(rows))
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	processor := NewProcessor(repo, wallets, logger, Options{Workers: 8, BatchSize: len(rows), Lease: time.Minute})
+
+	processed, err := processor.ProcessOnce()
+	assert.NoError(t, err)
+	assert.Equal(t, len(rows), processed)
+}
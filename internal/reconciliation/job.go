@@ -0,0 +1,64 @@
+package reconciliation
+
+import (
+	"context"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Actor — имя, от которого плановая сверка записывается в журнал аудита
+const Actor = "reconciliation"
+
+// Reconciler сверяет балансы кошельков с журналом операций. Пустой walletIDs означает все кошельки
+type Reconciler interface {
+	Reconcile(actor string, walletIDs []string) (models.ReconciliationReport, error)
+}
+
+// Job периодически сверяет балансы всех кошельков с журналом операций и публикует результат в метриках
+type Job struct {
+	reconciler Reconciler
+	logger     *logrus.Logger
+	interval   time.Duration
+}
+
+func NewJob(reconciler Reconciler, logger *logrus.Logger, interval time.Duration) *Job {
+	return &Job{
+		reconciler: reconciler,
+		logger:     logger,
+		interval:   interval,
+	}
+}
+
+// Run выполняет сверку раз в интервал до отмены контекста
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		j.RunOnce()
+	}
+}
+
+// RunOnce выполняет одну сверку всех кошельков
+func (j *Job) RunOnce() {
+	report, err := j.reconciler.Reconcile(Actor, nil)
+	if err != nil {
+		failures.Add(1)
+		j.logger.Errorf("Scheduled reconciliation failed: %v", err)
+		return
+	}
+	observe(report)
+	if report.Clean() {
+		j.logger.Infof("Scheduled reconciliation checked %d wallets, no discrepancies", report.Checked)
+		return
+	}
+	j.logger.Warnf("Scheduled reconciliation checked %d wallets, found %d discrepancies, invariant holds: %t",
+		report.Checked, len(report.Discrepancies), report.Invariant == nil || report.Invariant.Holds)
+}
@@ -0,0 +1,70 @@
+package reconciliation
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestJob_RunOnce проверяет, что плановая сверка проверяет все кошельки и обновляет метрики,
+// а ошибка учитывается в счетчике неудачных запусков
+func TestJob_RunOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := mock.NewMockAdminService(ctrl)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	job := NewJob(svc, logger, time.Hour)
+
+	started := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string                      // Название теста
+		report     models.ReconciliationReport // Результат сверки
+		err        error                       // Ошибка сверки
+		runs       int64                       // Ожидаемый прирост числа выполненных сверок
+		failures   int64                       // Ожидаемый прирост числа неудачных запусков
+		mismatches int64                       // Ожидаемое число расхождений последней сверки
+		holds      int64                       // Ожидаемый признак выполнения инварианта
+	}{
+		{
+			name: "Clean",
+			report: models.ReconciliationReport{StartedAt: started, FinishedAt: started.Add(1500 * time.Millisecond), Checked: 3,
+				Discrepancies: []models.WalletDiscrepancy{}, Invariant: &models.LedgerInvariant{TotalBalance: 10, Credits: 10, Holds: true}},
+			runs: 1, holds: 1,
+		},
+		{
+			name: "Discrepancies",
+			report: models.ReconciliationReport{StartedAt: started, FinishedAt: started.Add(time.Second), Checked: 3,
+				Discrepancies: []models.WalletDiscrepancy{{WalletID: "wallet-1", Balance: 11, LedgerBalance: 10}},
+				Invariant:     &models.LedgerInvariant{TotalBalance: 11, Credits: 10}},
+			runs: 1, mismatches: 1,
+		},
+		{
+			name: "Failure", err: errors.New("connection refused"),
+			failures: 1, mismatches: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runsBefore, failuresBefore := runs.Value(), failures.Value()
+			svc.EXPECT().Reconcile(Actor, nil).Return(tt.report, tt.err)
+
+			job.RunOnce()
+			assert.Equal(t, tt.runs, runs.Value()-runsBefore)
+			assert.Equal(t, tt.failures, failures.Value()-failuresBefore)
+			assert.Equal(t, tt.mismatches, discrepancies.Value())
+			assert.Equal(t, tt.holds, invariantHolds.Value())
+		})
+	}
+	assert.Equal(t, int64(1000), durationMs.Value())
+	assert.Equal(t, started.Add(time.Second).Unix(), lastRun.Value())
+}
@@ -0,0 +1,44 @@
+package reconciliation
+
+import (
+	"expvar"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+)
+
+// Результаты плановых сверок публикуются через expvar в переменной wallet_reconciliation и доступны по /debug/vars.
+// Показатели последней сверки заменяются при каждом запуске, runs и failures накапливаются с момента запуска
+var (
+	runs           = new(expvar.Int)
+	failures       = new(expvar.Int)
+	checked        = new(expvar.Int)
+	discrepancies  = new(expvar.Int)
+	invariantHolds = new(expvar.Int)
+	lastRun        = new(expvar.Int)
+	durationMs     = new(expvar.Int)
+)
+
+func init() {
+	metrics := expvar.NewMap("wallet_reconciliation")
+	metrics.Set("runs", runs)
+	metrics.Set("failures", failures)
+	metrics.Set("checked", checked)
+	metrics.Set("discrepancies", discrepancies)
+	metrics.Set("invariant_holds", invariantHolds)
+	metrics.Set("last_run_unix", lastRun)
+	metrics.Set("duration_ms", durationMs)
+}
+
+// observe обновляет показатели по результату сверки всех кошельков
+func observe(report models.ReconciliationReport) {
+	runs.Add(1)
+	checked.Set(int64(report.Checked))
+	discrepancies.Set(int64(len(report.Discrepancies)))
+	holds := int64(0)
+	if report.Invariant != nil && report.Invariant.Holds {
+		holds = 1
+	}
+	invariantHolds.Set(holds)
+	lastRun.Set(report.FinishedAt.Unix())
+	durationMs.Set(report.FinishedAt.Sub(report.StartedAt).Milliseconds())
+}
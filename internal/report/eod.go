@@ -0,0 +1,70 @@
+package report
+
+import (
+	"context"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// dayLength — длительность суток UTC
+const dayLength = 24 * time.Hour
+
+// EndOfDay закрывает сутки UTC: сохраняет по каждому кошельку баланс на начало и конец суток, суммы
+// и количество пополнений и списаний. Отчеты строятся по журналу операций, поэтому сутки закрываются
+// с задержкой SettleDelay после их окончания
+type EndOfDay struct {
+	repo   repository.ReportRepository
+	logger *logrus.Logger
+}
+
+func NewEndOfDay(repo repository.ReportRepository, logger *logrus.Logger) *EndOfDay {
+	return &EndOfDay{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Run закрывает сутки до отмены контекста. Сутки, пропущенные, пока приложение не работало,
+// закрываются при запуске
+func (e *EndOfDay) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.RunOnce(time.Now()); err != nil {
+			e.logger.Errorf("Failed to close day: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce закрывает по порядку все сутки после последних закрытых, окончившиеся к моменту now с учетом
+// SettleDelay: баланс на начало суток берется из отчета за предыдущие. При первом запуске закрываются только
+// последние окончившиеся сутки
+func (e *EndOfDay) RunOnce(now time.Time) error {
+	due := now.Add(-SettleDelay).UTC().Truncate(dayLength).Add(-dayLength)
+	last, err := e.repo.LastDailyReport()
+	if err != nil {
+		return err
+	}
+
+	next := due
+	if !last.IsZero() {
+		next = last.UTC().Truncate(dayLength).Add(dayLength)
+	}
+	for ; !next.After(due); next = next.Add(dayLength) {
+		closed, err := e.repo.CloseDay(next)
+		if err != nil {
+			return err
+		}
+		e.logger.Infof("Closed day %s for %d wallets", next.Format(time.DateOnly), closed)
+	}
+	return nil
+}
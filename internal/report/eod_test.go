@@ -0,0 +1,54 @@
+package report
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEndOfDay_RunOnce проверяет выбор суток, которые закрываются дневными отчетами
+func TestEndOfDay_RunOnce(t *testing.T) {
+	date := func(d int) time.Time { return time.Date(2026, 9, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name    string      // Название теста
+		now     time.Time   // Текущее время
+		last    time.Time   // Последние закрытые сутки
+		want    []time.Time // Ожидаемые закрываемые сутки
+		wantErr bool        // Ожидается ли ошибка
+	}{
+		{"First Run", date(30).Add(10 * time.Hour), time.Time{}, []time.Time{date(29)}, false},
+		{"Up To Date", date(30).Add(10 * time.Hour), date(29), nil, false},
+		{"Within Settle Delay", date(30).Add(2 * time.Minute), date(28), nil, false},
+		{"Catch Up", date(30).Add(SettleDelay), date(26), []time.Time{date(27), date(28), date(29)}, false},
+		{"Close Failure", date(30).Add(time.Hour), date(27), []time.Time{date(28)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock.NewMockReportRepository(ctrl)
+			repo.EXPECT().LastDailyReport().Return(tt.last, nil)
+			for i, d := range tt.want {
+				var err error
+				if tt.wantErr && i == len(tt.want)-1 {
+					err = errors.New("connection refused")
+				}
+				repo.EXPECT().CloseDay(d).Return(3, err)
+			}
+
+			logger := logrus.New()
+			logger.SetOutput(io.Discard)
+			err := NewEndOfDay(repo, logger).RunOnce(tt.now)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
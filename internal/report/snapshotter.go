@@ -0,0 +1,79 @@
+package report
+
+import (
+	"context"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// SettleDelay — задержка снимка после границы периода. Операция записывается со временем начала транзакции,
+// поэтому снимок на границу делается, когда транзакции, начатые до нее, уже зафиксированы
+const SettleDelay = 5 * time.Minute
+
+// pollInterval — период проверки, наступила ли очередная граница периода
+const pollInterval = time.Minute
+
+// Snapshotter сохраняет снимки балансов всех кошельков на границы периодов длиной interval, выровненные по UTC.
+// Баланс на момент времени вычисляется по ближайшему предыдущему снимку, поэтому interval ограничивает
+// количество операций, которые нужно просуммировать
+type Snapshotter struct {
+	repo     repository.ReportRepository
+	logger   *logrus.Logger
+	interval time.Duration
+}
+
+func NewSnapshotter(repo repository.ReportRepository, logger *logrus.Logger, interval time.Duration) *Snapshotter {
+	return &Snapshotter{
+		repo:     repo,
+		logger:   logger,
+		interval: interval,
+	}
+}
+
+// Run сохраняет снимки до отмены контекста. Границы, пропущенные, пока приложение не работало,
+// досоздаются при запуске
+func (s *Snapshotter) Run(ctx context.Context) {
+	poll := pollInterval
+	if s.interval < poll {
+		poll = s.interval
+	}
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RunOnce(time.Now()); err != nil {
+			s.logger.Errorf("Failed to take balance snapshots: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce сохраняет снимки на все границы после последнего снимка, наступившие к моменту now с учетом SettleDelay.
+// Первый снимок делается только на последнюю наступившую границу
+func (s *Snapshotter) RunOnce(now time.Time) error {
+	due := now.Add(-SettleDelay).Truncate(s.interval).UTC()
+	last, err := s.repo.LastSnapshot()
+	if err != nil {
+		return err
+	}
+
+	next := due
+	if !last.IsZero() {
+		next = last.Truncate(s.interval).Add(s.interval).UTC()
+	}
+	for ; !next.After(due); next = next.Add(s.interval) {
+		taken, err := s.repo.TakeSnapshots(next)
+		if err != nil {
+			return err
+		}
+		s.logger.Infof("Took %d balance snapshots at %s", taken, next.Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package report
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSnapshotter_RunOnce проверяет выбор границ периодов, на которые сохраняются снимки
+func TestSnapshotter_RunOnce(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 9, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name    string      // Название теста
+		now     time.Time   // Текущее время
+		last    time.Time   // Время последнего снимка
+		want    []time.Time // Ожидаемые границы снимков
+		wantErr bool        // Ожидается ли ошибка
+	}{
+		{"First Snapshot", day(30).Add(10 * time.Hour), time.Time{}, []time.Time{day(30)}, false},
+		{"Up To Date", day(30).Add(10 * time.Hour), day(30), nil, false},
+		{"Within Settle Delay", day(30).Add(2 * time.Minute), day(29), nil, false},
+		{"Catch Up", day(30).Add(SettleDelay), day(27), []time.Time{day(28), day(29), day(30)}, false},
+		{"Snapshot Failure", day(30).Add(time.Hour), day(28), []time.Time{day(29)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock.NewMockReportRepository(ctrl)
+			repo.EXPECT().LastSnapshot().Return(tt.last, nil)
+			for i, at := range tt.want {
+				var err error
+				if tt.wantErr && i == len(tt.want)-1 {
+					err = errors.New("connection refused")
+				}
+				repo.EXPECT().TakeSnapshots(at).Return(3, err)
+			}
+
+			logger := logrus.New()
+			logger.SetOutput(io.Discard)
+			err := NewSnapshotter(repo, logger, 24*time.Hour).RunOnce(tt.now)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
@@ -0,0 +1,465 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// AdminRepository — операции операторов над кошельками. Изменения записываются в журнал аудита
+// в той же транзакции, что и сами изменения. Транзакции, прерванные сбоем сериализации или взаимоблокировкой,
+// повторяются так же, как транзакции операций с кошельками
+type AdminRepository interface {
+	CreateWallet(actor, walletID string, balance float64) (models.WalletInfo, error)
+	GetWalletInfo(walletID string) (models.WalletInfo, error)
+	Adjust(actor, walletID string, amount float64, reason string) (models.Operation, error)
+	SetFrozen(actor, walletID string, frozen bool, reason string) (models.WalletInfo, error)
+	Reconcile(walletIDs []string) (models.ReconciliationReport, error)
+	Statement(walletID string, from, to time.Time) (models.Statement, error)
+	RecordAudit(entry models.AuditEntry) (models.AuditEntry, error)
+	RecordReconciliation(entry models.AuditEntry, discrepancies []models.WalletDiscrepancy) (models.AuditEntry, error)
+	GetAuditLog(walletID string, afterID int64, limit int) ([]models.AuditEntry, error)
+	SealAudit(limit int) (int, error)
+	WalkAuditChain(fn func(entry models.AuditEntry) bool) (int64, string, error)
+	GetAuditHead() (int64, string, error)
+	ListCheckpoints(afterID int64, limit int) ([]models.AuditCheckpoint, error)
+	InsertCheckpoint(checkpoint models.AuditCheckpoint) (models.AuditCheckpoint, bool, error)
+}
+
+type ApiAdminRepository struct {
+	db        *pgxpool.Pool
+	wallets   *ApiWalletRepository // Изменение балансов с журналом операций, уведомлениями и outbox
+	logger    *logrus.Logger
+	requestID string // ID запроса, с которым действия операторов записываются в журнал аудита
+}
+
+func NewApiAdminRepository(wallets *ApiWalletRepository, logger *logrus.Logger) *ApiAdminRepository {
+	return &ApiAdminRepository{
+		db:      wallets.db,
+		wallets: wallets,
+		logger:  logger,
+	}
+}
+
+// WithRequestID записывает действия операторов в журнал аудита с ID запроса requestID и возвращает репозиторий
+func (r *ApiAdminRepository) WithRequestID(requestID string) *ApiAdminRepository {
+	r.requestID = requestID
+	return r
+}
+
+// auditEntry возвращает запись журнала аудита о действии оператора
+func (r *ApiAdminRepository) auditEntry(actor, action, walletID string, details map[string]interface{}) *models.AuditEntry {
+	return &models.AuditEntry{Actor: actor, Action: action, WalletID: walletID, RequestID: r.requestID, Details: details}
+}
+
+// Создание кошелька. Пустой walletID означает, что ID сгенерирует база. Ненулевой начальный баланс
+// записывается в журнал операцией OPENING
+func (r *ApiAdminRepository) CreateWallet(actor, walletID string, balance float64) (models.WalletInfo, error) {
+	var info models.WalletInfo
+	err := runTx(r.db, r.logger, "create wallet", pgx.ReadCommitted, func(tx pgx.Tx) error {
+		var createdAt *time.Time
+		err := tx.QueryRow(context.Background(), `INSERT INTO wallets (wallet_id, balance)
+			VALUES (COALESCE(NULLIF($1, '')::uuid, gen_random_uuid()), $2)
+			RETURNING wallet_id, balance, version, frozen, created_at`, walletID, balance).
+			Scan(&info.ID, scanMoney(&info.Balance), &info.Version, &info.Frozen, &createdAt)
+		if isPgError(err, "23505") {
+			return ErrWalletExists
+		}
+		if err != nil {
+			return err
+		}
+		if createdAt != nil {
+			info.CreatedAt = *createdAt
+		}
+
+		entry := r.auditEntry(actor, models.AuditWalletCreated, info.ID, map[string]interface{}{"balance": balance})
+		entry.BalanceAfter = &info.Balance
+		if balance > 0 {
+			op := models.Operation{WalletID: info.ID, Type: models.OperationOpening, Amount: balance, BalanceAfter: balance, Version: info.Version}
+			if err := insertOperation(tx, &op, ""); err != nil {
+				return err
+			}
+			if err := notifyOperation(tx, op); err != nil {
+				return err
+			}
+			if err := insertOutboxEvent(tx, operationEventType(op.Type), op.WalletID, op); err != nil {
+				return err
+			}
+			entry.OperationID = op.ID
+		}
+		return appendAudit(tx, entry)
+	})
+	if err != nil {
+		r.logger.Errorf("Error creating wallet %s: %v", walletID, err)
+		return models.WalletInfo{}, err
+	}
+	r.logger.Infof("Operator %s created wallet %s with balance %f", actor, info.ID, balance)
+	return info, nil
+}
+
+// Получение кошелька с признаком заморозки. Баланс и версия учитывают буферизованные пополнения
+func (r *ApiAdminRepository) GetWalletInfo(walletID string) (models.WalletInfo, error) {
+	info, err := scanWalletInfo(r.db.QueryRow(context.Background(), walletInfoQuery, walletID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Warnf("Wallet with ID %s not found", walletID)
+			return models.WalletInfo{}, ErrWalletNotFound
+		}
+		r.logger.Errorf("Error retrieving wallet %s: %v", walletID, err)
+		return models.WalletInfo{}, err
+	}
+	return info, nil
+}
+
+const walletInfoQuery = `SELECT w.wallet_id, w.balance + COALESCE(p.amount, 0), w.version + p.count, w.frozen, w.created_at
+	FROM wallets w
+	CROSS JOIN LATERAL (SELECT SUM(amount) AS amount, COUNT(*) AS count
+		FROM wallet_pending_deposits WHERE wallet_id = w.wallet_id) p
+	WHERE w.wallet_id = $1`
+
+func scanWalletInfo(row pgx.Row) (models.WalletInfo, error) {
+	var (
+		info      models.WalletInfo
+		createdAt *time.Time
+	)
+	if err := row.Scan(&info.ID, scanMoney(&info.Balance), &info.Version, &info.Frozen, &createdAt); err != nil {
+		return models.WalletInfo{}, err
+	}
+	if createdAt != nil {
+		info.CreatedAt = *createdAt
+	}
+	return info, nil
+}
+
+// Ручная корректировка баланса. Положительная сумма зачисляется, отрицательная списывается.
+// Корректировка разрешена и для замороженного кошелька
+func (r *ApiAdminRepository) Adjust(actor, walletID string, amount float64, reason string) (models.Operation, error) {
+	opType := models.OperationAdjustmentCredit
+	if amount < 0 {
+		opType = models.OperationAdjustmentDebit
+	}
+	op, err := r.wallets.changeBalance(walletID, opType, fromCents(abs(toCents(amount))), anyVersion, "", func(entry *models.AuditEntry) {
+		entry.Actor, entry.Action, entry.RequestID = actor, models.AuditWalletAdjusted, r.requestID
+		entry.Details["reason"] = reason
+	})
+	if err != nil {
+		r.logger.Errorf("Error adjusting wallet %s by %f: %v", walletID, amount, err)
+		return models.Operation{}, err
+	}
+	r.logger.Infof("Operator %s adjusted wallet %s by %f: %s", actor, walletID, amount, reason)
+	return op, nil
+}
+
+// Заморозка или разморозка кошелька. Операции с замороженным кошельком отклоняются с ErrWalletFrozen
+func (r *ApiAdminRepository) SetFrozen(actor, walletID string, frozen bool, reason string) (models.WalletInfo, error) {
+	action := models.AuditWalletUnfrozen
+	if frozen {
+		action = models.AuditWalletFrozen
+	}
+
+	var info models.WalletInfo
+	err := runTx(r.db, r.logger, "set frozen", pgx.ReadCommitted, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(context.Background(), `UPDATE wallets SET frozen = $2 WHERE wallet_id = $1`, walletID, frozen)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrWalletNotFound
+		}
+		if info, err = scanWalletInfo(tx.QueryRow(context.Background(), walletInfoQuery, walletID)); err != nil {
+			return err
+		}
+		return appendAudit(tx, r.auditEntry(actor, action, walletID, map[string]interface{}{"reason": reason}))
+	})
+	if err != nil {
+		r.logger.Errorf("Error setting frozen=%t for wallet %s: %v", frozen, walletID, err)
+		return models.WalletInfo{}, err
+	}
+	r.logger.Infof("Operator %s set frozen=%t for wallet %s: %s", actor, frozen, walletID, reason)
+	return info, nil
+}
+
+// Сверка балансов кошельков с журналом операций. Баланс должен совпадать с суммой операций с учетом их знака
+// и с балансом после последней операции. Пустой walletIDs означает все кошельки, и тогда в том же снимке
+// проверяется глобальный инвариант. Буферизованные пополнения не входят ни в баланс, ни в журнал
+// и в сверке не участвуют
+func (r *ApiAdminRepository) Reconcile(walletIDs []string) (models.ReconciliationReport, error) {
+	if walletIDs == nil {
+		walletIDs = []string{}
+	}
+	report := models.ReconciliationReport{StartedAt: time.Now(), Discrepancies: []models.WalletDiscrepancy{}}
+	err := execTx(r.db, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		rows, err := tx.Query(context.Background(), reconcileQuery, walletIDs, debitOperationTypes)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var d models.WalletDiscrepancy
+			if err := rows.Scan(&d.WalletID, scanMoney(&d.Balance), scanMoney(&d.LedgerBalance), scanMoney(&d.LastBalanceAfter), &d.Operations); err != nil {
+				return err
+			}
+			report.Checked++
+			balance := toCents(d.Balance)
+			if toCents(d.LedgerBalance) != balance || d.Operations > 0 && toCents(d.LastBalanceAfter) != balance {
+				report.Discrepancies = append(report.Discrepancies, d)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(walletIDs) > 0 {
+			return nil
+		}
+
+		var inv models.LedgerInvariant
+		err = tx.QueryRow(context.Background(), invariantQuery, creditOperationTypes, withdrawalOperationTypes,
+			models.OperationTransferIn, models.OperationTransferOut).Scan(scanMoney(&inv.TotalBalance), scanMoney(&inv.Credits), scanMoney(&inv.Debits), scanMoney(&inv.TransfersIn), scanMoney(&inv.TransfersOut))
+		if err != nil {
+			return err
+		}
+		inv.Holds = toCents(inv.TotalBalance) == toCents(inv.Credits)-toCents(inv.Debits) && toCents(inv.TransfersIn) == toCents(inv.TransfersOut)
+		report.Invariant = &inv
+		return nil
+	})
+	if err != nil {
+		r.logger.Errorf("Error reconciling wallets: %v", err)
+		return models.ReconciliationReport{}, err
+	}
+	report.FinishedAt = time.Now()
+	r.logger.Infof("Reconciled %d wallets, found %d discrepancies", report.Checked, len(report.Discrepancies))
+	return report, nil
+}
+
+const reconcileQuery = `SELECT w.wallet_id, w.balance, COALESCE(j.ledger, 0), COALESCE(j.last_after, 0), j.count
+	FROM wallets w
+	CROSS JOIN LATERAL (
+		SELECT SUM(CASE WHEN operation_type = ANY ($2::text[]) THEN -amount ELSE amount END) AS ledger,
+			(ARRAY_AGG(balance_after ORDER BY operation_id DESC))[1] AS last_after,
+			COUNT(*) AS count
+		FROM wallet_operations WHERE wallet_id = w.wallet_id) j
+	WHERE cardinality($1::uuid[]) = 0 OR w.wallet_id = ANY ($1::uuid[])
+	ORDER BY w.wallet_id`
+
+const invariantQuery = `SELECT (SELECT COALESCE(SUM(balance), 0) FROM wallets),
+		COALESCE(SUM(amount) FILTER (WHERE operation_type = ANY ($1::text[])), 0),
+		COALESCE(SUM(amount) FILTER (WHERE operation_type = ANY ($2::text[])), 0),
+		COALESCE(SUM(amount) FILTER (WHERE operation_type = $3), 0),
+		COALESCE(SUM(amount) FILTER (WHERE operation_type = $4), 0)
+	FROM wallet_operations`
+
+// Типы операций, которыми деньги поступают в систему кошельков извне и выводятся из нее
+var (
+	creditOperationTypes     = []string{models.OperationOpening, models.OperationDeposit, models.OperationAdjustmentCredit}
+	withdrawalOperationTypes = []string{models.OperationWithdraw, models.OperationAdjustmentDebit}
+)
+
+// Запись результата сверки в журнал аудита. Если discrepancies не пуст, в той же транзакции в outbox
+// сохраняется событие BalanceDiscrepancyDetected для каждого расхождения
+func (r *ApiAdminRepository) RecordReconciliation(entry models.AuditEntry, discrepancies []models.WalletDiscrepancy) (models.AuditEntry, error) {
+	if entry.RequestID == "" {
+		entry.RequestID = r.requestID
+	}
+	err := runTx(r.db, r.logger, "record reconciliation", pgx.ReadCommitted, func(tx pgx.Tx) error {
+		for _, d := range discrepancies {
+			if err := insertOutboxEvent(tx, models.EventBalanceDiscrepancy, d.WalletID, d); err != nil {
+				return err
+			}
+		}
+		return appendAudit(tx, &entry)
+	})
+	if err != nil {
+		r.logger.Errorf("Error recording reconciliation by %s: %v", entry.Actor, err)
+		return models.AuditEntry{}, err
+	}
+	return entry, nil
+}
+
+// Выписка по кошельку за период [from, to). Баланс на начало — баланс после последней операции до from
+func (r *ApiAdminRepository) Statement(walletID string, from, to time.Time) (models.Statement, error) {
+	statement := models.Statement{WalletID: walletID, From: from, To: to, Operations: []models.Operation{}}
+	err := execTx(r.db, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		var exists bool
+		if err := tx.QueryRow(context.Background(), `SELECT EXISTS (SELECT 1 FROM wallets WHERE wallet_id = $1)`, walletID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrWalletNotFound
+		}
+
+		err := tx.QueryRow(context.Background(), `SELECT COALESCE((SELECT balance_after FROM wallet_operations
+			WHERE wallet_id = $1 AND created_at < $2
+			ORDER BY operation_id DESC
+			LIMIT 1), 0)`, walletID, from).Scan(scanMoney(&statement.OpeningBalance))
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(context.Background(), `SELECT operation_id, wallet_id, operation_type, amount, balance_after, wallet_version, created_at
+			FROM wallet_operations
+			WHERE wallet_id = $1 AND created_at >= $2 AND created_at < $3
+			ORDER BY operation_id`, walletID, from, to)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var op models.Operation
+			if err := rows.Scan(&op.ID, &op.WalletID, &op.Type, scanMoney(&op.Amount), scanMoney(&op.BalanceAfter), &op.Version, &op.CreatedAt); err != nil {
+				return err
+			}
+			statement.Operations = append(statement.Operations, op)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		if !errors.Is(err, ErrWalletNotFound) {
+			r.logger.Errorf("Error building statement for wallet %s: %v", walletID, err)
+		}
+		return models.Statement{}, err
+	}
+
+	statement.ClosingBalance = statement.OpeningBalance
+	if n := len(statement.Operations); n > 0 {
+		statement.ClosingBalance = statement.Operations[n-1].BalanceAfter
+	}
+	return statement, nil
+}
+
+// Запись действия оператора, которое не изменяет данные, в журнал аудита
+func (r *ApiAdminRepository) RecordAudit(entry models.AuditEntry) (models.AuditEntry, error) {
+	if entry.RequestID == "" {
+		entry.RequestID = r.requestID
+	}
+	err := runTx(r.db, r.logger, "record audit", pgx.ReadCommitted, func(tx pgx.Tx) error {
+		return appendAudit(tx, &entry)
+	})
+	if err != nil {
+		r.logger.Errorf("Error recording audit entry %s by %s: %v", entry.Action, entry.Actor, err)
+		return models.AuditEntry{}, err
+	}
+	return entry, nil
+}
+
+// Получение записей журнала аудита кошелька с ID больше afterID в порядке их записи
+func (r *ApiAdminRepository) GetAuditLog(walletID string, afterID int64, limit int) ([]models.AuditEntry, error) {
+	rows, err := r.db.Query(context.Background(), `SELECT `+auditColumns+`
+		FROM audit_log
+		WHERE wallet_id = $1 AND audit_id > $2
+		ORDER BY audit_id
+		LIMIT $3`, walletID, afterID, limit)
+	if err != nil {
+		r.logger.Errorf("Error retrieving audit log for wallet %s after %d: %v", walletID, afterID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		entry, err := scanAudit(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// Обход цепочки журнала аудита по порядку номеров. fn вызывается для каждой записи, пока не вернет false.
+// Записи и голова цепочки читаются из одного снимка базы. Возвращает номер и хэш головы цепочки
+func (r *ApiAdminRepository) WalkAuditChain(fn func(entry models.AuditEntry) bool) (int64, string, error) {
+	var (
+		headSeq  int64
+		headHash string
+	)
+	err := execTx(r.db, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(context.Background(), `SELECT chain_seq, hash FROM audit_chain_head`).Scan(&headSeq, &headHash); err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(context.Background(), `SELECT `+auditColumns+`
+			FROM audit_log
+			WHERE chain_seq IS NOT NULL
+			ORDER BY chain_seq`)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			entry, err := scanAudit(rows)
+			if err != nil {
+				return err
+			}
+			if !fn(entry) {
+				return nil
+			}
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		r.logger.Errorf("Error walking audit chain: %v", err)
+		return 0, "", err
+	}
+	return headSeq, headHash, nil
+}
+
+// Получение номера и хэша последней записи цепочки журнала аудита
+func (r *ApiAdminRepository) GetAuditHead() (int64, string, error) {
+	var (
+		seq  int64
+		hash string
+	)
+	if err := r.db.QueryRow(context.Background(), `SELECT chain_seq, hash FROM audit_chain_head`).Scan(&seq, &hash); err != nil {
+		r.logger.Errorf("Error retrieving audit chain head: %v", err)
+		return 0, "", err
+	}
+	return seq, hash, nil
+}
+
+// Получение контрольных точек журнала аудита с ID больше afterID. Неположительный limit снимает ограничение
+func (r *ApiAdminRepository) ListCheckpoints(afterID int64, limit int) ([]models.AuditCheckpoint, error) {
+	rows, err := r.db.Query(context.Background(), `SELECT checkpoint_id, chain_seq, hash, key_id, signature, created_at
+		FROM audit_checkpoints
+		WHERE checkpoint_id > $1
+		ORDER BY checkpoint_id
+		LIMIT $2`, afterID, limitOrAll(limit))
+	if err != nil {
+		r.logger.Errorf("Error retrieving audit checkpoints after %d: %v", afterID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	checkpoints := []models.AuditCheckpoint{}
+	for rows.Next() {
+		var cp models.AuditCheckpoint
+		if err := rows.Scan(&cp.ID, &cp.Seq, &cp.Hash, &cp.KeyID, &cp.Signature, &cp.CreatedAt); err != nil {
+			return nil, err
+		}
+		checkpoints = append(checkpoints, cp)
+	}
+	return checkpoints, rows.Err()
+}
+
+// Сохранение подписанной контрольной точки. Возвращает false, если для этой записи цепочки контрольная точка уже есть
+func (r *ApiAdminRepository) InsertCheckpoint(checkpoint models.AuditCheckpoint) (models.AuditCheckpoint, bool, error) {
+	err := r.db.QueryRow(context.Background(), `INSERT INTO audit_checkpoints (chain_seq, hash, key_id, signature, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (chain_seq) DO NOTHING
+		RETURNING checkpoint_id`, checkpoint.Seq, checkpoint.Hash, checkpoint.KeyID, checkpoint.Signature, checkpoint.CreatedAt).Scan(&checkpoint.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.AuditCheckpoint{}, false, nil
+	}
+	if err != nil {
+		r.logger.Errorf("Error saving audit checkpoint at entry %d: %v", checkpoint.Seq, err)
+		return models.AuditCheckpoint{}, false, err
+	}
+	return checkpoint, true, nil
+}
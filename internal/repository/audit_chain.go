@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/audit"
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// appendAudit добавляет записи в журнал аудита и заполняет их ID и время. Записи добавляются без номера в цепочке
+// и хэшей и не блокируют общих строк, поэтому транзакции разных кошельков фиксируются независимо.
+// В цепочку их выстраивает SealAudit после фиксации транзакции
+func appendAudit(tx pgx.Tx, entries ...*models.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var (
+		operationIDs                                          []int64
+		actors, actions, walletIDs, requestIDs, before, after []string
+		details                                               []string
+		createdAt                                             []time.Time
+	)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	for _, entry := range entries {
+		entry.CreatedAt = now
+		data, err := json.Marshal(entry.Details)
+		if err != nil {
+			return err
+		}
+		actors = append(actors, entry.Actor)
+		actions = append(actions, entry.Action)
+		walletIDs = append(walletIDs, entry.WalletID)
+		requestIDs = append(requestIDs, entry.RequestID)
+		operationIDs = append(operationIDs, entry.OperationID)
+		before = append(before, formatAuditBalance(entry.BalanceBefore))
+		after = append(after, formatAuditBalance(entry.BalanceAfter))
+		details = append(details, string(data))
+		createdAt = append(createdAt, entry.CreatedAt)
+	}
+
+	// ID выделяются заранее и по возрастанию, чтобы записи транзакции запечатывались в порядке entries
+	ids, err := nextAuditIDs(tx, len(entries))
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(context.Background(), `INSERT INTO audit_log (audit_id, actor, action, wallet_id, request_id, operation_id,
+			balance_before, balance_after, details, created_at)
+		SELECT a.audit_id, a.actor, a.action, NULLIF(a.wallet_id, '')::uuid, a.request_id, NULLIF(a.operation_id, 0),
+			NULLIF(a.balance_before, '')::numeric, NULLIF(a.balance_after, '')::numeric, a.details::jsonb, a.created_at
+		FROM unnest($1::bigint[], $2::text[], $3::text[], $4::text[], $5::text[], $6::bigint[],
+			$7::text[], $8::text[], $9::text[], $10::timestamptz[])
+			AS a (audit_id, actor, action, wallet_id, request_id, operation_id, balance_before, balance_after, details, created_at)`,
+		ids, actors, actions, walletIDs, requestIDs, operationIDs, before, after, details, createdAt)
+	if err != nil {
+		return err
+	}
+	for i, entry := range entries {
+		entry.ID = ids[i]
+	}
+	return nil
+}
+
+// nextAuditIDs выделяет n значений последовательности audit_log.audit_id в порядке возрастания
+func nextAuditIDs(tx pgx.Tx, n int) ([]int64, error) {
+	rows, err := tx.Query(context.Background(), `SELECT nextval(pg_get_serial_sequence('audit_log', 'audit_id'))
+		FROM generate_series(1, $1)`, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0, n)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// Запечатывание записей журнала аудита: до limit записей без номера в цепочке в порядке audit_id получают
+// следующие номера, хэш предыдущей записи и свой хэш. Строка головы цепочки блокируется только на время
+// запечатывания, поэтому параллельные вызовы выполняются по очереди и продолжают одну цепочку. Записи
+// транзакции, зафиксированной позже записей с большими ID, запечатываются следующим вызовом.
+// Возвращает количество запечатанных записей
+func (r *ApiAdminRepository) SealAudit(limit int) (int, error) {
+	var sealed int
+	err := runTx(r.db, r.logger, "seal audit", pgx.ReadCommitted, func(tx pgx.Tx) error {
+		sealed = 0
+		var (
+			seq  int64
+			hash string
+		)
+		if err := tx.QueryRow(context.Background(), `SELECT chain_seq, hash FROM audit_chain_head FOR UPDATE`).Scan(&seq, &hash); err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(context.Background(), `SELECT `+auditColumns+`
+			FROM audit_log
+			WHERE chain_seq IS NULL
+			ORDER BY audit_id
+			LIMIT $1`, limit)
+		if err != nil {
+			return err
+		}
+		var entries []models.AuditEntry
+		for rows.Next() {
+			entry, err := scanAudit(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			entries = append(entries, entry)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+
+		ids, seqs := make([]int64, len(entries)), make([]int64, len(entries))
+		prevHashes, hashes := make([]string, len(entries)), make([]string, len(entries))
+		for i := range entries {
+			seq++
+			if err := audit.Seal(&entries[i], seq, hash); err != nil {
+				return err
+			}
+			hash = entries[i].Hash
+			ids[i], seqs[i], prevHashes[i], hashes[i] = entries[i].ID, seq, entries[i].PrevHash, hash
+		}
+
+		_, err = tx.Exec(context.Background(), `UPDATE audit_log l
+			SET chain_seq = a.chain_seq, prev_hash = a.prev_hash, hash = a.hash
+			FROM unnest($1::bigint[], $2::bigint[], $3::text[], $4::text[]) AS a (audit_id, chain_seq, prev_hash, hash)
+			WHERE l.audit_id = a.audit_id`, ids, seqs, prevHashes, hashes)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(context.Background(), `UPDATE audit_chain_head SET chain_seq = $1, hash = $2`, seq, hash); err != nil {
+			return err
+		}
+		sealed = len(entries)
+		return nil
+	})
+	if err != nil {
+		r.logger.Errorf("Error sealing audit log: %v", err)
+		return 0, err
+	}
+	return sealed, nil
+}
+
+// operationAudit описывает операцию журнала для журнала аудита: баланс до и после операции
+func operationAudit(origin models.Origin, op models.Operation) *models.AuditEntry {
+	delta := op.Amount
+	if isDebit(op.Type) {
+		delta = -delta
+	}
+	before := fromCents(toCents(op.BalanceAfter) - toCents(delta))
+	after := op.BalanceAfter
+	return &models.AuditEntry{
+		Actor:         origin.Actor,
+		Action:        models.AuditOperationApplied,
+		WalletID:      op.WalletID,
+		RequestID:     origin.RequestID,
+		OperationID:   op.ID,
+		BalanceBefore: &before,
+		BalanceAfter:  &after,
+		Details:       map[string]interface{}{"operationType": op.Type, "amount": op.Amount, "version": op.Version},
+	}
+}
+
+// formatAuditBalance возвращает баланс для приведения к NUMERIC; пустая строка означает NULL
+func formatAuditBalance(balance *float64) string {
+	if balance == nil {
+		return ""
+	}
+	return formatCents(toCents(*balance))
+}
+
+const auditColumns = `audit_id, chain_seq, actor, action, COALESCE(wallet_id::text, ''), request_id, COALESCE(operation_id, 0),
+	balance_before, balance_after, details, created_at, COALESCE(prev_hash, ''), COALESCE(hash, '')`
+
+// scanAudit читает запись журнала аудита, выбранную с колонками auditColumns
+func scanAudit(row pgx.Row) (models.AuditEntry, error) {
+	var (
+		entry models.AuditEntry
+		seq   *int64
+	)
+	err := row.Scan(&entry.ID, &seq, &entry.Actor, &entry.Action, &entry.WalletID, &entry.RequestID, &entry.OperationID,
+		scanNullMoney(&entry.BalanceBefore), scanNullMoney(&entry.BalanceAfter), &entry.Details, &entry.CreatedAt, &entry.PrevHash, &entry.Hash)
+	if seq != nil {
+		entry.Seq = *seq
+	}
+	return entry, err
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOperationAudit проверяет баланс до и после операции в записи журнала аудита
+func TestOperationAudit(t *testing.T) {
+	origin := models.Origin{Actor: "api:10.0.0.1", RequestID: "req-1"}
+
+	tests := []struct {
+		name   string           // Название теста
+		op     models.Operation // Операция журнала
+		before float64          // Ожидаемый баланс до операции
+	}{
+		{"Deposit", models.Operation{ID: 1, WalletID: walletA, Type: models.OperationDeposit, Amount: 10.1, BalanceAfter: 30.2}, 20.1},
+		{"Withdraw", models.Operation{ID: 2, WalletID: walletA, Type: models.OperationWithdraw, Amount: 0.3, BalanceAfter: 0}, 0.3},
+		{"Adjustment Debit", models.Operation{ID: 3, WalletID: walletA, Type: models.OperationAdjustmentDebit, Amount: 5, BalanceAfter: 95}, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := operationAudit(origin, tt.op)
+			assert.Equal(t, models.AuditOperationApplied, entry.Action)
+			assert.Equal(t, origin.Actor, entry.Actor)
+			assert.Equal(t, origin.RequestID, entry.RequestID)
+			assert.Equal(t, tt.op.ID, entry.OperationID)
+			require.NotNil(t, entry.BalanceBefore)
+			require.NotNil(t, entry.BalanceAfter)
+			assert.Equal(t, tt.before, *entry.BalanceBefore)
+			assert.Equal(t, tt.op.BalanceAfter, *entry.BalanceAfter)
+			assert.Equal(t, tt.op.Type, entry.Details["operationType"])
+		})
+	}
+}
+
+// TestWithOrigin проверяет, что копия репозитория не меняет источник изменений исходного репозитория
+func TestWithOrigin(t *testing.T) {
+	repo := NewApiWalletRepository(nil, nil).WithHotWallets([]string{walletA})
+	origin := models.Origin{Actor: "grpc:10.0.0.1:5000", RequestID: "req-1"}
+
+	scoped := ScopeOrigin(repo, origin).(*ApiWalletRepository)
+	assert.Equal(t, origin, scoped.origin)
+	assert.Equal(t, models.SystemOrigin, repo.origin)
+	assert.True(t, scoped.isHot(walletA))
+}
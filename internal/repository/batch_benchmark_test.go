@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// Количество пополнений в одном пакете бенчмарка
+const benchBatchSize = 1000
+
+// benchRepository подключается к базе из WALLET_BENCH_DSN с примененными миграциями и создает кошельки для бенчмарка.
+// Без WALLET_BENCH_DSN бенчмарк пропускается
+func benchRepository(b *testing.B) (*ApiWalletRepository, []string) {
+	db := benchPool(b)
+
+	rows, err := db.Query(context.Background(), `INSERT INTO wallets (wallet_id) SELECT gen_random_uuid() FROM generate_series(1, 100) RETURNING wallet_id`)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var walletIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			b.Fatal(err)
+		}
+		walletIDs = append(walletIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		b.Fatal(err)
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewApiWalletRepository(db, logger), walletIDs
+}
+
+// benchPool подключается к базе из WALLET_BENCH_DSN; без нее бенчмарк пропускается
+func benchPool(b *testing.B) *pgxpool.Pool {
+	dsn := os.Getenv("WALLET_BENCH_DSN")
+	if dsn == "" {
+		b.Skip("WALLET_BENCH_DSN is not set")
+	}
+	db, err := pgxpool.New(context.Background(), dsn)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(db.Close)
+	return db
+}
+
+// BenchmarkDeposits_Individual выполняет пополнения по одному, как при последовательных вызовах PATCH /api/v1/wallets/
+func BenchmarkDeposits_Individual(b *testing.B) {
+	repo, walletIDs := benchRepository(b)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for i := 0; i < benchBatchSize; i++ {
+			if err := repo.Deposit(walletIDs[i%len(walletIDs)], 1); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkDeposits_Batch выполняет те же пополнения одним атомарным пакетом
+func BenchmarkDeposits_Batch(b *testing.B) {
+	repo, walletIDs := benchRepository(b)
+	items := make([]models.BatchItem, benchBatchSize)
+	for i := range items {
+		items[i] = models.BatchItem{OperationType: models.OperationDeposit, WalletID: walletIDs[i%len(walletIDs)], Amount: 1}
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := repo.ApplyBatch(items, true); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
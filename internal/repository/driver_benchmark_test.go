@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	_ "github.com/lib/pq"
+)
+
+// Бенчмарки сравнивают горячие пути на прежнем драйвере (database/sql и lib/pq) и на пуле pgx:
+// одинаковые запросы выполняются через оба драйвера к одной базе из WALLET_BENCH_DSN.
+// Результаты сравниваются benchstat по ключу подтеста driver
+
+// benchLibPQ открывает подключение к той же базе через database/sql и lib/pq
+func benchLibPQ(b *testing.B) *sql.DB {
+	db, err := sql.Open("postgres", os.Getenv("WALLET_BENCH_DSN"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Close() })
+	return db
+}
+
+// BenchmarkDriver_GetWallet — чтение баланса и версии кошелька
+func BenchmarkDriver_GetWallet(b *testing.B) {
+	repo, walletIDs := benchRepository(b)
+	libpq := benchLibPQ(b)
+
+	b.Run("driver=lib-pq", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			var wallet models.Wallet
+			if err := libpq.QueryRow(walletStateQuery, walletIDs[n%len(walletIDs)]).Scan(&wallet.Balance, &wallet.Version); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("driver=pgx", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			if _, err := repo.GetWallet(walletIDs[n%len(walletIDs)]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkDriver_GetOperationsAfter — чтение страницы журнала, как при возобновлении потока
+func BenchmarkDriver_GetOperationsAfter(b *testing.B) {
+	repo, walletIDs := benchRepository(b)
+	libpq := benchLibPQ(b)
+	walletID := walletIDs[0]
+	for i := 0; i < resumeBenchPage; i++ {
+		if err := repo.Deposit(walletID, 1); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.Run("driver=lib-pq", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			rows, err := libpq.Query(operationsAfterQuery, walletID, 0, resumeBenchPage)
+			if err != nil {
+				b.Fatal(err)
+			}
+			for rows.Next() {
+				var op models.Operation
+				if err := rows.Scan(&op.ID, &op.WalletID, &op.Type, &op.Amount, &op.BalanceAfter, &op.Version, &op.CreatedAt); err != nil {
+					b.Fatal(err)
+				}
+			}
+			rows.Close()
+		}
+	})
+	b.Run("driver=pgx", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			if _, err := repo.GetOperationsAfter(walletID, 0, resumeBenchPage); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// Размер страницы журнала в BenchmarkDriver_GetOperationsAfter
+const resumeBenchPage = 500
+
+// BenchmarkDriver_Deposit — пополнение: изменение баланса, запись в журнал, уведомление и событие outbox в одной транзакции
+func BenchmarkDriver_Deposit(b *testing.B) {
+	repo, walletIDs := benchRepository(b)
+	libpq := benchLibPQ(b)
+
+	b.Run("driver=lib-pq", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			if err := libPQDeposit(libpq, walletIDs[n%len(walletIDs)], 1); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("driver=pgx", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			if err := repo.Deposit(walletIDs[n%len(walletIDs)], 1); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// libPQDeposit выполняет те же запросы, что и Deposit, через database/sql
+func libPQDeposit(db *sql.DB, walletID string, amount float64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	op := models.Operation{WalletID: walletID, Type: models.OperationDeposit, Amount: amount}
+	if err := tx.QueryRow(changeBalanceQuery, amount, walletID, anyVersion).Scan(&op.BalanceAfter, &op.Version); err != nil {
+		return err
+	}
+	if err := tx.QueryRow(insertOperationQuery, op.WalletID, op.Type, op.Amount, op.BalanceAfter, op.Version, "").Scan(&op.ID, &op.CreatedAt); err != nil {
+		return err
+	}
+	payload, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`SELECT pg_notify($1, $2)`, OperationsChannel, string(payload)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO outbox_events (event_type, wallet_id, payload) VALUES ($1, $2, $3)`,
+		models.EventWalletDeposited, walletID, payload); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
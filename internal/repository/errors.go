@@ -0,0 +1,16 @@
+package repository
+
+import "errors"
+
+// Ошибки репозитория, по которым верхние слои определяют ответ клиенту
+var (
+	ErrWalletNotFound    = errors.New("wallet not found")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrVersionMismatch   = errors.New("wallet version mismatch")
+	ErrEndpointNotFound  = errors.New("webhook endpoint not found")
+	ErrDeliveryNotFound  = errors.New("webhook delivery not found")
+	ErrBatchRejected     = errors.New("batch rejected")
+	ErrJobNotFound       = errors.New("job not found")
+	ErrWalletFrozen      = errors.New("wallet is frozen")
+	ErrWalletExists      = errors.New("wallet already exists")
+)
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+// benchmarkHotWalletDeposits выполняет параллельные пополнения одного кошелька и сообщает пропускную способность.
+// Количество параллельных клиентов задается флагом -cpu
+func benchmarkHotWalletDeposits(b *testing.B, repo *ApiWalletRepository, walletID string) {
+	start := time.Now()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := repo.Deposit(walletID, 1); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+	b.ReportMetric(float64(b.N)/time.Since(start).Seconds(), "deposits/s")
+}
+
+// BenchmarkHotWalletDeposits_Locked — параллельные пополнения одного кошелька, каждое ждет блокировку его строки
+func BenchmarkHotWalletDeposits_Locked(b *testing.B) {
+	repo, walletIDs := benchRepository(b)
+	benchmarkHotWalletDeposits(b, repo, walletIDs[0])
+}
+
+// BenchmarkHotWalletDeposits_Buffered — те же пополнения в режиме горячего кошелька с переносом буфера после замера
+func BenchmarkHotWalletDeposits_Buffered(b *testing.B) {
+	repo, walletIDs := benchRepository(b)
+	repo.WithHotWallets(walletIDs[:1])
+	benchmarkHotWalletDeposits(b, repo, walletIDs[0])
+
+	b.StopTimer()
+	for {
+		folded, err := repo.FoldPendingDeposits(10000)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if folded == 0 {
+			break
+		}
+	}
+}
@@ -0,0 +1,7 @@
+package repository
+
+import "expvar"
+
+// Повторы транзакций публикуются через expvar в переменной db_tx_retries и доступны по /debug/vars:
+// количество повторов по SQLSTATE и количество транзакций, для которых попытки исчерпаны
+var txRetries = expvar.NewMap("db_tx_retries")
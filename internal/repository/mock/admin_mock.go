@@ -0,0 +1,249 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/admin_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	reflect "reflect"
+	time "time"
+
+	models "github.com/VadimBorzenkov/WalletAPI/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAdminRepository is a mock of AdminRepository interface.
+type MockAdminRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminRepositoryMockRecorder
+}
+
+// MockAdminRepositoryMockRecorder is the mock recorder for MockAdminRepository.
+type MockAdminRepositoryMockRecorder struct {
+	mock *MockAdminRepository
+}
+
+// NewMockAdminRepository creates a new mock instance.
+func NewMockAdminRepository(ctrl *gomock.Controller) *MockAdminRepository {
+	mock := &MockAdminRepository{ctrl: ctrl}
+	mock.recorder = &MockAdminRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminRepository) EXPECT() *MockAdminRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Adjust mocks base method.
+func (m *MockAdminRepository) Adjust(actor, walletID string, amount float64, reason string) (models.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Adjust", actor, walletID, amount, reason)
+	ret0, _ := ret[0].(models.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Adjust indicates an expected call of Adjust.
+func (mr *MockAdminRepositoryMockRecorder) Adjust(actor, walletID, amount, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Adjust", reflect.TypeOf((*MockAdminRepository)(nil).Adjust), actor, walletID, amount, reason)
+}
+
+// CreateWallet mocks base method.
+func (m *MockAdminRepository) CreateWallet(actor, walletID string, balance float64) (models.WalletInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWallet", actor, walletID, balance)
+	ret0, _ := ret[0].(models.WalletInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWallet indicates an expected call of CreateWallet.
+func (mr *MockAdminRepositoryMockRecorder) CreateWallet(actor, walletID, balance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockAdminRepository)(nil).CreateWallet), actor, walletID, balance)
+}
+
+// GetAuditHead mocks base method.
+func (m *MockAdminRepository) GetAuditHead() (int64, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditHead")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAuditHead indicates an expected call of GetAuditHead.
+func (mr *MockAdminRepositoryMockRecorder) GetAuditHead() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditHead", reflect.TypeOf((*MockAdminRepository)(nil).GetAuditHead))
+}
+
+// GetAuditLog mocks base method.
+func (m *MockAdminRepository) GetAuditLog(walletID string, afterID int64, limit int) ([]models.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLog", walletID, afterID, limit)
+	ret0, _ := ret[0].([]models.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLog indicates an expected call of GetAuditLog.
+func (mr *MockAdminRepositoryMockRecorder) GetAuditLog(walletID, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLog", reflect.TypeOf((*MockAdminRepository)(nil).GetAuditLog), walletID, afterID, limit)
+}
+
+// GetWalletInfo mocks base method.
+func (m *MockAdminRepository) GetWalletInfo(walletID string) (models.WalletInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletInfo", walletID)
+	ret0, _ := ret[0].(models.WalletInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletInfo indicates an expected call of GetWalletInfo.
+func (mr *MockAdminRepositoryMockRecorder) GetWalletInfo(walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletInfo", reflect.TypeOf((*MockAdminRepository)(nil).GetWalletInfo), walletID)
+}
+
+// InsertCheckpoint mocks base method.
+func (m *MockAdminRepository) InsertCheckpoint(checkpoint models.AuditCheckpoint) (models.AuditCheckpoint, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertCheckpoint", checkpoint)
+	ret0, _ := ret[0].(models.AuditCheckpoint)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// InsertCheckpoint indicates an expected call of InsertCheckpoint.
+func (mr *MockAdminRepositoryMockRecorder) InsertCheckpoint(checkpoint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertCheckpoint", reflect.TypeOf((*MockAdminRepository)(nil).InsertCheckpoint), checkpoint)
+}
+
+// ListCheckpoints mocks base method.
+func (m *MockAdminRepository) ListCheckpoints(afterID int64, limit int) ([]models.AuditCheckpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCheckpoints", afterID, limit)
+	ret0, _ := ret[0].([]models.AuditCheckpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCheckpoints indicates an expected call of ListCheckpoints.
+func (mr *MockAdminRepositoryMockRecorder) ListCheckpoints(afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCheckpoints", reflect.TypeOf((*MockAdminRepository)(nil).ListCheckpoints), afterID, limit)
+}
+
+// Reconcile mocks base method.
+func (m *MockAdminRepository) Reconcile(walletIDs []string) (models.ReconciliationReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconcile", walletIDs)
+	ret0, _ := ret[0].(models.ReconciliationReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reconcile indicates an expected call of Reconcile.
+func (mr *MockAdminRepositoryMockRecorder) Reconcile(walletIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockAdminRepository)(nil).Reconcile), walletIDs)
+}
+
+// RecordAudit mocks base method.
+func (m *MockAdminRepository) RecordAudit(entry models.AuditEntry) (models.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAudit", entry)
+	ret0, _ := ret[0].(models.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordAudit indicates an expected call of RecordAudit.
+func (mr *MockAdminRepositoryMockRecorder) RecordAudit(entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAudit", reflect.TypeOf((*MockAdminRepository)(nil).RecordAudit), entry)
+}
+
+// RecordReconciliation mocks base method.
+func (m *MockAdminRepository) RecordReconciliation(entry models.AuditEntry, discrepancies []models.WalletDiscrepancy) (models.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordReconciliation", entry, discrepancies)
+	ret0, _ := ret[0].(models.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordReconciliation indicates an expected call of RecordReconciliation.
+func (mr *MockAdminRepositoryMockRecorder) RecordReconciliation(entry, discrepancies interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordReconciliation", reflect.TypeOf((*MockAdminRepository)(nil).RecordReconciliation), entry, discrepancies)
+}
+
+// SealAudit mocks base method.
+func (m *MockAdminRepository) SealAudit(limit int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SealAudit", limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SealAudit indicates an expected call of SealAudit.
+func (mr *MockAdminRepositoryMockRecorder) SealAudit(limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SealAudit", reflect.TypeOf((*MockAdminRepository)(nil).SealAudit), limit)
+}
+
+// SetFrozen mocks base method.
+func (m *MockAdminRepository) SetFrozen(actor, walletID string, frozen bool, reason string) (models.WalletInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFrozen", actor, walletID, frozen, reason)
+	ret0, _ := ret[0].(models.WalletInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetFrozen indicates an expected call of SetFrozen.
+func (mr *MockAdminRepositoryMockRecorder) SetFrozen(actor, walletID, frozen, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFrozen", reflect.TypeOf((*MockAdminRepository)(nil).SetFrozen), actor, walletID, frozen, reason)
+}
+
+// Statement mocks base method.
+func (m *MockAdminRepository) Statement(walletID string, from, to time.Time) (models.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Statement", walletID, from, to)
+	ret0, _ := ret[0].(models.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Statement indicates an expected call of Statement.
+func (mr *MockAdminRepositoryMockRecorder) Statement(walletID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Statement", reflect.TypeOf((*MockAdminRepository)(nil).Statement), walletID, from, to)
+}
+
+// WalkAuditChain mocks base method.
+func (m *MockAdminRepository) WalkAuditChain(fn func(models.AuditEntry) bool) (int64, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WalkAuditChain", fn)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// WalkAuditChain indicates an expected call of WalkAuditChain.
+func (mr *MockAdminRepositoryMockRecorder) WalkAuditChain(fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalkAuditChain", reflect.TypeOf((*MockAdminRepository)(nil).WalkAuditChain), fn)
+}
@@ -7,6 +7,7 @@ package mock
 import (
 	reflect "reflect"
 
+	models "github.com/VadimBorzenkov/WalletAPI/internal/models"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -33,6 +34,21 @@ func (m *MockWalletRepository) EXPECT() *MockWalletRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ApplyBatch mocks base method.
+func (m *MockWalletRepository) ApplyBatch(items []models.BatchItem, atomic bool) ([]models.BatchItemResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyBatch", items, atomic)
+	ret0, _ := ret[0].([]models.BatchItemResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyBatch indicates an expected call of ApplyBatch.
+func (mr *MockWalletRepositoryMockRecorder) ApplyBatch(items, atomic interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyBatch", reflect.TypeOf((*MockWalletRepository)(nil).ApplyBatch), items, atomic)
+}
+
 // Deposit mocks base method.
 func (m *MockWalletRepository) Deposit(walletID string, amount float64) error {
 	m.ctrl.T.Helper()
@@ -47,6 +63,81 @@ func (mr *MockWalletRepositoryMockRecorder) Deposit(walletID, amount interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*MockWalletRepository)(nil).Deposit), walletID, amount)
 }
 
+// DepositIdempotent mocks base method.
+func (m *MockWalletRepository) DepositIdempotent(walletID string, amount float64, key string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DepositIdempotent", walletID, amount, key)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DepositIdempotent indicates an expected call of DepositIdempotent.
+func (mr *MockWalletRepositoryMockRecorder) DepositIdempotent(walletID, amount, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DepositIdempotent", reflect.TypeOf((*MockWalletRepository)(nil).DepositIdempotent), walletID, amount, key)
+}
+
+// DepositIfVersion mocks base method.
+func (m *MockWalletRepository) DepositIfVersion(walletID string, amount float64, version int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DepositIfVersion", walletID, amount, version)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DepositIfVersion indicates an expected call of DepositIfVersion.
+func (mr *MockWalletRepositoryMockRecorder) DepositIfVersion(walletID, amount, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DepositIfVersion", reflect.TypeOf((*MockWalletRepository)(nil).DepositIfVersion), walletID, amount, version)
+}
+
+// FoldPendingDeposits mocks base method.
+func (m *MockWalletRepository) FoldPendingDeposits(limit int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FoldPendingDeposits", limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FoldPendingDeposits indicates an expected call of FoldPendingDeposits.
+func (mr *MockWalletRepositoryMockRecorder) FoldPendingDeposits(limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FoldPendingDeposits", reflect.TypeOf((*MockWalletRepository)(nil).FoldPendingDeposits), limit)
+}
+
+// GetOperationsAfter mocks base method.
+func (m *MockWalletRepository) GetOperationsAfter(walletID string, afterID int64, limit int) ([]models.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOperationsAfter", walletID, afterID, limit)
+	ret0, _ := ret[0].([]models.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOperationsAfter indicates an expected call of GetOperationsAfter.
+func (mr *MockWalletRepositoryMockRecorder) GetOperationsAfter(walletID, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOperationsAfter", reflect.TypeOf((*MockWalletRepository)(nil).GetOperationsAfter), walletID, afterID, limit)
+}
+
+// GetWallet mocks base method.
+func (m *MockWalletRepository) GetWallet(walletID string) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWallet", walletID)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWallet indicates an expected call of GetWallet.
+func (mr *MockWalletRepositoryMockRecorder) GetWallet(walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockWalletRepository)(nil).GetWallet), walletID)
+}
+
 // GetWalletBalance mocks base method.
 func (m *MockWalletRepository) GetWalletBalance(walletID string) (float64, error) {
 	m.ctrl.T.Helper()
@@ -75,3 +166,18 @@ func (mr *MockWalletRepositoryMockRecorder) Withdraw(walletID, amount interface{
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockWalletRepository)(nil).Withdraw), walletID, amount)
 }
+
+// WithdrawIfVersion mocks base method.
+func (m *MockWalletRepository) WithdrawIfVersion(walletID string, amount float64, version int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithdrawIfVersion", walletID, amount, version)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WithdrawIfVersion indicates an expected call of WithdrawIfVersion.
+func (mr *MockWalletRepositoryMockRecorder) WithdrawIfVersion(walletID, amount, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithdrawIfVersion", reflect.TypeOf((*MockWalletRepository)(nil).WithdrawIfVersion), walletID, amount, version)
+}
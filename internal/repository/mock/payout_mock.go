@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/payout_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	reflect "reflect"
+	time "time"
+
+	models "github.com/VadimBorzenkov/WalletAPI/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPayoutRepository is a mock of PayoutRepository interface.
+type MockPayoutRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPayoutRepositoryMockRecorder
+}
+
+// MockPayoutRepositoryMockRecorder is the mock recorder for MockPayoutRepository.
+type MockPayoutRepositoryMockRecorder struct {
+	mock *MockPayoutRepository
+}
+
+// NewMockPayoutRepository creates a new mock instance.
+func NewMockPayoutRepository(ctrl *gomock.Controller) *MockPayoutRepository {
+	mock := &MockPayoutRepository{ctrl: ctrl}
+	mock.recorder = &MockPayoutRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPayoutRepository) EXPECT() *MockPayoutRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimPendingRows mocks base method.
+func (m *MockPayoutRepository) ClaimPendingRows(limit int, lease time.Duration) ([]models.PayoutRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimPendingRows", limit, lease)
+	ret0, _ := ret[0].([]models.PayoutRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimPendingRows indicates an expected call of ClaimPendingRows.
+func (mr *MockPayoutRepositoryMockRecorder) ClaimPendingRows(limit, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimPendingRows", reflect.TypeOf((*MockPayoutRepository)(nil).ClaimPendingRows), limit, lease)
+}
+
+// CompleteRow mocks base method.
+func (m *MockPayoutRepository) CompleteRow(jobID string, row int, failure string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteRow", jobID, row, failure)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteRow indicates an expected call of CompleteRow.
+func (mr *MockPayoutRepositoryMockRecorder) CompleteRow(jobID, row, failure interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteRow", reflect.TypeOf((*MockPayoutRepository)(nil).CompleteRow), jobID, row, failure)
+}
+
+// CreateJob mocks base method.
+func (m *MockPayoutRepository) CreateJob(rows []models.PayoutRow) (models.PayoutJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJob", rows)
+	ret0, _ := ret[0].(models.PayoutJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateJob indicates an expected call of CreateJob.
+func (mr *MockPayoutRepositoryMockRecorder) CreateJob(rows interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJob", reflect.TypeOf((*MockPayoutRepository)(nil).CreateJob), rows)
+}
+
+// GetJob mocks base method.
+func (m *MockPayoutRepository) GetJob(jobID string) (models.PayoutJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", jobID)
+	ret0, _ := ret[0].(models.PayoutJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockPayoutRepositoryMockRecorder) GetJob(jobID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockPayoutRepository)(nil).GetJob), jobID)
+}
+
+// ListFailedRows mocks base method.
+func (m *MockPayoutRepository) ListFailedRows(jobID string, afterRow, limit int) ([]models.PayoutRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFailedRows", jobID, afterRow, limit)
+	ret0, _ := ret[0].([]models.PayoutRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFailedRows indicates an expected call of ListFailedRows.
+func (mr *MockPayoutRepositoryMockRecorder) ListFailedRows(jobID, afterRow, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFailedRows", reflect.TypeOf((*MockPayoutRepository)(nil).ListFailedRows), jobID, afterRow, limit)
+}
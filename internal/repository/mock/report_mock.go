@@ -0,0 +1,141 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/report_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	reflect "reflect"
+	time "time"
+
+	models "github.com/VadimBorzenkov/WalletAPI/internal/models"
+	statement "github.com/VadimBorzenkov/WalletAPI/internal/statement"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockReportRepository is a mock of ReportRepository interface.
+type MockReportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportRepositoryMockRecorder
+}
+
+// MockReportRepositoryMockRecorder is the mock recorder for MockReportRepository.
+type MockReportRepositoryMockRecorder struct {
+	mock *MockReportRepository
+}
+
+// NewMockReportRepository creates a new mock instance.
+func NewMockReportRepository(ctrl *gomock.Controller) *MockReportRepository {
+	mock := &MockReportRepository{ctrl: ctrl}
+	mock.recorder = &MockReportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportRepository) EXPECT() *MockReportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CloseDay mocks base method.
+func (m *MockReportRepository) CloseDay(day time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseDay", day)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseDay indicates an expected call of CloseDay.
+func (mr *MockReportRepositoryMockRecorder) CloseDay(day interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseDay", reflect.TypeOf((*MockReportRepository)(nil).CloseDay), day)
+}
+
+// DailyTotals mocks base method.
+func (m *MockReportRepository) DailyTotals(walletID string, from, to time.Time, groupBy string) ([]models.ReportPeriod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DailyTotals", walletID, from, to, groupBy)
+	ret0, _ := ret[0].([]models.ReportPeriod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DailyTotals indicates an expected call of DailyTotals.
+func (mr *MockReportRepositoryMockRecorder) DailyTotals(walletID, from, to, groupBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DailyTotals", reflect.TypeOf((*MockReportRepository)(nil).DailyTotals), walletID, from, to, groupBy)
+}
+
+// GetBalanceAt mocks base method.
+func (m *MockReportRepository) GetBalanceAt(walletID string, at time.Time) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceAt", walletID, at)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceAt indicates an expected call of GetBalanceAt.
+func (mr *MockReportRepositoryMockRecorder) GetBalanceAt(walletID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceAt", reflect.TypeOf((*MockReportRepository)(nil).GetBalanceAt), walletID, at)
+}
+
+// LastDailyReport mocks base method.
+func (m *MockReportRepository) LastDailyReport() (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastDailyReport")
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastDailyReport indicates an expected call of LastDailyReport.
+func (mr *MockReportRepositoryMockRecorder) LastDailyReport() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastDailyReport", reflect.TypeOf((*MockReportRepository)(nil).LastDailyReport))
+}
+
+// LastSnapshot mocks base method.
+func (m *MockReportRepository) LastSnapshot() (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastSnapshot")
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastSnapshot indicates an expected call of LastSnapshot.
+func (mr *MockReportRepositoryMockRecorder) LastSnapshot() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastSnapshot", reflect.TypeOf((*MockReportRepository)(nil).LastSnapshot))
+}
+
+// StreamStatement mocks base method.
+func (m *MockReportRepository) StreamStatement(walletID string, from, to time.Time, w statement.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamStatement", walletID, from, to, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamStatement indicates an expected call of StreamStatement.
+func (mr *MockReportRepositoryMockRecorder) StreamStatement(walletID, from, to, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamStatement", reflect.TypeOf((*MockReportRepository)(nil).StreamStatement), walletID, from, to, w)
+}
+
+// TakeSnapshots mocks base method.
+func (m *MockReportRepository) TakeSnapshots(at time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeSnapshots", at)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeSnapshots indicates an expected call of TakeSnapshots.
+func (mr *MockReportRepositoryMockRecorder) TakeSnapshots(at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeSnapshots", reflect.TypeOf((*MockReportRepository)(nil).TakeSnapshots), at)
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Приемники значений NUMERIC(20, 2). Значение декодируется из pgtype.Numeric в копейки целочисленно,
+// без промежуточного float64, а сумма с долями копейки или вне диапазона int64 считается ошибкой, а не
+// округляется. float64 получается из копеек только на границе с моделями
+
+// scanCents возвращает приемник суммы NUMERIC в копейках
+func scanCents(dst *int64) pgtype.NumericScanner {
+	return centsScanner{dst: dst}
+}
+
+// scanMoney возвращает приемник суммы NUMERIC в рублях
+func scanMoney(dst *float64) pgtype.NumericScanner {
+	return moneyScanner{dst: dst}
+}
+
+// scanNullMoney возвращает приемник суммы NUMERIC, допускающей NULL: NULL записывается как nil
+func scanNullMoney(dst **float64) pgtype.NumericScanner {
+	return nullMoneyScanner{dst: dst}
+}
+
+type centsScanner struct{ dst *int64 }
+
+func (s centsScanner) ScanNumeric(n pgtype.Numeric) error {
+	cents, err := numericCents(n)
+	if err != nil {
+		return err
+	}
+	*s.dst = cents
+	return nil
+}
+
+type moneyScanner struct{ dst *float64 }
+
+func (s moneyScanner) ScanNumeric(n pgtype.Numeric) error {
+	cents, err := numericCents(n)
+	if err != nil {
+		return err
+	}
+	*s.dst = fromCents(cents)
+	return nil
+}
+
+type nullMoneyScanner struct{ dst **float64 }
+
+func (s nullMoneyScanner) ScanNumeric(n pgtype.Numeric) error {
+	if !n.Valid {
+		*s.dst = nil
+		return nil
+	}
+	cents, err := numericCents(n)
+	if err != nil {
+		return err
+	}
+	amount := fromCents(cents)
+	*s.dst = &amount
+	return nil
+}
+
+var errNullNumeric = errors.New("cannot scan NULL numeric into amount")
+
+var bigTen = big.NewInt(10)
+
+// numericCents переводит NUMERIC в копейки: значение равно n.Int * 10^n.Exp, копейки — n.Int * 10^(n.Exp+2)
+func numericCents(n pgtype.Numeric) (int64, error) {
+	switch {
+	case !n.Valid:
+		return 0, errNullNumeric
+	case n.NaN || n.InfinityModifier != pgtype.Finite:
+		return 0, errors.New("cannot scan non-finite numeric into amount")
+	}
+
+	cents := new(big.Int)
+	if n.Int != nil {
+		cents.Set(n.Int)
+	}
+	if exp := int64(n.Exp) + 2; exp >= 0 {
+		cents.Mul(cents, new(big.Int).Exp(bigTen, big.NewInt(exp), nil))
+	} else {
+		var rem big.Int
+		cents.QuoRem(cents, new(big.Int).Exp(bigTen, big.NewInt(-exp), nil), &rem)
+		if rem.Sign() != 0 {
+			return 0, fmt.Errorf("numeric %se%d has fractions of a cent", n.Int, n.Exp)
+		}
+	}
+	if !cents.IsInt64() {
+		return 0, fmt.Errorf("numeric %se%d is out of range", n.Int, n.Exp)
+	}
+	return cents.Int64(), nil
+}
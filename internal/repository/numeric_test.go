@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNumericCents проверяет точный перевод NUMERIC в копейки
+func TestNumericCents(t *testing.T) {
+	tests := []struct {
+		name     string         // Название теста
+		value    pgtype.Numeric // Значение из базы
+		expected int64          // Ожидаемая сумма в копейках
+		hasError bool           // Ожидается ли ошибка
+	}{
+		{name: "Two Decimals", value: pgtype.Numeric{Int: big.NewInt(12345), Exp: -2, Valid: true}, expected: 12345},
+		{name: "Negative", value: pgtype.Numeric{Int: big.NewInt(-10), Exp: -2, Valid: true}, expected: -10},
+		{name: "Integer", value: pgtype.Numeric{Int: big.NewInt(5), Exp: 3, Valid: true}, expected: 500000},
+		{name: "Zero", value: pgtype.Numeric{Int: big.NewInt(0), Valid: true}, expected: 0},
+		{name: "Trailing Zeros", value: pgtype.Numeric{Int: big.NewInt(1230), Exp: -3, Valid: true}, expected: 123},
+		{name: "One Decimal", value: pgtype.Numeric{Int: big.NewInt(3), Exp: -1, Valid: true}, expected: 30},
+		{name: "Large Balance", value: pgtype.Numeric{Int: big.NewInt(999999999999999999), Exp: -2, Valid: true}, expected: 999999999999999999},
+		{name: "Fractions Of Cent", value: pgtype.Numeric{Int: big.NewInt(1001), Exp: -3, Valid: true}, hasError: true},
+		{name: "Out Of Range", value: pgtype.Numeric{Int: big.NewInt(1), Exp: 30, Valid: true}, hasError: true},
+		{name: "NaN", value: pgtype.Numeric{NaN: true, Valid: true}, hasError: true},
+		{name: "Infinity", value: pgtype.Numeric{InfinityModifier: pgtype.Infinity, Valid: true}, hasError: true},
+		{name: "Null", value: pgtype.Numeric{}, hasError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cents, err := numericCents(tt.value)
+			if tt.hasError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cents)
+		})
+	}
+}
+
+// TestScanMoney проверяет приемники сумм, в том числе NULL в необязательной сумме
+func TestScanMoney(t *testing.T) {
+	value := pgtype.Numeric{Int: big.NewInt(10010), Exp: -2, Valid: true}
+
+	var amount float64
+	require.NoError(t, scanMoney(&amount).ScanNumeric(value))
+	assert.Equal(t, 100.1, amount)
+
+	var cents int64
+	require.NoError(t, scanCents(&cents).ScanNumeric(value))
+	assert.Equal(t, int64(10010), cents)
+
+	optional := &amount
+	require.NoError(t, scanNullMoney(&optional).ScanNumeric(pgtype.Numeric{}))
+	assert.Nil(t, optional)
+	require.NoError(t, scanNullMoney(&optional).ScanNumeric(value))
+	require.NotNil(t, optional)
+	assert.Equal(t, 100.1, *optional)
+
+	assert.Error(t, scanMoney(&amount).ScanNumeric(pgtype.Numeric{}))
+}
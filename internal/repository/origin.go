@@ -0,0 +1,17 @@
+package repository
+
+import "github.com/VadimBorzenkov/WalletAPI/internal/models"
+
+// OriginScoper — репозиторий, изменения которого можно записывать в журнал аудита от имени источника запроса
+type OriginScoper interface {
+	WithOrigin(origin models.Origin) WalletRepository
+}
+
+// ScopeOrigin возвращает репозиторий, изменения которого записываются от имени origin.
+// Репозиторий без журнала аудита возвращается без изменений
+func ScopeOrigin(repo WalletRepository, origin models.Origin) WalletRepository {
+	if scoper, ok := repo.(OriginScoper); ok {
+		return scoper.WithOrigin(origin)
+	}
+	return repo
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// outboxLockKey — ключ advisory-блокировки, под которой события outbox захватывает только один экземпляр приложения
+// одновременно. Блокировка удерживается только на время захвата, публикация выполняется после фиксации транзакции
+const outboxLockKey int64 = 0x6f7574626f78
+
+// outboxMaxBackoff ограничивает показатель степени в задержке повторной публикации: retryDelay * 2^attempts
+const outboxMaxBackoff = 6
+
+type OutboxRepository interface {
+	// ClaimOutbox захватывает на время lease до limit неопубликованных событий в порядке их записи. События кошелька,
+	// более раннее событие которого уже захвачено или отложено после неудачной публикации, пропускаются
+	ClaimOutbox(limit int, lease time.Duration) ([]models.Event, error)
+	// CompleteOutbox помечает события published опубликованными, откладывает события failed на retryDelay,
+	// удваивая задержку с каждой неудачной попыткой, и снимает захват с событий released
+	CompleteOutbox(published, failed, released []int64, retryDelay time.Duration) error
+}
+
+type ApiOutboxRepository struct {
+	db     *pgxpool.Pool
+	logger *logrus.Logger
+}
+
+func NewApiOutboxRepository(db *pgxpool.Pool, logger *logrus.Logger) *ApiOutboxRepository {
+	return &ApiOutboxRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Захват очередной порции событий outbox. Порядок событий одного кошелька сохраняется: событие захватывается,
+// только если ни одно более раннее неопубликованное событие кошелька не захвачено и не ждет повторной публикации
+func (r *ApiOutboxRepository) ClaimOutbox(limit int, lease time.Duration) ([]models.Event, error) {
+	var events []models.Event
+	err := withTx(r.db, func(tx pgx.Tx) error {
+		events = nil
+		var locked bool
+		if err := tx.QueryRow(context.Background(), `SELECT pg_try_advisory_xact_lock($1)`, outboxLockKey).Scan(&locked); err != nil {
+			return err
+		}
+		if !locked {
+			// События захватывает другой экземпляр приложения
+			return nil
+		}
+
+		rows, err := tx.Query(context.Background(), `UPDATE outbox_events
+			SET claimed_until = NOW() + make_interval(secs => $2::float8)
+			WHERE event_id IN (
+				SELECT e.event_id FROM outbox_events e
+				WHERE e.published_at IS NULL AND NOT EXISTS (
+					SELECT 1 FROM outbox_events b
+					WHERE b.wallet_id = e.wallet_id AND b.published_at IS NULL AND b.event_id <= e.event_id
+						AND (b.claimed_until > NOW() OR b.next_attempt_at > NOW()))
+				ORDER BY e.event_id
+				LIMIT $1)
+			RETURNING event_id, event_type, wallet_id, payload, created_at`, limit, lease.Seconds())
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var event models.Event
+			if err := rows.Scan(&event.ID, &event.Type, &event.WalletID, &event.Payload, &event.CreatedAt); err != nil {
+				return err
+			}
+			events = append(events, event)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		r.logger.Errorf("Error claiming outbox events: %v", err)
+		return nil, err
+	}
+	// RETURNING не гарантирует порядок строк
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
+
+// Завершение публикации захваченных событий
+func (r *ApiOutboxRepository) CompleteOutbox(published, failed, released []int64, retryDelay time.Duration) error {
+	err := withTx(r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(context.Background(), `UPDATE outbox_events SET published_at = NOW(), claimed_until = NULL
+			WHERE event_id = ANY ($1)`, published); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(context.Background(), `UPDATE outbox_events
+			SET claimed_until = NULL, attempts = attempts + 1,
+				next_attempt_at = NOW() + make_interval(secs => $2::float8 * power(2, LEAST(attempts, $3::int)))
+			WHERE event_id = ANY ($1)`, failed, retryDelay.Seconds(), outboxMaxBackoff); err != nil {
+			return err
+		}
+		_, err := tx.Exec(context.Background(), `UPDATE outbox_events SET claimed_until = NULL WHERE event_id = ANY ($1)`, released)
+		return err
+	})
+	if err != nil {
+		r.logger.Errorf("Error completing outbox events: %v", err)
+		return err
+	}
+	return nil
+}
+
+// insertOutboxEvent сохраняет доменное событие в outbox в рамках транзакции изменения баланса
+func insertOutboxEvent(tx pgx.Tx, eventType, walletID string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(context.Background(), `INSERT INTO outbox_events (event_type, wallet_id, payload) VALUES ($1, $2, $3)`, eventType, walletID, data)
+	return err
+}
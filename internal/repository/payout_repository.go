@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+type PayoutRepository interface {
+	CreateJob(rows []models.PayoutRow) (models.PayoutJob, error)
+	GetJob(jobID string) (models.PayoutJob, error)
+	ListFailedRows(jobID string, afterRow, limit int) ([]models.PayoutRow, error)
+	ClaimPendingRows(limit int, lease time.Duration) ([]models.PayoutRow, error)
+	CompleteRow(jobID string, row int, failure string) error
+}
+
+type ApiPayoutRepository struct {
+	db     *pgxpool.Pool
+	logger *logrus.Logger
+}
+
+func NewApiPayoutRepository(db *pgxpool.Pool, logger *logrus.Logger) *ApiPayoutRepository {
+	return &ApiPayoutRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const payoutJobColumns = `job_id, status, total_rows, processed_rows, failed_rows, created_at, completed_at`
+
+// Создание задания на выплаты. Строки загружаются через COPY; строки, не прошедшие проверку,
+// сразу учитываются как обработанные с ошибкой
+func (r *ApiPayoutRepository) CreateJob(rows []models.PayoutRow) (models.PayoutJob, error) {
+	failed := 0
+	for _, row := range rows {
+		if row.Status == models.PayoutRowFailed {
+			failed++
+		}
+	}
+
+	var job models.PayoutJob
+	err := withTx(r.db, func(tx pgx.Tx) error {
+		query := `INSERT INTO payout_jobs (status, total_rows, processed_rows, failed_rows, completed_at)
+			SELECT CASE WHEN $2 = $1 THEN 'COMPLETED' ELSE 'RUNNING' END, $1, $2, $2,
+				CASE WHEN $2 = $1 THEN NOW() END
+			RETURNING ` + payoutJobColumns
+		if err := tx.QueryRow(context.Background(), query, len(rows), failed).Scan(payoutJobDest(&job)...); err != nil {
+			return err
+		}
+
+		// COPY передает значения в двоичном формате, поэтому ID задания передается как UUID, а не строкой
+		var jobID pgtype.UUID
+		if err := jobID.Scan(job.ID); err != nil {
+			return err
+		}
+		_, err := tx.CopyFrom(context.Background(), pgx.Identifier{"payout_rows"},
+			[]string{"job_id", "row_number", "wallet_id", "amount", "status", "error", "processed_at"},
+			pgx.CopyFromSlice(len(rows), func(i int) ([]interface{}, error) {
+				row := rows[i]
+				var failure, processedAt interface{}
+				if row.Status == models.PayoutRowFailed {
+					failure, processedAt = row.Error, job.CreatedAt
+				}
+				return []interface{}{jobID, row.Row, row.WalletID, row.Amount, row.Status, failure, processedAt}, nil
+			}))
+		return err
+	})
+	if err != nil {
+		r.logger.Errorf("Error creating payout job with %d rows: %v", len(rows), err)
+		return models.PayoutJob{}, err
+	}
+	r.logger.Infof("Created payout job %s with %d rows, %d rejected on upload", job.ID, job.TotalRows, failed)
+	return job, nil
+}
+
+// Получение задания на выплаты с его прогрессом
+func (r *ApiPayoutRepository) GetJob(jobID string) (models.PayoutJob, error) {
+	var job models.PayoutJob
+	err := r.db.QueryRow(context.Background(), `SELECT `+payoutJobColumns+` FROM payout_jobs WHERE job_id = $1`, jobID).Scan(payoutJobDest(&job)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.PayoutJob{}, ErrJobNotFound
+	}
+	if err != nil {
+		r.logger.Errorf("Error retrieving payout job %s: %v", jobID, err)
+		return models.PayoutJob{}, err
+	}
+	return job, nil
+}
+
+// Получение строк задания, завершившихся ошибкой, с номером больше afterRow
+func (r *ApiPayoutRepository) ListFailedRows(jobID string, afterRow, limit int) ([]models.PayoutRow, error) {
+	query := `SELECT job_id, row_number, wallet_id, amount, status, error FROM payout_rows
+		WHERE job_id = $1 AND status = 'FAILED' AND row_number > $2
+		ORDER BY row_number
+		LIMIT $3`
+	rows, err := r.db.Query(context.Background(), query, jobID, afterRow, limit)
+	if err != nil {
+		r.logger.Errorf("Error listing failed rows of payout job %s: %v", jobID, err)
+		return nil, err
+	}
+	return scanPayoutRows(rows)
+}
+
+// Захват необработанных строк заданий. Захваченные строки откладываются на lease, чтобы их не взял другой
+// обработчик; строки, захват которых истек из-за остановки приложения, будут захвачены повторно
+func (r *ApiPayoutRepository) ClaimPendingRows(limit int, lease time.Duration) ([]models.PayoutRow, error) {
+	query := `UPDATE payout_rows SET lease_until = NOW() + make_interval(secs => $2)
+		WHERE (job_id, row_number) IN (
+			SELECT job_id, row_number FROM payout_rows
+			WHERE status = 'PENDING' AND (lease_until IS NULL OR lease_until <= NOW())
+			ORDER BY job_id, row_number
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED)
+		RETURNING job_id, row_number, wallet_id, amount, status, error`
+	rows, err := r.db.Query(context.Background(), query, limit, lease.Seconds())
+	if err != nil {
+		r.logger.Errorf("Error claiming payout rows: %v", err)
+		return nil, err
+	}
+	return scanPayoutRows(rows)
+}
+
+// Фиксация результата строки и прогресса задания. Пустой failure означает успешную выплату.
+// Уже обработанная строка повторно не учитывается
+func (r *ApiPayoutRepository) CompleteRow(jobID string, row int, failure string) error {
+	status := models.PayoutRowPaid
+	if failure != "" {
+		status = models.PayoutRowFailed
+	}
+	query := `WITH done AS (
+			UPDATE payout_rows SET status = $3, error = NULLIF($4, ''), lease_until = NULL, processed_at = NOW()
+			WHERE job_id = $1 AND row_number = $2 AND status = 'PENDING'
+			RETURNING job_id
+		)
+		UPDATE payout_jobs j SET
+			processed_rows = j.processed_rows + 1,
+			failed_rows = j.failed_rows + CASE WHEN $3 = 'FAILED' THEN 1 ELSE 0 END,
+			status = CASE WHEN j.processed_rows + 1 >= j.total_rows THEN 'COMPLETED' ELSE j.status END,
+			completed_at = CASE WHEN j.processed_rows + 1 >= j.total_rows THEN NOW() ELSE j.completed_at END
+		FROM done WHERE j.job_id = done.job_id`
+	if _, err := r.db.Exec(context.Background(), query, jobID, row, status, failure); err != nil {
+		r.logger.Errorf("Error completing row %d of payout job %s: %v", row, jobID, err)
+		return err
+	}
+	return nil
+}
+
+// payoutJobDest возвращает адреса полей задания в порядке payoutJobColumns
+func payoutJobDest(job *models.PayoutJob) []interface{} {
+	return []interface{}{&job.ID, &job.Status, &job.TotalRows, &job.ProcessedRows, &job.FailedRows, &job.CreatedAt, &job.CompletedAt}
+}
+
+// scanPayoutRows читает строки заданий и закрывает rows
+func scanPayoutRows(rows pgx.Rows) ([]models.PayoutRow, error) {
+	defer rows.Close()
+
+	result := []models.PayoutRow{}
+	for rows.Next() {
+		var (
+			row     models.PayoutRow
+			failure pgtype.Text
+		)
+		if err := rows.Scan(&row.JobID, &row.Row, &row.WalletID, scanNullMoney(&row.Amount), &row.Status, &failure); err != nil {
+			return nil, err
+		}
+		row.Error = failure.String
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// WalletReader — чтения кошельков, которые допускают выполнение на реплике
+type WalletReader interface {
+	GetWalletBalance(walletID string) (float64, error)
+	GetWallet(walletID string) (models.Wallet, error)
+	GetOperationsAfter(walletID string, afterID int64, limit int) ([]models.Operation, error)
+}
+
+// ReplicaWalletReader читает кошельки и журнал операций с реплики, пока ее отставание не превышает maxLag.
+// Если реплика отстает, недоступна или еще не получила кошелек, чтение выполняется на основной базе
+type ReplicaWalletReader struct {
+	db        *pgxpool.Pool
+	primaryDB *pgxpool.Pool // Основная база, с позицией WAL которой сравнивается реплика
+	replica   WalletReader
+	primary   WalletReader
+	maxLag    time.Duration
+	logger    *logrus.Logger
+	lagging   atomic.Bool // До первой проверки отставания реплика не используется
+}
+
+func NewReplicaWalletReader(db, primaryDB *pgxpool.Pool, primary WalletReader, maxLag time.Duration, logger *logrus.Logger) *ReplicaWalletReader {
+	r := &ReplicaWalletReader{
+		db:        db,
+		primaryDB: primaryDB,
+		replica:   NewApiWalletRepository(db, logger),
+		primary:   primary,
+		maxLag:    maxLag,
+		logger:    logger,
+	}
+	r.lagging.Store(true)
+	return r
+}
+
+// Run проверяет отставание реплики с периодом interval до отмены контекста
+func (r *ReplicaWalletReader) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		r.CheckLag()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// errLagUnknown — отставание реплики измерить нельзя: она не воспроизводит WAL или еще не воспроизвела
+// ни одной транзакции
+var errLagUnknown = errors.New("replica lag is unknown")
+
+// CheckLag измеряет отставание реплики и включает или отключает чтение с нее
+func (r *ReplicaWalletReader) CheckLag() {
+	lag, err := r.measureLag()
+	r.setLag(lag, err)
+}
+
+// measureLag сравнивает воспроизведенную репликой позицию WAL с текущей позицией основной базы. Реплика,
+// воспроизведшая WAL до этой позиции, не отстает. Иначе отставание — время с последней воспроизведенной
+// транзакции: если реплика перестала получать WAL, оно растет, даже когда ей нечего воспроизводить
+func (r *ReplicaWalletReader) measureLag() (time.Duration, error) {
+	var lsn string
+	if err := r.primaryDB.QueryRow(context.Background(), `SELECT pg_current_wal_lsn()::text`).Scan(&lsn); err != nil {
+		return 0, err
+	}
+
+	var seconds *float64
+	query := `SELECT CASE
+			WHEN pg_last_wal_replay_lsn() >= $1::pg_lsn THEN 0
+			ELSE EXTRACT(EPOCH FROM NOW() - pg_last_xact_replay_timestamp())
+		END`
+	if err := r.db.QueryRow(context.Background(), query, lsn).Scan(&seconds); err != nil {
+		return 0, err
+	}
+	if seconds == nil {
+		return 0, errLagUnknown
+	}
+	return time.Duration(*seconds * float64(time.Second)), nil
+}
+
+// setLag включает чтение с реплики, если отставание измерено и не превышает maxLag
+func (r *ReplicaWalletReader) setLag(lag time.Duration, err error) {
+	lagging := err != nil || lag > r.maxLag
+	if lagging != r.lagging.Swap(lagging) {
+		switch {
+		case err != nil:
+			r.logger.Warnf("Replica is unavailable, reading from primary: %v", err)
+		case lagging:
+			r.logger.Warnf("Replica lag %s exceeds %s, reading from primary", lag, r.maxLag)
+		default:
+			r.logger.Infof("Replica lag %s is within %s, reading from replica", lag, r.maxLag)
+		}
+	}
+}
+
+func (r *ReplicaWalletReader) GetWalletBalance(walletID string) (float64, error) {
+	wallet, err := r.GetWallet(walletID)
+	if err != nil {
+		return 0, err
+	}
+	return wallet.Balance, nil
+}
+
+func (r *ReplicaWalletReader) GetWallet(walletID string) (models.Wallet, error) {
+	if !r.lagging.Load() {
+		wallet, err := r.replica.GetWallet(walletID)
+		if err == nil {
+			return wallet, nil
+		}
+		r.fallback("wallet "+walletID, err)
+	}
+	return r.primary.GetWallet(walletID)
+}
+
+func (r *ReplicaWalletReader) GetOperationsAfter(walletID string, afterID int64, limit int) ([]models.Operation, error) {
+	if !r.lagging.Load() {
+		operations, err := r.replica.GetOperationsAfter(walletID, afterID, limit)
+		if err == nil {
+			return operations, nil
+		}
+		r.fallback("operations of wallet "+walletID, err)
+	}
+	return r.primary.GetOperationsAfter(walletID, afterID, limit)
+}
+
+// fallback сообщает о чтении с основной базы после ошибки реплики. Кошелек, которого еще нет на реплике, —
+// ожидаемое следствие отставания
+func (r *ReplicaWalletReader) fallback(what string, err error) {
+	if errors.Is(err, ErrWalletNotFound) {
+		return
+	}
+	r.logger.Warnf("Failed to read %s from replica, reading from primary: %v", what, err)
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeReader отдает один и тот же кошелек или ошибку и считает обращения
+type fakeReader struct {
+	wallet models.Wallet
+	err    error
+	calls  int
+}
+
+func (f *fakeReader) GetWalletBalance(walletID string) (float64, error) {
+	wallet, err := f.GetWallet(walletID)
+	return wallet.Balance, err
+}
+
+func (f *fakeReader) GetWallet(walletID string) (models.Wallet, error) {
+	f.calls++
+	return f.wallet, f.err
+}
+
+func (f *fakeReader) GetOperationsAfter(walletID string, afterID int64, limit int) ([]models.Operation, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []models.Operation{{ID: afterID + 1, WalletID: walletID, Version: f.wallet.Version}}, nil
+}
+
+// TestReplicaWalletReader проверяет выбор между репликой и основной базой
+func TestReplicaWalletReader(t *testing.T) {
+	tests := []struct {
+		name            string // Название теста
+		lagging         bool   // Реплика отстает больше допустимого
+		replicaErr      error  // Ошибка чтения с реплики
+		expectedVersion int64  // Версия прочитанного кошелька
+		replicaCalls    int    // Ожидаемое количество обращений к реплике
+		primaryCalls    int    // Ожидаемое количество обращений к основной базе
+	}{
+		{name: "Replica Up To Date", expectedVersion: 4, replicaCalls: 2},
+		{name: "Replica Lagging", lagging: true, expectedVersion: 5, primaryCalls: 2},
+		{name: "Replica Unavailable", replicaErr: errors.New("connection refused"), expectedVersion: 5, replicaCalls: 2, primaryCalls: 2},
+		{name: "Wallet Not Replicated Yet", replicaErr: ErrWalletNotFound, expectedVersion: 5, replicaCalls: 2, primaryCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replica := &fakeReader{wallet: models.Wallet{ID: walletA, Version: 4}, err: tt.replicaErr}
+			primary := &fakeReader{wallet: models.Wallet{ID: walletA, Version: 5}}
+			reader := &ReplicaWalletReader{replica: replica, primary: primary, logger: logrus.New()}
+			reader.lagging.Store(tt.lagging)
+
+			wallet, err := reader.GetWallet(walletA)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedVersion, wallet.Version)
+
+			operations, err := reader.GetOperationsAfter(walletA, 10, 100)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedVersion, operations[0].Version)
+
+			assert.Equal(t, tt.replicaCalls, replica.calls)
+			assert.Equal(t, tt.primaryCalls, primary.calls)
+		})
+	}
+}
+
+// TestNewReplicaWalletReader проверяет, что до первой проверки отставания чтения идут в основную базу
+func TestNewReplicaWalletReader(t *testing.T) {
+	primary := &fakeReader{wallet: models.Wallet{ID: walletA, Version: 5}}
+	reader := NewReplicaWalletReader(nil, nil, primary, time.Second, logrus.New())
+
+	wallet, err := reader.GetWallet(walletA)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), wallet.Version)
+}
+
+// TestReplicaWalletReader_SetLag проверяет, что реплика с неизвестным отставанием не используется
+func TestReplicaWalletReader_SetLag(t *testing.T) {
+	tests := []struct {
+		name    string        // Название теста
+		lag     time.Duration // Измеренное отставание
+		err     error         // Ошибка измерения
+		lagging bool          // Ожидаемое отключение реплики
+	}{
+		{name: "Caught Up", lag: 0, lagging: false},
+		{name: "Within Max Lag", lag: time.Second, lagging: false},
+		{name: "Over Max Lag", lag: time.Minute, lagging: true},
+		{name: "Lag Unknown", err: errLagUnknown, lagging: true},
+		{name: "Unavailable", err: errors.New("connection refused"), lagging: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewReplicaWalletReader(nil, nil, &fakeReader{}, 5*time.Second, logrus.New())
+			reader.lagging.Store(!tt.lagging)
+
+			reader.setLag(tt.lag, tt.err)
+			assert.Equal(t, tt.lagging, reader.lagging.Load())
+		})
+	}
+}
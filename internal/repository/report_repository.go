@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/statement"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// ReportRepository — исторические балансы и отчеты по журналу операций
+type ReportRepository interface {
+	GetBalanceAt(walletID string, at time.Time) (float64, error)
+	StreamStatement(walletID string, from, to time.Time, w statement.Writer) error
+	LastSnapshot() (time.Time, error)
+	TakeSnapshots(at time.Time) (int, error)
+	LastDailyReport() (time.Time, error)
+	CloseDay(day time.Time) (int, error)
+	DailyTotals(walletID string, from, to time.Time, groupBy string) ([]models.ReportPeriod, error)
+}
+
+type ApiReportRepository struct {
+	db     *pgxpool.Pool
+	logger *logrus.Logger
+}
+
+func NewApiReportRepository(db *pgxpool.Pool, logger *logrus.Logger) *ApiReportRepository {
+	return &ApiReportRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Баланс кошелька на момент at. Кошелек без операций до at имел нулевой баланс
+func (r *ApiReportRepository) GetBalanceAt(walletID string, at time.Time) (float64, error) {
+	var balance float64
+	err := execTx(r.db, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		if err := requireWalletTx(tx, walletID); err != nil {
+			return err
+		}
+		var err error
+		balance, err = balanceAt(tx, walletID, at)
+		return err
+	})
+	if err != nil {
+		r.logger.Errorf("Error retrieving balance of wallet %s at %s: %v", walletID, at.Format(time.RFC3339), err)
+		return 0, err
+	}
+	return balance, nil
+}
+
+// Выписка по кошельку за период [from, to) в порядке времени операций. Операции читаются курсором и сразу
+// передаются w, поэтому выписка не собирается в памяти. Баланс после операции считается от баланса на начало
+// периода, так что баланс на конец всегда равен балансу на начало с учетом операций выписки
+func (r *ApiReportRepository) StreamStatement(walletID string, from, to time.Time, w statement.Writer) error {
+	err := execTx(r.db, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		if err := requireWalletTx(tx, walletID); err != nil {
+			return err
+		}
+		// Время в PostgreSQL хранится с точностью до микросекунды, поэтому баланс до from — это баланс
+		// на микросекунду раньше
+		opening, err := balanceAt(tx, walletID, from.Add(-time.Microsecond))
+		if err != nil {
+			return err
+		}
+		if err := w.Opening(opening); err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(context.Background(), `SELECT operation_id, wallet_id, operation_type, amount, balance_after, wallet_version, created_at
+			FROM wallet_operations
+			WHERE wallet_id = $1 AND created_at >= $2 AND created_at < $3
+			ORDER BY created_at, operation_id`, walletID, from, to)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		balance := toCents(opening)
+		for rows.Next() {
+			var op models.Operation
+			if err := rows.Scan(&op.ID, &op.WalletID, &op.Type, scanMoney(&op.Amount), scanMoney(&op.BalanceAfter), &op.Version, &op.CreatedAt); err != nil {
+				return err
+			}
+			if isDebit(op.Type) {
+				balance -= toCents(op.Amount)
+			} else {
+				balance += toCents(op.Amount)
+			}
+			op.BalanceAfter = fromCents(balance)
+			if err := w.Operation(op); err != nil {
+				return err
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return w.Closing(fromCents(balance))
+	})
+	if err != nil {
+		if !errors.Is(err, ErrWalletNotFound) {
+			r.logger.Errorf("Error streaming statement for wallet %s: %v", walletID, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// requireWalletTx возвращает ErrWalletNotFound, если кошелька нет
+func requireWalletTx(tx pgx.Tx, walletID string) error {
+	var exists bool
+	if err := tx.QueryRow(context.Background(), `SELECT EXISTS (SELECT 1 FROM wallets WHERE wallet_id = $1)`, walletID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrWalletNotFound
+	}
+	return nil
+}
+
+// balanceAt вычисляет баланс на момент at по ближайшему снимку не позже at и сумме операций после снимка
+// с учетом их знака. Без снимка операции суммируются с начала журнала
+func balanceAt(tx pgx.Tx, walletID string, at time.Time) (float64, error) {
+	var (
+		balance    int64
+		snapshotAt *time.Time
+	)
+	err := tx.QueryRow(context.Background(), `SELECT taken_at, balance FROM wallet_balance_snapshots
+		WHERE wallet_id = $1 AND taken_at <= $2
+		ORDER BY taken_at DESC
+		LIMIT 1`, walletID, at).Scan(&snapshotAt, scanCents(&balance))
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, err
+	}
+
+	var delta int64
+	if err := tx.QueryRow(context.Background(), `SELECT COALESCE(SUM(CASE WHEN operation_type = ANY ($4::text[]) THEN -amount ELSE amount END), 0)
+		FROM wallet_operations
+		WHERE wallet_id = $1 AND created_at > COALESCE($2, '-infinity'::timestamptz) AND created_at <= $3`,
+		walletID, snapshotAt, at, debitOperationTypes).Scan(scanCents(&delta)); err != nil {
+		return 0, err
+	}
+	return fromCents(balance + delta), nil
+}
+
+// Время последнего снимка балансов; нулевое, если снимков еще нет
+func (r *ApiReportRepository) LastSnapshot() (time.Time, error) {
+	var last *time.Time
+	if err := r.db.QueryRow(context.Background(), `SELECT MAX(taken_at) FROM wallet_balance_snapshots`).Scan(&last); err != nil {
+		r.logger.Errorf("Error retrieving last balance snapshot: %v", err)
+		return time.Time{}, err
+	}
+	if last == nil {
+		return time.Time{}, nil
+	}
+	return *last, nil
+}
+
+// Снимок балансов всех кошельков на момент at по предыдущему снимку и операциям после него. Кошельки
+// без операций до at пропускаются, уже существующие снимки на at не перезаписываются. Возвращает количество
+// сохраненных снимков
+func (r *ApiReportRepository) TakeSnapshots(at time.Time) (int, error) {
+	tag, err := r.db.Exec(context.Background(), `INSERT INTO wallet_balance_snapshots (wallet_id, taken_at, balance)
+		SELECT w.wallet_id, $1, COALESCE(s.balance, 0) + COALESCE(o.delta, 0)
+		FROM wallets w
+		LEFT JOIN LATERAL (
+			SELECT taken_at, balance FROM wallet_balance_snapshots
+			WHERE wallet_id = w.wallet_id AND taken_at < $1
+			ORDER BY taken_at DESC
+			LIMIT 1) s ON TRUE
+		CROSS JOIN LATERAL (
+			SELECT SUM(CASE WHEN operation_type = ANY ($2::text[]) THEN -amount ELSE amount END) AS delta
+			FROM wallet_operations
+			WHERE wallet_id = w.wallet_id AND created_at > COALESCE(s.taken_at, '-infinity'::timestamptz) AND created_at <= $1) o
+		WHERE s.balance IS NOT NULL OR o.delta IS NOT NULL
+		ON CONFLICT (wallet_id, taken_at) DO NOTHING`, at, debitOperationTypes)
+	if err != nil {
+		r.logger.Errorf("Error taking balance snapshots at %s: %v", at.Format(time.RFC3339), err)
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// День последнего дневного отчета; нулевое время, если отчетов еще нет
+func (r *ApiReportRepository) LastDailyReport() (time.Time, error) {
+	var last *time.Time
+	if err := r.db.QueryRow(context.Background(), `SELECT MAX(day) FROM wallet_daily_reports`).Scan(&last); err != nil {
+		r.logger.Errorf("Error retrieving last daily report: %v", err)
+		return time.Time{}, err
+	}
+	if last == nil {
+		return time.Time{}, nil
+	}
+	return *last, nil
+}
+
+// Дневные отчеты всех кошельков за сутки UTC, начинающиеся в day. Баланс на начало суток берется из отчета
+// за предыдущий день, а без него вычисляется по всему журналу кошелька. Кошельки без баланса и операций
+// до конца суток пропускаются, уже существующие отчеты за day не перезаписываются. Возвращает количество
+// сохраненных отчетов
+func (r *ApiReportRepository) CloseDay(day time.Time) (int, error) {
+	start := day.UTC().Truncate(24 * time.Hour)
+	tag, err := r.db.Exec(context.Background(), `INSERT INTO wallet_daily_reports
+			(wallet_id, day, opening_balance, closing_balance, deposits, withdrawals, deposit_count, withdrawal_count)
+		SELECT w.wallet_id, $1::date,
+			COALESCE(p.closing_balance, o.balance, 0),
+			COALESCE(p.closing_balance, o.balance, 0) + COALESCE(t.deposits, 0) - COALESCE(t.withdrawals, 0),
+			COALESCE(t.deposits, 0), COALESCE(t.withdrawals, 0), t.deposit_count, t.withdrawal_count
+		FROM wallets w
+		LEFT JOIN wallet_daily_reports p ON p.wallet_id = w.wallet_id AND p.day = $1::date - 1
+		CROSS JOIN LATERAL (
+			SELECT SUM(CASE WHEN operation_type = ANY ($4::text[]) THEN -amount ELSE amount END) AS balance
+			FROM wallet_operations
+			WHERE p.wallet_id IS NULL AND wallet_id = w.wallet_id AND created_at < $2) o
+		CROSS JOIN LATERAL (
+			SELECT SUM(amount) FILTER (WHERE operation_type <> ALL ($4::text[])) AS deposits,
+				SUM(amount) FILTER (WHERE operation_type = ANY ($4::text[])) AS withdrawals,
+				COUNT(*) FILTER (WHERE operation_type <> ALL ($4::text[])) AS deposit_count,
+				COUNT(*) FILTER (WHERE operation_type = ANY ($4::text[])) AS withdrawal_count
+			FROM wallet_operations
+			WHERE wallet_id = w.wallet_id AND created_at >= $2 AND created_at < $3) t
+		WHERE p.wallet_id IS NOT NULL OR o.balance IS NOT NULL OR t.deposit_count + t.withdrawal_count > 0
+		ON CONFLICT (wallet_id, day) DO NOTHING`, start, start, start.Add(24*time.Hour), debitOperationTypes)
+	if err != nil {
+		r.logger.Errorf("Error closing day %s: %v", start.Format(time.DateOnly), err)
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// Итоги дневных отчетов за дни с from по to включительно, сгруппированные по дням, неделям или месяцам.
+// Без walletID итоги суммируются по всем кошелькам
+func (r *ApiReportRepository) DailyTotals(walletID string, from, to time.Time, groupBy string) ([]models.ReportPeriod, error) {
+	var wallet *string
+	if walletID != "" {
+		wallet = &walletID
+	}
+	periods := []models.ReportPeriod{}
+	err := execTx(r.db, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		if wallet != nil {
+			if err := requireWalletTx(tx, walletID); err != nil {
+				return err
+			}
+		}
+		rows, err := tx.Query(context.Background(), `SELECT period, COUNT(*), SUM(opening_balance), SUM(closing_balance),
+				SUM(deposits), SUM(withdrawals), SUM(deposit_count)::bigint, SUM(withdrawal_count)::bigint
+			FROM (
+				SELECT wallet_id, GREATEST(date_trunc($1, day::timestamp)::date, $2::date) AS period,
+					(array_agg(opening_balance ORDER BY day))[1] AS opening_balance,
+					(array_agg(closing_balance ORDER BY day DESC))[1] AS closing_balance,
+					SUM(deposits) AS deposits, SUM(withdrawals) AS withdrawals,
+					SUM(deposit_count) AS deposit_count, SUM(withdrawal_count) AS withdrawal_count
+				FROM wallet_daily_reports
+				WHERE day BETWEEN $2::date AND $3::date AND ($4::uuid IS NULL OR wallet_id = $4::uuid)
+				GROUP BY wallet_id, 2) w
+			GROUP BY period
+			ORDER BY period`, groupBy, from, to, wallet)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var (
+				period time.Time
+				p      models.ReportPeriod
+			)
+			if err := rows.Scan(&period, &p.Wallets, scanMoney(&p.OpeningBalance), scanMoney(&p.ClosingBalance), scanMoney(&p.Deposits), scanMoney(&p.Withdrawals),
+				&p.DepositCount, &p.WithdrawalCount); err != nil {
+				return err
+			}
+			p.Period = period.Format(time.DateOnly)
+			periods = append(periods, p)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		if !errors.Is(err, ErrWalletNotFound) {
+			r.logger.Errorf("Error retrieving daily totals: %v", err)
+		}
+		return nil, err
+	}
+	return periods, nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// SQLSTATE ошибок, после которых транзакцию можно безопасно выполнить заново
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+// Повторы транзакции: количество попыток и границы паузы перед повтором
+var (
+	txMaxAttempts = 5
+	txBackoffBase = 5 * time.Millisecond
+	txBackoffMax  = 250 * time.Millisecond
+)
+
+// withTx выполняет fn в транзакции: фиксирует ее при успехе и откатывает при ошибке
+func withTx(db *pgxpool.Pool, fn func(tx pgx.Tx) error) error {
+	return execTx(db, pgx.TxOptions{}, fn)
+}
+
+// runTx выполняет fn в транзакции с уровнем изоляции iso. Если транзакция завершилась сбоем сериализации
+// или взаимоблокировкой, она повторяется целиком с паузой со случайной составляющей, но не больше txMaxAttempts раз.
+// fn может выполниться несколько раз, поэтому состояние, которое она заполняет, должно перезаписываться на каждой попытке
+func runTx(db *pgxpool.Pool, logger *logrus.Logger, name string, iso pgx.TxIsoLevel, fn func(tx pgx.Tx) error) error {
+	return retryTx(logger, name, func() error {
+		return execTx(db, pgx.TxOptions{IsoLevel: iso}, fn)
+	})
+}
+
+// retryTx повторяет attempt, пока он завершается повторяемой ошибкой и попытки не исчерпаны
+func retryTx(logger *logrus.Logger, name string, attempt func() error) error {
+	for n := 1; ; n++ {
+		err := attempt()
+		state, ok := retryableState(err)
+		if !ok {
+			return err
+		}
+		txRetries.Add(state, 1)
+
+		fields := logrus.Fields{"tx": name, "sqlstate": state, "attempt": n}
+		if n >= txMaxAttempts {
+			txRetries.Add("exhausted", 1)
+			logger.WithFields(fields).Errorf("Transaction failed after %d attempts: %v", n, err)
+			return err
+		}
+		delay := txBackoff(n)
+		logger.WithFields(fields).Warnf("Transaction conflict, retrying in %s: %v", delay, err)
+		time.Sleep(delay)
+	}
+}
+
+// execTx выполняет fn в одной транзакции
+func execTx(db *pgxpool.Pool, options pgx.TxOptions, fn func(tx pgx.Tx) error) error {
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// retryableState возвращает SQLSTATE ошибки, если после нее транзакцию можно повторить
+func retryableState(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return "", false
+	}
+	switch pgErr.Code {
+	case sqlStateSerializationFailure, sqlStateDeadlockDetected:
+		return pgErr.Code, true
+	}
+	return "", false
+}
+
+// txBackoff возвращает случайную паузу перед попыткой n+1, верхняя граница которой удваивается с каждой попыткой,
+// чтобы конфликтующие транзакции не повторялись одновременно
+func txBackoff(n int) time.Duration {
+	limit := txBackoffBase << (n - 1)
+	if limit > txBackoffMax || limit <= 0 {
+		limit = txBackoffMax
+	}
+	return time.Duration(rand.Int63n(int64(limit))) + 1
+}
+
+// isPgError сообщает, что PostgreSQL завершил запрос ошибкой с кодом code
+func isPgError(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRetryTx проверяет, что транзакция повторяется только после сбоя сериализации или взаимоблокировки
+// и не больше txMaxAttempts раз
+func TestRetryTx(t *testing.T) {
+	defer func(base, max time.Duration) { txBackoffBase, txBackoffMax = base, max }(txBackoffBase, txBackoffMax)
+	txBackoffBase, txBackoffMax = time.Microsecond, time.Microsecond
+
+	serialization := &pgconn.PgError{Code: sqlStateSerializationFailure}
+	deadlock := &pgconn.PgError{Code: sqlStateDeadlockDetected}
+	tests := []struct {
+		name          string  // Название теста
+		errs          []error // Результаты попыток по порядку; после них попытки завершаются успешно
+		expectedErr   error   // Ожидаемая ошибка
+		expectedCalls int     // Ожидаемое количество попыток
+	}{
+		{name: "Success", expectedCalls: 1},
+		{name: "Serialization Failure Then Success", errs: []error{serialization}, expectedCalls: 2},
+		{name: "Deadlock Then Success", errs: []error{deadlock, deadlock}, expectedCalls: 3},
+		{name: "Not Retryable", errs: []error{ErrInsufficientFunds}, expectedErr: ErrInsufficientFunds, expectedCalls: 1},
+		{name: "Unique Violation Not Retryable", errs: []error{&pgconn.PgError{Code: "23505"}}, expectedErr: &pgconn.PgError{Code: "23505"}, expectedCalls: 1},
+		{
+			name:          "Attempts Exhausted",
+			errs:          []error{serialization, serialization, serialization, serialization, serialization, serialization},
+			expectedErr:   serialization,
+			expectedCalls: txMaxAttempts,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetOutput(io.Discard)
+
+			calls := 0
+			err := retryTx(logger, "test", func() error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expectedCalls, calls)
+		})
+	}
+}
+
+// TestRetryTx_Metrics проверяет учет повторов по SQLSTATE и исчерпанных попыток
+func TestRetryTx_Metrics(t *testing.T) {
+	defer func(base, max time.Duration) { txBackoffBase, txBackoffMax = base, max }(txBackoffBase, txBackoffMax)
+	txBackoffBase, txBackoffMax = time.Microsecond, time.Microsecond
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	counter := func(key string) int64 {
+		if v, ok := txRetries.Get(key).(interface{ Value() int64 }); ok {
+			return v.Value()
+		}
+		return 0
+	}
+	deadlocks, exhausted := counter(sqlStateDeadlockDetected), counter("exhausted")
+
+	err := retryTx(logger, "test", func() error { return &pgconn.PgError{Code: sqlStateDeadlockDetected} })
+
+	assert.True(t, errors.As(err, new(*pgconn.PgError)))
+	assert.Equal(t, deadlocks+int64(txMaxAttempts), counter(sqlStateDeadlockDetected))
+	assert.Equal(t, exhausted+1, counter("exhausted"))
+}
+
+// TestTxBackoff проверяет, что пауза положительна и не превышает удвоенную для попытки границу и txBackoffMax
+func TestTxBackoff(t *testing.T) {
+	for n := 1; n <= 20; n++ {
+		limit := txBackoffBase << (n - 1)
+		if limit > txBackoffMax || limit <= 0 {
+			limit = txBackoffMax
+		}
+		for i := 0; i < 100; i++ {
+			delay := txBackoff(n)
+			assert.Greater(t, delay, time.Duration(0))
+			assert.LessOrEqual(t, delay, limit)
+		}
+	}
+}
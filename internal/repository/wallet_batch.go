@@ -0,0 +1,377 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+var errSameWallet = errors.New("cannot transfer to the same wallet")
+
+// Выполнение пакета операций. Кошельки пакета блокируются одним запросом, операции проверяются
+// по порядку, а изменения балансов, журнал, уведомления и outbox записываются многострочными запросами.
+// В атомарном режиме ошибка любой операции отменяет весь пакет и возвращается ErrBatchRejected
+func (r *ApiWalletRepository) ApplyBatch(items []models.BatchItem, atomic bool) ([]models.BatchItemResult, error) {
+	var results []models.BatchItemResult
+	err := runTx(r.db, r.logger, "apply batch", pgx.ReadCommitted, func(tx pgx.Tx) error {
+		wallets, err := lockWallets(tx, batchWalletIDs(items))
+		if err != nil {
+			return err
+		}
+		if _, err := foldPending(tx, wallets, 0); err != nil {
+			return err
+		}
+
+		plan := planBatch(items, wallets)
+		plan.origin = r.origin
+		results = plan.results
+		if atomic && plan.failed > 0 {
+			plan.rollBack()
+			return ErrBatchRejected
+		}
+		if len(plan.operations) == 0 {
+			return nil
+		}
+		return plan.apply(tx)
+	})
+	if err != nil && !errors.Is(err, ErrBatchRejected) {
+		r.logger.Errorf("Error applying batch of %d operations: %v", len(items), err)
+		return nil, err
+	}
+	if err != nil {
+		r.logger.Warnf("Rejected batch of %d operations", len(items))
+		return results, err
+	}
+	r.logger.Infof("Applied batch of %d operations", len(items))
+	return results, nil
+}
+
+// batchWallet хранит состояние кошелька в копейках по мере проверки операций пакета
+type batchWallet struct {
+	id      string
+	cents   int64
+	version int64
+	frozen  bool
+	touched bool
+}
+
+// lockWallets блокирует кошельки пакета в порядке их ID, чтобы параллельные пакеты не взаимоблокировались.
+// FOR NO KEY UPDATE не мешает записи пополнений в буфер, которой нужна только блокировка по внешнему ключу
+func lockWallets(tx pgx.Tx, walletIDs []string) (map[string]*batchWallet, error) {
+	rows, err := tx.Query(context.Background(), `SELECT wallet_id, balance, version, frozen FROM wallets
+		WHERE wallet_id = ANY ($1::uuid[])
+		ORDER BY wallet_id
+		FOR NO KEY UPDATE`, walletIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	wallets := make(map[string]*batchWallet, len(walletIDs))
+	for rows.Next() {
+		var w batchWallet
+		if err := rows.Scan(&w.id, scanCents(&w.cents), &w.version, &w.frozen); err != nil {
+			return nil, err
+		}
+		wallets[w.id] = &w
+	}
+	return wallets, rows.Err()
+}
+
+// batchWalletIDs возвращает ID всех кошельков пакета без повторов
+func batchWalletIDs(items []models.BatchItem) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, item := range items {
+		for _, id := range []string{item.WalletID, item.ToWalletID} {
+			id = strings.ToLower(id)
+			if id != "" && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// batchPlan — результат проверки пакета: записи журнала и события, которые нужно сохранить
+type batchPlan struct {
+	results    []models.BatchItemResult
+	failed     int
+	wallets    map[string]*batchWallet
+	operations []*models.Operation
+	keys       []string // Ключи идемпотентности записей журнала; nil, если ключей нет
+	events     []batchEvent
+	origin     models.Origin   // Источник операций для журнала аудита
+	origins    []models.Origin // Источники отдельных записей журнала; nil, если у всех источник origin
+
+	allowFrozen bool // Не отклонять операции с замороженными кошельками
+}
+
+// batchEvent — событие outbox, содержимое которого известно после записи операций в журнал
+type batchEvent struct {
+	eventType string
+	walletID  string
+	payload   func() interface{}
+}
+
+// planBatch проверяет операции пакета по порядку на заблокированных кошельках.
+// Операция, которую нельзя выполнить, не меняет состояние кошельков
+func planBatch(items []models.BatchItem, wallets map[string]*batchWallet) *batchPlan {
+	return planItems(&batchPlan{wallets: wallets}, items)
+}
+
+// planItems проверяет операции пакета и добавляет выполнимые в план
+func planItems(plan *batchPlan, items []models.BatchItem) *batchPlan {
+	plan.results = make([]models.BatchItemResult, len(items))
+	for i, item := range items {
+		plan.results[i] = models.BatchItemResult{Index: i, Status: models.BatchItemApplied}
+		if err := plan.add(i, item); err != nil {
+			plan.results[i].Status = models.BatchItemFailed
+			plan.results[i].Error = err.Error()
+			plan.failed++
+		}
+	}
+	return plan
+}
+
+// add проверяет операцию пакета и, если она выполнима, добавляет ее записи журнала в план
+func (p *batchPlan) add(index int, item models.BatchItem) error {
+	amount := toCents(item.Amount)
+	from, ok := p.wallets[strings.ToLower(item.WalletID)]
+	if !ok {
+		return ErrWalletNotFound
+	}
+	if from.frozen && !p.allowFrozen {
+		return ErrWalletFrozen
+	}
+
+	switch item.OperationType {
+	case models.OperationDeposit:
+		op := p.change(index, from, models.OperationDeposit, amount)
+		p.events = append(p.events, batchEvent{models.EventWalletDeposited, from.id, func() interface{} { return *op }})
+	case models.OperationWithdraw:
+		if from.cents < amount {
+			return ErrInsufficientFunds
+		}
+		op := p.change(index, from, models.OperationWithdraw, -amount)
+		p.events = append(p.events, batchEvent{models.EventWalletWithdrawn, from.id, func() interface{} { return *op }})
+	case models.OperationTransfer:
+		to, ok := p.wallets[strings.ToLower(item.ToWalletID)]
+		if !ok {
+			return ErrWalletNotFound
+		}
+		if to == from {
+			return errSameWallet
+		}
+		if to.frozen && !p.allowFrozen {
+			return ErrWalletFrozen
+		}
+		if from.cents < amount {
+			return ErrInsufficientFunds
+		}
+		debit := p.change(index, from, models.OperationTransferOut, -amount)
+		credit := p.change(index, to, models.OperationTransferIn, amount)
+		p.events = append(p.events, batchEvent{models.EventTransferCompleted, from.id, func() interface{} {
+			return models.Transfer{FromWalletID: from.id, ToWalletID: to.id, Amount: item.Amount, Debit: *debit, Credit: *credit}
+		}})
+	default:
+		return errors.New("invalid operation type")
+	}
+	return nil
+}
+
+// change изменяет баланс кошелька в плане и добавляет запись журнала с новым балансом и версией
+func (p *batchPlan) change(index int, w *batchWallet, opType string, delta int64) *models.Operation {
+	w.cents += delta
+	w.version++
+	w.touched = true
+
+	op := &models.Operation{
+		WalletID:     w.id,
+		Type:         opType,
+		Amount:       fromCents(abs(delta)),
+		BalanceAfter: fromCents(w.cents),
+		Version:      w.version,
+	}
+	p.operations = append(p.operations, op)
+	p.results[index].Operations = append(p.results[index].Operations, models.Operation{})
+	return op
+}
+
+// rollBack помечает операции отклоненного пакета как невыполненные
+func (p *batchPlan) rollBack() {
+	for i := range p.results {
+		if p.results[i].Status == models.BatchItemApplied {
+			p.results[i].Status = models.BatchItemRolledBack
+		}
+		p.results[i].Operations = nil
+	}
+}
+
+// apply записывает план в базу: по одному запросу на балансы, журнал, уведомления, outbox и журнал аудита
+func (p *batchPlan) apply(tx pgx.Tx) error {
+	var (
+		ids, balances []string
+		versions      []int64
+	)
+	for _, w := range p.wallets {
+		if w.touched {
+			ids = append(ids, w.id)
+			balances = append(balances, formatCents(w.cents))
+			versions = append(versions, w.version)
+		}
+	}
+	_, err := tx.Exec(context.Background(), `UPDATE wallets w SET balance = d.balance, version = d.version
+		FROM unnest($1::uuid[], $2::numeric[], $3::bigint[]) AS d (wallet_id, balance, version)
+		WHERE w.wallet_id = d.wallet_id`, ids, balances, versions)
+	if err != nil {
+		return err
+	}
+
+	if err := p.insertOperations(tx); err != nil {
+		return err
+	}
+	p.fillResults()
+
+	notifications := make([]string, 0, len(p.operations))
+	for _, op := range p.operations {
+		payload, err := json.Marshal(op)
+		if err != nil {
+			return err
+		}
+		notifications = append(notifications, string(payload))
+	}
+	_, err = tx.Exec(context.Background(), `SELECT pg_notify($1, n.payload)
+		FROM unnest($2::text[]) WITH ORDINALITY AS n (payload, ord)
+		ORDER BY n.ord`, OperationsChannel, notifications)
+	if err != nil {
+		return err
+	}
+
+	if err := p.insertEvents(tx); err != nil {
+		return err
+	}
+
+	entries := make([]*models.AuditEntry, len(p.operations))
+	for i, op := range p.operations {
+		origin := p.origin
+		if p.origins != nil {
+			origin = p.origins[i]
+		}
+		entries[i] = operationAudit(origin, *op)
+	}
+	return appendAudit(tx, entries...)
+}
+
+// insertOperations добавляет записи журнала в порядке выполнения операций и заполняет их ID и время.
+// Версии операций одного кошелька различаются, поэтому записи сопоставляются по кошельку и версии
+func (p *batchPlan) insertOperations(tx pgx.Tx) error {
+	var (
+		walletIDs, types, amounts, balances, keys []string
+		versions                                  []int64
+	)
+	for i, op := range p.operations {
+		walletIDs = append(walletIDs, op.WalletID)
+		types = append(types, op.Type)
+		amounts = append(amounts, formatCents(toCents(op.Amount)))
+		balances = append(balances, formatCents(toCents(op.BalanceAfter)))
+		versions = append(versions, op.Version)
+		key := ""
+		if p.keys != nil {
+			key = p.keys[i]
+		}
+		keys = append(keys, key)
+	}
+	rows, err := tx.Query(context.Background(), `INSERT INTO wallet_operations (wallet_id, operation_type, amount, balance_after, wallet_version, idempotency_key)
+		SELECT o.wallet_id, o.operation_type, o.amount, o.balance_after, o.wallet_version, NULLIF(o.idempotency_key, '')
+		FROM unnest($1::uuid[], $2::text[], $3::numeric[], $4::numeric[], $5::bigint[], $6::text[])
+			WITH ORDINALITY AS o (wallet_id, operation_type, amount, balance_after, wallet_version, idempotency_key, ord)
+		ORDER BY o.ord
+		RETURNING operation_id, wallet_id, wallet_version, created_at`,
+		walletIDs, types, amounts, balances, versions, keys)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	byKey := make(map[string]*models.Operation, len(p.operations))
+	for _, op := range p.operations {
+		byKey[op.WalletID+"/"+strconv.FormatInt(op.Version, 10)] = op
+	}
+	for rows.Next() {
+		var (
+			stored   models.Operation
+			walletID string
+		)
+		if err := rows.Scan(&stored.ID, &walletID, &stored.Version, &stored.CreatedAt); err != nil {
+			return err
+		}
+		if op, ok := byKey[walletID+"/"+strconv.FormatInt(stored.Version, 10)]; ok {
+			op.ID, op.CreatedAt = stored.ID, stored.CreatedAt
+		}
+	}
+	return rows.Err()
+}
+
+// fillResults копирует сохраненные записи журнала в результаты операций пакета
+func (p *batchPlan) fillResults() {
+	next := 0
+	for i := range p.results {
+		for j := range p.results[i].Operations {
+			p.results[i].Operations[j] = *p.operations[next]
+			next++
+		}
+	}
+}
+
+// insertEvents сохраняет события пакета в outbox в порядке выполнения операций
+func (p *batchPlan) insertEvents(tx pgx.Tx) error {
+	var types, walletIDs, payloads []string
+	for _, event := range p.events {
+		data, err := json.Marshal(event.payload())
+		if err != nil {
+			return err
+		}
+		types = append(types, event.eventType)
+		walletIDs = append(walletIDs, event.walletID)
+		payloads = append(payloads, string(data))
+	}
+	_, err := tx.Exec(context.Background(), `INSERT INTO outbox_events (event_type, wallet_id, payload)
+		SELECT e.event_type, e.wallet_id, e.payload::jsonb
+		FROM unnest($1::text[], $2::uuid[], $3::text[]) WITH ORDINALITY AS e (event_type, wallet_id, payload, ord)
+		ORDER BY e.ord`, types, walletIDs, payloads)
+	return err
+}
+
+// toCents переводит сумму в копейки. Суммы хранятся в NUMERIC(20, 2), поэтому точность не теряется
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func fromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
+
+// formatCents представляет сумму в копейках строкой для приведения к NUMERIC без ошибок округления
+func formatCents(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
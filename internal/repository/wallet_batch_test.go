@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	walletA = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	walletB = "9b2d7c1e-0f4a-4e8b-a1c3-5d6e7f809a1b"
+	missing = "00000000-0000-4000-8000-000000000000"
+)
+
+// testWallets возвращает заблокированные кошельки для проверки плана пакета
+func testWallets() map[string]*batchWallet {
+	return map[string]*batchWallet{
+		walletA: {id: walletA, cents: 10000, version: 3},
+		walletB: {id: walletB, cents: 0, version: 1},
+	}
+}
+
+// TestPlanBatch проверяет последовательную проверку операций пакета на заблокированных кошельках
+func TestPlanBatch(t *testing.T) {
+	tests := []struct {
+		name             string             // Название теста
+		items            []models.BatchItem // Операции пакета
+		expectedStatuses []string           // Ожидаемые статусы операций
+		expectedErrors   []string           // Ожидаемые ошибки операций
+		expectedBalances map[string]int64   // Ожидаемые балансы кошельков в копейках
+		expectedVersions map[string]int64   // Ожидаемые версии кошельков
+		expectedOps      []models.Operation // Ожидаемые записи журнала
+		expectedEvents   []string           // Ожидаемые типы событий
+	}{
+		{
+			// Операции выполняются по порядку: перевод возможен благодаря предшествующему пополнению
+			name: "Sequential Operations",
+			items: []models.BatchItem{
+				{OperationType: models.OperationDeposit, WalletID: walletA, Amount: 0.1},
+				{OperationType: models.OperationTransfer, WalletID: walletA, ToWalletID: walletB, Amount: 100.1},
+				{OperationType: models.OperationWithdraw, WalletID: walletB, Amount: 0.2},
+			},
+			expectedStatuses: []string{models.BatchItemApplied, models.BatchItemApplied, models.BatchItemApplied},
+			expectedErrors:   []string{"", "", ""},
+			expectedBalances: map[string]int64{walletA: 0, walletB: 9990},
+			expectedVersions: map[string]int64{walletA: 5, walletB: 3},
+			expectedOps: []models.Operation{
+				{WalletID: walletA, Type: models.OperationDeposit, Amount: 0.1, BalanceAfter: 100.1, Version: 4},
+				{WalletID: walletA, Type: models.OperationTransferOut, Amount: 100.1, BalanceAfter: 0, Version: 5},
+				{WalletID: walletB, Type: models.OperationTransferIn, Amount: 100.1, BalanceAfter: 100.1, Version: 2},
+				{WalletID: walletB, Type: models.OperationWithdraw, Amount: 0.2, BalanceAfter: 99.9, Version: 3},
+			},
+			expectedEvents: []string{models.EventWalletDeposited, models.EventTransferCompleted, models.EventWalletWithdrawn},
+		},
+		{
+			// Невыполнимые операции не меняют состояние кошельков
+			name: "Failed Operations",
+			items: []models.BatchItem{
+				{OperationType: models.OperationWithdraw, WalletID: walletB, Amount: 1},
+				{OperationType: models.OperationDeposit, WalletID: missing, Amount: 1},
+				{OperationType: models.OperationTransfer, WalletID: walletA, ToWalletID: missing, Amount: 1},
+				{OperationType: models.OperationTransfer, WalletID: walletA, ToWalletID: walletA, Amount: 1},
+				{OperationType: models.OperationWithdraw, WalletID: walletA, Amount: 100},
+			},
+			expectedStatuses: []string{
+				models.BatchItemFailed, models.BatchItemFailed, models.BatchItemFailed, models.BatchItemFailed, models.BatchItemApplied,
+			},
+			expectedErrors: []string{
+				ErrInsufficientFunds.Error(), ErrWalletNotFound.Error(), ErrWalletNotFound.Error(), errSameWallet.Error(), "",
+			},
+			expectedBalances: map[string]int64{walletA: 0, walletB: 0},
+			expectedVersions: map[string]int64{walletA: 4, walletB: 1},
+			expectedOps: []models.Operation{
+				{WalletID: walletA, Type: models.OperationWithdraw, Amount: 100, BalanceAfter: 0, Version: 4},
+			},
+			expectedEvents: []string{models.EventWalletWithdrawn},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wallets := testWallets()
+			plan := planBatch(tt.items, wallets)
+
+			for i, result := range plan.results {
+				assert.Equal(t, i, result.Index)
+				assert.Equal(t, tt.expectedStatuses[i], result.Status)
+				assert.Equal(t, tt.expectedErrors[i], result.Error)
+			}
+			for id, cents := range tt.expectedBalances {
+				assert.Equal(t, cents, wallets[id].cents, id)
+				assert.Equal(t, tt.expectedVersions[id], wallets[id].version, id)
+			}
+
+			ops := make([]models.Operation, 0, len(plan.operations))
+			for _, op := range plan.operations {
+				ops = append(ops, *op)
+			}
+			assert.Equal(t, tt.expectedOps, ops)
+
+			events := make([]string, 0, len(plan.events))
+			for _, event := range plan.events {
+				events = append(events, event.eventType)
+			}
+			assert.Equal(t, tt.expectedEvents, events)
+		})
+	}
+}
+
+// TestPlanBatch_RollBack проверяет результаты отклоненного атомарного пакета
+func TestPlanBatch_RollBack(t *testing.T) {
+	plan := planBatch([]models.BatchItem{
+		{OperationType: models.OperationDeposit, WalletID: walletA, Amount: 1},
+		{OperationType: models.OperationWithdraw, WalletID: walletB, Amount: 1},
+	}, testWallets())
+	plan.rollBack()
+
+	assert.Equal(t, 1, plan.failed)
+	assert.Equal(t, models.BatchItemRolledBack, plan.results[0].Status)
+	assert.Nil(t, plan.results[0].Operations)
+	assert.Equal(t, models.BatchItemFailed, plan.results[1].Status)
+}
+
+// TestPlanBatch_Frozen проверяет, что операции с замороженным кошельком отклоняются,
+// а перенос пополнений, принятых до заморозки, выполняется
+func TestPlanBatch_Frozen(t *testing.T) {
+	items := []models.BatchItem{
+		{OperationType: models.OperationDeposit, WalletID: walletB, Amount: 1},
+		{OperationType: models.OperationTransfer, WalletID: walletA, ToWalletID: walletB, Amount: 1},
+		{OperationType: models.OperationWithdraw, WalletID: walletA, Amount: 1},
+	}
+
+	wallets := testWallets()
+	wallets[walletB].frozen = true
+	plan := planBatch(items, wallets)
+	assert.Equal(t, 2, plan.failed)
+	assert.Equal(t, ErrWalletFrozen.Error(), plan.results[0].Error)
+	assert.Equal(t, ErrWalletFrozen.Error(), plan.results[1].Error)
+	assert.Equal(t, models.BatchItemApplied, plan.results[2].Status)
+
+	wallets = testWallets()
+	wallets[walletB].frozen = true
+	folded := planFold([]pendingDeposit{{id: 1, walletID: walletB, amount: 1}}, wallets)
+	assert.Zero(t, folded.failed)
+	assert.Equal(t, int64(100), wallets[walletB].cents)
+}
+
+// TestPlanBatch_FillResults проверяет, что результаты операций получают сохраненные записи журнала
+func TestPlanBatch_FillResults(t *testing.T) {
+	plan := planBatch([]models.BatchItem{
+		{OperationType: models.OperationWithdraw, WalletID: walletB, Amount: 1},
+		{OperationType: models.OperationTransfer, WalletID: walletA, ToWalletID: walletB, Amount: 1},
+	}, testWallets())
+	for i, op := range plan.operations {
+		op.ID = int64(i + 10)
+	}
+	plan.fillResults()
+
+	assert.Empty(t, plan.results[0].Operations)
+	assert.Equal(t, []int64{10, 11}, []int64{plan.results[1].Operations[0].ID, plan.results[1].Operations[1].ID})
+	assert.Equal(t, models.OperationTransferIn, plan.results[1].Operations[1].Type)
+}
+
+// TestFormatCents проверяет представление сумм для приведения к NUMERIC
+func TestFormatCents(t *testing.T) {
+	assert.Equal(t, "0.05", formatCents(5))
+	assert.Equal(t, "100.10", formatCents(10010))
+	assert.Equal(t, "-1.50", formatCents(-150))
+	assert.Equal(t, int64(29), toCents(0.29))
+}
@@ -0,0 +1,383 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/db"
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/pkg/migrator"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// walletFixture создает репозиторий с новыми кошельками с указанными балансами и возвращает их ID
+type walletFixture func(t *testing.T, balances ...float64) (WalletRepository, []string)
+
+// TestWalletRepositoryConformance проверяет, что реализации WalletRepository ведут себя одинаково.
+// Репозиторий PostgreSQL проверяется на базе из WALLET_TEST_DSN с примененными миграциями, без нее проверка пропускается
+func TestWalletRepositoryConformance(t *testing.T) {
+	fixtures := []struct {
+		name    string        // Название реализации
+		fixture walletFixture // Создание репозитория и кошельков
+	}{
+		{"Memory", memoryFixture},
+		{"SQLite", sqliteFixture},
+		{"Postgres", postgresFixture},
+	}
+
+	for _, f := range fixtures {
+		t.Run(f.name, func(t *testing.T) {
+			testWalletRepository(t, f.fixture)
+		})
+	}
+}
+
+func testWalletRepository(t *testing.T, newRepo walletFixture) {
+	t.Run("Wallet Not Found", func(t *testing.T) {
+		repo, _ := newRepo(t)
+		walletID := newWalletID()
+
+		_, err := repo.GetWallet(walletID)
+		assert.ErrorIs(t, err, ErrWalletNotFound)
+		_, err = repo.GetWalletBalance(walletID)
+		assert.ErrorIs(t, err, ErrWalletNotFound)
+		assert.ErrorIs(t, repo.Deposit(walletID, 1), ErrWalletNotFound)
+		assert.ErrorIs(t, repo.Withdraw(walletID, 1), ErrWalletNotFound)
+		_, err = repo.DepositIfVersion(walletID, 1, 1)
+		assert.ErrorIs(t, err, ErrWalletNotFound)
+		_, err = repo.DepositIdempotent(walletID, 1, "conformance:"+walletID)
+		assert.ErrorIs(t, err, ErrWalletNotFound)
+
+		operations, err := repo.GetOperationsAfter(walletID, 0, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, operations)
+	})
+
+	t.Run("Deposit And Withdraw", func(t *testing.T) {
+		repo, ids := newRepo(t, 100)
+		before := mustGetWallet(t, repo, ids[0])
+
+		require.NoError(t, repo.Deposit(ids[0], 50.5))
+		require.NoError(t, repo.Withdraw(ids[0], 20.25))
+
+		wallet := mustGetWallet(t, repo, ids[0])
+		assert.Equal(t, 130.25, wallet.Balance)
+		assert.Equal(t, before.Version+2, wallet.Version)
+
+		operations, err := repo.GetOperationsAfter(ids[0], 0, 10)
+		require.NoError(t, err)
+		require.Len(t, operations, 2)
+		assert.Equal(t, models.OperationDeposit, operations[0].Type)
+		assert.Equal(t, 50.5, operations[0].Amount)
+		assert.Equal(t, 150.5, operations[0].BalanceAfter)
+		assert.Equal(t, before.Version+1, operations[0].Version)
+		assert.Equal(t, models.OperationWithdraw, operations[1].Type)
+		assert.Equal(t, 130.25, operations[1].BalanceAfter)
+		assert.Greater(t, operations[1].ID, operations[0].ID)
+		assert.Equal(t, ids[0], operations[0].WalletID)
+	})
+
+	t.Run("Insufficient Funds", func(t *testing.T) {
+		repo, ids := newRepo(t, 10)
+		before := mustGetWallet(t, repo, ids[0])
+
+		assert.ErrorIs(t, repo.Withdraw(ids[0], 10.01), ErrInsufficientFunds)
+		assert.Equal(t, before, mustGetWallet(t, repo, ids[0]))
+		operations, err := repo.GetOperationsAfter(ids[0], 0, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, operations)
+
+		// Баланс может стать нулевым
+		assert.NoError(t, repo.Withdraw(ids[0], 10))
+		assert.Equal(t, 0.0, mustGetWallet(t, repo, ids[0]).Balance)
+	})
+
+	t.Run("Conditional Operations", func(t *testing.T) {
+		repo, ids := newRepo(t, 10)
+		version := mustGetWallet(t, repo, ids[0]).Version
+
+		_, err := repo.DepositIfVersion(ids[0], 5, version+1)
+		assert.ErrorIs(t, err, ErrVersionMismatch)
+
+		newVersion, err := repo.DepositIfVersion(ids[0], 5, version)
+		require.NoError(t, err)
+		assert.Equal(t, version+1, newVersion)
+
+		// Несовпадение версии проверяется раньше достаточности средств
+		_, err = repo.WithdrawIfVersion(ids[0], 1000, version)
+		assert.ErrorIs(t, err, ErrVersionMismatch)
+		_, err = repo.WithdrawIfVersion(ids[0], 1000, newVersion)
+		assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+		newVersion, err = repo.WithdrawIfVersion(ids[0], 15, newVersion)
+		require.NoError(t, err)
+		assert.Equal(t, version+2, newVersion)
+		assert.Equal(t, 0.0, mustGetWallet(t, repo, ids[0]).Balance)
+	})
+
+	t.Run("Frozen Wallet", func(t *testing.T) {
+		repo, ids := newRepo(t, 100, 50)
+		freezeWallet(t, repo, ids[0])
+		before := mustGetWallet(t, repo, ids[0])
+
+		assert.ErrorIs(t, repo.Deposit(ids[0], 1), ErrWalletFrozen)
+		assert.ErrorIs(t, repo.Withdraw(ids[0], 1), ErrWalletFrozen)
+		// Заморозка проверяется раньше версии
+		_, err := repo.WithdrawIfVersion(ids[0], 1, before.Version+1)
+		assert.ErrorIs(t, err, ErrWalletFrozen)
+		_, err = repo.DepositIdempotent(ids[0], 1, "conformance:"+ids[0])
+		assert.ErrorIs(t, err, ErrWalletFrozen)
+
+		results, err := repo.ApplyBatch([]models.BatchItem{
+			{OperationType: models.OperationTransfer, WalletID: ids[1], ToWalletID: ids[0], Amount: 10},
+			{OperationType: models.OperationWithdraw, WalletID: ids[0], Amount: 10},
+		}, false)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, ErrWalletFrozen.Error(), results[0].Error)
+		assert.Equal(t, ErrWalletFrozen.Error(), results[1].Error)
+
+		assert.Equal(t, before, mustGetWallet(t, repo, ids[0]))
+		operations, err := repo.GetOperationsAfter(ids[0], 0, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, operations)
+	})
+
+	t.Run("Idempotent Deposit", func(t *testing.T) {
+		repo, ids := newRepo(t, 0)
+		key := "conformance:" + ids[0]
+
+		applied, err := repo.DepositIdempotent(ids[0], 10, key)
+		require.NoError(t, err)
+		assert.True(t, applied)
+		applied, err = repo.DepositIdempotent(ids[0], 10, key)
+		require.NoError(t, err)
+		assert.False(t, applied)
+
+		assert.Equal(t, 10.0, mustGetWallet(t, repo, ids[0]).Balance)
+	})
+
+	t.Run("Operations Paging", func(t *testing.T) {
+		repo, ids := newRepo(t, 0, 0)
+		for i := 0; i < 5; i++ {
+			require.NoError(t, repo.Deposit(ids[0], float64(i+1)))
+			require.NoError(t, repo.Deposit(ids[1], 1))
+		}
+
+		first, err := repo.GetOperationsAfter(ids[0], 0, 2)
+		require.NoError(t, err)
+		require.Len(t, first, 2)
+		rest, err := repo.GetOperationsAfter(ids[0], first[1].ID, 10)
+		require.NoError(t, err)
+		require.Len(t, rest, 3)
+
+		var amounts []float64
+		for _, op := range append(first, rest...) {
+			assert.Equal(t, ids[0], op.WalletID)
+			amounts = append(amounts, op.Amount)
+		}
+		assert.Equal(t, []float64{1, 2, 3, 4, 5}, amounts)
+	})
+
+	t.Run("Atomic Batch Rejected", func(t *testing.T) {
+		repo, ids := newRepo(t, 100, 0)
+		before := []models.Wallet{mustGetWallet(t, repo, ids[0]), mustGetWallet(t, repo, ids[1])}
+
+		results, err := repo.ApplyBatch([]models.BatchItem{
+			{OperationType: models.OperationDeposit, WalletID: ids[0], Amount: 10},
+			{OperationType: models.OperationWithdraw, WalletID: ids[1], Amount: 5},
+		}, true)
+
+		assert.ErrorIs(t, err, ErrBatchRejected)
+		require.Len(t, results, 2)
+		assert.Equal(t, models.BatchItemRolledBack, results[0].Status)
+		assert.Equal(t, models.BatchItemFailed, results[1].Status)
+		assert.Equal(t, ErrInsufficientFunds.Error(), results[1].Error)
+		assert.Equal(t, before[0], mustGetWallet(t, repo, ids[0]))
+		assert.Equal(t, before[1], mustGetWallet(t, repo, ids[1]))
+	})
+
+	t.Run("Best Effort Batch", func(t *testing.T) {
+		repo, ids := newRepo(t, 100, 0)
+		versions := []int64{mustGetWallet(t, repo, ids[0]).Version, mustGetWallet(t, repo, ids[1]).Version}
+
+		results, err := repo.ApplyBatch([]models.BatchItem{
+			{OperationType: models.OperationTransfer, WalletID: ids[0], ToWalletID: ids[1], Amount: 30},
+			{OperationType: models.OperationWithdraw, WalletID: ids[1], Amount: 100},
+			{OperationType: models.OperationDeposit, WalletID: newWalletID(), Amount: 1},
+			{OperationType: models.OperationTransfer, WalletID: ids[0], ToWalletID: ids[0], Amount: 1},
+		}, false)
+
+		require.NoError(t, err)
+		require.Len(t, results, 4)
+		assert.Equal(t, models.BatchItemApplied, results[0].Status)
+		assert.Equal(t, ErrInsufficientFunds.Error(), results[1].Error)
+		assert.Equal(t, ErrWalletNotFound.Error(), results[2].Error)
+		assert.Equal(t, errSameWallet.Error(), results[3].Error)
+
+		require.Len(t, results[0].Operations, 2)
+		debit, credit := results[0].Operations[0], results[0].Operations[1]
+		assert.Equal(t, models.OperationTransferOut, debit.Type)
+		assert.Equal(t, 70.0, debit.BalanceAfter)
+		assert.Equal(t, models.OperationTransferIn, credit.Type)
+		assert.Equal(t, 30.0, credit.BalanceAfter)
+		assert.NotZero(t, debit.ID)
+		assert.NotZero(t, credit.ID)
+
+		assert.Equal(t, models.Wallet{ID: ids[0], Balance: 70, Version: versions[0] + 1}, mustGetWallet(t, repo, ids[0]))
+		assert.Equal(t, models.Wallet{ID: ids[1], Balance: 30, Version: versions[1] + 1}, mustGetWallet(t, repo, ids[1]))
+	})
+
+	t.Run("Concurrent Deposits", func(t *testing.T) {
+		repo, ids := newRepo(t, 0)
+		version := mustGetWallet(t, repo, ids[0]).Version
+
+		var wg sync.WaitGroup
+		for g := 0; g < 10; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 10; i++ {
+					assert.NoError(t, repo.Deposit(ids[0], 1))
+				}
+			}()
+		}
+		wg.Wait()
+
+		wallet := mustGetWallet(t, repo, ids[0])
+		assert.Equal(t, 100.0, wallet.Balance)
+		assert.Equal(t, version+100, wallet.Version)
+
+		// Каждая операция получает свою версию и баланс после нее
+		operations, err := repo.GetOperationsAfter(ids[0], 0, 1000)
+		require.NoError(t, err)
+		require.Len(t, operations, 100)
+		for i, op := range operations {
+			assert.Equal(t, version+int64(i)+1, op.Version)
+			assert.Equal(t, float64(i+1), op.BalanceAfter)
+		}
+	})
+
+	t.Run("Concurrent Withdrawals", func(t *testing.T) {
+		repo, ids := newRepo(t, 50)
+
+		var (
+			wg        sync.WaitGroup
+			mu        sync.Mutex
+			succeeded int
+		)
+		for g := 0; g < 100; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := repo.Withdraw(ids[0], 1)
+				if err != nil {
+					assert.ErrorIs(t, err, ErrInsufficientFunds)
+					return
+				}
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 50, succeeded)
+		assert.Equal(t, 0.0, mustGetWallet(t, repo, ids[0]).Balance)
+	})
+}
+
+// freezeWallet замораживает кошелек средствами реализации. В схеме SQLite заморозки нет, и проверка пропускается
+func freezeWallet(t *testing.T, repo WalletRepository, walletID string) {
+	switch r := repo.(type) {
+	case *MemoryWalletRepository:
+		require.NoError(t, r.SetFrozen(walletID, true))
+	case *ApiWalletRepository:
+		_, err := r.db.Exec(context.Background(), `UPDATE wallets SET frozen = TRUE WHERE wallet_id = $1`, walletID)
+		require.NoError(t, err)
+	default:
+		t.Skipf("%T does not support frozen wallets", repo)
+	}
+}
+
+func mustGetWallet(t *testing.T, repo WalletRepository, walletID string) models.Wallet {
+	t.Helper()
+	wallet, err := repo.GetWallet(walletID)
+	require.NoError(t, err)
+	return wallet
+}
+
+// newWalletID возвращает случайный UUID версии 4
+func newWalletID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func quietLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func memoryFixture(t *testing.T, balances ...float64) (WalletRepository, []string) {
+	repo := NewMemoryWalletRepository(quietLogger())
+	ids := make([]string, len(balances))
+	for i, balance := range balances {
+		ids[i] = newWalletID()
+		repo.CreateWallet(ids[i], balance)
+	}
+	return repo, ids
+}
+
+func postgresFixture(t *testing.T, balances ...float64) (WalletRepository, []string) {
+	dsn := os.Getenv("WALLET_TEST_DSN")
+	if dsn == "" {
+		t.Skip("WALLET_TEST_DSN is not set")
+	}
+	db, err := pgxpool.New(context.Background(), dsn)
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	ids := make([]string, len(balances))
+	for i := range balances {
+		ids[i] = newWalletID()
+	}
+	_, err = db.Exec(context.Background(), `INSERT INTO wallets (wallet_id, balance) SELECT * FROM unnest($1::uuid[], $2::numeric[])`, ids, balances)
+	require.NoError(t, err)
+	return NewApiWalletRepository(db, quietLogger()), ids
+}
+
+// sqliteFixture создает новую базу SQLite во временном каталоге
+func sqliteFixture(t *testing.T, balances ...float64) (WalletRepository, []string) {
+	sqliteDB := openSQLite(t, filepath.Join(t.TempDir(), "wallets.db"))
+
+	ids := make([]string, len(balances))
+	for i, balance := range balances {
+		ids[i] = newWalletID()
+		_, err := sqliteDB.Exec(`INSERT INTO wallets (wallet_id, balance) VALUES (?, ?)`, ids[i], toCents(balance))
+		require.NoError(t, err)
+	}
+	return NewSQLiteWalletRepository(sqliteDB, quietLogger()), ids
+}
+
+// openSQLite открывает базу SQLite по пути path и применяет к ней встроенные миграции SQLite
+func openSQLite(t *testing.T, path string) *sql.DB {
+	sqliteDB, err := db.OpenSQLite(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { sqliteDB.Close() })
+	require.NoError(t, migrator.RunSQLiteMigrations(sqliteDB))
+	return sqliteDB
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// WithHotWallets включает буферизацию пополнений для указанных кошельков и возвращает репозиторий.
+// Пополнение горячего кошелька записывается в буфер без блокировки строки кошелька, а в баланс
+// и журнал операций переносится FoldPendingDeposits или следующей операцией с этим кошельком
+func (r *ApiWalletRepository) WithHotWallets(walletIDs []string) *ApiWalletRepository {
+	r.hot = make(map[string]bool, len(walletIDs))
+	for _, id := range walletIDs {
+		r.hot[strings.ToLower(id)] = true
+	}
+	return r
+}
+
+func (r *ApiWalletRepository) isHot(walletID string) bool {
+	return r.hot[strings.ToLower(walletID)]
+}
+
+// bufferDeposit записывает пополнение горячего кошелька в буфер. Непустой key проверяется так же, как в changeBalance.
+// Пополнение замороженного кошелька не записывается. Источник пополнения сохраняется в буфере и попадает
+// в журнал аудита при переносе в баланс
+func (r *ApiWalletRepository) bufferDeposit(walletID string, amount float64, key string) error {
+	return runTx(r.db, r.logger, "buffer deposit", pgx.ReadCommitted, func(tx pgx.Tx) error {
+		if key != "" {
+			var applied bool
+			if err := tx.QueryRow(context.Background(), idempotencyKeyQuery, key).Scan(&applied); err != nil {
+				return err
+			}
+			if applied {
+				return errAlreadyApplied
+			}
+		}
+
+		tag, err := tx.Exec(context.Background(), `INSERT INTO wallet_pending_deposits (wallet_id, amount, idempotency_key, actor, request_id)
+			SELECT $1::uuid, $2::numeric, NULLIF($3::text, ''), $4, $5
+			WHERE NOT EXISTS (SELECT 1 FROM wallets WHERE wallet_id = $1::uuid AND frozen)`, walletID, amount, key, r.origin.Actor, r.origin.RequestID)
+		if isPgError(err, "23503") {
+			return ErrWalletNotFound
+		}
+		if key != "" && isPgError(err, "23505") {
+			return errAlreadyApplied
+		}
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrWalletFrozen
+		}
+		return nil
+	})
+}
+
+// Перенос буферизованных пополнений в балансы кошельков. За один вызов переносится не более limit пополнений.
+// Возвращает количество перенесенных пополнений
+func (r *ApiWalletRepository) FoldPendingDeposits(limit int) (int, error) {
+	var folded int
+	err := runTx(r.db, r.logger, "fold pending deposits", pgx.ReadCommitted, func(tx pgx.Tx) error {
+		folded = 0
+		rows, err := tx.Query(context.Background(), `SELECT DISTINCT wallet_id FROM wallet_pending_deposits`)
+		if err != nil {
+			return err
+		}
+		var walletIDs []string
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			walletIDs = append(walletIDs, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil || len(walletIDs) == 0 {
+			return err
+		}
+
+		folded, err = foldWallets(tx, walletIDs, limit)
+		return err
+	})
+	if err != nil {
+		r.logger.Errorf("Error folding pending deposits: %v", err)
+		return 0, err
+	}
+	if folded > 0 {
+		r.logger.Infof("Folded %d pending deposits into wallet balances", folded)
+	}
+	return folded, nil
+}
+
+// pendingDeposit — пополнение, извлеченное из буфера для переноса в баланс
+type pendingDeposit struct {
+	id       int64
+	walletID string
+	amount   float64
+	key      string
+	origin   models.Origin
+}
+
+// foldWallets блокирует кошельки и переносит в их балансы буферизованные пополнения
+func foldWallets(tx pgx.Tx, walletIDs []string, limit int) (int, error) {
+	wallets, err := lockWallets(tx, walletIDs)
+	if err != nil {
+		return 0, err
+	}
+	return foldPending(tx, wallets, limit)
+}
+
+// foldPending переносит буферизованные пополнения заблокированных кошельков в их балансы и журнал операций
+// в порядке поступления. Состояние кошельков в wallets обновляется. Нулевой limit снимает ограничение
+func foldPending(tx pgx.Tx, wallets map[string]*batchWallet, limit int) (int, error) {
+	if len(wallets) == 0 {
+		return 0, nil
+	}
+	walletIDs := make([]string, 0, len(wallets))
+	for id := range wallets {
+		walletIDs = append(walletIDs, id)
+	}
+
+	rows, err := tx.Query(context.Background(), `DELETE FROM wallet_pending_deposits
+		WHERE deposit_id IN (
+			SELECT deposit_id FROM wallet_pending_deposits
+			WHERE wallet_id = ANY ($1::uuid[])
+			ORDER BY deposit_id
+			LIMIT $2)
+		RETURNING deposit_id, wallet_id, amount, COALESCE(idempotency_key, ''), actor, request_id`,
+		walletIDs, limitOrAll(limit))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var deposits []pendingDeposit
+	for rows.Next() {
+		var d pendingDeposit
+		if err := rows.Scan(&d.id, &d.walletID, scanMoney(&d.amount), &d.key, &d.origin.Actor, &d.origin.RequestID); err != nil {
+			return 0, err
+		}
+		deposits = append(deposits, d)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(deposits) == 0 {
+		return 0, nil
+	}
+
+	if err := planFold(deposits, wallets).apply(tx); err != nil {
+		return 0, err
+	}
+	return len(deposits), nil
+}
+
+// planFold строит план переноса пополнений: каждое пополнение становится отдельной операцией журнала
+// со своей версией кошелька, событием WalletDeposited и записью аудита от имени источника пополнения.
+// Пополнения, принятые до заморозки кошелька, переносятся
+func planFold(deposits []pendingDeposit, wallets map[string]*batchWallet) *batchPlan {
+	sort.Slice(deposits, func(i, j int) bool { return deposits[i].id < deposits[j].id })
+
+	items := make([]models.BatchItem, len(deposits))
+	keys := make([]string, len(deposits))
+	origins := make([]models.Origin, len(deposits))
+	for i, d := range deposits {
+		items[i] = models.BatchItem{OperationType: models.OperationDeposit, WalletID: d.walletID, Amount: d.amount}
+		keys[i] = d.key
+		origins[i] = d.origin
+	}
+	plan := planItems(&batchPlan{wallets: wallets, allowFrozen: true}, items)
+	plan.keys = keys
+	plan.origins = origins
+	return plan
+}
+
+// limitOrAll возвращает значение для LIMIT: NULL, то есть без ограничения, если limit не положителен
+func limitOrAll(limit int) *int {
+	if limit <= 0 {
+		return nil
+	}
+	return &limit
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPlanFold проверяет, что буферизованные пополнения переносятся в порядке поступления
+// отдельными операциями журнала с последовательными версиями, своими ключами идемпотентности и источниками
+func TestPlanFold(t *testing.T) {
+	wallets := testWallets()
+	plan := planFold([]pendingDeposit{
+		{id: 12, walletID: walletA, amount: 0.5, origin: models.Origin{Actor: "api:10.0.0.1", RequestID: "req-2"}},
+		{id: 10, walletID: walletA, amount: 1, key: "payout:job:1", origin: models.Origin{Actor: "payout", RequestID: "payout:job:1"}},
+		{id: 11, walletID: walletB, amount: 2.25, origin: models.SystemOrigin},
+	}, wallets)
+
+	assert.Equal(t, 0, plan.failed)
+	assert.Equal(t, []string{"payout:job:1", "", ""}, plan.keys)
+	assert.Equal(t, []string{"payout", "system", "api:10.0.0.1"}, []string{plan.origins[0].Actor, plan.origins[1].Actor, plan.origins[2].Actor})
+	assert.Equal(t, []models.Operation{
+		{WalletID: walletA, Type: models.OperationDeposit, Amount: 1, BalanceAfter: 101, Version: 4},
+		{WalletID: walletB, Type: models.OperationDeposit, Amount: 2.25, BalanceAfter: 2.25, Version: 2},
+		{WalletID: walletA, Type: models.OperationDeposit, Amount: 0.5, BalanceAfter: 101.5, Version: 5},
+	}, []models.Operation{*plan.operations[0], *plan.operations[1], *plan.operations[2]})
+	assert.Len(t, plan.events, 3)
+
+	// Состояние кошельков обновляется, чтобы следующие операции в той же транзакции видели перенесенные пополнения
+	assert.Equal(t, int64(10150), wallets[walletA].cents)
+	assert.Equal(t, int64(5), wallets[walletA].version)
+}
+
+// TestWithHotWallets проверяет, что горячие кошельки определяются без учета регистра ID
+func TestWithHotWallets(t *testing.T) {
+	repo := NewApiWalletRepository(nil, nil).WithHotWallets([]string{"3FA85F64-5717-4562-B3FC-2C963F66AFA6"})
+
+	assert.True(t, repo.isHot(walletA))
+	assert.False(t, repo.isHot(walletB))
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// MemoryWalletRepository хранит кошельки и журнал операций в памяти процесса. Семантика операций совпадает
+// с ApiWalletRepository: те же ошибки, атомарность пакетов, проверки заморозки, версии и средств. Все изменения выполняются
+// под одной блокировкой, поэтому каждая операция атомарна. Предназначен для тестов и локального запуска без базы данных
+type MemoryWalletRepository struct {
+	logger *logrus.Logger
+	notify func(op models.Operation) // Получатель выполненных операций; nil — операции никуда не передаются
+
+	mu         sync.RWMutex
+	wallets    map[string]*memoryWallet
+	operations []models.Operation
+	keys       map[string]bool // Использованные ключи идемпотентности
+}
+
+// memoryWallet хранит состояние кошелька и индексы его операций в общем журнале
+type memoryWallet struct {
+	cents      int64
+	version    int64
+	frozen     bool
+	operations []int
+}
+
+func NewMemoryWalletRepository(logger *logrus.Logger) *MemoryWalletRepository {
+	return &MemoryWalletRepository{
+		logger:  logger,
+		wallets: make(map[string]*memoryWallet),
+		keys:    make(map[string]bool),
+	}
+}
+
+// WithNotify передает каждую выполненную операцию в notify в порядке выполнения и возвращает репозиторий.
+// Заменяет LISTEN/NOTIFY, которым операции доставляются потокам при работе с базой данных
+func (r *MemoryWalletRepository) WithNotify(notify func(op models.Operation)) *MemoryWalletRepository {
+	r.notify = notify
+	return r
+}
+
+// CreateWallet создает кошелек с начальным балансом или заменяет существующий. Как и в базе данных,
+// новый кошелек имеет версию 1, а начальный баланс не записывается в журнал операций
+func (r *MemoryWalletRepository) CreateWallet(walletID string, balance float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.wallets[strings.ToLower(walletID)] = &memoryWallet{cents: toCents(balance), version: 1}
+}
+
+// SetFrozen замораживает или размораживает кошелек. Как и в базе данных, операции с замороженным кошельком
+// отклоняются с ErrWalletFrozen, а заморозка не меняет версию кошелька
+func (r *MemoryWalletRepository) SetFrozen(walletID string, frozen bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w, ok := r.wallets[strings.ToLower(walletID)]
+	if !ok {
+		return ErrWalletNotFound
+	}
+	w.frozen = frozen
+	return nil
+}
+
+func (r *MemoryWalletRepository) GetWalletBalance(walletID string) (float64, error) {
+	wallet, err := r.GetWallet(walletID)
+	if err != nil {
+		return 0, err
+	}
+	return wallet.Balance, nil
+}
+
+func (r *MemoryWalletRepository) GetWallet(walletID string) (models.Wallet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.wallets[strings.ToLower(walletID)]
+	if !ok {
+		return models.Wallet{}, ErrWalletNotFound
+	}
+	return models.Wallet{ID: walletID, Balance: fromCents(w.cents), Version: w.version}, nil
+}
+
+func (r *MemoryWalletRepository) Deposit(walletID string, amount float64) error {
+	_, err := r.changeBalance(walletID, models.OperationDeposit, amount, anyVersion, "")
+	return err
+}
+
+func (r *MemoryWalletRepository) Withdraw(walletID string, amount float64) error {
+	_, err := r.changeBalance(walletID, models.OperationWithdraw, amount, anyVersion, "")
+	return err
+}
+
+func (r *MemoryWalletRepository) DepositIfVersion(walletID string, amount float64, version int64) (int64, error) {
+	op, err := r.changeBalance(walletID, models.OperationDeposit, amount, version, "")
+	return op.Version, err
+}
+
+func (r *MemoryWalletRepository) WithdrawIfVersion(walletID string, amount float64, version int64) (int64, error) {
+	op, err := r.changeBalance(walletID, models.OperationWithdraw, amount, version, "")
+	return op.Version, err
+}
+
+func (r *MemoryWalletRepository) DepositIdempotent(walletID string, amount float64, key string) (bool, error) {
+	_, err := r.changeBalance(walletID, models.OperationDeposit, amount, anyVersion, key)
+	if err == errAlreadyApplied {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// changeBalance проверяет ключ идемпотентности, заморозку, версию и достаточность средств, изменяет баланс
+// и записывает операцию в журнал
+func (r *MemoryWalletRepository) changeBalance(walletID, opType string, amount float64, version int64, key string) (models.Operation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if key != "" && r.keys[key] {
+		return models.Operation{}, errAlreadyApplied
+	}
+	id := strings.ToLower(walletID)
+	w, ok := r.wallets[id]
+	if !ok {
+		return models.Operation{}, ErrWalletNotFound
+	}
+	if w.frozen {
+		return models.Operation{}, ErrWalletFrozen
+	}
+	if version != anyVersion && w.version != version {
+		return models.Operation{}, ErrVersionMismatch
+	}
+	delta := toCents(amount)
+	if opType == models.OperationWithdraw {
+		delta = -delta
+	}
+	if w.cents+delta < 0 {
+		return models.Operation{}, ErrInsufficientFunds
+	}
+
+	w.cents += delta
+	w.version++
+	if key != "" {
+		r.keys[key] = true
+	}
+	op := r.append(models.Operation{
+		WalletID:     id,
+		Type:         opType,
+		Amount:       fromCents(abs(delta)),
+		BalanceAfter: fromCents(w.cents),
+		Version:      w.version,
+	})
+	r.logger.Infof("Applied %s of %f to wallet %s, version %d", opType, amount, walletID, w.version)
+	return op, nil
+}
+
+// append записывает операцию в журнал, присваивая ей ID и время, и передает ее получателю
+func (r *MemoryWalletRepository) append(op models.Operation) models.Operation {
+	op.ID = int64(len(r.operations)) + 1
+	op.CreatedAt = time.Now()
+	r.operations = append(r.operations, op)
+	w := r.wallets[op.WalletID]
+	w.operations = append(w.operations, len(r.operations)-1)
+	if r.notify != nil {
+		r.notify(op)
+	}
+	return op
+}
+
+func (r *MemoryWalletRepository) GetOperationsAfter(walletID string, afterID int64, limit int) ([]models.Operation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	operations := []models.Operation{}
+	w, ok := r.wallets[strings.ToLower(walletID)]
+	if !ok {
+		return operations, nil
+	}
+	for _, i := range w.operations {
+		if len(operations) == limit {
+			break
+		}
+		if r.operations[i].ID > afterID {
+			operations = append(operations, r.operations[i])
+		}
+	}
+	return operations, nil
+}
+
+// ApplyBatch проверяет операции пакета тем же планировщиком, что и ApiWalletRepository, и применяет план
+// под блокировкой репозитория
+func (r *MemoryWalletRepository) ApplyBatch(items []models.BatchItem, atomic bool) ([]models.BatchItemResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wallets := make(map[string]*batchWallet)
+	for _, id := range batchWalletIDs(items) {
+		if w, ok := r.wallets[id]; ok {
+			wallets[id] = &batchWallet{id: id, cents: w.cents, version: w.version, frozen: w.frozen}
+		}
+	}
+
+	plan := planBatch(items, wallets)
+	if atomic && plan.failed > 0 {
+		plan.rollBack()
+		r.logger.Warnf("Rejected batch of %d operations", len(items))
+		return plan.results, ErrBatchRejected
+	}
+	for id, w := range wallets {
+		if w.touched {
+			r.wallets[id].cents, r.wallets[id].version = w.cents, w.version
+		}
+	}
+	for _, op := range plan.operations {
+		*op = r.append(*op)
+	}
+	plan.fillResults()
+	r.logger.Infof("Applied batch of %d operations", len(items))
+	return plan.results, nil
+}
+
+// FoldPendingDeposits ничего не делает: пополнения не буферизуются и сразу попадают в баланс
+func (r *MemoryWalletRepository) FoldPendingDeposits(limit int) (int, error) {
+	return 0, nil
+}
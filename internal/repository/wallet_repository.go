@@ -1,39 +1,66 @@
 package repository
 
 import (
-	"database/sql"
-	"fmt"
+	"context"
+	"encoding/json"
+	"errors"
 
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sirupsen/logrus"
 )
 
 type WalletRepository interface {
 	GetWalletBalance(walletID string) (float64, error)
+	GetWallet(walletID string) (models.Wallet, error)
 	Deposit(walletID string, amount float64) error
 	Withdraw(walletID string, amount float64) error
+	DepositIfVersion(walletID string, amount float64, version int64) (int64, error)
+	WithdrawIfVersion(walletID string, amount float64, version int64) (int64, error)
+	GetOperationsAfter(walletID string, afterID int64, limit int) ([]models.Operation, error)
+	ApplyBatch(items []models.BatchItem, atomic bool) ([]models.BatchItemResult, error)
+	DepositIdempotent(walletID string, amount float64, key string) (bool, error)
+	FoldPendingDeposits(limit int) (int, error)
 }
 
+// OperationsChannel — канал LISTEN/NOTIFY, в который публикуется каждая зафиксированная операция
+const OperationsChannel = "wallet_operations"
+
 type ApiWalletRepository struct {
-	db     *sql.DB
+	db     *pgxpool.Pool
 	logger *logrus.Logger
+	hot    map[string]bool // Горячие кошельки, пополнения которых буферизуются
+	origin models.Origin   // Источник изменений для журнала аудита
 }
 
-func NewApiWalletRepository(db *sql.DB, logger *logrus.Logger) *ApiWalletRepository {
+func NewApiWalletRepository(db *pgxpool.Pool, logger *logrus.Logger) *ApiWalletRepository {
 	return &ApiWalletRepository{
 		db:     db,
 		logger: logger,
+		origin: models.SystemOrigin,
 	}
 }
 
+// WithOrigin возвращает копию репозитория, изменения которой записываются в журнал аудита от имени origin.
+// Копия работает с тем же пулом соединений, поэтому ее можно создавать на каждый запрос
+func (r *ApiWalletRepository) WithOrigin(origin models.Origin) WalletRepository {
+	scoped := *r
+	scoped.origin = origin
+	return &scoped
+}
+
 // Получение баланса кошелька по ID
 func (r *ApiWalletRepository) GetWalletBalance(walletID string) (float64, error) {
-	var balance float64
-	query := `SELECT balance FROM wallets WHERE wallet_id = $1`
-	err := r.db.QueryRow(query, walletID).Scan(&balance)
+	var (
+		balance float64
+		version int64
+	)
+	err := r.db.QueryRow(context.Background(), walletStateQuery, walletID).Scan(scanMoney(&balance), &version)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			r.logger.Warnf("Wallet with ID %s not found", walletID)
-			return 0, fmt.Errorf("wallet not found")
+			return 0, ErrWalletNotFound
 		}
 		r.logger.Errorf("Error retrieving balance for wallet %s: %v", walletID, err)
 		return 0, err
@@ -42,10 +69,40 @@ func (r *ApiWalletRepository) GetWalletBalance(walletID string) (float64, error)
 	return balance, nil
 }
 
-// Депозит средств на кошелек
-func (r *ApiWalletRepository) Deposit(walletID string, amount float64) error {
-	_, err := r.db.Exec(`UPDATE wallets SET balance = balance + $1 WHERE wallet_id = $2`, amount, walletID)
+// Получение баланса и версии кошелька по ID
+func (r *ApiWalletRepository) GetWallet(walletID string) (models.Wallet, error) {
+	wallet := models.Wallet{ID: walletID}
+	err := r.db.QueryRow(context.Background(), walletStateQuery, walletID).Scan(scanMoney(&wallet.Balance), &wallet.Version)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Warnf("Wallet with ID %s not found", walletID)
+			return models.Wallet{}, ErrWalletNotFound
+		}
+		r.logger.Errorf("Error retrieving wallet %s: %v", walletID, err)
+		return models.Wallet{}, err
+	}
+	r.logger.Infof("Retrieved wallet %s: balance %f, version %d", walletID, wallet.Balance, wallet.Version)
+	return wallet, nil
+}
+
+// walletStateQuery читает баланс и версию кошелька с учетом буферизованных пополнений. При переносе в баланс
+// каждое пополнение увеличит версию на единицу, поэтому результат совпадает с состоянием после переноса
+const walletStateQuery = `SELECT w.balance + COALESCE(p.amount, 0), w.version + p.count
+	FROM wallets w
+	CROSS JOIN LATERAL (SELECT SUM(amount) AS amount, COUNT(*) AS count
+		FROM wallet_pending_deposits WHERE wallet_id = w.wallet_id) p
+	WHERE w.wallet_id = $1`
+
+// Депозит средств на кошелек. Пополнение горячего кошелька записывается в буфер
+func (r *ApiWalletRepository) Deposit(walletID string, amount float64) error {
+	if r.isHot(walletID) {
+		if err := r.bufferDeposit(walletID, amount, ""); err != nil {
+			r.logger.Errorf("Error buffering deposit of %f to wallet %s: %v", amount, walletID, err)
+			return err
+		}
+		return nil
+	}
+	if _, err := r.changeBalance(walletID, models.OperationDeposit, amount, anyVersion, "", nil); err != nil {
 		r.logger.Errorf("Error depositing %f to wallet %s: %v", amount, walletID, err)
 		return err
 	}
@@ -55,24 +112,235 @@ func (r *ApiWalletRepository) Deposit(walletID string, amount float64) error {
 
 // Вывод средств с кошелька
 func (r *ApiWalletRepository) Withdraw(walletID string, amount float64) error {
-	// Проверяем текущий баланс
-	currentBalance, err := r.GetWalletBalance(walletID)
+	if _, err := r.changeBalance(walletID, models.OperationWithdraw, amount, anyVersion, "", nil); err != nil {
+		r.logger.Errorf("Error withdrawing %f from wallet %s: %v", amount, walletID, err)
+		return err
+	}
+	r.logger.Infof("Withdrew %f from wallet %s", amount, walletID)
+	return nil
+}
+
+// Депозит средств при условии, что версия кошелька не изменилась. Возвращает новую версию
+func (r *ApiWalletRepository) DepositIfVersion(walletID string, amount float64, version int64) (int64, error) {
+	op, err := r.changeBalance(walletID, models.OperationDeposit, amount, version, "", nil)
+	if err != nil {
+		r.logger.Errorf("Error depositing %f to wallet %s at version %d: %v", amount, walletID, version, err)
+		return 0, err
+	}
+	r.logger.Infof("Deposited %f to wallet %s, version %d -> %d", amount, walletID, version, op.Version)
+	return op.Version, nil
+}
+
+// Вывод средств при условии, что версия кошелька не изменилась. Возвращает новую версию
+func (r *ApiWalletRepository) WithdrawIfVersion(walletID string, amount float64, version int64) (int64, error) {
+	op, err := r.changeBalance(walletID, models.OperationWithdraw, amount, version, "", nil)
+	if err != nil {
+		r.logger.Errorf("Error withdrawing %f from wallet %s at version %d: %v", amount, walletID, version, err)
+		return 0, err
+	}
+	r.logger.Infof("Withdrew %f from wallet %s, version %d -> %d", amount, walletID, version, op.Version)
+	return op.Version, nil
+}
+
+// Депозит средств с ключом идемпотентности. Если операция с таким ключом уже выполнена,
+// баланс не меняется и возвращается false
+func (r *ApiWalletRepository) DepositIdempotent(walletID string, amount float64, key string) (bool, error) {
+	var err error
+	if r.isHot(walletID) {
+		err = r.bufferDeposit(walletID, amount, key)
+	} else {
+		_, err = r.changeBalance(walletID, models.OperationDeposit, amount, anyVersion, key, nil)
+	}
+	if err == errAlreadyApplied {
+		r.logger.Infof("Deposit with key %s to wallet %s is already applied", key, walletID)
+		return false, nil
+	}
+	if err != nil {
+		r.logger.Errorf("Error depositing %f to wallet %s with key %s: %v", amount, walletID, key, err)
+		return false, err
+	}
+	r.logger.Infof("Deposited %f to wallet %s with key %s", amount, walletID, key)
+	return true, nil
+}
+
+// anyVersion отключает проверку версии кошелька в changeBalance
+const anyVersion int64 = -1
+
+// errAlreadyApplied сообщает, что операция с тем же ключом идемпотентности уже записана в журнал
+var errAlreadyApplied = errors.New("operation already applied")
+
+// changeBalance в одной транзакции изменяет баланс, записывает операцию в журнал и событие в outbox.
+// Проверка версии и достаточности средств выполняется одним UPDATE, поэтому между ними нет гонки.
+// Непустой key записывается в журнал с уникальным индексом: из параллельных операций с одним ключом
+// зафиксируется только одна, остальные завершатся errAlreadyApplied. Перед изменением горячего кошелька в баланс переносятся его буферизованные
+// пополнения, чтобы проверка средств и версии выполнялась по точному состоянию. Операции с замороженным кошельком
+// отклоняются, кроме корректировок оператора. Каждая операция записывается в журнал аудита от имени r.origin;
+// непустой describe может дополнить или изменить эту запись
+func (r *ApiWalletRepository) changeBalance(walletID, opType string, amount float64, version int64, key string, describe func(entry *models.AuditEntry)) (models.Operation, error) {
+	delta := amount
+	if isDebit(opType) {
+		delta = -amount
+	}
+
+	op := models.Operation{WalletID: walletID, Type: opType, Amount: amount}
+	err := runTx(r.db, r.logger, "change balance", pgx.ReadCommitted, func(tx pgx.Tx) error {
+		if key != "" {
+			var applied bool
+			if err := tx.QueryRow(context.Background(), idempotencyKeyQuery, key).Scan(&applied); err != nil {
+				return err
+			}
+			if applied {
+				return errAlreadyApplied
+			}
+		}
+		if r.isHot(walletID) {
+			if _, err := foldWallets(tx, []string{walletID}, 0); err != nil {
+				return err
+			}
+		}
+
+		err := tx.QueryRow(context.Background(), changeBalanceQuery, delta, walletID, version, isAdjustment(opType)).Scan(scanMoney(&op.BalanceAfter), &op.Version)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.conditionalUpdateError(tx, walletID, amount, version, isAdjustment(opType))
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := insertOperation(tx, &op, key); err != nil {
+			if key != "" && isPgError(err, "23505") {
+				return errAlreadyApplied
+			}
+			return err
+		}
+		if err := notifyOperation(tx, op); err != nil {
+			return err
+		}
+		if err := insertOutboxEvent(tx, operationEventType(opType), walletID, op); err != nil {
+			return err
+		}
+		entry := operationAudit(r.origin, op)
+		if describe != nil {
+			describe(entry)
+		}
+		return appendAudit(tx, entry)
+	})
+	if err != nil {
+		return models.Operation{}, err
+	}
+	return op, nil
+}
+
+// changeBalanceQuery изменяет баланс, если версия совпадает (или не проверяется), средств достаточно
+// и кошелек не заморожен. $4 разрешает изменять замороженный кошелек
+const changeBalanceQuery = `UPDATE wallets SET balance = balance + $1, version = version + 1
+	WHERE wallet_id = $2 AND ($3::bigint < 0 OR version = $3) AND balance + $1 >= 0 AND ($4::boolean OR NOT frozen)
+	RETURNING balance, version`
+
+// idempotencyKeyQuery проверяет, выполнена ли операция с ключом: она есть в журнале или ждет переноса в буфере
+const idempotencyKeyQuery = `SELECT EXISTS (SELECT 1 FROM wallet_operations WHERE idempotency_key = $1)
+	OR EXISTS (SELECT 1 FROM wallet_pending_deposits WHERE idempotency_key = $1)`
+
+// insertOperation добавляет операцию в журнал и заполняет ее ID и время
+func insertOperation(tx pgx.Tx, op *models.Operation, key string) error {
+	return tx.QueryRow(context.Background(), insertOperationQuery, op.WalletID, op.Type, op.Amount, op.BalanceAfter, op.Version, key).Scan(&op.ID, &op.CreatedAt)
+}
+
+const insertOperationQuery = `INSERT INTO wallet_operations (wallet_id, operation_type, amount, balance_after, wallet_version, idempotency_key)
+	VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
+	RETURNING operation_id, created_at`
+
+// notifyOperation публикует операцию в OperationsChannel. PostgreSQL доставит уведомление только после фиксации транзакции
+func notifyOperation(tx pgx.Tx, op models.Operation) error {
+	payload, err := json.Marshal(op)
 	if err != nil {
 		return err
 	}
+	_, err = tx.Exec(context.Background(), `SELECT pg_notify($1, $2)`, OperationsChannel, string(payload))
+	return err
+}
 
-	// Проверяем, достаточно ли средств для вывода
-	if currentBalance < amount {
-		r.logger.Warnf("Insufficient funds for wallet %s: current balance is %f, requested withdrawal is %f", walletID, currentBalance, amount)
-		return fmt.Errorf("insufficient funds")
+// Получение операций кошелька с ID больше afterID в порядке их выполнения
+func (r *ApiWalletRepository) GetOperationsAfter(walletID string, afterID int64, limit int) ([]models.Operation, error) {
+	rows, err := r.db.Query(context.Background(), operationsAfterQuery, walletID, afterID, limit)
+	if err != nil {
+		r.logger.Errorf("Error retrieving operations for wallet %s after %d: %v", walletID, afterID, err)
+		return nil, err
 	}
+	defer rows.Close()
 
-	// Выполняем вывод
-	_, err = r.db.Exec(`UPDATE wallets SET balance = balance - $1 WHERE wallet_id = $2`, amount, walletID)
+	operations := []models.Operation{}
+	for rows.Next() {
+		var op models.Operation
+		if err := rows.Scan(&op.ID, &op.WalletID, &op.Type, scanMoney(&op.Amount), scanMoney(&op.BalanceAfter), &op.Version, &op.CreatedAt); err != nil {
+			return nil, err
+		}
+		operations = append(operations, op)
+	}
+	return operations, rows.Err()
+}
+
+const operationsAfterQuery = `SELECT operation_id, wallet_id, operation_type, amount, balance_after, wallet_version, created_at
+	FROM wallet_operations
+	WHERE wallet_id = $1 AND operation_id > $2
+	ORDER BY operation_id
+	LIMIT $3`
+
+// operationEventType сопоставляет тип операции с типом доменного события
+func operationEventType(opType string) string {
+	switch {
+	case isAdjustment(opType):
+		return models.EventWalletAdjusted
+	case opType == models.OperationWithdraw:
+		return models.EventWalletWithdrawn
+	}
+	return models.EventWalletDeposited
+}
+
+// debitOperationTypes — типы операций, которые уменьшают баланс кошелька
+var debitOperationTypes = []string{models.OperationWithdraw, models.OperationTransferOut, models.OperationAdjustmentDebit}
+
+// isDebit сообщает, уменьшает ли операция баланс кошелька
+func isDebit(opType string) bool {
+	for _, debit := range debitOperationTypes {
+		if opType == debit {
+			return true
+		}
+	}
+	return false
+}
+
+// isAdjustment сообщает, является ли операция ручной корректировкой оператора
+func isAdjustment(opType string) bool {
+	return opType == models.OperationAdjustmentCredit || opType == models.OperationAdjustmentDebit
+}
+
+// conditionalUpdateError определяет, почему условный UPDATE не затронул ни одной строки. Состояние кошелька
+// читается в той же транзакции и тем же запросом, что проверял UPDATE, без второго подключения из пула
+func (r *ApiWalletRepository) conditionalUpdateError(tx pgx.Tx, walletID string, amount float64, version int64, allowFrozen bool) error {
+	var (
+		frozen  bool
+		balance float64
+		actual  int64
+	)
+	err := tx.QueryRow(context.Background(), `SELECT frozen, balance, version FROM wallets WHERE wallet_id = $1`, walletID).
+		Scan(&frozen, scanMoney(&balance), &actual)
+	if errors.Is(err, pgx.ErrNoRows) {
+		r.logger.Warnf("Wallet with ID %s not found", walletID)
+		return ErrWalletNotFound
+	}
 	if err != nil {
-		r.logger.Errorf("Error withdrawing %f from wallet %s: %v", amount, walletID, err)
 		return err
 	}
-	r.logger.Infof("Withdrew %f from wallet %s", amount, walletID)
-	return nil
+	if frozen && !allowFrozen {
+		r.logger.Warnf("Wallet %s is frozen", walletID)
+		return ErrWalletFrozen
+	}
+
+	if version != anyVersion && actual != version {
+		r.logger.Warnf("Version mismatch for wallet %s: expected %d, actual %d", walletID, version, actual)
+		return ErrVersionMismatch
+	}
+	r.logger.Warnf("Insufficient funds for wallet %s: current balance is %f, requested withdrawal is %f", walletID, balance, amount)
+	return ErrInsufficientFunds
 }
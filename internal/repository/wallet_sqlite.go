@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// SQLiteWalletRepository хранит кошельки и журнал операций в локальной базе SQLite для автономных узлов.
+// Семантика операций совпадает с ApiWalletRepository, кроме заморозки: в схеме SQLite ее нет. База открывается через db.OpenSQLite: каждая транзакция
+// сразу получает блокировку записи, поэтому проверка средств и изменение баланса не пересекаются
+// с параллельными списаниями. Суммы хранятся в копейках
+type SQLiteWalletRepository struct {
+	db     *sql.DB
+	logger *logrus.Logger
+	notify func(op models.Operation) // Получатель зафиксированных операций; nil — операции никуда не передаются
+
+	// Упорядочивает транзакции с записью процесса, чтобы получатель узнавал об операциях в порядке их фиксации
+	mu sync.Mutex
+}
+
+func NewSQLiteWalletRepository(db *sql.DB, logger *logrus.Logger) *SQLiteWalletRepository {
+	return &SQLiteWalletRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithNotify передает каждую зафиксированную операцию в notify в порядке выполнения и возвращает репозиторий.
+// Заменяет LISTEN/NOTIFY, которым операции доставляются потокам при работе с PostgreSQL
+func (r *SQLiteWalletRepository) WithNotify(notify func(op models.Operation)) *SQLiteWalletRepository {
+	r.notify = notify
+	return r
+}
+
+// Получение баланса кошелька по ID
+func (r *SQLiteWalletRepository) GetWalletBalance(walletID string) (float64, error) {
+	wallet, err := r.GetWallet(walletID)
+	if err != nil {
+		return 0, err
+	}
+	return wallet.Balance, nil
+}
+
+// Получение баланса и версии кошелька по ID
+func (r *SQLiteWalletRepository) GetWallet(walletID string) (models.Wallet, error) {
+	var cents int64
+	wallet := models.Wallet{ID: walletID}
+	err := r.db.QueryRow(`SELECT balance, version FROM wallets WHERE wallet_id = ?`, strings.ToLower(walletID)).Scan(&cents, &wallet.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Warnf("Wallet with ID %s not found", walletID)
+			return models.Wallet{}, ErrWalletNotFound
+		}
+		r.logger.Errorf("Error retrieving wallet %s: %v", walletID, err)
+		return models.Wallet{}, err
+	}
+	wallet.Balance = fromCents(cents)
+	r.logger.Infof("Retrieved wallet %s: balance %f, version %d", walletID, wallet.Balance, wallet.Version)
+	return wallet, nil
+}
+
+func (r *SQLiteWalletRepository) Deposit(walletID string, amount float64) error {
+	_, err := r.changeBalance(walletID, models.OperationDeposit, amount, anyVersion, "")
+	return err
+}
+
+func (r *SQLiteWalletRepository) Withdraw(walletID string, amount float64) error {
+	_, err := r.changeBalance(walletID, models.OperationWithdraw, amount, anyVersion, "")
+	return err
+}
+
+func (r *SQLiteWalletRepository) DepositIfVersion(walletID string, amount float64, version int64) (int64, error) {
+	op, err := r.changeBalance(walletID, models.OperationDeposit, amount, version, "")
+	return op.Version, err
+}
+
+func (r *SQLiteWalletRepository) WithdrawIfVersion(walletID string, amount float64, version int64) (int64, error) {
+	op, err := r.changeBalance(walletID, models.OperationWithdraw, amount, version, "")
+	return op.Version, err
+}
+
+func (r *SQLiteWalletRepository) DepositIdempotent(walletID string, amount float64, key string) (bool, error) {
+	_, err := r.changeBalance(walletID, models.OperationDeposit, amount, anyVersion, key)
+	if err == errAlreadyApplied {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// changeBalance в одной транзакции проверяет ключ идемпотентности, изменяет баланс условным UPDATE
+// и записывает операцию в журнал. Получатель узнает об операции после фиксации транзакции
+func (r *SQLiteWalletRepository) changeBalance(walletID, opType string, amount float64, version int64, key string) (models.Operation, error) {
+	id := strings.ToLower(walletID)
+	delta := toCents(amount)
+	if opType == models.OperationWithdraw {
+		delta = -delta
+	}
+
+	op := models.Operation{WalletID: id, Type: opType, Amount: fromCents(abs(delta))}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := r.withTx(func(tx *sql.Tx) error {
+		if key != "" {
+			var applied bool
+			if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM wallet_operations WHERE idempotency_key = ?)`, key).Scan(&applied); err != nil {
+				return err
+			}
+			if applied {
+				return errAlreadyApplied
+			}
+		}
+
+		var cents int64
+		err := tx.QueryRow(sqliteChangeBalanceQuery, delta, id, version).Scan(&cents, &op.Version)
+		if errors.Is(err, sql.ErrNoRows) {
+			return r.conditionalUpdateError(tx, walletID, amount, version)
+		}
+		if err != nil {
+			return err
+		}
+		op.BalanceAfter = fromCents(cents)
+		return insertSQLiteOperation(tx, &op, key)
+	})
+	if err != nil {
+		if err != errAlreadyApplied {
+			r.logger.Errorf("Error applying %s of %f to wallet %s: %v", opType, amount, walletID, err)
+		}
+		return models.Operation{}, err
+	}
+	r.publish(op)
+	r.logger.Infof("Applied %s of %f to wallet %s, version %d", opType, amount, walletID, op.Version)
+	return op, nil
+}
+
+// sqliteChangeBalanceQuery изменяет баланс, если версия совпадает (или не проверяется) и средств достаточно
+const sqliteChangeBalanceQuery = `UPDATE wallets SET balance = balance + ?1, version = version + 1
+	WHERE wallet_id = ?2 AND (?3 < 0 OR version = ?3) AND balance + ?1 >= 0
+	RETURNING balance, version`
+
+// conditionalUpdateError определяет, почему условный UPDATE не затронул ни одной строки
+func (r *SQLiteWalletRepository) conditionalUpdateError(tx *sql.Tx, walletID string, amount float64, version int64) error {
+	var cents, actual int64
+	err := tx.QueryRow(`SELECT balance, version FROM wallets WHERE wallet_id = ?`, strings.ToLower(walletID)).Scan(&cents, &actual)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.logger.Warnf("Wallet with ID %s not found", walletID)
+		return ErrWalletNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if version != anyVersion && actual != version {
+		r.logger.Warnf("Version mismatch for wallet %s: expected %d, actual %d", walletID, version, actual)
+		return ErrVersionMismatch
+	}
+	r.logger.Warnf("Insufficient funds for wallet %s: current balance is %f, requested withdrawal is %f", walletID, fromCents(cents), amount)
+	return ErrInsufficientFunds
+}
+
+// insertSQLiteOperation добавляет операцию в журнал и заполняет ее ID и время
+func insertSQLiteOperation(tx *sql.Tx, op *models.Operation, key string) error {
+	op.CreatedAt = time.Now().UTC()
+	return tx.QueryRow(`INSERT INTO wallet_operations (wallet_id, operation_type, amount, balance_after, wallet_version, idempotency_key, created_at)
+		VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), ?)
+		RETURNING operation_id`,
+		op.WalletID, op.Type, toCents(op.Amount), toCents(op.BalanceAfter), op.Version, key, op.CreatedAt).Scan(&op.ID)
+}
+
+// Получение операций кошелька с ID больше afterID в порядке их выполнения
+func (r *SQLiteWalletRepository) GetOperationsAfter(walletID string, afterID int64, limit int) ([]models.Operation, error) {
+	rows, err := r.db.Query(`SELECT operation_id, wallet_id, operation_type, amount, balance_after, wallet_version, created_at
+		FROM wallet_operations
+		WHERE wallet_id = ? AND operation_id > ?
+		ORDER BY operation_id
+		LIMIT ?`, strings.ToLower(walletID), afterID, limit)
+	if err != nil {
+		r.logger.Errorf("Error retrieving operations for wallet %s after %d: %v", walletID, afterID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	operations := []models.Operation{}
+	for rows.Next() {
+		var (
+			op            models.Operation
+			amount, after int64
+		)
+		if err := rows.Scan(&op.ID, &op.WalletID, &op.Type, &amount, &after, &op.Version, &op.CreatedAt); err != nil {
+			return nil, err
+		}
+		op.Amount, op.BalanceAfter = fromCents(amount), fromCents(after)
+		operations = append(operations, op)
+	}
+	return operations, rows.Err()
+}
+
+// ApplyBatch проверяет операции пакета тем же планировщиком, что и ApiWalletRepository, и записывает план
+// в одной транзакции. В атомарном режиме ошибка любой операции отменяет весь пакет
+func (r *SQLiteWalletRepository) ApplyBatch(items []models.BatchItem, atomic bool) ([]models.BatchItemResult, error) {
+	var plan *batchPlan
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := r.withTx(func(tx *sql.Tx) error {
+		wallets, err := loadSQLiteWallets(tx, batchWalletIDs(items))
+		if err != nil {
+			return err
+		}
+
+		plan = planBatch(items, wallets)
+		if atomic && plan.failed > 0 {
+			plan.rollBack()
+			return ErrBatchRejected
+		}
+		for _, w := range wallets {
+			if !w.touched {
+				continue
+			}
+			if _, err := tx.Exec(`UPDATE wallets SET balance = ?, version = ? WHERE wallet_id = ?`, w.cents, w.version, w.id); err != nil {
+				return err
+			}
+		}
+		for _, op := range plan.operations {
+			if err := insertSQLiteOperation(tx, op, ""); err != nil {
+				return err
+			}
+		}
+		plan.fillResults()
+		return nil
+	})
+	if errors.Is(err, ErrBatchRejected) {
+		r.logger.Warnf("Rejected batch of %d operations", len(items))
+		return plan.results, err
+	}
+	if err != nil {
+		r.logger.Errorf("Error applying batch of %d operations: %v", len(items), err)
+		return nil, err
+	}
+	for _, op := range plan.operations {
+		r.publish(*op)
+	}
+	r.logger.Infof("Applied batch of %d operations", len(items))
+	return plan.results, nil
+}
+
+// loadSQLiteWallets читает кошельки пакета. Блокировка записи уже получена в начале транзакции
+func loadSQLiteWallets(tx *sql.Tx, walletIDs []string) (map[string]*batchWallet, error) {
+	wallets := make(map[string]*batchWallet, len(walletIDs))
+	if len(walletIDs) == 0 {
+		return wallets, nil
+	}
+	args := make([]interface{}, len(walletIDs))
+	for i, id := range walletIDs {
+		args[i] = id
+	}
+	rows, err := tx.Query(`SELECT wallet_id, balance, version FROM wallets
+		WHERE wallet_id IN (?`+strings.Repeat(", ?", len(walletIDs)-1)+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var w batchWallet
+		if err := rows.Scan(&w.id, &w.cents, &w.version); err != nil {
+			return nil, err
+		}
+		wallets[w.id] = &w
+	}
+	return wallets, rows.Err()
+}
+
+// FoldPendingDeposits ничего не делает: пополнения не буферизуются и сразу попадают в баланс
+func (r *SQLiteWalletRepository) FoldPendingDeposits(limit int) (int, error) {
+	return 0, nil
+}
+
+// withTx выполняет fn в транзакции и фиксирует ее, если fn не вернула ошибку
+func (r *SQLiteWalletRepository) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// publish передает операцию получателю, если он задан
+func (r *SQLiteWalletRepository) publish(op models.Operation) {
+	if r.notify != nil {
+		r.notify(op)
+	}
+}
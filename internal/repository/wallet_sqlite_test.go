@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSQLiteWalletRepository_WithdrawLocking проверяет, что списания из разных подключений к одному файлу,
+// как из разных процессов узла, не уводят баланс ниже нуля и не теряют операции
+func TestSQLiteWalletRepository_WithdrawLocking(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wallets.db")
+	repos := []*SQLiteWalletRepository{
+		NewSQLiteWalletRepository(openSQLite(t, path), quietLogger()),
+		NewSQLiteWalletRepository(openSQLite(t, path), quietLogger()),
+	}
+	walletID := newWalletID()
+	_, err := repos[0].db.Exec(`INSERT INTO wallets (wallet_id, balance) VALUES (?, ?)`, walletID, toCents(10))
+	require.NoError(t, err)
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+	)
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func(repo *SQLiteWalletRepository) {
+			defer wg.Done()
+			err := repo.Withdraw(walletID, 1)
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+				return
+			}
+			assert.ErrorIs(t, err, ErrInsufficientFunds)
+		}(repos[i%len(repos)])
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, succeeded)
+	wallet, err := repos[1].GetWallet(walletID)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, wallet.Balance)
+	assert.Equal(t, int64(11), wallet.Version)
+
+	operations, err := repos[1].GetOperationsAfter(walletID, 0, 100)
+	require.NoError(t, err)
+	assert.Len(t, operations, 10)
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+type WebhookRepository interface {
+	CreateEndpoint(endpoint models.WebhookEndpoint) (models.WebhookEndpoint, error)
+	ListEndpoints() ([]models.WebhookEndpoint, error)
+	DeleteEndpoint(endpointID string) error
+	EnqueueDeliveries(event models.Event, body []byte) (int, error)
+	ClaimDueDeliveries(limit int, lease time.Duration) ([]models.DueDelivery, error)
+	MarkDelivered(deliveryID int64, statusCode int) error
+	MarkFailed(deliveryID int64, statusCode int, reason string, nextAttemptAt time.Time, dead bool) error
+	ListDeliveries(endpointID, status string, limit int) ([]models.WebhookDelivery, error)
+	Redeliver(deliveryID int64) (models.WebhookDelivery, error)
+}
+
+type ApiWebhookRepository struct {
+	db     *pgxpool.Pool
+	logger *logrus.Logger
+}
+
+func NewApiWebhookRepository(db *pgxpool.Pool, logger *logrus.Logger) *ApiWebhookRepository {
+	return &ApiWebhookRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const deliveryColumns = `delivery_id, endpoint_id, event_id, event_type, payload, status, attempts,
+	next_attempt_at, last_status_code, last_error, created_at, delivered_at`
+
+// Регистрация получателя вебхуков
+func (r *ApiWebhookRepository) CreateEndpoint(endpoint models.WebhookEndpoint) (models.WebhookEndpoint, error) {
+	query := `INSERT INTO webhook_endpoints (url, secret, event_types) VALUES ($1, $2, $3)
+		RETURNING endpoint_id, created_at`
+	err := r.db.QueryRow(context.Background(), query, endpoint.URL, endpoint.Secret, endpoint.EventTypes).Scan(&endpoint.ID, &endpoint.CreatedAt)
+	if err != nil {
+		r.logger.Errorf("Error creating webhook endpoint %s: %v", endpoint.URL, err)
+		return models.WebhookEndpoint{}, err
+	}
+	r.logger.Infof("Created webhook endpoint %s for %s", endpoint.ID, endpoint.URL)
+	return endpoint, nil
+}
+
+// Получение списка получателей вебхуков без их секретов
+func (r *ApiWebhookRepository) ListEndpoints() ([]models.WebhookEndpoint, error) {
+	rows, err := r.db.Query(context.Background(), `SELECT endpoint_id, url, event_types, created_at FROM webhook_endpoints ORDER BY created_at`)
+	if err != nil {
+		r.logger.Errorf("Error listing webhook endpoints: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	endpoints := []models.WebhookEndpoint{}
+	for rows.Next() {
+		var endpoint models.WebhookEndpoint
+		if err := rows.Scan(&endpoint.ID, &endpoint.URL, &endpoint.EventTypes, &endpoint.CreatedAt); err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, rows.Err()
+}
+
+// Удаление получателя вебхуков вместе с журналом его доставок
+func (r *ApiWebhookRepository) DeleteEndpoint(endpointID string) error {
+	res, err := r.db.Exec(context.Background(), `DELETE FROM webhook_endpoints WHERE endpoint_id = $1`, endpointID)
+	if err != nil {
+		r.logger.Errorf("Error deleting webhook endpoint %s: %v", endpointID, err)
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrEndpointNotFound
+	}
+	r.logger.Infof("Deleted webhook endpoint %s", endpointID)
+	return nil
+}
+
+// Постановка события в очередь доставки всем подписанным на него получателям.
+// Повторная постановка того же события не создает дубликатов
+func (r *ApiWebhookRepository) EnqueueDeliveries(event models.Event, body []byte) (int, error) {
+	query := `INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload)
+		SELECT endpoint_id, $1, $2, $3 FROM webhook_endpoints
+		WHERE $2 = ANY (event_types) OR '*' = ANY (event_types)
+		ON CONFLICT (endpoint_id, event_id) DO NOTHING`
+	res, err := r.db.Exec(context.Background(), query, event.ID, event.Type, body)
+	if err != nil {
+		r.logger.Errorf("Error enqueuing webhook deliveries for event %d: %v", event.ID, err)
+		return 0, err
+	}
+	return int(res.RowsAffected()), nil
+}
+
+// Захват доставок, время попытки которых наступило. Захваченные доставки откладываются на lease,
+// чтобы другие экземпляры приложения не отправили их параллельно
+func (r *ApiWebhookRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]models.DueDelivery, error) {
+	query := `UPDATE webhook_deliveries d SET next_attempt_at = NOW() + make_interval(secs => $2)
+		FROM webhook_endpoints e
+		WHERE e.endpoint_id = d.endpoint_id AND d.delivery_id IN (
+			SELECT delivery_id FROM webhook_deliveries
+			WHERE status = 'PENDING' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED)
+		RETURNING d.delivery_id, d.endpoint_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+			d.next_attempt_at, d.last_status_code, d.last_error, d.created_at, d.delivered_at, e.url, e.secret`
+	rows, err := r.db.Query(context.Background(), query, limit, lease.Seconds())
+	if err != nil {
+		r.logger.Errorf("Error claiming webhook deliveries: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []models.DueDelivery
+	for rows.Next() {
+		var d models.DueDelivery
+		if err := rows.Scan(deliveryDest(&d.WebhookDelivery, &d.URL, &d.Secret)...); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// Фиксация успешной доставки
+func (r *ApiWebhookRepository) MarkDelivered(deliveryID int64, statusCode int) error {
+	_, err := r.db.Exec(context.Background(), `UPDATE webhook_deliveries
+		SET status = 'DELIVERED', attempts = attempts + 1, last_status_code = $2, last_error = NULL, delivered_at = NOW()
+		WHERE delivery_id = $1`, deliveryID, statusCode)
+	if err != nil {
+		r.logger.Errorf("Error marking webhook delivery %d as delivered: %v", deliveryID, err)
+	}
+	return err
+}
+
+// Фиксация неудачной попытки: доставка либо откладывается до nextAttemptAt, либо переводится в DEAD
+func (r *ApiWebhookRepository) MarkFailed(deliveryID int64, statusCode int, reason string, nextAttemptAt time.Time, dead bool) error {
+	status := models.DeliveryPending
+	if dead {
+		status = models.DeliveryDead
+	}
+	_, err := r.db.Exec(context.Background(), `UPDATE webhook_deliveries
+		SET status = $2, attempts = attempts + 1, last_status_code = NULLIF($3, 0), last_error = $4, next_attempt_at = $5
+		WHERE delivery_id = $1`, deliveryID, status, statusCode, reason, nextAttemptAt)
+	if err != nil {
+		r.logger.Errorf("Error marking webhook delivery %d as failed: %v", deliveryID, err)
+	}
+	return err
+}
+
+// Журнал доставок получателя, начиная с последних. Пустой status означает доставки в любом статусе
+func (r *ApiWebhookRepository) ListDeliveries(endpointID, status string, limit int) ([]models.WebhookDelivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries
+		WHERE endpoint_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY delivery_id DESC
+		LIMIT $3`
+	rows, err := r.db.Query(context.Background(), query, endpointID, status, limit)
+	if err != nil {
+		r.logger.Errorf("Error listing webhook deliveries for endpoint %s: %v", endpointID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(deliveryDest(&d)...); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// Повторная отправка доставки вручную: счетчик попыток сбрасывается, отправка назначается немедленно
+func (r *ApiWebhookRepository) Redeliver(deliveryID int64) (models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	query := `UPDATE webhook_deliveries
+		SET status = 'PENDING', attempts = 0, next_attempt_at = NOW(), delivered_at = NULL
+		WHERE delivery_id = $1
+		RETURNING ` + deliveryColumns
+	err := r.db.QueryRow(context.Background(), query, deliveryID).Scan(deliveryDest(&d)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.WebhookDelivery{}, ErrDeliveryNotFound
+	}
+	if err != nil {
+		r.logger.Errorf("Error scheduling redelivery of webhook delivery %d: %v", deliveryID, err)
+		return models.WebhookDelivery{}, err
+	}
+	r.logger.Infof("Scheduled redelivery of webhook delivery %d", deliveryID)
+	return d, nil
+}
+
+// deliveryDest возвращает адреса полей доставки в порядке deliveryColumns, дополненные extra
+func deliveryDest(d *models.WebhookDelivery, extra ...interface{}) []interface{} {
+	return append([]interface{}{
+		&d.ID, &d.EndpointID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt,
+	}, extra...)
+}
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/audit"
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// maxAdminPageLimit ограничивает количество записей истории и журнала аудита в одном ответе
+const maxAdminPageLimit = 1000
+
+// Интерфейс сервиса операций операторов. Каждое действие, включая чтение, записывается в журнал аудита
+// от имени оператора actor
+type AdminService interface {
+	CreateWallet(actor, walletID string, balance float64) (models.WalletInfo, error)
+	GetWallet(actor, walletID string) (models.WalletInfo, error)
+	History(actor, walletID string, afterID int64, limit int) ([]models.Operation, error)
+	AuditLog(actor, walletID string, afterID int64, limit int) ([]models.AuditEntry, error)
+	Adjust(actor, walletID string, amount float64, reason string) (models.Operation, error)
+	Freeze(actor, walletID, reason string) (models.WalletInfo, error)
+	Unfreeze(actor, walletID, reason string) (models.WalletInfo, error)
+	Reconcile(actor string, walletIDs []string) (models.ReconciliationReport, error)
+	Statement(actor, walletID string, from, to time.Time) (models.Statement, error)
+	VerifyAuditChain(actor string) (models.AuditVerification, error)
+	Checkpoints(actor string, afterID int64, limit int) (models.AuditCheckpointExport, error)
+	CreateCheckpoint() (models.AuditCheckpoint, bool, error)
+}
+
+// Структура сервиса операций операторов
+type ApiAdminService struct {
+	repo    repository.AdminRepository
+	wallets repository.WalletRepository
+	signer  *audit.Signer // Подпись контрольных точек журнала аудита; nil — контрольные точки не создаются
+	events  bool          // Публиковать расхождения, найденные сверкой, событиями outbox
+	logger  *logrus.Logger
+}
+
+// Конструктор для ApiAdminService
+func NewApiAdminService(repo repository.AdminRepository, wallets repository.WalletRepository, logger *logrus.Logger) *ApiAdminService {
+	return &ApiAdminService{
+		repo:    repo,
+		wallets: wallets,
+		logger:  logger,
+	}
+}
+
+// WithCheckpointSigner включает создание контрольных точек журнала аудита, подписанных signer, и возвращает сервис
+func (s *ApiAdminService) WithCheckpointSigner(signer *audit.Signer) *ApiAdminService {
+	s.signer = signer
+	return s
+}
+
+// WithDiscrepancyEvents включает публикацию события BalanceDiscrepancyDetected для каждого расхождения,
+// найденного сверкой, и возвращает сервис
+func (s *ApiAdminService) WithDiscrepancyEvents() *ApiAdminService {
+	s.events = true
+	return s
+}
+
+// Создание кошелька. Пустой walletID означает, что ID будет сгенерирован
+func (s *ApiAdminService) CreateWallet(actor, walletID string, balance float64) (models.WalletInfo, error) {
+	if err := requireActor(actor); err != nil {
+		return models.WalletInfo{}, err
+	}
+	if balance < 0 {
+		return models.WalletInfo{}, fmt.Errorf("%w: initial balance must not be negative", ErrInvalidArgument)
+	}
+
+	info, err := s.repo.CreateWallet(actor, walletID, balance)
+	if err != nil {
+		s.logger.Errorf("Failed to create wallet %s: %v", walletID, err)
+		return models.WalletInfo{}, fmt.Errorf("could not create wallet: %w", err)
+	}
+	return info, nil
+}
+
+// Получение кошелька с признаком заморозки
+func (s *ApiAdminService) GetWallet(actor, walletID string) (models.WalletInfo, error) {
+	if err := requireWallet(actor, walletID); err != nil {
+		return models.WalletInfo{}, err
+	}
+
+	info, err := s.repo.GetWalletInfo(walletID)
+	if err != nil {
+		s.logger.Errorf("Failed to get wallet %s: %v", walletID, err)
+		return models.WalletInfo{}, fmt.Errorf("could not retrieve wallet: %w", err)
+	}
+	if err := s.audit(actor, models.AuditWalletViewed, walletID, nil); err != nil {
+		return models.WalletInfo{}, err
+	}
+	return info, nil
+}
+
+// Получение операций кошелька с ID больше afterID
+func (s *ApiAdminService) History(actor, walletID string, afterID int64, limit int) ([]models.Operation, error) {
+	if err := requireWallet(actor, walletID); err != nil {
+		return nil, err
+	}
+	limit = adminPageLimit(limit)
+
+	if _, err := s.repo.GetWalletInfo(walletID); err != nil {
+		return nil, fmt.Errorf("could not retrieve wallet: %w", err)
+	}
+	operations, err := s.wallets.GetOperationsAfter(walletID, afterID, limit)
+	if err != nil {
+		s.logger.Errorf("Failed to get operations for wallet %s: %v", walletID, err)
+		return nil, fmt.Errorf("could not retrieve operations: %w", err)
+	}
+	details := map[string]interface{}{"afterId": afterID, "limit": limit}
+	if err := s.audit(actor, models.AuditHistoryViewed, walletID, details); err != nil {
+		return nil, err
+	}
+	return operations, nil
+}
+
+// Получение записей журнала аудита кошелька с ID больше afterID. Просмотр журнала тоже записывается в журнал
+func (s *ApiAdminService) AuditLog(actor, walletID string, afterID int64, limit int) ([]models.AuditEntry, error) {
+	if err := requireWallet(actor, walletID); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.GetAuditLog(walletID, afterID, adminPageLimit(limit))
+	if err != nil {
+		s.logger.Errorf("Failed to get audit log for wallet %s: %v", walletID, err)
+		return nil, fmt.Errorf("could not retrieve audit log: %w", err)
+	}
+	details := map[string]interface{}{"afterId": afterID}
+	if err := s.audit(actor, models.AuditLogViewed, walletID, details); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Ручная корректировка баланса с обязательной причиной. Положительная сумма зачисляется, отрицательная списывается
+func (s *ApiAdminService) Adjust(actor, walletID string, amount float64, reason string) (models.Operation, error) {
+	if err := requireWallet(actor, walletID); err != nil {
+		return models.Operation{}, err
+	}
+	if err := requireReason(reason); err != nil {
+		return models.Operation{}, err
+	}
+	if amount == 0 {
+		return models.Operation{}, fmt.Errorf("%w: adjustment amount must not be zero", ErrInvalidArgument)
+	}
+
+	op, err := s.repo.Adjust(actor, walletID, amount, strings.TrimSpace(reason))
+	if err != nil {
+		s.logger.Errorf("Failed to adjust wallet %s by %f: %v", walletID, amount, err)
+		return models.Operation{}, fmt.Errorf("could not adjust balance: %w", err)
+	}
+	return op, nil
+}
+
+// Заморозка кошелька: пока кошелек заморожен, операции с ним отклоняются
+func (s *ApiAdminService) Freeze(actor, walletID, reason string) (models.WalletInfo, error) {
+	return s.setFrozen(actor, walletID, true, reason)
+}
+
+// Разморозка кошелька
+func (s *ApiAdminService) Unfreeze(actor, walletID, reason string) (models.WalletInfo, error) {
+	return s.setFrozen(actor, walletID, false, reason)
+}
+
+func (s *ApiAdminService) setFrozen(actor, walletID string, frozen bool, reason string) (models.WalletInfo, error) {
+	if err := requireWallet(actor, walletID); err != nil {
+		return models.WalletInfo{}, err
+	}
+	if err := requireReason(reason); err != nil {
+		return models.WalletInfo{}, err
+	}
+
+	info, err := s.repo.SetFrozen(actor, walletID, frozen, strings.TrimSpace(reason))
+	if err != nil {
+		s.logger.Errorf("Failed to set frozen=%t for wallet %s: %v", frozen, walletID, err)
+		return models.WalletInfo{}, fmt.Errorf("could not update wallet: %w", err)
+	}
+	return info, nil
+}
+
+// Сверка балансов кошельков с журналом операций. Пустой walletIDs означает все кошельки и проверку
+// глобального инварианта
+func (s *ApiAdminService) Reconcile(actor string, walletIDs []string) (models.ReconciliationReport, error) {
+	if err := requireActor(actor); err != nil {
+		return models.ReconciliationReport{}, err
+	}
+
+	report, err := s.repo.Reconcile(walletIDs)
+	if err != nil {
+		s.logger.Errorf("Failed to reconcile wallets: %v", err)
+		return models.ReconciliationReport{}, fmt.Errorf("could not reconcile wallets: %w", err)
+	}
+	if len(report.Discrepancies) > 0 {
+		s.logger.Errorf("Reconciliation by %s found %d wallets whose balance does not match the ledger", actor, len(report.Discrepancies))
+	}
+	if report.Invariant != nil && !report.Invariant.Holds {
+		s.logger.Errorf("Ledger invariant violated: total balance %.2f, credits %.2f, debits %.2f, transfers in %.2f, out %.2f",
+			report.Invariant.TotalBalance, report.Invariant.Credits, report.Invariant.Debits, report.Invariant.TransfersIn, report.Invariant.TransfersOut)
+	}
+
+	details := map[string]interface{}{
+		"wallets":       walletIDs,
+		"checked":       report.Checked,
+		"discrepancies": len(report.Discrepancies),
+	}
+	if report.Invariant != nil {
+		details["invariantHolds"] = report.Invariant.Holds
+	}
+	var events []models.WalletDiscrepancy
+	if s.events {
+		events = report.Discrepancies
+	}
+	entry := models.AuditEntry{Actor: actor, Action: models.AuditReconciled, Details: details}
+	if _, err := s.repo.RecordReconciliation(entry, events); err != nil {
+		s.logger.Errorf("Failed to record reconciliation by %s: %v", actor, err)
+		return models.ReconciliationReport{}, fmt.Errorf("could not record audit entry: %w", err)
+	}
+	return report, nil
+}
+
+// Выписка по кошельку за период [from, to)
+func (s *ApiAdminService) Statement(actor, walletID string, from, to time.Time) (models.Statement, error) {
+	if err := requireWallet(actor, walletID); err != nil {
+		return models.Statement{}, err
+	}
+	if !from.Before(to) {
+		return models.Statement{}, fmt.Errorf("%w: statement period start must be before its end", ErrInvalidArgument)
+	}
+
+	statement, err := s.repo.Statement(walletID, from, to)
+	if err != nil {
+		s.logger.Errorf("Failed to build statement for wallet %s: %v", walletID, err)
+		return models.Statement{}, fmt.Errorf("could not build statement: %w", err)
+	}
+	details := map[string]interface{}{"from": from, "to": to, "operations": len(statement.Operations)}
+	if err := s.audit(actor, models.AuditStatementIssued, walletID, details); err != nil {
+		return models.Statement{}, err
+	}
+	return statement, nil
+}
+
+// Проверка цепочки журнала аудита от первой записи до головы со сверкой с контрольными точками.
+// Нарушение цепочки не является ошибкой: оно описывается в результате первым нарушенным звеном
+func (s *ApiAdminService) VerifyAuditChain(actor string) (models.AuditVerification, error) {
+	if err := requireActor(actor); err != nil {
+		return models.AuditVerification{}, err
+	}
+
+	if err := s.sealAudit(); err != nil {
+		return models.AuditVerification{}, err
+	}
+	checkpoints, err := s.repo.ListCheckpoints(0, 0)
+	if err != nil {
+		return models.AuditVerification{}, fmt.Errorf("could not retrieve audit checkpoints: %w", err)
+	}
+	// Без ключа подписи контрольные точки сверяются только по хэшу
+	var publicKey ed25519.PublicKey
+	if s.signer != nil {
+		publicKey = s.signer.PublicKey()
+	}
+	verifier := audit.NewVerifier(checkpoints, publicKey)
+	headSeq, headHash, err := s.repo.WalkAuditChain(verifier.Add)
+	if err != nil {
+		return models.AuditVerification{}, fmt.Errorf("could not read audit log: %w", err)
+	}
+	result := verifier.Finish(headSeq, headHash)
+	if !result.Valid {
+		s.logger.Errorf("Audit chain is broken at entry %d: %s", result.Break.Seq, result.Break.Reason)
+	}
+
+	details := map[string]interface{}{"valid": result.Valid, "checked": result.Checked, "headSeq": result.HeadSeq}
+	if result.Break != nil {
+		details["breakSeq"] = result.Break.Seq
+	}
+	if err := s.audit(actor, models.AuditChainVerified, "", details); err != nil {
+		return models.AuditVerification{}, err
+	}
+	return result, nil
+}
+
+// Выгрузка контрольных точек журнала аудита с ID больше afterID вместе с открытым ключом подписи
+func (s *ApiAdminService) Checkpoints(actor string, afterID int64, limit int) (models.AuditCheckpointExport, error) {
+	if err := requireActor(actor); err != nil {
+		return models.AuditCheckpointExport{}, err
+	}
+
+	checkpoints, err := s.repo.ListCheckpoints(afterID, adminPageLimit(limit))
+	if err != nil {
+		s.logger.Errorf("Failed to get audit checkpoints after %d: %v", afterID, err)
+		return models.AuditCheckpointExport{}, fmt.Errorf("could not retrieve audit checkpoints: %w", err)
+	}
+	export := models.AuditCheckpointExport{Checkpoints: checkpoints}
+	if s.signer != nil {
+		export.KeyID = s.signer.KeyID()
+		export.PublicKey = base64.StdEncoding.EncodeToString(s.signer.PublicKey())
+	}
+	return export, nil
+}
+
+// Создание подписанной контрольной точки по текущей голове цепочки журнала аудита.
+// Возвращает false, если цепочка пуста или для ее головы контрольная точка уже есть
+func (s *ApiAdminService) CreateCheckpoint() (models.AuditCheckpoint, bool, error) {
+	if s.signer == nil {
+		return models.AuditCheckpoint{}, false, ErrCheckpointsDisabled
+	}
+
+	if err := s.sealAudit(); err != nil {
+		return models.AuditCheckpoint{}, false, err
+	}
+	seq, hash, err := s.repo.GetAuditHead()
+	if err != nil {
+		return models.AuditCheckpoint{}, false, fmt.Errorf("could not retrieve audit chain head: %w", err)
+	}
+	if seq == 0 {
+		return models.AuditCheckpoint{}, false, nil
+	}
+	checkpoint := models.AuditCheckpoint{Seq: seq, Hash: hash}
+	s.signer.Sign(&checkpoint, time.Now())
+
+	checkpoint, created, err := s.repo.InsertCheckpoint(checkpoint)
+	if err != nil {
+		s.logger.Errorf("Failed to save audit checkpoint at entry %d: %v", seq, err)
+		return models.AuditCheckpoint{}, false, fmt.Errorf("could not save audit checkpoint: %w", err)
+	}
+	return checkpoint, created, nil
+}
+
+// sealAudit запечатывает записи журнала, зафиксированные к моменту вызова, чтобы проверка и контрольная
+// точка учитывали их, не дожидаясь фонового запечатывания
+func (s *ApiAdminService) sealAudit() error {
+	if _, err := audit.SealPending(s.repo); err != nil {
+		s.logger.Errorf("Failed to seal audit log: %v", err)
+		return fmt.Errorf("could not seal audit log: %w", err)
+	}
+	return nil
+}
+
+// audit записывает действие, не изменяющее данные. Если записать действие не удалось, его результат
+// не возвращается оператору
+func (s *ApiAdminService) audit(actor, action, walletID string, details map[string]interface{}) error {
+	_, err := s.repo.RecordAudit(models.AuditEntry{Actor: actor, Action: action, WalletID: walletID, Details: details})
+	if err != nil {
+		s.logger.Errorf("Failed to record %s by %s: %v", action, actor, err)
+		return fmt.Errorf("could not record audit entry: %w", err)
+	}
+	return nil
+}
+
+func requireActor(actor string) error {
+	if strings.TrimSpace(actor) == "" {
+		return fmt.Errorf("%w: operator identity is required", ErrInvalidArgument)
+	}
+	return nil
+}
+
+func requireWallet(actor, walletID string) error {
+	if err := requireActor(actor); err != nil {
+		return err
+	}
+	if walletID == "" {
+		return fmt.Errorf("%w: wallet ID is required", ErrInvalidArgument)
+	}
+	return nil
+}
+
+func requireReason(reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("%w: reason is required", ErrInvalidArgument)
+	}
+	return nil
+}
+
+// adminPageLimit приводит размер страницы к допустимому
+func adminPageLimit(limit int) int {
+	if limit <= 0 || limit > maxAdminPageLimit {
+		return maxAdminPageLimit
+	}
+	return limit
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/audit"
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// auditMatcher сопоставляет запись журнала аудита по оператору, действию и кошельку
+type auditMatcher struct {
+	actor, action, walletID string
+}
+
+func auditEntry(actor, action, walletID string) gomock.Matcher {
+	return auditMatcher{actor, action, walletID}
+}
+
+func (m auditMatcher) Matches(x interface{}) bool {
+	entry, ok := x.(models.AuditEntry)
+	return ok && entry.Actor == m.actor && entry.Action == m.action && entry.WalletID == m.walletID
+}
+
+func (m auditMatcher) String() string {
+	return "audit entry " + m.action + " by " + m.actor + " for wallet " + m.walletID
+}
+
+// TestApiAdminService_Validation проверяет, что некорректные действия отклоняются без обращения к репозиторию
+func TestApiAdminService_Validation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := NewApiAdminService(mock.NewMockAdminRepository(ctrl), mock.NewMockWalletRepository(ctrl), logrus.New())
+	now := time.Now()
+
+	tests := []struct {
+		name string       // Название теста
+		call func() error // Действие оператора
+	}{
+		{"Missing Operator", func() error { _, err := service.GetWallet(" ", "wallet-1"); return err }},
+		{"Missing Wallet", func() error { _, err := service.History("alice", "", 0, 10); return err }},
+		{"Negative Initial Balance", func() error { _, err := service.CreateWallet("alice", "", -1); return err }},
+		{"Missing Adjustment Reason", func() error { _, err := service.Adjust("alice", "wallet-1", 10, " "); return err }},
+		{"Zero Adjustment", func() error { _, err := service.Adjust("alice", "wallet-1", 0, "refund"); return err }},
+		{"Missing Freeze Reason", func() error { _, err := service.Freeze("alice", "wallet-1", ""); return err }},
+		{"Empty Statement Period", func() error { _, err := service.Statement("alice", "wallet-1", now, now); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.call(), ErrInvalidArgument)
+		})
+	}
+}
+
+// TestApiAdminService_Adjust проверяет передачу корректировки в репозиторий
+func TestApiAdminService_Adjust(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockAdminRepository(ctrl)
+	service := NewApiAdminService(mockRepo, mock.NewMockWalletRepository(ctrl), logrus.New())
+
+	op := models.Operation{ID: 7, WalletID: "wallet-1", Type: models.OperationAdjustmentDebit, Amount: 5, BalanceAfter: 95}
+	mockRepo.EXPECT().Adjust("alice", "wallet-1", -5.0, "duplicate payout").Return(op, nil)
+
+	result, err := service.Adjust("alice", "wallet-1", -5, "  duplicate payout ")
+	assert.NoError(t, err)
+	assert.Equal(t, op, result)
+
+	mockRepo.EXPECT().Adjust("alice", "wallet-1", -500.0, "chargeback").Return(models.Operation{}, repository.ErrInsufficientFunds)
+	_, err = service.Adjust("alice", "wallet-1", -500, "chargeback")
+	assert.ErrorIs(t, err, repository.ErrInsufficientFunds)
+}
+
+// TestApiAdminService_ReadsAreAudited проверяет, что чтения записываются в журнал аудита от имени оператора
+func TestApiAdminService_ReadsAreAudited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockAdminRepository(ctrl)
+	mockWallets := mock.NewMockWalletRepository(ctrl)
+	service := NewApiAdminService(mockRepo, mockWallets, logrus.New())
+
+	info := models.WalletInfo{ID: "wallet-1", Balance: 100, Version: 3}
+	mockRepo.EXPECT().GetWalletInfo("wallet-1").Return(info, nil).Times(2)
+	mockRepo.EXPECT().RecordAudit(auditEntry("alice", models.AuditWalletViewed, "wallet-1")).Return(models.AuditEntry{}, nil)
+	result, err := service.GetWallet("alice", "wallet-1")
+	assert.NoError(t, err)
+	assert.Equal(t, info, result)
+
+	operations := []models.Operation{{ID: 1, WalletID: "wallet-1", Type: models.OperationDeposit, Amount: 100}}
+	mockWallets.EXPECT().GetOperationsAfter("wallet-1", int64(0), maxAdminPageLimit).Return(operations, nil)
+	mockRepo.EXPECT().RecordAudit(auditEntry("alice", models.AuditHistoryViewed, "wallet-1")).Return(models.AuditEntry{}, nil)
+	history, err := service.History("alice", "wallet-1", 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, operations, history)
+
+	report := models.ReconciliationReport{Checked: 2, Discrepancies: []models.WalletDiscrepancy{}}
+	mockRepo.EXPECT().Reconcile([]string(nil)).Return(report, nil)
+	mockRepo.EXPECT().RecordReconciliation(auditEntry("alice", models.AuditReconciled, ""), nil).Return(models.AuditEntry{}, nil)
+	reconciled, err := service.Reconcile("alice", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, report, reconciled)
+}
+
+// TestApiAdminService_ReconcileEvents проверяет публикацию расхождений событиями только при включенной публикации
+func TestApiAdminService_ReconcileEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	discrepancies := []models.WalletDiscrepancy{{WalletID: "wallet-1", Balance: 51, LedgerBalance: 50, LastBalanceAfter: 50, Operations: 3}}
+	report := models.ReconciliationReport{
+		Checked:       2,
+		Discrepancies: discrepancies,
+		Invariant:     &models.LedgerInvariant{TotalBalance: 101, Credits: 100, Holds: false},
+	}
+
+	tests := []struct {
+		name   string                     // Название теста
+		events bool                       // Включена ли публикация событий
+		want   []models.WalletDiscrepancy // Расхождения, передаваемые для публикации
+	}{
+		{"Events Disabled", false, nil},
+		{"Events Enabled", true, discrepancies},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mock.NewMockAdminRepository(ctrl)
+			service := NewApiAdminService(mockRepo, mock.NewMockWalletRepository(ctrl), logrus.New())
+			if tt.events {
+				service.WithDiscrepancyEvents()
+			}
+
+			mockRepo.EXPECT().Reconcile([]string(nil)).Return(report, nil)
+			mockRepo.EXPECT().RecordReconciliation(auditEntry("reconciliation", models.AuditReconciled, ""), tt.want).Return(models.AuditEntry{}, nil)
+			reconciled, err := service.Reconcile("reconciliation", nil)
+			require.NoError(t, err)
+			assert.False(t, reconciled.Clean())
+		})
+	}
+}
+
+// TestApiAdminService_AuditFailure проверяет, что результат не возвращается, если действие не записано в журнал аудита
+func TestApiAdminService_AuditFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockAdminRepository(ctrl)
+	service := NewApiAdminService(mockRepo, mock.NewMockWalletRepository(ctrl), logrus.New())
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	mockRepo.EXPECT().Statement("wallet-1", from, to).Return(models.Statement{WalletID: "wallet-1"}, nil)
+	mockRepo.EXPECT().RecordAudit(auditEntry("alice", models.AuditStatementIssued, "wallet-1")).Return(models.AuditEntry{}, errors.New("connection refused"))
+
+	_, err := service.Statement("alice", "wallet-1", from, to)
+	assert.ErrorContains(t, err, "could not record audit entry")
+}
+
+// newTestSigner создает подписывающего контрольные точки с нулевым ключом
+func newTestSigner(t *testing.T) *audit.Signer {
+	t.Helper()
+	signer, err := audit.NewSigner(base64.StdEncoding.EncodeToString(make([]byte, ed25519.SeedSize)))
+	require.NoError(t, err)
+	return signer
+}
+
+// TestApiAdminService_VerifyAuditChain проверяет обход цепочки со сверкой с контрольной точкой
+func TestApiAdminService_VerifyAuditChain(t *testing.T) {
+	signer := newTestSigner(t)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	entries := make([]models.AuditEntry, 3)
+	for i := range entries {
+		entries[i] = models.AuditEntry{ID: int64(i + 1), Actor: "system", Action: models.AuditOperationApplied, WalletID: "wallet-1", CreatedAt: now}
+		prev := ""
+		if i > 0 {
+			prev = entries[i-1].Hash
+		}
+		require.NoError(t, audit.Seal(&entries[i], int64(i+1), prev))
+	}
+	checkpoint := models.AuditCheckpoint{ID: 1, Seq: 2, Hash: entries[1].Hash}
+	signer.Sign(&checkpoint, now)
+	tampered := append([]models.AuditEntry(nil), entries...)
+	tampered[1].Actor = "mallory"
+
+	tests := []struct {
+		name      string              // Название теста
+		entries   []models.AuditEntry // Записи журнала в базе
+		valid     bool                // Ожидаемый результат проверки
+		breakSeq  int64               // Номер первого нарушенного звена
+		checkedCp int                 // Количество сверенных контрольных точек
+	}{
+		{"Intact", entries, true, 0, 1},
+		{"Tampered", tampered, false, 2, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mock.NewMockAdminRepository(ctrl)
+			service := NewApiAdminService(mockRepo, mock.NewMockWalletRepository(ctrl), logrus.New()).WithCheckpointSigner(signer)
+
+			mockRepo.EXPECT().SealAudit(audit.SealBatch).Return(0, nil)
+			mockRepo.EXPECT().ListCheckpoints(int64(0), 0).Return([]models.AuditCheckpoint{checkpoint}, nil)
+			mockRepo.EXPECT().WalkAuditChain(gomock.Any()).DoAndReturn(func(fn func(models.AuditEntry) bool) (int64, string, error) {
+				for _, entry := range tt.entries {
+					if !fn(entry) {
+						break
+					}
+				}
+				return 3, entries[2].Hash, nil
+			})
+			mockRepo.EXPECT().RecordAudit(auditEntry("alice", models.AuditChainVerified, "")).Return(models.AuditEntry{}, nil)
+
+			result, err := service.VerifyAuditChain("alice")
+			require.NoError(t, err)
+			assert.Equal(t, tt.valid, result.Valid)
+			assert.Equal(t, tt.checkedCp, result.Checkpoints)
+			if tt.valid {
+				assert.Nil(t, result.Break)
+				assert.Equal(t, int64(3), result.Checked)
+			} else {
+				require.NotNil(t, result.Break)
+				assert.Equal(t, tt.breakSeq, result.Break.Seq)
+			}
+		})
+	}
+}
+
+// TestApiAdminService_CreateCheckpoint проверяет подпись головы цепочки и пропуск неизменившейся цепочки
+func TestApiAdminService_CreateCheckpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockAdminRepository(ctrl)
+	service := NewApiAdminService(mockRepo, mock.NewMockWalletRepository(ctrl), logrus.New())
+	_, _, err := service.CreateCheckpoint()
+	assert.ErrorIs(t, err, ErrCheckpointsDisabled)
+
+	signer := newTestSigner(t)
+	service.WithCheckpointSigner(signer)
+
+	mockRepo.EXPECT().SealAudit(audit.SealBatch).Return(0, nil)
+	mockRepo.EXPECT().GetAuditHead().Return(int64(0), "", nil)
+	_, created, err := service.CreateCheckpoint()
+	assert.NoError(t, err)
+	assert.False(t, created)
+
+	// Записи, добавленные после фонового запечатывания, запечатываются до чтения головы
+	gomock.InOrder(
+		mockRepo.EXPECT().SealAudit(audit.SealBatch).Return(2, nil),
+		mockRepo.EXPECT().GetAuditHead().Return(int64(5), "5b3c", nil),
+	)
+	mockRepo.EXPECT().InsertCheckpoint(gomock.Any()).DoAndReturn(func(cp models.AuditCheckpoint) (models.AuditCheckpoint, bool, error) {
+		assert.True(t, audit.VerifyCheckpoint(signer.PublicKey(), cp))
+		cp.ID = 1
+		return cp, true, nil
+	})
+	checkpoint, created, err := service.CreateCheckpoint()
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, int64(5), checkpoint.Seq)
+	assert.Equal(t, signer.KeyID(), checkpoint.KeyID)
+
+	mockRepo.EXPECT().SealAudit(audit.SealBatch).Return(0, errors.New("db down"))
+	_, _, err = service.CreateCheckpoint()
+	assert.Error(t, err)
+}
@@ -0,0 +1,9 @@
+package service
+
+import "errors"
+
+// ErrInvalidArgument оборачивает ошибки проверки входных данных, чтобы обработчики могли ответить 400
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// ErrCheckpointsDisabled сообщает, что ключ подписи контрольных точек журнала аудита не настроен
+var ErrCheckpointsDisabled = errors.New("audit checkpoints are disabled: signing key is not configured")
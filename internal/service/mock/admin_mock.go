@@ -0,0 +1,217 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/admin_service.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	reflect "reflect"
+	time "time"
+
+	models "github.com/VadimBorzenkov/WalletAPI/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAdminService is a mock of AdminService interface.
+type MockAdminService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminServiceMockRecorder
+}
+
+// MockAdminServiceMockRecorder is the mock recorder for MockAdminService.
+type MockAdminServiceMockRecorder struct {
+	mock *MockAdminService
+}
+
+// NewMockAdminService creates a new mock instance.
+func NewMockAdminService(ctrl *gomock.Controller) *MockAdminService {
+	mock := &MockAdminService{ctrl: ctrl}
+	mock.recorder = &MockAdminServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminService) EXPECT() *MockAdminServiceMockRecorder {
+	return m.recorder
+}
+
+// Adjust mocks base method.
+func (m *MockAdminService) Adjust(actor, walletID string, amount float64, reason string) (models.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Adjust", actor, walletID, amount, reason)
+	ret0, _ := ret[0].(models.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Adjust indicates an expected call of Adjust.
+func (mr *MockAdminServiceMockRecorder) Adjust(actor, walletID, amount, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Adjust", reflect.TypeOf((*MockAdminService)(nil).Adjust), actor, walletID, amount, reason)
+}
+
+// AuditLog mocks base method.
+func (m *MockAdminService) AuditLog(actor, walletID string, afterID int64, limit int) ([]models.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditLog", actor, walletID, afterID, limit)
+	ret0, _ := ret[0].([]models.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuditLog indicates an expected call of AuditLog.
+func (mr *MockAdminServiceMockRecorder) AuditLog(actor, walletID, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditLog", reflect.TypeOf((*MockAdminService)(nil).AuditLog), actor, walletID, afterID, limit)
+}
+
+// Checkpoints mocks base method.
+func (m *MockAdminService) Checkpoints(actor string, afterID int64, limit int) (models.AuditCheckpointExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Checkpoints", actor, afterID, limit)
+	ret0, _ := ret[0].(models.AuditCheckpointExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Checkpoints indicates an expected call of Checkpoints.
+func (mr *MockAdminServiceMockRecorder) Checkpoints(actor, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkpoints", reflect.TypeOf((*MockAdminService)(nil).Checkpoints), actor, afterID, limit)
+}
+
+// CreateCheckpoint mocks base method.
+func (m *MockAdminService) CreateCheckpoint() (models.AuditCheckpoint, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCheckpoint")
+	ret0, _ := ret[0].(models.AuditCheckpoint)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateCheckpoint indicates an expected call of CreateCheckpoint.
+func (mr *MockAdminServiceMockRecorder) CreateCheckpoint() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCheckpoint", reflect.TypeOf((*MockAdminService)(nil).CreateCheckpoint))
+}
+
+// CreateWallet mocks base method.
+func (m *MockAdminService) CreateWallet(actor, walletID string, balance float64) (models.WalletInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWallet", actor, walletID, balance)
+	ret0, _ := ret[0].(models.WalletInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWallet indicates an expected call of CreateWallet.
+func (mr *MockAdminServiceMockRecorder) CreateWallet(actor, walletID, balance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockAdminService)(nil).CreateWallet), actor, walletID, balance)
+}
+
+// Freeze mocks base method.
+func (m *MockAdminService) Freeze(actor, walletID, reason string) (models.WalletInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Freeze", actor, walletID, reason)
+	ret0, _ := ret[0].(models.WalletInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Freeze indicates an expected call of Freeze.
+func (mr *MockAdminServiceMockRecorder) Freeze(actor, walletID, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Freeze", reflect.TypeOf((*MockAdminService)(nil).Freeze), actor, walletID, reason)
+}
+
+// GetWallet mocks base method.
+func (m *MockAdminService) GetWallet(actor, walletID string) (models.WalletInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWallet", actor, walletID)
+	ret0, _ := ret[0].(models.WalletInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWallet indicates an expected call of GetWallet.
+func (mr *MockAdminServiceMockRecorder) GetWallet(actor, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockAdminService)(nil).GetWallet), actor, walletID)
+}
+
+// History mocks base method.
+func (m *MockAdminService) History(actor, walletID string, afterID int64, limit int) ([]models.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", actor, walletID, afterID, limit)
+	ret0, _ := ret[0].([]models.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockAdminServiceMockRecorder) History(actor, walletID, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockAdminService)(nil).History), actor, walletID, afterID, limit)
+}
+
+// Reconcile mocks base method.
+func (m *MockAdminService) Reconcile(actor string, walletIDs []string) (models.ReconciliationReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconcile", actor, walletIDs)
+	ret0, _ := ret[0].(models.ReconciliationReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reconcile indicates an expected call of Reconcile.
+func (mr *MockAdminServiceMockRecorder) Reconcile(actor, walletIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockAdminService)(nil).Reconcile), actor, walletIDs)
+}
+
+// Statement mocks base method.
+func (m *MockAdminService) Statement(actor, walletID string, from, to time.Time) (models.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Statement", actor, walletID, from, to)
+	ret0, _ := ret[0].(models.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Statement indicates an expected call of Statement.
+func (mr *MockAdminServiceMockRecorder) Statement(actor, walletID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Statement", reflect.TypeOf((*MockAdminService)(nil).Statement), actor, walletID, from, to)
+}
+
+// Unfreeze mocks base method.
+func (m *MockAdminService) Unfreeze(actor, walletID, reason string) (models.WalletInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unfreeze", actor, walletID, reason)
+	ret0, _ := ret[0].(models.WalletInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unfreeze indicates an expected call of Unfreeze.
+func (mr *MockAdminServiceMockRecorder) Unfreeze(actor, walletID, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unfreeze", reflect.TypeOf((*MockAdminService)(nil).Unfreeze), actor, walletID, reason)
+}
+
+// VerifyAuditChain mocks base method.
+func (m *MockAdminService) VerifyAuditChain(actor string) (models.AuditVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyAuditChain", actor)
+	ret0, _ := ret[0].(models.AuditVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyAuditChain indicates an expected call of VerifyAuditChain.
+func (mr *MockAdminServiceMockRecorder) VerifyAuditChain(actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyAuditChain", reflect.TypeOf((*MockAdminService)(nil).VerifyAuditChain), actor)
+}
@@ -7,6 +7,7 @@ package mock
 import (
 	reflect "reflect"
 
+	models "github.com/VadimBorzenkov/WalletAPI/internal/models"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -47,6 +48,51 @@ func (mr *MockWalletServiceMockRecorder) Deposit(walletID, amount interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*MockWalletService)(nil).Deposit), walletID, amount)
 }
 
+// DepositIdempotent mocks base method.
+func (m *MockWalletService) DepositIdempotent(walletID string, amount float64, key string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DepositIdempotent", walletID, amount, key)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DepositIdempotent indicates an expected call of DepositIdempotent.
+func (mr *MockWalletServiceMockRecorder) DepositIdempotent(walletID, amount, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DepositIdempotent", reflect.TypeOf((*MockWalletService)(nil).DepositIdempotent), walletID, amount, key)
+}
+
+// DepositIfVersion mocks base method.
+func (m *MockWalletService) DepositIfVersion(walletID string, amount float64, version int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DepositIfVersion", walletID, amount, version)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DepositIfVersion indicates an expected call of DepositIfVersion.
+func (mr *MockWalletServiceMockRecorder) DepositIfVersion(walletID, amount, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DepositIfVersion", reflect.TypeOf((*MockWalletService)(nil).DepositIfVersion), walletID, amount, version)
+}
+
+// ExecuteBatch mocks base method.
+func (m *MockWalletService) ExecuteBatch(items []models.BatchItem, mode string) ([]models.BatchItemResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteBatch", items, mode)
+	ret0, _ := ret[0].([]models.BatchItemResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecuteBatch indicates an expected call of ExecuteBatch.
+func (mr *MockWalletServiceMockRecorder) ExecuteBatch(items, mode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteBatch", reflect.TypeOf((*MockWalletService)(nil).ExecuteBatch), items, mode)
+}
+
 // GetBalance mocks base method.
 func (m *MockWalletService) GetBalance(walletID string) (float64, error) {
 	m.ctrl.T.Helper()
@@ -62,6 +108,66 @@ func (mr *MockWalletServiceMockRecorder) GetBalance(walletID interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockWalletService)(nil).GetBalance), walletID)
 }
 
+// GetOperationsAfter mocks base method.
+func (m *MockWalletService) GetOperationsAfter(walletID string, afterID int64, limit int) ([]models.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOperationsAfter", walletID, afterID, limit)
+	ret0, _ := ret[0].([]models.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOperationsAfter indicates an expected call of GetOperationsAfter.
+func (mr *MockWalletServiceMockRecorder) GetOperationsAfter(walletID, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOperationsAfter", reflect.TypeOf((*MockWalletService)(nil).GetOperationsAfter), walletID, afterID, limit)
+}
+
+// GetOperationsAfterEventual mocks base method.
+func (m *MockWalletService) GetOperationsAfterEventual(walletID string, afterID int64, limit int) ([]models.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOperationsAfterEventual", walletID, afterID, limit)
+	ret0, _ := ret[0].([]models.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOperationsAfterEventual indicates an expected call of GetOperationsAfterEventual.
+func (mr *MockWalletServiceMockRecorder) GetOperationsAfterEventual(walletID, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOperationsAfterEventual", reflect.TypeOf((*MockWalletService)(nil).GetOperationsAfterEventual), walletID, afterID, limit)
+}
+
+// GetWallet mocks base method.
+func (m *MockWalletService) GetWallet(walletID string) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWallet", walletID)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWallet indicates an expected call of GetWallet.
+func (mr *MockWalletServiceMockRecorder) GetWallet(walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockWalletService)(nil).GetWallet), walletID)
+}
+
+// GetWalletEventual mocks base method.
+func (m *MockWalletService) GetWalletEventual(walletID string) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletEventual", walletID)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletEventual indicates an expected call of GetWalletEventual.
+func (mr *MockWalletServiceMockRecorder) GetWalletEventual(walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletEventual", reflect.TypeOf((*MockWalletService)(nil).GetWalletEventual), walletID)
+}
+
 // Withdraw mocks base method.
 func (m *MockWalletService) Withdraw(walletID string, amount float64) error {
 	m.ctrl.T.Helper()
@@ -75,3 +181,18 @@ func (mr *MockWalletServiceMockRecorder) Withdraw(walletID, amount interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockWalletService)(nil).Withdraw), walletID, amount)
 }
+
+// WithdrawIfVersion mocks base method.
+func (m *MockWalletService) WithdrawIfVersion(walletID string, amount float64, version int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithdrawIfVersion", walletID, amount, version)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WithdrawIfVersion indicates an expected call of WithdrawIfVersion.
+func (mr *MockWalletServiceMockRecorder) WithdrawIfVersion(walletID, amount, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithdrawIfVersion", reflect.TypeOf((*MockWalletService)(nil).WithdrawIfVersion), walletID, amount, version)
+}
@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/payout_service.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	reflect "reflect"
+
+	models "github.com/VadimBorzenkov/WalletAPI/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPayoutService is a mock of PayoutService interface.
+type MockPayoutService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPayoutServiceMockRecorder
+}
+
+// MockPayoutServiceMockRecorder is the mock recorder for MockPayoutService.
+type MockPayoutServiceMockRecorder struct {
+	mock *MockPayoutService
+}
+
+// NewMockPayoutService creates a new mock instance.
+func NewMockPayoutService(ctrl *gomock.Controller) *MockPayoutService {
+	mock := &MockPayoutService{ctrl: ctrl}
+	mock.recorder = &MockPayoutServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPayoutService) EXPECT() *MockPayoutServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateJob mocks base method.
+func (m *MockPayoutService) CreateJob(rows []models.PayoutRow) (models.PayoutJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJob", rows)
+	ret0, _ := ret[0].(models.PayoutJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateJob indicates an expected call of CreateJob.
+func (mr *MockPayoutServiceMockRecorder) CreateJob(rows interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJob", reflect.TypeOf((*MockPayoutService)(nil).CreateJob), rows)
+}
+
+// GetJob mocks base method.
+func (m *MockPayoutService) GetJob(jobID string) (models.PayoutJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", jobID)
+	ret0, _ := ret[0].(models.PayoutJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockPayoutServiceMockRecorder) GetJob(jobID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockPayoutService)(nil).GetJob), jobID)
+}
+
+// ListFailedRows mocks base method.
+func (m *MockPayoutService) ListFailedRows(jobID string, afterRow, limit int) ([]models.PayoutRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFailedRows", jobID, afterRow, limit)
+	ret0, _ := ret[0].([]models.PayoutRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFailedRows indicates an expected call of ListFailedRows.
+func (mr *MockPayoutServiceMockRecorder) ListFailedRows(jobID, afterRow, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFailedRows", reflect.TypeOf((*MockPayoutService)(nil).ListFailedRows), jobID, afterRow, limit)
+}
@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/report_service.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	reflect "reflect"
+	time "time"
+
+	models "github.com/VadimBorzenkov/WalletAPI/internal/models"
+	statement "github.com/VadimBorzenkov/WalletAPI/internal/statement"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockReportService is a mock of ReportService interface.
+type MockReportService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportServiceMockRecorder
+}
+
+// MockReportServiceMockRecorder is the mock recorder for MockReportService.
+type MockReportServiceMockRecorder struct {
+	mock *MockReportService
+}
+
+// NewMockReportService creates a new mock instance.
+func NewMockReportService(ctrl *gomock.Controller) *MockReportService {
+	mock := &MockReportService{ctrl: ctrl}
+	mock.recorder = &MockReportServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportService) EXPECT() *MockReportServiceMockRecorder {
+	return m.recorder
+}
+
+// BalanceAt mocks base method.
+func (m *MockReportService) BalanceAt(walletID string, at time.Time) (models.BalanceAt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BalanceAt", walletID, at)
+	ret0, _ := ret[0].(models.BalanceAt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BalanceAt indicates an expected call of BalanceAt.
+func (mr *MockReportServiceMockRecorder) BalanceAt(walletID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BalanceAt", reflect.TypeOf((*MockReportService)(nil).BalanceAt), walletID, at)
+}
+
+// DailyReport mocks base method.
+func (m *MockReportService) DailyReport(walletID string, from, to time.Time, groupBy string) (models.DailyReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DailyReport", walletID, from, to, groupBy)
+	ret0, _ := ret[0].(models.DailyReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DailyReport indicates an expected call of DailyReport.
+func (mr *MockReportServiceMockRecorder) DailyReport(walletID, from, to, groupBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DailyReport", reflect.TypeOf((*MockReportService)(nil).DailyReport), walletID, from, to, groupBy)
+}
+
+// Statement mocks base method.
+func (m *MockReportService) Statement(walletID string, from, to time.Time, w statement.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Statement", walletID, from, to, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Statement indicates an expected call of Statement.
+func (mr *MockReportServiceMockRecorder) Statement(walletID, from, to, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Statement", reflect.TypeOf((*MockReportService)(nil).Statement), walletID, from, to, w)
+}
@@ -0,0 +1,17 @@
+package service
+
+import "github.com/VadimBorzenkov/WalletAPI/internal/models"
+
+// OriginScoper — сервис, изменения которого можно записывать в журнал аудита от имени источника запроса
+type OriginScoper interface {
+	WithOrigin(origin models.Origin) WalletService
+}
+
+// ScopeOrigin возвращает сервис, изменения которого записываются от имени origin.
+// Сервис без журнала аудита возвращается без изменений
+func ScopeOrigin(svc WalletService, origin models.Origin) WalletService {
+	if scoper, ok := svc.(OriginScoper); ok {
+		return scoper.WithOrigin(origin)
+	}
+	return svc
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// MaxPayoutRows ограничивает количество строк в одном задании на выплаты
+const MaxPayoutRows = 1_000_000
+
+// Максимальное количество строк с ошибками в одном ответе
+const maxFailedRowsLimit = 1000
+
+// Интерфейс сервиса массовых выплат
+type PayoutService interface {
+	CreateJob(rows []models.PayoutRow) (models.PayoutJob, error)
+	GetJob(jobID string) (models.PayoutJob, error)
+	ListFailedRows(jobID string, afterRow, limit int) ([]models.PayoutRow, error)
+}
+
+// Структура сервиса массовых выплат
+type ApiPayoutService struct {
+	repo   repository.PayoutRepository
+	logger *logrus.Logger
+}
+
+// Конструктор для ApiPayoutService
+func NewApiPayoutService(repo repository.PayoutRepository, logger *logrus.Logger) *ApiPayoutService {
+	return &ApiPayoutService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Создание задания на выплаты. Сами выплаты выполняются в фоне обработчиком заданий
+func (s *ApiPayoutService) CreateJob(rows []models.PayoutRow) (models.PayoutJob, error) {
+	if len(rows) == 0 {
+		return models.PayoutJob{}, fmt.Errorf("%w: payout file contains no rows", ErrInvalidArgument)
+	}
+	if len(rows) > MaxPayoutRows {
+		return models.PayoutJob{}, fmt.Errorf("%w: payout file contains more than %d rows", ErrInvalidArgument, MaxPayoutRows)
+	}
+
+	job, err := s.repo.CreateJob(rows)
+	if err != nil {
+		s.logger.Errorf("Failed to create payout job with %d rows: %v", len(rows), err)
+		return models.PayoutJob{}, fmt.Errorf("could not create payout job: %w", err)
+	}
+	s.logger.Infof("Created payout job %s with %d rows", job.ID, job.TotalRows)
+	return job, nil
+}
+
+// Получение задания на выплаты с его прогрессом
+func (s *ApiPayoutService) GetJob(jobID string) (models.PayoutJob, error) {
+	job, err := s.repo.GetJob(jobID)
+	if err != nil {
+		s.logger.Errorf("Failed to get payout job %s: %v", jobID, err)
+		return models.PayoutJob{}, fmt.Errorf("could not retrieve payout job: %w", err)
+	}
+	return job, nil
+}
+
+// Получение строк задания, завершившихся ошибкой, постранично по номеру строки
+func (s *ApiPayoutService) ListFailedRows(jobID string, afterRow, limit int) ([]models.PayoutRow, error) {
+	if limit <= 0 || limit > maxFailedRowsLimit {
+		limit = maxFailedRowsLimit
+	}
+	rows, err := s.repo.ListFailedRows(jobID, afterRow, limit)
+	if err != nil {
+		s.logger.Errorf("Failed to list failed rows of payout job %s: %v", jobID, err)
+		return nil, fmt.Errorf("could not retrieve failed rows: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestApiPayoutService_CreateJob проверяет создание задания на выплаты
+func TestApiPayoutService_CreateJob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockPayoutRepository(ctrl)
+	service := NewApiPayoutService(mockRepo, logrus.New())
+
+	amount := 10.0
+	rows := []models.PayoutRow{{Row: 1, WalletID: "wallet-1", Amount: &amount, Status: models.PayoutRowPending}}
+	mockRepo.EXPECT().CreateJob(rows).Return(models.PayoutJob{ID: "job-1", Status: models.PayoutJobRunning, TotalRows: 1}, nil)
+
+	job, err := service.CreateJob(rows)
+	assert.NoError(t, err)
+	assert.Equal(t, "job-1", job.ID)
+
+	// Пустой файл отклоняется без обращения к репозиторию
+	_, err = service.CreateJob(nil)
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+}
+
+// TestApiPayoutService_GetJob_NotFound проверяет, что отсутствие задания можно распознать по ошибке репозитория
+func TestApiPayoutService_GetJob_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockPayoutRepository(ctrl)
+	service := NewApiPayoutService(mockRepo, logrus.New())
+
+	mockRepo.EXPECT().GetJob("job-1").Return(models.PayoutJob{}, repository.ErrJobNotFound)
+
+	_, err := service.GetJob("job-1")
+	assert.ErrorIs(t, err, repository.ErrJobNotFound)
+}
+
+// TestApiPayoutService_ListFailedRows_Limit проверяет ограничение размера страницы строк с ошибками
+func TestApiPayoutService_ListFailedRows_Limit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockPayoutRepository(ctrl)
+	service := NewApiPayoutService(mockRepo, logrus.New())
+
+	tests := []struct {
+		name          string // Название теста
+		limit         int    // Запрошенный размер страницы
+		expectedLimit int    // Размер страницы, переданный в репозиторий
+	}{
+		{"Within limit", 100, 100},
+		{"Zero", 0, maxFailedRowsLimit},
+		{"Above limit", 5000, maxFailedRowsLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.EXPECT().ListFailedRows("job-1", 0, tt.expectedLimit).Return([]models.PayoutRow{}, nil)
+			_, err := service.ListFailedRows("job-1", 0, tt.limit)
+			assert.NoError(t, err)
+		})
+	}
+}
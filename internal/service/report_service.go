@@ -0,0 +1,93 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/statement"
+	"github.com/sirupsen/logrus"
+)
+
+// Интерфейс сервиса исторических балансов и отчетов
+type ReportService interface {
+	BalanceAt(walletID string, at time.Time) (models.BalanceAt, error)
+	Statement(walletID string, from, to time.Time, w statement.Writer) error
+	DailyReport(walletID string, from, to time.Time, groupBy string) (models.DailyReport, error)
+}
+
+// Структура сервиса исторических балансов и отчетов
+type ApiReportService struct {
+	repo   repository.ReportRepository
+	logger *logrus.Logger
+}
+
+// Конструктор для ApiReportService
+func NewApiReportService(repo repository.ReportRepository, logger *logrus.Logger) *ApiReportService {
+	return &ApiReportService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Баланс кошелька на прошедший момент at
+func (s *ApiReportService) BalanceAt(walletID string, at time.Time) (models.BalanceAt, error) {
+	if at.After(time.Now()) {
+		return models.BalanceAt{}, fmt.Errorf("%w: balance time must not be in the future", ErrInvalidArgument)
+	}
+
+	balance, err := s.repo.GetBalanceAt(walletID, at)
+	if err != nil {
+		s.logger.Errorf("Failed to get balance of wallet %s at %s: %v", walletID, at.Format(time.RFC3339), err)
+		return models.BalanceAt{}, fmt.Errorf("could not retrieve balance: %w", err)
+	}
+	return models.BalanceAt{WalletID: walletID, At: at, Balance: balance}, nil
+}
+
+// Выписка по кошельку за период [from, to), передаваемая w по мере чтения операций
+func (s *ApiReportService) Statement(walletID string, from, to time.Time, w statement.Writer) error {
+	if !from.Before(to) {
+		return fmt.Errorf("%w: statement period start must be before its end", ErrInvalidArgument)
+	}
+
+	if err := s.repo.StreamStatement(walletID, from, to, w); err != nil {
+		s.logger.Errorf("Failed to stream statement for wallet %s: %v", walletID, err)
+		return fmt.Errorf("could not build statement: %w", err)
+	}
+	return nil
+}
+
+// Итоги дневных отчетов за дни с from по to включительно по всем кошелькам (walletID пустой) или по одному
+// кошельку, сгруппированные по groupBy
+func (s *ApiReportService) DailyReport(walletID string, from, to time.Time, groupBy string) (models.DailyReport, error) {
+	if !isKnownReportGroup(groupBy) {
+		return models.DailyReport{}, fmt.Errorf("%w: unknown report grouping %q", ErrInvalidArgument, groupBy)
+	}
+	if from.After(to) {
+		return models.DailyReport{}, fmt.Errorf("%w: report start date must not be after its end date", ErrInvalidArgument)
+	}
+
+	periods, err := s.repo.DailyTotals(walletID, from, to, groupBy)
+	if err != nil {
+		s.logger.Errorf("Failed to get daily report from %s to %s: %v", from.Format(time.DateOnly), to.Format(time.DateOnly), err)
+		return models.DailyReport{}, fmt.Errorf("could not build daily report: %w", err)
+	}
+	return models.DailyReport{
+		WalletID: walletID,
+		From:     from.Format(time.DateOnly),
+		To:       to.Format(time.DateOnly),
+		GroupBy:  groupBy,
+		Periods:  periods,
+	}, nil
+}
+
+// isKnownReportGroup проверяет, что дневные отчеты можно сгруппировать по groupBy
+func isKnownReportGroup(groupBy string) bool {
+	for _, known := range models.ReportGroups {
+		if groupBy == known {
+			return true
+		}
+	}
+	return false
+}
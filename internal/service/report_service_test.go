@@ -0,0 +1,220 @@
+package service
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository/mock"
+	"github.com/VadimBorzenkov/WalletAPI/internal/statement"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestApiReportService_BalanceAt тестирует получение баланса на момент времени
+func TestApiReportService_BalanceAt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockReportRepository(ctrl)
+	service := NewApiReportService(mockRepo, logrus.New())
+	past := time.Date(2024, 9, 30, 23, 59, 59, 0, time.UTC)
+
+	tests := []struct {
+		name      string           // Название теста
+		at        time.Time        // Момент времени
+		mockSetup func()           // Настройка мока
+		want      models.BalanceAt // Ожидаемый результат
+		wantErr   error            // Ожидаемая ошибка
+	}{
+		{
+			name: "Past Balance",
+			at:   past,
+			mockSetup: func() {
+				mockRepo.EXPECT().GetBalanceAt("wallet-1", past).Return(125.5, nil)
+			},
+			want: models.BalanceAt{WalletID: "wallet-1", At: past, Balance: 125.5},
+		},
+		{
+			name:      "Future Time",
+			at:        time.Now().Add(time.Hour),
+			mockSetup: func() {},
+			wantErr:   ErrInvalidArgument,
+		},
+		{
+			name: "Wallet Not Found",
+			at:   past,
+			mockSetup: func() {
+				mockRepo.EXPECT().GetBalanceAt("wallet-1", past).Return(0.0, repository.ErrWalletNotFound)
+			},
+			wantErr: repository.ErrWalletNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			got, err := service.BalanceAt("wallet-1", tt.at)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestApiReportService_Statement тестирует проверку периода выписки и передачу ошибок хранилища
+func TestApiReportService_Statement(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockReportRepository(ctrl)
+	writer, err := statement.NewWriter(statement.FormatJSON, io.Discard, statement.Header{WalletID: "wallet-1"})
+	require.NoError(t, err)
+	service := NewApiReportService(mockRepo, logrus.New())
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name      string    // Название теста
+		from      time.Time // Начало периода
+		to        time.Time // Конец периода
+		mockSetup func()    // Настройка мока
+		wantErr   error     // Ожидаемая ошибка
+	}{
+		{
+			name: "Statement Streamed",
+			from: from,
+			to:   to,
+			mockSetup: func() {
+				mockRepo.EXPECT().StreamStatement("wallet-1", from, to, writer).Return(nil)
+			},
+		},
+		{
+			name:      "Empty Period",
+			from:      from,
+			to:        from,
+			mockSetup: func() {},
+			wantErr:   ErrInvalidArgument,
+		},
+		{
+			name:      "Reversed Period",
+			from:      to,
+			to:        from,
+			mockSetup: func() {},
+			wantErr:   ErrInvalidArgument,
+		},
+		{
+			name: "Wallet Not Found",
+			from: from,
+			to:   to,
+			mockSetup: func() {
+				mockRepo.EXPECT().StreamStatement("wallet-1", from, to, writer).Return(repository.ErrWalletNotFound)
+			},
+			wantErr: repository.ErrWalletNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			err := service.Statement("wallet-1", tt.from, tt.to, writer)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// TestApiReportService_DailyReport тестирует проверку параметров дневного отчета
+func TestApiReportService_DailyReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockReportRepository(ctrl)
+	service := NewApiReportService(mockRepo, logrus.New())
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)
+	periods := []models.ReportPeriod{{Period: "2026-09-01", Wallets: 2, OpeningBalance: 10, ClosingBalance: 15, Deposits: 7, Withdrawals: 2, DepositCount: 2, WithdrawalCount: 1}}
+
+	tests := []struct {
+		name      string             // Название теста
+		walletID  string             // ID кошелька; пустой — все кошельки
+		from      time.Time          // Первый день отчета
+		to        time.Time          // Последний день отчета
+		groupBy   string             // Группировка
+		mockSetup func()             // Настройка мока
+		want      models.DailyReport // Ожидаемый результат
+		wantErr   error              // Ожидаемая ошибка
+	}{
+		{
+			name:    "All Wallets By Month",
+			from:    from,
+			to:      to,
+			groupBy: models.ReportGroupMonth,
+			mockSetup: func() {
+				mockRepo.EXPECT().DailyTotals("", from, to, models.ReportGroupMonth).Return(periods, nil)
+			},
+			want: models.DailyReport{From: "2026-09-01", To: "2026-09-30", GroupBy: models.ReportGroupMonth, Periods: periods},
+		},
+		{
+			name:     "Single Day For Wallet",
+			walletID: "wallet-1",
+			from:     from,
+			to:       from,
+			groupBy:  models.ReportGroupDay,
+			mockSetup: func() {
+				mockRepo.EXPECT().DailyTotals("wallet-1", from, from, models.ReportGroupDay).Return(periods, nil)
+			},
+			want: models.DailyReport{WalletID: "wallet-1", From: "2026-09-01", To: "2026-09-01", GroupBy: models.ReportGroupDay, Periods: periods},
+		},
+		{
+			name:      "Unknown Grouping",
+			from:      from,
+			to:        to,
+			groupBy:   "year",
+			mockSetup: func() {},
+			wantErr:   ErrInvalidArgument,
+		},
+		{
+			name:      "Reversed Dates",
+			from:      to,
+			to:        from,
+			groupBy:   models.ReportGroupDay,
+			mockSetup: func() {},
+			wantErr:   ErrInvalidArgument,
+		},
+		{
+			name:     "Wallet Not Found",
+			walletID: "wallet-1",
+			from:     from,
+			to:       to,
+			groupBy:  models.ReportGroupWeek,
+			mockSetup: func() {
+				mockRepo.EXPECT().DailyTotals("wallet-1", from, to, models.ReportGroupWeek).Return(nil, repository.ErrWalletNotFound)
+			},
+			wantErr: repository.ErrWalletNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			got, err := service.DailyReport(tt.walletID, tt.from, tt.to, tt.groupBy)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
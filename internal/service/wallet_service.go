@@ -1,8 +1,10 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
 	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
 	"github.com/sirupsen/logrus"
 )
@@ -10,14 +12,23 @@ import (
 // Интерфейс сервиса для кошелька
 type WalletService interface {
 	GetBalance(walletID string) (float64, error)
+	GetWallet(walletID string) (models.Wallet, error)
 	Deposit(walletID string, amount float64) error
 	Withdraw(walletID string, amount float64) error
+	DepositIfVersion(walletID string, amount float64, version int64) (int64, error)
+	WithdrawIfVersion(walletID string, amount float64, version int64) (int64, error)
+	GetOperationsAfter(walletID string, afterID int64, limit int) ([]models.Operation, error)
+	GetWalletEventual(walletID string) (models.Wallet, error)
+	GetOperationsAfterEventual(walletID string, afterID int64, limit int) ([]models.Operation, error)
+	ExecuteBatch(items []models.BatchItem, mode string) ([]models.BatchItemResult, error)
+	DepositIdempotent(walletID string, amount float64, key string) (bool, error)
 }
 
 // Структура сервиса для API-кошелька
 type ApiWalletService struct {
-	repo   repository.WalletRepository
-	logger *logrus.Logger
+	repo    repository.WalletRepository
+	replica repository.WalletReader // Чтения с согласованностью eventual; nil — читать из repo
+	logger  *logrus.Logger
 }
 
 // Конструктор для ApiWalletService
@@ -28,6 +39,19 @@ func NewApiWalletService(repo repository.WalletRepository, logger *logrus.Logger
 	}
 }
 
+// WithReplica направляет чтения с согласованностью eventual в reader и возвращает сервис
+func (s *ApiWalletService) WithReplica(reader repository.WalletReader) *ApiWalletService {
+	s.replica = reader
+	return s
+}
+
+// WithOrigin возвращает копию сервиса, изменения которой записываются в журнал аудита от имени origin
+func (s *ApiWalletService) WithOrigin(origin models.Origin) WalletService {
+	scoped := *s
+	scoped.repo = repository.ScopeOrigin(s.repo, origin)
+	return &scoped
+}
+
 // Получение баланса кошелька
 func (s *ApiWalletService) GetBalance(walletID string) (float64, error) {
 	balance, err := s.repo.GetWalletBalance(walletID)
@@ -66,3 +90,121 @@ func (s *ApiWalletService) Withdraw(walletID string, amount float64) error {
 	s.logger.Infof("Withdrew %f from wallet %s", amount, walletID)
 	return nil
 }
+
+// Получение кошелька вместе с его версией
+func (s *ApiWalletService) GetWallet(walletID string) (models.Wallet, error) {
+	return s.getWallet(s.repo, walletID)
+}
+
+// Получение кошелька с реплики, если клиент допускает отставание от последних операций
+func (s *ApiWalletService) GetWalletEventual(walletID string) (models.Wallet, error) {
+	return s.getWallet(s.eventualReader(), walletID)
+}
+
+func (s *ApiWalletService) getWallet(reader repository.WalletReader, walletID string) (models.Wallet, error) {
+	wallet, err := reader.GetWallet(walletID)
+	if err != nil {
+		s.logger.Errorf("Failed to get wallet %s: %v", walletID, err)
+		return models.Wallet{}, fmt.Errorf("could not retrieve balance: %w", err)
+	}
+	return wallet, nil
+}
+
+// Депозит средств при совпадении версии кошелька
+func (s *ApiWalletService) DepositIfVersion(walletID string, amount float64, version int64) (int64, error) {
+	if amount <= 0 {
+		return 0, fmt.Errorf("deposit amount must be positive")
+	}
+	newVersion, err := s.repo.DepositIfVersion(walletID, amount, version)
+	if err != nil {
+		s.logger.Errorf("Failed to deposit %f to wallet %s at version %d: %v", amount, walletID, version, err)
+		return 0, fmt.Errorf("could not deposit amount: %w", err)
+	}
+	s.logger.Infof("Deposited %f to wallet %s at version %d", amount, walletID, version)
+	return newVersion, nil
+}
+
+// Вывод средств при совпадении версии кошелька
+func (s *ApiWalletService) WithdrawIfVersion(walletID string, amount float64, version int64) (int64, error) {
+	if amount <= 0 {
+		return 0, fmt.Errorf("withdrawal amount must be positive")
+	}
+	newVersion, err := s.repo.WithdrawIfVersion(walletID, amount, version)
+	if err != nil {
+		s.logger.Errorf("Failed to withdraw %f from wallet %s at version %d: %v", amount, walletID, version, err)
+		return 0, fmt.Errorf("could not withdraw amount: %w", err)
+	}
+	s.logger.Infof("Withdrew %f from wallet %s at version %d", amount, walletID, version)
+	return newVersion, nil
+}
+
+// Получение операций кошелька, выполненных после операции afterID
+func (s *ApiWalletService) GetOperationsAfter(walletID string, afterID int64, limit int) ([]models.Operation, error) {
+	return s.getOperationsAfter(s.repo, walletID, afterID, limit)
+}
+
+// Получение операций кошелька с реплики. Последние операции могут отсутствовать в результате
+func (s *ApiWalletService) GetOperationsAfterEventual(walletID string, afterID int64, limit int) ([]models.Operation, error) {
+	return s.getOperationsAfter(s.eventualReader(), walletID, afterID, limit)
+}
+
+func (s *ApiWalletService) getOperationsAfter(reader repository.WalletReader, walletID string, afterID int64, limit int) ([]models.Operation, error) {
+	operations, err := reader.GetOperationsAfter(walletID, afterID, limit)
+	if err != nil {
+		s.logger.Errorf("Failed to get operations for wallet %s after %d: %v", walletID, afterID, err)
+		return nil, fmt.Errorf("could not retrieve operations: %w", err)
+	}
+	return operations, nil
+}
+
+// eventualReader возвращает источник чтений с согласованностью eventual
+func (s *ApiWalletService) eventualReader() repository.WalletReader {
+	if s.replica != nil {
+		return s.replica
+	}
+	return s.repo
+}
+
+// Выполнение пакета операций в атомарном режиме или в режиме best-effort
+func (s *ApiWalletService) ExecuteBatch(items []models.BatchItem, mode string) ([]models.BatchItemResult, error) {
+	if mode != models.BatchAtomic && mode != models.BatchBestEffort {
+		return nil, fmt.Errorf("%w: unknown batch mode %q", ErrInvalidArgument, mode)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: batch is empty", ErrInvalidArgument)
+	}
+	for i, item := range items {
+		if item.Amount <= 0 {
+			return nil, fmt.Errorf("%w: operation %d amount must be positive", ErrInvalidArgument, i)
+		}
+	}
+
+	results, err := s.repo.ApplyBatch(items, mode == models.BatchAtomic)
+	if errors.Is(err, repository.ErrBatchRejected) {
+		s.logger.Warnf("Batch of %d operations rejected", len(items))
+		return results, err
+	}
+	if err != nil {
+		s.logger.Errorf("Failed to execute batch of %d operations: %v", len(items), err)
+		return nil, fmt.Errorf("could not execute batch: %w", err)
+	}
+	s.logger.Infof("Executed batch of %d operations in %s mode", len(items), mode)
+	return results, nil
+}
+
+// Депозит средств, который выполняется не более одного раза для ключа идемпотентности.
+// Возвращает false, если операция с этим ключом уже выполнена
+func (s *ApiWalletService) DepositIdempotent(walletID string, amount float64, key string) (bool, error) {
+	if amount <= 0 {
+		return false, fmt.Errorf("deposit amount must be positive")
+	}
+	if key == "" {
+		return false, fmt.Errorf("%w: idempotency key is required", ErrInvalidArgument)
+	}
+	applied, err := s.repo.DepositIdempotent(walletID, amount, key)
+	if err != nil {
+		s.logger.Errorf("Failed to deposit %f to wallet %s with key %s: %v", amount, walletID, key, err)
+		return false, fmt.Errorf("could not deposit amount: %w", err)
+	}
+	return applied, nil
+}
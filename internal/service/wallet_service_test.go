@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
 	"github.com/VadimBorzenkov/WalletAPI/internal/repository/mock"
 	"github.com/golang/mock/gomock"
 	"github.com/sirupsen/logrus"
@@ -35,6 +37,40 @@ func TestApiWalletService_GetBalance(t *testing.T) {
 	assert.Equal(t, expectedBalance, balance)
 }
 
+// TestApiWalletService_GetWalletEventual проверяет, что чтения с согласованностью eventual выполняются на реплике,
+// а без реплики — в основном репозитории
+func TestApiWalletService_GetWalletEventual(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockWalletRepository(ctrl)
+	mockReplica := mock.NewMockWalletRepository(ctrl)
+	walletID := "test_wallet"
+
+	// Без реплики чтение выполняется в основном репозитории
+	service := NewApiWalletService(mockRepo, logrus.New())
+	mockRepo.EXPECT().GetWallet(walletID).Return(models.Wallet{ID: walletID, Balance: 100, Version: 3}, nil)
+	wallet, err := service.GetWalletEventual(walletID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), wallet.Version)
+
+	// С репликой основной репозиторий используется только для строгих чтений
+	service.WithReplica(mockReplica)
+	mockReplica.EXPECT().GetWallet(walletID).Return(models.Wallet{ID: walletID, Balance: 90, Version: 2}, nil)
+	mockReplica.EXPECT().GetOperationsAfter(walletID, int64(5), 10).Return([]models.Operation{{ID: 6}}, nil)
+	mockRepo.EXPECT().GetWallet(walletID).Return(models.Wallet{ID: walletID, Balance: 100, Version: 3}, nil)
+
+	wallet, err = service.GetWalletEventual(walletID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), wallet.Version)
+	operations, err := service.GetOperationsAfterEventual(walletID, 5, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Operation{{ID: 6}}, operations)
+	wallet, err = service.GetWallet(walletID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), wallet.Version)
+}
+
 // TestApiWalletService_Deposit тестирует успешный случай метода Deposit в ApiWalletService
 func TestApiWalletService_Deposit(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -140,3 +176,146 @@ func TestApiWalletService_Withdraw_Failure(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, "could not withdraw amount: insufficient funds", err.Error())
 }
+
+// TestApiWalletService_WithdrawIfVersion тестирует условный вывод средств с проверкой версии кошелька
+func TestApiWalletService_WithdrawIfVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockWalletRepository(ctrl)
+	logger := logrus.New()
+	service := NewApiWalletService(mockRepo, logger)
+
+	walletID := "test_wallet"
+	amount := 30.0
+
+	// Ожидаем, что репозиторий вернет новую версию кошелька
+	mockRepo.EXPECT().WithdrawIfVersion(walletID, amount, int64(2)).Return(int64(3), nil)
+
+	// Вызываем метод WithdrawIfVersion и проверяем новую версию
+	version, err := service.WithdrawIfVersion(walletID, amount, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), version)
+}
+
+// TestApiWalletService_WithdrawIfVersion_Mismatch проверяет, что конфликт версий доступен через errors.Is
+func TestApiWalletService_WithdrawIfVersion_Mismatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockWalletRepository(ctrl)
+	logger := logrus.New()
+	service := NewApiWalletService(mockRepo, logger)
+
+	walletID := "test_wallet"
+	amount := 30.0
+
+	// Ожидаем, что репозиторий сообщит о несовпадении версии
+	mockRepo.EXPECT().WithdrawIfVersion(walletID, amount, int64(2)).Return(int64(0), repository.ErrVersionMismatch)
+
+	// Вызываем метод WithdrawIfVersion и проверяем, что ошибка сохраняет причину
+	_, err := service.WithdrawIfVersion(walletID, amount, 2)
+	assert.ErrorIs(t, err, repository.ErrVersionMismatch)
+}
+
+// TestApiWalletService_ExecuteBatch тестирует выполнение пакета операций в разных режимах
+func TestApiWalletService_ExecuteBatch(t *testing.T) {
+	items := []models.BatchItem{
+		{OperationType: models.OperationDeposit, WalletID: "wallet_a", Amount: 10},
+		{OperationType: models.OperationTransfer, WalletID: "wallet_a", ToWalletID: "wallet_b", Amount: 5},
+	}
+	results := []models.BatchItemResult{
+		{Index: 0, Status: models.BatchItemApplied},
+		{Index: 1, Status: models.BatchItemFailed, Error: "insufficient funds"},
+	}
+
+	tests := []struct {
+		name        string                                // Название теста
+		items       []models.BatchItem                    // Операции пакета
+		mode        string                                // Режим выполнения пакета
+		mockRepo    func(repo *mock.MockWalletRepository) // Настройка mock репозитория
+		wantResults []models.BatchItemResult              // Ожидаемые результаты операций
+		wantErr     error                                 // Ожидаемая ошибка
+	}{
+		{
+			name:  "Best Effort",
+			items: items,
+			mode:  models.BatchBestEffort,
+			mockRepo: func(repo *mock.MockWalletRepository) {
+				repo.EXPECT().ApplyBatch(items, false).Return(results, nil)
+			},
+			wantResults: results,
+		},
+		{
+			// Отклоненный атомарный пакет возвращает результаты вместе с ошибкой
+			name:  "Atomic Rejected",
+			items: items,
+			mode:  models.BatchAtomic,
+			mockRepo: func(repo *mock.MockWalletRepository) {
+				repo.EXPECT().ApplyBatch(items, true).Return(results, repository.ErrBatchRejected)
+			},
+			wantResults: results,
+			wantErr:     repository.ErrBatchRejected,
+		},
+		{
+			name:     "Unknown Mode",
+			items:    items,
+			mode:     "PARTIAL",
+			mockRepo: func(repo *mock.MockWalletRepository) {},
+			wantErr:  ErrInvalidArgument,
+		},
+		{
+			name:     "Non Positive Amount",
+			items:    []models.BatchItem{{OperationType: models.OperationDeposit, WalletID: "wallet_a", Amount: 0}},
+			mode:     models.BatchAtomic,
+			mockRepo: func(repo *mock.MockWalletRepository) {},
+			wantErr:  ErrInvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mock.NewMockWalletRepository(ctrl)
+			tt.mockRepo(mockRepo)
+			service := NewApiWalletService(mockRepo, logrus.New())
+
+			got, err := service.ExecuteBatch(tt.items, tt.mode)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantResults, got)
+		})
+	}
+}
+
+// TestApiWalletService_DepositIdempotent проверяет пополнение с ключом идемпотентности
+func TestApiWalletService_DepositIdempotent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockWalletRepository(ctrl)
+	service := NewApiWalletService(mockRepo, logrus.New())
+
+	// Повторное пополнение с тем же ключом не выполняется, но и не считается ошибкой
+	gomock.InOrder(
+		mockRepo.EXPECT().DepositIdempotent("test_wallet", 50.0, "payout:job:1").Return(true, nil),
+		mockRepo.EXPECT().DepositIdempotent("test_wallet", 50.0, "payout:job:1").Return(false, nil),
+	)
+
+	applied, err := service.DepositIdempotent("test_wallet", 50, "payout:job:1")
+	assert.NoError(t, err)
+	assert.True(t, applied)
+
+	applied, err = service.DepositIdempotent("test_wallet", 50, "payout:job:1")
+	assert.NoError(t, err)
+	assert.False(t, applied)
+
+	// Без ключа пополнение отклоняется без обращения к репозиторию
+	_, err = service.DepositIdempotent("test_wallet", 50, "")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+}
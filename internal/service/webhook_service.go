@@ -0,0 +1,137 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// Максимальное количество записей журнала доставок в одном ответе
+const maxDeliveriesLimit = 500
+
+// Интерфейс сервиса управления вебхуками
+type WebhookService interface {
+	RegisterEndpoint(endpointURL, secret string, eventTypes []string) (models.WebhookEndpoint, error)
+	ListEndpoints() ([]models.WebhookEndpoint, error)
+	DeleteEndpoint(endpointID string) error
+	ListDeliveries(endpointID, status string, limit int) ([]models.WebhookDelivery, error)
+	Redeliver(deliveryID int64) (models.WebhookDelivery, error)
+}
+
+// Структура сервиса управления вебхуками
+type ApiWebhookService struct {
+	repo   repository.WebhookRepository
+	logger *logrus.Logger
+}
+
+// Конструктор для ApiWebhookService
+func NewApiWebhookService(repo repository.WebhookRepository, logger *logrus.Logger) *ApiWebhookService {
+	return &ApiWebhookService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Регистрация получателя вебхуков. Если секрет не передан, он генерируется и возвращается один раз
+func (s *ApiWebhookService) RegisterEndpoint(endpointURL, secret string, eventTypes []string) (models.WebhookEndpoint, error) {
+	parsed, err := url.Parse(endpointURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return models.WebhookEndpoint{}, fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidArgument)
+	}
+	if len(eventTypes) == 0 {
+		return models.WebhookEndpoint{}, fmt.Errorf("%w: at least one event type is required", ErrInvalidArgument)
+	}
+	for _, eventType := range eventTypes {
+		if !isKnownEventType(eventType) {
+			return models.WebhookEndpoint{}, fmt.Errorf("%w: unknown event type %q", ErrInvalidArgument, eventType)
+		}
+	}
+	if secret == "" {
+		if secret, err = generateSecret(); err != nil {
+			return models.WebhookEndpoint{}, err
+		}
+	}
+
+	endpoint, err := s.repo.CreateEndpoint(models.WebhookEndpoint{URL: endpointURL, Secret: secret, EventTypes: eventTypes})
+	if err != nil {
+		s.logger.Errorf("Failed to register webhook endpoint %s: %v", endpointURL, err)
+		return models.WebhookEndpoint{}, fmt.Errorf("could not register webhook endpoint: %w", err)
+	}
+	s.logger.Infof("Registered webhook endpoint %s for %s", endpoint.ID, endpointURL)
+	return endpoint, nil
+}
+
+// Получение списка получателей вебхуков
+func (s *ApiWebhookService) ListEndpoints() ([]models.WebhookEndpoint, error) {
+	endpoints, err := s.repo.ListEndpoints()
+	if err != nil {
+		s.logger.Errorf("Failed to list webhook endpoints: %v", err)
+		return nil, fmt.Errorf("could not list webhook endpoints: %w", err)
+	}
+	return endpoints, nil
+}
+
+// Удаление получателя вебхуков
+func (s *ApiWebhookService) DeleteEndpoint(endpointID string) error {
+	if err := s.repo.DeleteEndpoint(endpointID); err != nil {
+		s.logger.Errorf("Failed to delete webhook endpoint %s: %v", endpointID, err)
+		return fmt.Errorf("could not delete webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+// Получение журнала доставок получателя
+func (s *ApiWebhookService) ListDeliveries(endpointID, status string, limit int) ([]models.WebhookDelivery, error) {
+	switch status {
+	case "", models.DeliveryPending, models.DeliveryDelivered, models.DeliveryDead:
+	default:
+		return nil, fmt.Errorf("%w: unknown delivery status %q", ErrInvalidArgument, status)
+	}
+	if limit <= 0 || limit > maxDeliveriesLimit {
+		limit = maxDeliveriesLimit
+	}
+
+	deliveries, err := s.repo.ListDeliveries(endpointID, status, limit)
+	if err != nil {
+		s.logger.Errorf("Failed to list deliveries for webhook endpoint %s: %v", endpointID, err)
+		return nil, fmt.Errorf("could not list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Повторная отправка доставки вручную
+func (s *ApiWebhookService) Redeliver(deliveryID int64) (models.WebhookDelivery, error) {
+	delivery, err := s.repo.Redeliver(deliveryID)
+	if err != nil {
+		s.logger.Errorf("Failed to redeliver webhook delivery %d: %v", deliveryID, err)
+		return models.WebhookDelivery{}, fmt.Errorf("could not redeliver webhook: %w", err)
+	}
+	return delivery, nil
+}
+
+// isKnownEventType проверяет, что на тип события можно подписаться; "*" означает все события
+func isKnownEventType(eventType string) bool {
+	if eventType == "*" {
+		return true
+	}
+	for _, known := range models.EventTypes {
+		if eventType == known {
+			return true
+		}
+	}
+	return false
+}
+
+// generateSecret создает случайный секрет для подписи вебхуков
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestApiWebhookService_RegisterEndpoint проверяет регистрацию получателя с генерацией секрета
+func TestApiWebhookService_RegisterEndpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockWebhookRepository(ctrl)
+	service := NewApiWebhookService(mockRepo, logrus.New())
+
+	// Ожидаем, что в репозиторий попадет сгенерированный секрет
+	mockRepo.EXPECT().CreateEndpoint(gomock.Any()).DoAndReturn(func(endpoint models.WebhookEndpoint) (models.WebhookEndpoint, error) {
+		assert.Len(t, endpoint.Secret, 64)
+		endpoint.ID = "endpoint-1"
+		return endpoint, nil
+	})
+
+	endpoint, err := service.RegisterEndpoint("https://merchant.example/hooks", "", []string{models.EventWalletDeposited})
+	assert.NoError(t, err)
+	assert.Equal(t, "endpoint-1", endpoint.ID)
+	assert.NotEmpty(t, endpoint.Secret)
+}
+
+// TestApiWebhookService_RegisterEndpoint_Invalid проверяет отклонение некорректных параметров без обращения к репозиторию
+func TestApiWebhookService_RegisterEndpoint_Invalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock.NewMockWebhookRepository(ctrl)
+	service := NewApiWebhookService(mockRepo, logrus.New())
+
+	tests := []struct {
+		name       string   // Название теста
+		url        string   // Адрес получателя
+		eventTypes []string // Типы событий
+	}{
+		{"Relative URL", "/hooks", []string{models.EventWalletDeposited}},
+		{"Unsupported scheme", "ftp://merchant.example", []string{models.EventWalletDeposited}},
+		{"No event types", "https://merchant.example/hooks", nil},
+		{"Unknown event type", "https://merchant.example/hooks", []string{"WalletDeleted"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.RegisterEndpoint(tt.url, "secret", tt.eventTypes)
+			assert.ErrorIs(t, err, ErrInvalidArgument)
+		})
+	}
+}
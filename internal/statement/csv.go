@@ -0,0 +1,43 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+)
+
+// Строки баланса на начало и конец периода в выписке CSV
+const (
+	csvOpeningBalance = "OPENING_BALANCE"
+	csvClosingBalance = "CLOSING_BALANCE"
+)
+
+// csvWriter пишет выписку таблицей CSV: строка баланса на начало периода, операции со знаковой суммой
+// и балансом после каждой, строка баланса на конец периода
+type csvWriter struct {
+	w      *csv.Writer
+	header Header
+}
+
+func newCSVWriter(out io.Writer, header Header) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(out), header: header}
+}
+
+func (c *csvWriter) Opening(balance float64) error {
+	c.w.Write([]string{"operation_id", "occurred_at", "operation_type", "amount", "balance"})
+	c.w.Write([]string{"", formatTime(c.header.From), csvOpeningBalance, "", formatAmount(balance)})
+	return c.w.Error()
+}
+
+func (c *csvWriter) Operation(op models.Operation) error {
+	c.w.Write([]string{strconv.FormatInt(op.ID, 10), formatTime(op.CreatedAt), op.Type, formatAmount(op.SignedAmount()), formatAmount(op.BalanceAfter)})
+	return c.w.Error()
+}
+
+func (c *csvWriter) Closing(balance float64) error {
+	c.w.Write([]string{"", formatTime(c.header.To), csvClosingBalance, "", formatAmount(balance)})
+	c.w.Flush()
+	return c.w.Error()
+}
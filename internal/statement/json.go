@@ -0,0 +1,58 @@
+package statement
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+)
+
+// jsonWriter пишет выписку объектом в формате models.Statement. Операции пишутся в массив по одной,
+// поэтому баланс на конец периода следует за ними
+type jsonWriter struct {
+	w      *bufio.Writer
+	header Header
+	count  int
+}
+
+func newJSONWriter(out io.Writer, header Header) *jsonWriter {
+	return &jsonWriter{w: bufio.NewWriter(out), header: header}
+}
+
+func (j *jsonWriter) Opening(balance float64) error {
+	head := struct {
+		WalletID       string  `json:"walletId"`
+		From           string  `json:"from"`
+		To             string  `json:"to"`
+		OpeningBalance float64 `json:"openingBalance"`
+	}{j.header.WalletID, formatTime(j.header.From), formatTime(j.header.To), balance}
+	data, err := json.Marshal(head)
+	if err != nil {
+		return err
+	}
+	// Поле operations дописывается в объект вместо закрывающей скобки
+	j.w.Write(data[:len(data)-1])
+	_, err = j.w.WriteString(`,"operations":[`)
+	return err
+}
+
+func (j *jsonWriter) Operation(op models.Operation) error {
+	data, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+	if j.count > 0 {
+		j.w.WriteByte(',')
+	}
+	j.count++
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonWriter) Closing(balance float64) error {
+	if _, err := j.w.WriteString(`],"closingBalance":` + formatAmount(balance) + "}\n"); err != nil {
+		return err
+	}
+	return j.w.Flush()
+}
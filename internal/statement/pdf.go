@@ -0,0 +1,91 @@
+package statement
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/go-pdf/fpdf"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+)
+
+// Колонки таблицы операций в выписке PDF и их ширина в миллиметрах
+var (
+	pdfColumns = []string{"ID", "Date (UTC)", "Type", "Amount", "Balance"}
+	pdfWidths  = []float64{25, 50, 45, 35, 35}
+)
+
+const pdfRowHeight = 7
+
+// pdfWriter собирает выписку документом PDF формата A4. Заголовок таблицы повторяется на каждой странице,
+// документ пишется в out при получении баланса на конец периода
+type pdfWriter struct {
+	pdf    *fpdf.Fpdf
+	out    io.Writer
+	header Header
+	table  bool
+}
+
+func newPDFWriter(out io.Writer, header Header) *pdfWriter {
+	p := &pdfWriter{pdf: fpdf.New("P", "mm", "A4", ""), out: out, header: header}
+	p.pdf.SetTitle("Account statement", false)
+	p.pdf.SetHeaderFunc(func() {
+		if p.table {
+			p.tableHeader()
+		}
+	})
+	p.pdf.SetFooterFunc(func() {
+		p.pdf.SetY(-15)
+		p.pdf.SetFont("Helvetica", "I", 8)
+		p.pdf.CellFormat(0, 10, fmt.Sprintf("Page %d/{nb}", p.pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	p.pdf.AliasNbPages("")
+	return p
+}
+
+func (p *pdfWriter) Opening(balance float64) error {
+	p.pdf.AddPage()
+	p.pdf.SetFont("Helvetica", "B", 16)
+	p.pdf.CellFormat(0, 10, "Account statement", "", 1, "L", false, 0, "")
+	p.pdf.SetFont("Helvetica", "", 10)
+	p.pdf.CellFormat(0, 6, "Wallet: "+p.header.WalletID, "", 1, "L", false, 0, "")
+	p.pdf.CellFormat(0, 6, "Period: "+formatTime(p.header.From)+" - "+formatTime(p.header.To), "", 1, "L", false, 0, "")
+	p.pdf.CellFormat(0, 6, "Opening balance: "+formatAmount(balance), "", 1, "L", false, 0, "")
+	p.pdf.Ln(4)
+	p.table = true
+	p.tableHeader()
+	return p.pdf.Error()
+}
+
+func (p *pdfWriter) Operation(op models.Operation) error {
+	p.pdf.SetFont("Helvetica", "", 9)
+	cells := []string{strconv.FormatInt(op.ID, 10), formatTime(op.CreatedAt), op.Type, formatAmount(op.SignedAmount()), formatAmount(op.BalanceAfter)}
+	for i, cell := range cells {
+		align := "L"
+		if i >= 3 {
+			align = "R"
+		}
+		p.pdf.CellFormat(pdfWidths[i], pdfRowHeight, cell, "1", 0, align, false, 0, "")
+	}
+	p.pdf.Ln(-1)
+	return p.pdf.Error()
+}
+
+func (p *pdfWriter) Closing(balance float64) error {
+	p.table = false
+	p.pdf.Ln(4)
+	p.pdf.SetFont("Helvetica", "B", 10)
+	p.pdf.CellFormat(0, 6, "Closing balance: "+formatAmount(balance), "", 1, "L", false, 0, "")
+	return p.pdf.Output(p.out)
+}
+
+// tableHeader рисует заголовок таблицы операций
+func (p *pdfWriter) tableHeader() {
+	p.pdf.SetFont("Helvetica", "B", 9)
+	p.pdf.SetFillColor(230, 230, 230)
+	for i, column := range pdfColumns {
+		p.pdf.CellFormat(pdfWidths[i], pdfRowHeight, column, "1", 0, "C", true, 0, "")
+	}
+	p.pdf.Ln(-1)
+}
@@ -0,0 +1,75 @@
+// Package statement форматирует выписки по кошельку в CSV, JSON и PDF по мере чтения журнала операций
+package statement
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+)
+
+// Форматы выписки
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatPDF  = "pdf"
+)
+
+// Writer получает выписку по мере чтения журнала операций: баланс на начало периода, операции
+// с балансом после каждой из них и баланс на конец периода
+type Writer interface {
+	Opening(balance float64) error
+	Operation(op models.Operation) error
+	Closing(balance float64) error
+}
+
+// Header — реквизиты выписки, известные до чтения журнала операций
+type Header struct {
+	WalletID string
+	From     time.Time
+	To       time.Time
+}
+
+// NewWriter создает запись выписки в формате format в out. CSV и JSON пишутся в out по мере получения операций,
+// PDF — целиком при получении баланса на конец периода, потому что таблица ссылок PDF указывает смещения
+// всех объектов документа
+func NewWriter(format string, out io.Writer, header Header) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(out, header), nil
+	case FormatJSON:
+		return newJSONWriter(out, header), nil
+	case FormatPDF:
+		return newPDFWriter(out, header), nil
+	default:
+		return nil, fmt.Errorf("unknown statement format %q", format)
+	}
+}
+
+// ContentType возвращает MIME-тип выписки в формате format
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatPDF:
+		return "application/pdf"
+	default:
+		return "application/json"
+	}
+}
+
+// Filename возвращает имя файла выписки для заголовка Content-Disposition
+func Filename(format string, header Header) string {
+	return fmt.Sprintf("statement-%s-%s-%s.%s", header.WalletID, header.From.UTC().Format("20060102"), header.To.UTC().Format("20060102"), format)
+}
+
+// formatTime возвращает время в UTC в формате RFC 3339
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatAmount возвращает сумму с двумя знаками после запятой
+func formatAmount(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
@@ -0,0 +1,126 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testHeader = Header{
+	WalletID: "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	From:     time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+	To:       time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// writeStatement передает w выписку из n операций: пополнения на 10 и каждое третье списание на 5
+func writeStatement(t *testing.T, w Writer, n int) {
+	balance := 100.0
+	require.NoError(t, w.Opening(balance))
+	for i := 1; i <= n; i++ {
+		op := models.Operation{ID: int64(i), WalletID: testHeader.WalletID, Type: models.OperationDeposit, Amount: 10, CreatedAt: testHeader.From.Add(time.Duration(i) * time.Minute)}
+		if i%3 == 0 {
+			op.Type, op.Amount = models.OperationWithdraw, 5
+		}
+		balance += op.SignedAmount()
+		op.BalanceAfter = balance
+		require.NoError(t, w.Operation(op))
+	}
+	require.NoError(t, w.Closing(balance))
+}
+
+// TestNewWriter проверяет выбор формата выписки
+func TestNewWriter(t *testing.T) {
+	tests := []struct {
+		name    string // Название теста
+		format  string // Формат выписки
+		wantErr bool   // Ожидается ли ошибка
+	}{
+		{"CSV", FormatCSV, false},
+		{"JSON", FormatJSON, false},
+		{"PDF", FormatPDF, false},
+		{"Unknown Format", "xlsx", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := NewWriter(tt.format, &bytes.Buffer{}, testHeader)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantErr, w == nil)
+		})
+	}
+}
+
+// TestCSVWriter проверяет строки выписки CSV
+func TestCSVWriter(t *testing.T) {
+	var out bytes.Buffer
+	writeStatement(t, newCSVWriter(&out, testHeader), 3)
+
+	want := "operation_id,occurred_at,operation_type,amount,balance\n" +
+		",2026-09-01T00:00:00Z,OPENING_BALANCE,,100.00\n" +
+		"1,2026-09-01T00:01:00Z,DEPOSIT,10.00,110.00\n" +
+		"2,2026-09-01T00:02:00Z,DEPOSIT,10.00,120.00\n" +
+		"3,2026-09-01T00:03:00Z,WITHDRAW,-5.00,115.00\n" +
+		",2026-10-01T00:00:00Z,CLOSING_BALANCE,,115.00\n"
+	assert.Equal(t, want, out.String())
+}
+
+// TestJSONWriter проверяет, что выписка JSON разбирается в models.Statement
+func TestJSONWriter(t *testing.T) {
+	tests := []struct {
+		name       string  // Название теста
+		operations int     // Количество операций
+		closing    float64 // Ожидаемый баланс на конец периода
+	}{
+		{"Empty Period", 0, 100},
+		{"Operations", 3, 115},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			writeStatement(t, newJSONWriter(&out, testHeader), tt.operations)
+
+			var got models.Statement
+			require.NoError(t, json.Unmarshal(out.Bytes(), &got))
+			assert.Equal(t, testHeader.WalletID, got.WalletID)
+			assert.True(t, testHeader.From.Equal(got.From))
+			assert.True(t, testHeader.To.Equal(got.To))
+			assert.Equal(t, 100.0, got.OpeningBalance)
+			assert.Equal(t, tt.closing, got.ClosingBalance)
+			assert.NotNil(t, got.Operations)
+			assert.Len(t, got.Operations, tt.operations)
+		})
+	}
+}
+
+// TestPDFWriter проверяет, что выписка PDF собирается в документ и переносит таблицу на новые страницы
+func TestPDFWriter(t *testing.T) {
+	tests := []struct {
+		name       string // Название теста
+		operations int    // Количество операций
+		pages      int    // Ожидаемое количество страниц
+	}{
+		{"Single Page", 3, 1},
+		{"Multiple Pages", 100, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			w := newPDFWriter(&out, testHeader)
+			w.pdf.SetCompression(false)
+			writeStatement(t, w, tt.operations)
+
+			assert.True(t, bytes.HasPrefix(out.Bytes(), []byte("%PDF-")))
+			assert.Regexp(t, regexp.MustCompile(fmt.Sprintf(`/Count %d\b`, tt.pages)), out.String())
+			assert.Contains(t, out.String(), "Closing balance: ")
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package stream
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+)
+
+// Размер буфера подписки. Подписчик, не успевающий читать операции, отключается
+// и должен переподключиться с Last-Event-ID, чтобы дочитать пропущенное из журнала
+const subscriptionBuffer = 64
+
+// Hub раздает операции подписчикам, следящим за конкретными кошельками
+type Hub struct {
+	mu   sync.Mutex
+	subs map[string]map[*Subscription]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: make(map[string]map[*Subscription]struct{})}
+}
+
+// Subscription — подписка на операции одного кошелька
+type Subscription struct {
+	hub      *Hub
+	walletID string
+	events   chan models.Operation
+	closed   bool
+}
+
+// Events возвращает канал операций. Канал закрывается, когда подписка завершена хабом или вызовом Close
+func (s *Subscription) Events() <-chan models.Operation {
+	return s.events
+}
+
+// Close отменяет подписку
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// Subscribe подписывает на операции кошелька
+func (h *Hub) Subscribe(walletID string) *Subscription {
+	walletID = walletKey(walletID)
+	sub := &Subscription{hub: h, walletID: walletID, events: make(chan models.Operation, subscriptionBuffer)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[walletID] == nil {
+		h.subs[walletID] = make(map[*Subscription]struct{})
+	}
+	h.subs[walletID][sub] = struct{}{}
+	return sub
+}
+
+// Publish передает операцию подписчикам ее кошелька, не блокируясь на медленных подписчиках
+func (h *Hub) Publish(op models.Operation) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs[walletKey(op.WalletID)] {
+		select {
+		case sub.events <- op:
+		default:
+			h.remove(sub)
+		}
+	}
+}
+
+// Reset завершает все подписки. Используется, когда уведомления могли быть потеряны
+func (h *Hub) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, subs := range h.subs {
+		for sub := range subs {
+			h.remove(sub)
+		}
+	}
+}
+
+// remove удаляет подписку и закрывает ее канал; вызывается под h.mu
+func (h *Hub) remove(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.events)
+	delete(h.subs[sub.walletID], sub)
+	if len(h.subs[sub.walletID]) == 0 {
+		delete(h.subs, sub.walletID)
+	}
+}
+
+// walletKey приводит ID кошелька к нижнему регистру: Postgres отдает UUID в нижнем регистре,
+// а клиент может передать его в любом
+func walletKey(walletID string) string {
+	return strings.ToLower(walletID)
+}
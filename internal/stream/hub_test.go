@@ -0,0 +1,68 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHub_PublishToWalletSubscribers проверяет, что операция доходит только до подписчиков ее кошелька
+func TestHub_PublishToWalletSubscribers(t *testing.T) {
+	hub := NewHub()
+	subA := hub.Subscribe("wallet-a")
+	subB := hub.Subscribe("wallet-b")
+	defer subA.Close()
+	defer subB.Close()
+
+	hub.Publish(models.Operation{ID: 1, WalletID: "wallet-a"})
+
+	assert.Equal(t, int64(1), (<-subA.Events()).ID)
+	assert.Len(t, subB.Events(), 0)
+}
+
+// TestHub_CaseInsensitiveWalletID проверяет, что подписка с ID в верхнем регистре получает операции,
+// которые Postgres присылает с ID в нижнем регистре, и корректно отменяется
+func TestHub_CaseInsensitiveWalletID(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe("3FA85F64-5717-4562-B3FC-2C963F66AFA6")
+
+	hub.Publish(models.Operation{ID: 1, WalletID: "3fa85f64-5717-4562-b3fc-2c963f66afa6"})
+	assert.Equal(t, int64(1), (<-sub.Events()).ID)
+
+	sub.Close()
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	assert.Empty(t, hub.subs)
+}
+
+// TestHub_DropsSlowSubscriber проверяет, что переполненная подписка завершается, не блокируя публикацию
+func TestHub_DropsSlowSubscriber(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe("wallet-a")
+
+	for i := 0; i <= subscriptionBuffer; i++ {
+		hub.Publish(models.Operation{ID: int64(i), WalletID: "wallet-a"})
+	}
+
+	// Канал содержит буферизованные операции и затем закрыт
+	received := 0
+	for range sub.Events() {
+		received++
+	}
+	assert.Equal(t, subscriptionBuffer, received)
+
+	// Повторное закрытие подписки безопасно
+	sub.Close()
+}
+
+// TestHub_Reset проверяет завершение всех подписок
+func TestHub_Reset(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe("wallet-a")
+
+	hub.Reset()
+
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+}
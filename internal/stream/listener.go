@@ -0,0 +1,124 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// Sink получает операции, принятые Listener. Reset вызывается, когда уведомления могли быть потеряны
+type Sink interface {
+	Publish(op models.Operation)
+	Reset()
+}
+
+// Sinks передает операции нескольким получателям по порядку
+type Sinks []Sink
+
+func (s Sinks) Publish(op models.Operation) {
+	for _, sink := range s {
+		sink.Publish(op)
+	}
+}
+
+func (s Sinks) Reset() {
+	for _, sink := range s {
+		sink.Reset()
+	}
+}
+
+// Listener получает уведомления об операциях через LISTEN/NOTIFY и передает их получателю: хабу потоков, кэшу.
+// Каждая реплика приложения слушает канал сама, поэтому получатели узнают об операциях, выполненных любой репликой
+type Listener struct {
+	dsn    string
+	sink   Sink
+	logger *logrus.Logger
+}
+
+func NewListener(dsn string, sink Sink, logger *logrus.Logger) *Listener {
+	return &Listener{
+		dsn:    dsn,
+		sink:   sink,
+		logger: logger,
+	}
+}
+
+// Границы паузы перед повторным подключением после разрыва соединения
+const (
+	reconnectMin = time.Second
+	reconnectMax = time.Minute
+)
+
+// Период проверки простаивающего соединения
+const keepAlive = 30 * time.Second
+
+// Run слушает канал операций до отмены контекста. Разорванное соединение восстанавливается с растущей паузой
+func (l *Listener) Run(ctx context.Context) error {
+	config, err := pgx.ParseConfig(l.dsn)
+	if err != nil {
+		return err
+	}
+	// Соединение без уведомлений ничего не читает и не пишет, поэтому его разрыв обнаруживается проверками TCP keepalive
+	config.DialFunc = (&net.Dialer{Timeout: 10 * time.Second, KeepAlive: keepAlive}).DialContext
+
+	backoff := reconnectMin
+	for connected := false; ; {
+		err := l.listen(ctx, config, func() {
+			if connected {
+				// Уведомления за время разрыва потеряны. Подписчики переподключатся и дочитают операции
+				// из журнала по Last-Event-ID, кэш будет очищен
+				l.sink.Reset()
+			}
+			connected = true
+			backoff = reconnectMin
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		l.logger.Warnf("Operations listener: %v, reconnecting in %s", err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > reconnectMax {
+			backoff = reconnectMax
+		}
+	}
+}
+
+// listen подключается к базе, подписывается на канал операций и передает уведомления получателю до ошибки
+// соединения или отмены контекста. onListen вызывается после успешной подписки
+func (l *Listener) listen(ctx context.Context, config *pgx.ConnConfig, onListen func()) error {
+	conn, err := pgx.ConnectConfig(ctx, config)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{repository.OperationsChannel}.Sanitize()); err != nil {
+		return err
+	}
+	l.logger.Infof("Listening for wallet operations on channel %s", repository.OperationsChannel)
+	onListen()
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var op models.Operation
+		if err := json.Unmarshal([]byte(n.Payload), &op); err != nil {
+			l.logger.Errorf("Invalid operation notification: %v", err)
+			continue
+		}
+		l.sink.Publish(op)
+	}
+}
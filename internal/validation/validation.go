@@ -0,0 +1,54 @@
+// Package validation содержит правила проверки входных данных, общие для HTTP и gRPC API
+package validation
+
+import (
+	"errors"
+	"math"
+	"regexp"
+)
+
+// MaxTransactionAmount ограничивает сумму одной операции
+const MaxTransactionAmount = 1_000_000_000
+
+// Количество знаков после запятой, допустимое в сумме операции
+const amountPrecision = 2
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+var (
+	ErrRequired      = errors.New("is required")
+	ErrInvalidUUID   = errors.New("must be a valid UUID")
+	ErrNotFinite     = errors.New("must be a finite number")
+	ErrNotPositive   = errors.New("must be positive")
+	ErrAmountTooHigh = errors.New("must not exceed 1000000000")
+	ErrPrecision     = errors.New("must have at most 2 decimal places")
+)
+
+// UUID проверяет, что ID кошелька, задания или получателя является UUID
+func UUID(id string) error {
+	switch {
+	case id == "":
+		return ErrRequired
+	case !uuidPattern.MatchString(id):
+		return ErrInvalidUUID
+	}
+	return nil
+}
+
+// Amount проверяет конечность, знак, верхнюю границу и точность суммы операции.
+// NaN и бесконечности, которые не выражаются в JSON, но допустимы в protobuf, отклоняются первыми
+func Amount(amount float64) error {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return ErrNotFinite
+	}
+	scaled := amount * math.Pow10(amountPrecision)
+	switch {
+	case amount <= 0:
+		return ErrNotPositive
+	case amount > MaxTransactionAmount:
+		return ErrAmountTooHigh
+	case math.Abs(scaled-math.Round(scaled)) > 1e-6:
+		return ErrPrecision
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package validation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAmount проверяет правила суммы операции, общие для HTTP и gRPC API
+func TestAmount(t *testing.T) {
+	tests := []struct {
+		name        string  // Название теста
+		amount      float64 // Проверяемая сумма
+		expectedErr error   // Ожидаемая ошибка
+	}{
+		{name: "Valid", amount: 19.99},
+		{name: "Maximum", amount: MaxTransactionAmount},
+		{name: "Zero", amount: 0, expectedErr: ErrNotPositive},
+		{name: "Negative", amount: -5, expectedErr: ErrNotPositive},
+		{name: "NaN", amount: math.NaN(), expectedErr: ErrNotFinite},
+		{name: "Positive Infinity", amount: math.Inf(1), expectedErr: ErrNotFinite},
+		{name: "Negative Infinity", amount: math.Inf(-1), expectedErr: ErrNotFinite},
+		{name: "Above Maximum", amount: MaxTransactionAmount + 0.01, expectedErr: ErrAmountTooHigh},
+		{name: "Too Many Decimal Places", amount: 10.005, expectedErr: ErrPrecision},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedErr, Amount(tt.amount))
+		})
+	}
+}
+
+// TestUUID проверяет формат ID
+func TestUUID(t *testing.T) {
+	assert.NoError(t, UUID("3fa85f64-5717-4562-b3fc-2c963f66afa6"))
+	assert.NoError(t, UUID("3FA85F64-5717-4562-B3FC-2C963F66AFA6"))
+	assert.Equal(t, ErrRequired, UUID(""))
+	assert.Equal(t, ErrInvalidUUID, UUID("wallet-123"))
+}
@@ -0,0 +1,470 @@
+// Package walletctl реализует команды walletctl — утилиты операторов для обслуживания кошельков.
+// Команды работают через service.AdminService, поэтому каждое действие записывается в журнал аудита
+// от имени оператора
+package walletctl
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service"
+)
+
+const usage = `Usage: walletctl [-operator NAME] [-output table|json] <command> [arguments]
+
+Commands:
+  create [-id ID] [-balance AMOUNT]             create a wallet, optionally with an opening balance
+  balance WALLET                                show the balance, version and state of a wallet
+  history [-after ID] [-limit N] WALLET         list wallet operations
+  audit [-after ID] [-limit N] WALLET           list audit log entries of a wallet
+  adjust -reason TEXT WALLET AMOUNT             credit (positive) or debit (negative) a wallet manually
+  freeze -reason TEXT WALLET                    reject operations with a wallet
+  unfreeze -reason TEXT WALLET                  accept operations with a wallet again
+  reconcile [WALLET...]                         compare balances with the operation journal (all wallets by default)
+  statement -from DATE -to DATE WALLET          export a statement for [from, to); DATE is YYYY-MM-DD or RFC 3339
+  verify                                        verify the audit log hash chain and report the first broken link
+  checkpoints [-after ID] [-limit N]            export signed audit checkpoints with the public key
+  checkpoint                                    sign the current head of the audit log now
+
+The operator defaults to $WALLETCTL_OPERATOR, then to the OS user name.
+`
+
+// ErrUsage сообщает, что команда или ее аргументы заданы неверно
+var ErrUsage = errors.New("invalid usage")
+
+// ErrDiscrepancies сообщает, что сверка нашла кошельки, балансы которых не сходятся с журналом операций,
+// или нарушение глобального инварианта
+var ErrDiscrepancies = errors.New("reconciliation found discrepancies")
+
+// ErrChainBroken сообщает, что проверка нашла нарушение цепочки журнала аудита
+var ErrChainBroken = errors.New("audit chain is broken")
+
+// Connect подключается к хранилищу и возвращает сервис операций операторов и функцию, закрывающую подключение
+type Connect func() (service.AdminService, func(), error)
+
+// command — разобранная команда, готовая к выполнению
+type command func(c *cli) error
+
+// cli хранит общие параметры команд
+type cli struct {
+	svc      service.AdminService
+	out      io.Writer
+	operator string
+	json     bool
+}
+
+// Run разбирает аргументы, подключается к хранилищу через connect и выполняет команду, выводя результат в out.
+// Ошибки разбора аргументов оборачивают ErrUsage, подключение к хранилищу при этом не выполняется
+func Run(args []string, out io.Writer, connect Connect) error {
+	global := flag.NewFlagSet("walletctl", flag.ContinueOnError)
+	global.SetOutput(io.Discard)
+	operator := global.String("operator", defaultOperator(), "operator identity recorded in the audit log")
+	output := global.String("output", "table", "output format: table or json")
+	if err := global.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if *output != "table" && *output != "json" {
+		return usageError(fmt.Errorf("unknown output format %q", *output))
+	}
+	if strings.TrimSpace(*operator) == "" {
+		return usageError(errors.New("operator identity is required: pass -operator or set WALLETCTL_OPERATOR"))
+	}
+
+	run, err := parseCommand(global.Args())
+	if err != nil {
+		return usageError(err)
+	}
+
+	svc, closeConn, err := connect()
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+	return run(&cli{svc: svc, out: out, operator: *operator, json: *output == "json"})
+}
+
+// Usage возвращает справку по командам
+func Usage() string {
+	return usage
+}
+
+func usageError(err error) error {
+	return fmt.Errorf("%w: %v", ErrUsage, err)
+}
+
+// defaultOperator возвращает оператора по умолчанию: WALLETCTL_OPERATOR или имя пользователя ОС
+func defaultOperator() string {
+	if operator := os.Getenv("WALLETCTL_OPERATOR"); operator != "" {
+		return operator
+	}
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return ""
+}
+
+// parseCommand разбирает команду и ее аргументы. Флаги команды указываются до позиционных аргументов
+func parseCommand(args []string) (command, error) {
+	if len(args) == 0 {
+		return nil, errors.New("missing command")
+	}
+	name, args := args[0], args[1:]
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	switch name {
+	case "create":
+		id := flags.String("id", "", "wallet ID; generated when empty")
+		balance := flags.Float64("balance", 0, "opening balance")
+		if err := parseArgs(flags, args, 0); err != nil {
+			return nil, err
+		}
+		return func(c *cli) error {
+			info, err := c.svc.CreateWallet(c.operator, *id, *balance)
+			if err != nil {
+				return err
+			}
+			return c.printWallet(info)
+		}, nil
+
+	case "balance":
+		if err := parseArgs(flags, args, 1); err != nil {
+			return nil, err
+		}
+		walletID := flags.Arg(0)
+		return func(c *cli) error {
+			info, err := c.svc.GetWallet(c.operator, walletID)
+			if err != nil {
+				return err
+			}
+			return c.printWallet(info)
+		}, nil
+
+	case "history", "audit":
+		after := flags.Int64("after", 0, "show entries with ID greater than this")
+		limit := flags.Int("limit", 100, "maximum number of entries")
+		if err := parseArgs(flags, args, 1); err != nil {
+			return nil, err
+		}
+		walletID := flags.Arg(0)
+		if name == "audit" {
+			return func(c *cli) error {
+				entries, err := c.svc.AuditLog(c.operator, walletID, *after, *limit)
+				if err != nil {
+					return err
+				}
+				return c.printAudit(entries)
+			}, nil
+		}
+		return func(c *cli) error {
+			operations, err := c.svc.History(c.operator, walletID, *after, *limit)
+			if err != nil {
+				return err
+			}
+			return c.printOperations(operations)
+		}, nil
+
+	case "adjust":
+		reason := flags.String("reason", "", "reason for the adjustment (required)")
+		if err := parseArgs(flags, args, 2); err != nil {
+			return nil, err
+		}
+		walletID := flags.Arg(0)
+		amount, err := strconv.ParseFloat(flags.Arg(1), 64)
+		if err != nil {
+			return nil, fmt.Errorf("adjust: invalid amount %q", flags.Arg(1))
+		}
+		if strings.TrimSpace(*reason) == "" {
+			return nil, errors.New("adjust: -reason is required")
+		}
+		return func(c *cli) error {
+			op, err := c.svc.Adjust(c.operator, walletID, amount, *reason)
+			if err != nil {
+				return err
+			}
+			return c.printOperations([]models.Operation{op})
+		}, nil
+
+	case "freeze", "unfreeze":
+		reason := flags.String("reason", "", "reason for the change (required)")
+		if err := parseArgs(flags, args, 1); err != nil {
+			return nil, err
+		}
+		walletID := flags.Arg(0)
+		if strings.TrimSpace(*reason) == "" {
+			return nil, fmt.Errorf("%s: -reason is required", name)
+		}
+		return func(c *cli) error {
+			setFrozen := c.svc.Freeze
+			if name == "unfreeze" {
+				setFrozen = c.svc.Unfreeze
+			}
+			info, err := setFrozen(c.operator, walletID, *reason)
+			if err != nil {
+				return err
+			}
+			return c.printWallet(info)
+		}, nil
+
+	case "reconcile":
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		walletIDs := flags.Args()
+		return func(c *cli) error {
+			report, err := c.svc.Reconcile(c.operator, walletIDs)
+			if err != nil {
+				return err
+			}
+			if err := c.printReconciliation(report); err != nil {
+				return err
+			}
+			if len(report.Discrepancies) > 0 {
+				return fmt.Errorf("%w: %d of %d wallets", ErrDiscrepancies, len(report.Discrepancies), report.Checked)
+			}
+			if !report.Clean() {
+				return fmt.Errorf("%w: ledger invariant violated", ErrDiscrepancies)
+			}
+			return nil
+		}, nil
+
+	case "statement":
+		fromArg := flags.String("from", "", "period start, inclusive")
+		toArg := flags.String("to", "", "period end, exclusive")
+		if err := parseArgs(flags, args, 1); err != nil {
+			return nil, err
+		}
+		walletID := flags.Arg(0)
+		from, err := parseTime(*fromArg)
+		if err != nil {
+			return nil, fmt.Errorf("statement: -from: %v", err)
+		}
+		to, err := parseTime(*toArg)
+		if err != nil {
+			return nil, fmt.Errorf("statement: -to: %v", err)
+		}
+		return func(c *cli) error {
+			statement, err := c.svc.Statement(c.operator, walletID, from, to)
+			if err != nil {
+				return err
+			}
+			return c.printStatement(statement)
+		}, nil
+
+	case "verify":
+		if err := parseArgs(flags, args, 0); err != nil {
+			return nil, err
+		}
+		return func(c *cli) error {
+			result, err := c.svc.VerifyAuditChain(c.operator)
+			if err != nil {
+				return err
+			}
+			if err := c.printVerification(result); err != nil {
+				return err
+			}
+			if !result.Valid {
+				return fmt.Errorf("%w at entry %d: %s", ErrChainBroken, result.Break.Seq, result.Break.Reason)
+			}
+			return nil
+		}, nil
+
+	case "checkpoints":
+		after := flags.Int64("after", 0, "show checkpoints with ID greater than this")
+		limit := flags.Int("limit", 100, "maximum number of checkpoints")
+		if err := parseArgs(flags, args, 0); err != nil {
+			return nil, err
+		}
+		return func(c *cli) error {
+			export, err := c.svc.Checkpoints(c.operator, *after, *limit)
+			if err != nil {
+				return err
+			}
+			return c.printCheckpoints(export)
+		}, nil
+
+	case "checkpoint":
+		if err := parseArgs(flags, args, 0); err != nil {
+			return nil, err
+		}
+		return func(c *cli) error {
+			checkpoint, created, err := c.svc.CreateCheckpoint()
+			if err != nil {
+				return err
+			}
+			export := models.AuditCheckpointExport{Checkpoints: []models.AuditCheckpoint{}}
+			if created {
+				export.Checkpoints = append(export.Checkpoints, checkpoint)
+			} else if !c.json {
+				fmt.Fprintln(c.out, "The audit log has not changed since the last checkpoint")
+				return nil
+			}
+			return c.printCheckpoints(export)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown command %q", name)
+}
+
+// parseArgs разбирает флаги команды и проверяет количество позиционных аргументов
+func parseArgs(flags *flag.FlagSet, args []string, positional int) error {
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%s: %v", flags.Name(), err)
+	}
+	if flags.NArg() != positional {
+		return fmt.Errorf("%s: expected %d arguments, got %d", flags.Name(), positional, flags.NArg())
+	}
+	return nil
+}
+
+// parseTime разбирает дату в формате YYYY-MM-DD (начало суток UTC) или время в формате RFC 3339
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, errors.New("value is required")
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected YYYY-MM-DD or RFC 3339", value)
+	}
+	return t, nil
+}
+
+func (c *cli) printWallet(info models.WalletInfo) error {
+	if c.json {
+		return c.printJSON(info)
+	}
+	w := c.table("WALLET\tBALANCE\tVERSION\tFROZEN\tCREATED")
+	fmt.Fprintf(w, "%s\t%.2f\t%d\t%t\t%s\n", info.ID, info.Balance, info.Version, info.Frozen, formatTime(info.CreatedAt))
+	return w.Flush()
+}
+
+func (c *cli) printOperations(operations []models.Operation) error {
+	if c.json {
+		return c.printJSON(operations)
+	}
+	w := c.table("ID\tTYPE\tAMOUNT\tBALANCE\tVERSION\tTIME")
+	for _, op := range operations {
+		fmt.Fprintf(w, "%d\t%s\t%.2f\t%.2f\t%d\t%s\n", op.ID, op.Type, op.Amount, op.BalanceAfter, op.Version, formatTime(op.CreatedAt))
+	}
+	return w.Flush()
+}
+
+func (c *cli) printAudit(entries []models.AuditEntry) error {
+	if c.json {
+		return c.printJSON(entries)
+	}
+	w := c.table("ID\tTIME\tOPERATOR\tACTION\tREQUEST\tBEFORE\tAFTER\tDETAILS")
+	for _, entry := range entries {
+		details, err := json.Marshal(entry.Details)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", entry.ID, formatTime(entry.CreatedAt), entry.Actor, entry.Action,
+			orDash(entry.RequestID), formatBalance(entry.BalanceBefore), formatBalance(entry.BalanceAfter), details)
+	}
+	return w.Flush()
+}
+
+func (c *cli) printVerification(result models.AuditVerification) error {
+	if c.json {
+		return c.printJSON(result)
+	}
+	fmt.Fprintf(c.out, "Checked entries: %d\nCheckpoints matched: %d\nHead: %d %s\n", result.Checked, result.Checkpoints, result.HeadSeq, orDash(result.HeadHash))
+	if result.Break != nil {
+		fmt.Fprintf(c.out, "Broken at entry %d (audit ID %d): %s\n", result.Break.Seq, result.Break.AuditID, result.Break.Reason)
+	}
+	return nil
+}
+
+func (c *cli) printCheckpoints(export models.AuditCheckpointExport) error {
+	if c.json {
+		return c.printJSON(export)
+	}
+	if export.PublicKey != "" {
+		fmt.Fprintf(c.out, "Key: %s %s\n\n", export.KeyID, export.PublicKey)
+	}
+	w := c.table("ID\tSEQ\tHASH\tTIME\tKEY\tSIGNATURE")
+	for _, cp := range export.Checkpoints {
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\n", cp.ID, cp.Seq, cp.Hash, formatTime(cp.CreatedAt), cp.KeyID, cp.Signature)
+	}
+	return w.Flush()
+}
+
+func (c *cli) printReconciliation(report models.ReconciliationReport) error {
+	if c.json {
+		return c.printJSON(report)
+	}
+	fmt.Fprintf(c.out, "Checked wallets: %d\nDiscrepancies: %d\n", report.Checked, len(report.Discrepancies))
+	if inv := report.Invariant; inv != nil {
+		fmt.Fprintf(c.out, "Total balance: %.2f\nCredits: %.2f\nDebits: %.2f\nTransfers in/out: %.2f/%.2f\nInvariant holds: %t\n",
+			inv.TotalBalance, inv.Credits, inv.Debits, inv.TransfersIn, inv.TransfersOut, inv.Holds)
+	}
+	if len(report.Discrepancies) == 0 {
+		return nil
+	}
+	fmt.Fprintln(c.out)
+	w := c.table("WALLET\tBALANCE\tLEDGER\tLAST BALANCE\tOPERATIONS")
+	for _, d := range report.Discrepancies {
+		fmt.Fprintf(w, "%s\t%.2f\t%.2f\t%.2f\t%d\n", d.WalletID, d.Balance, d.LedgerBalance, d.LastBalanceAfter, d.Operations)
+	}
+	return w.Flush()
+}
+
+func (c *cli) printStatement(statement models.Statement) error {
+	if c.json {
+		return c.printJSON(statement)
+	}
+	fmt.Fprintf(c.out, "Wallet: %s\nPeriod: %s - %s\nOpening balance: %.2f\n\n",
+		statement.WalletID, formatTime(statement.From), formatTime(statement.To), statement.OpeningBalance)
+	if err := c.printOperations(statement.Operations); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "\nClosing balance: %.2f\n", statement.ClosingBalance)
+	return nil
+}
+
+// table создает таблицу с выровненными колонками и выводит ее заголовок
+func (c *cli) table(header string) *tabwriter.Writer {
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, header)
+	return w
+}
+
+func (c *cli) printJSON(value interface{}) error {
+	encoder := json.NewEncoder(c.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func formatBalance(balance *float64) string {
+	if balance == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*balance, 'f', 2, 64)
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
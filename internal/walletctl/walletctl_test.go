@@ -0,0 +1,180 @@
+package walletctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service"
+	"github.com/VadimBorzenkov/WalletAPI/internal/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const walletID = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+
+// connectTo возвращает подключение, которое отдает svc и отмечает вызов в connected
+func connectTo(svc service.AdminService, connected *bool) Connect {
+	return func() (service.AdminService, func(), error) {
+		*connected = true
+		return svc, func() {}, nil
+	}
+}
+
+// TestRun_Usage проверяет, что неверные аргументы отклоняются без подключения к хранилищу
+func TestRun_Usage(t *testing.T) {
+	tests := []struct {
+		name string   // Название теста
+		args []string // Аргументы командной строки
+	}{
+		{"No Command", []string{"-operator", "alice"}},
+		{"Unknown Command", []string{"-operator", "alice", "drop"}},
+		{"Unknown Output", []string{"-operator", "alice", "-output", "xml", "balance", walletID}},
+		{"Empty Operator", []string{"-operator", " ", "balance", walletID}},
+		{"Missing Wallet", []string{"-operator", "alice", "balance"}},
+		{"Missing Reason", []string{"-operator", "alice", "adjust", walletID, "10"}},
+		{"Invalid Amount", []string{"-operator", "alice", "adjust", "-reason", "refund", walletID, "ten"}},
+		{"Flags After Wallet", []string{"-operator", "alice", "freeze", walletID, "-reason", "fraud"}},
+		{"Invalid Date", []string{"-operator", "alice", "statement", "-from", "01.02.2024", "-to", "2024-03-01", walletID}},
+		{"Verify With Arguments", []string{"-operator", "alice", "verify", walletID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connected := false
+			err := Run(tt.args, &bytes.Buffer{}, connectTo(nil, &connected))
+			assert.ErrorIs(t, err, ErrUsage)
+			assert.False(t, connected)
+		})
+	}
+}
+
+// TestRun_Commands проверяет передачу команд в сервис от имени оператора
+func TestRun_Commands(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := mock.NewMockAdminService(ctrl)
+	info := models.WalletInfo{ID: walletID, Balance: 90, Version: 4, Frozen: true}
+	from := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string   // Название теста
+		args   []string // Аргументы командной строки
+		expect func()   // Ожидаемый вызов сервиса
+	}{
+		{"Create", []string{"create", "-balance", "100"}, func() {
+			svc.EXPECT().CreateWallet("alice", "", 100.0).Return(info, nil)
+		}},
+		{"Balance", []string{"balance", walletID}, func() {
+			svc.EXPECT().GetWallet("alice", walletID).Return(info, nil)
+		}},
+		{"History", []string{"history", "-after", "5", "-limit", "10", walletID}, func() {
+			svc.EXPECT().History("alice", walletID, int64(5), 10).Return([]models.Operation{}, nil)
+		}},
+		{"Audit", []string{"audit", walletID}, func() {
+			svc.EXPECT().AuditLog("alice", walletID, int64(0), 100).Return([]models.AuditEntry{}, nil)
+		}},
+		{"Negative Adjustment", []string{"adjust", "-reason", "duplicate payout", walletID, "-10"}, func() {
+			svc.EXPECT().Adjust("alice", walletID, -10.0, "duplicate payout").Return(models.Operation{ID: 1}, nil)
+		}},
+		{"Freeze", []string{"freeze", "-reason", "fraud", walletID}, func() {
+			svc.EXPECT().Freeze("alice", walletID, "fraud").Return(info, nil)
+		}},
+		{"Unfreeze", []string{"unfreeze", "-reason", "cleared", walletID}, func() {
+			svc.EXPECT().Unfreeze("alice", walletID, "cleared").Return(info, nil)
+		}},
+		{"Reconcile", []string{"reconcile", walletID}, func() {
+			svc.EXPECT().Reconcile("alice", []string{walletID}).Return(models.ReconciliationReport{Checked: 1}, nil)
+		}},
+		{"Statement", []string{"statement", "-from", "2024-02-01", "-to", "2024-03-01T12:00:00Z", walletID}, func() {
+			svc.EXPECT().Statement("alice", walletID, from, to).Return(models.Statement{WalletID: walletID}, nil)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expect()
+			connected := false
+			args := append([]string{"-operator", "alice"}, tt.args...)
+			assert.NoError(t, Run(args, &bytes.Buffer{}, connectTo(svc, &connected)))
+			assert.True(t, connected)
+		})
+	}
+}
+
+// TestRun_Output проверяет вывод в виде таблицы и JSON
+func TestRun_Output(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := mock.NewMockAdminService(ctrl)
+	info := models.WalletInfo{ID: walletID, Balance: 90.5, Version: 4, Frozen: true}
+	svc.EXPECT().GetWallet("alice", walletID).Return(info, nil).Times(2)
+	connected := false
+
+	var out bytes.Buffer
+	require.NoError(t, Run([]string{"-operator", "alice", "balance", walletID}, &out, connectTo(svc, &connected)))
+	assert.Contains(t, out.String(), "WALLET")
+	assert.Contains(t, out.String(), walletID+"  90.50    4        true")
+
+	out.Reset()
+	require.NoError(t, Run([]string{"-operator", "alice", "-output", "json", "balance", walletID}, &out, connectTo(svc, &connected)))
+	var decoded models.WalletInfo
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, info, decoded)
+}
+
+// TestRun_Errors проверяет ошибки сервиса, расхождения и нарушение инварианта при сверке
+// и нарушение цепочки журнала аудита
+func TestRun_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := mock.NewMockAdminService(ctrl)
+	connected := false
+
+	svc.EXPECT().Adjust("alice", walletID, -500.0, "chargeback").Return(models.Operation{}, repository.ErrInsufficientFunds)
+	err := Run([]string{"-operator", "alice", "adjust", "-reason", "chargeback", walletID, "-500"}, &bytes.Buffer{}, connectTo(svc, &connected))
+	assert.ErrorIs(t, err, repository.ErrInsufficientFunds)
+
+	report := models.ReconciliationReport{
+		Checked:       3,
+		Discrepancies: []models.WalletDiscrepancy{{WalletID: walletID, Balance: 10, LedgerBalance: 9, LastBalanceAfter: 10, Operations: 2}},
+	}
+	svc.EXPECT().Reconcile("alice", []string{}).Return(report, nil)
+	var out bytes.Buffer
+	err = Run([]string{"-operator", "alice", "reconcile"}, &out, connectTo(svc, &connected))
+	assert.ErrorIs(t, err, ErrDiscrepancies)
+	assert.Contains(t, out.String(), "Discrepancies: 1")
+
+	broken := models.AuditVerification{Checked: 4, HeadSeq: 9, Break: &models.AuditChainBreak{Seq: 5, AuditID: 12, Reason: "entry content does not match its hash"}}
+	violated := models.ReconciliationReport{
+		Checked:       3,
+		Discrepancies: []models.WalletDiscrepancy{},
+		Invariant:     &models.LedgerInvariant{TotalBalance: 101, Credits: 100},
+	}
+	svc.EXPECT().Reconcile("alice", []string{}).Return(violated, nil)
+	out.Reset()
+	err = Run([]string{"-operator", "alice", "reconcile"}, &out, connectTo(svc, &connected))
+	assert.ErrorIs(t, err, ErrDiscrepancies)
+	assert.Contains(t, out.String(), "Invariant holds: false")
+
+	svc.EXPECT().VerifyAuditChain("alice").Return(broken, nil)
+	out.Reset()
+	err = Run([]string{"-operator", "alice", "verify"}, &out, connectTo(svc, &connected))
+	assert.ErrorIs(t, err, ErrChainBroken)
+	assert.Contains(t, out.String(), "Broken at entry 5 (audit ID 12)")
+
+	failed := errors.New("connection refused")
+	err = Run([]string{"-operator", "alice", "balance", walletID}, &bytes.Buffer{}, func() (service.AdminService, func(), error) {
+		return nil, nil, failed
+	})
+	assert.ErrorIs(t, err, failed)
+}
@@ -0,0 +1,27 @@
+package webhook
+
+import (
+	"encoding/json"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+)
+
+// Dispatcher подключается к outbox как публикатор и ставит события в очередь доставки подписанным получателям
+type Dispatcher struct {
+	repo repository.WebhookRepository
+}
+
+func NewDispatcher(repo repository.WebhookRepository) *Dispatcher {
+	return &Dispatcher{repo: repo}
+}
+
+// Publish сохраняет тело вебхука для каждого подписанного получателя
+func (d *Dispatcher) Publish(event models.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = d.repo.EnqueueDeliveries(event, body)
+	return err
+}
@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// Максимальная длина текста ошибки, сохраняемого в журнале доставок
+const maxErrorLength = 512
+
+// Options задает расписание доставки вебхуков
+type Options struct {
+	PollInterval time.Duration // Период опроса очереди доставок
+	BatchSize    int           // Количество доставок, захватываемых за один раз
+	MaxAttempts  int           // Количество попыток, после которого доставка переводится в DEAD
+	BackoffBase  time.Duration // Задержка перед второй попыткой; далее она удваивается
+	BackoffMax   time.Duration // Верхняя граница задержки между попытками
+}
+
+// Worker отправляет вебхуки получателям и планирует повторные попытки
+type Worker struct {
+	repo    repository.WebhookRepository
+	client  *http.Client
+	logger  *logrus.Logger
+	options Options
+	now     func() time.Time
+}
+
+func NewWorker(repo repository.WebhookRepository, client *http.Client, logger *logrus.Logger, options Options) *Worker {
+	return &Worker{
+		repo:    repo,
+		client:  client,
+		logger:  logger,
+		options: options,
+		now:     time.Now,
+	}
+}
+
+// Run отправляет вебхуки до отмены контекста
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.options.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			sent, err := w.DeliverOnce()
+			if err != nil || sent < w.options.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverOnce отправляет одну порцию доставок и возвращает их количество
+func (w *Worker) DeliverOnce() (int, error) {
+	// Захват продлевается на время, достаточное для отправки всей порции
+	lease := w.client.Timeout*time.Duration(w.options.BatchSize) + time.Minute
+	deliveries, err := w.repo.ClaimDueDeliveries(w.options.BatchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, d := range deliveries {
+		w.deliver(d)
+	}
+	return len(deliveries), nil
+}
+
+// deliver выполняет одну попытку доставки и сохраняет ее результат
+func (w *Worker) deliver(d models.DueDelivery) {
+	statusCode, err := w.send(d)
+	if err == nil {
+		if err := w.repo.MarkDelivered(d.ID, statusCode); err != nil {
+			w.logger.Errorf("Failed to record webhook delivery %d: %v", d.ID, err)
+		}
+		return
+	}
+
+	attempts := d.Attempts + 1
+	dead := attempts >= w.options.MaxAttempts
+	nextAttemptAt := w.now().Add(Backoff(attempts, w.options.BackoffBase, w.options.BackoffMax))
+	if dead {
+		w.logger.Errorf("Webhook delivery %d to %s moved to dead letter after %d attempts: %v", d.ID, d.URL, attempts, err)
+	} else {
+		w.logger.Warnf("Webhook delivery %d to %s failed (attempt %d), retry at %s: %v", d.ID, d.URL, attempts, nextAttemptAt.Format(time.RFC3339), err)
+	}
+
+	reason := err.Error()
+	if len(reason) > maxErrorLength {
+		reason = reason[:maxErrorLength]
+	}
+	if err := w.repo.MarkFailed(d.ID, statusCode, reason, nextAttemptAt, dead); err != nil {
+		w.logger.Errorf("Failed to record webhook delivery %d failure: %v", d.ID, err)
+	}
+}
+
+// send отправляет подписанное тело вебхука. Любой ответ, кроме 2xx, считается ошибкой
+func (w *Worker) send(d models.DueDelivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-ID", strconv.FormatInt(d.ID, 10))
+	req.Header.Set("X-Webhook-Event", d.EventType)
+	req.Header.Set(SignatureHeader, Sign(d.Secret, w.now(), d.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Backoff возвращает задержку после attempts неудачных попыток: base, 2*base, 4*base... но не более max
+func Backoff(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/models"
+	"github.com/VadimBorzenkov/WalletAPI/internal/repository/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+var testOptions = Options{
+	PollInterval: time.Second,
+	BatchSize:    10,
+	MaxAttempts:  3,
+	BackoffBase:  10 * time.Second,
+	BackoffMax:   time.Minute,
+}
+
+// TestWorker_DeliverSigned проверяет, что получатель получает тело события с корректной подписью
+func TestWorker_DeliverSigned(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	body := []byte(`{"eventId":1,"eventType":"WalletDeposited"}`)
+	var verifyErr error
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ := io.ReadAll(r.Body)
+		verifyErr = Verify("secret", r.Header.Get(SignatureHeader), received, time.Now(), time.Minute)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	repo := mock.NewMockWebhookRepository(ctrl)
+	repo.EXPECT().ClaimDueDeliveries(testOptions.BatchSize, gomock.Any()).Return([]models.DueDelivery{{
+		WebhookDelivery: models.WebhookDelivery{ID: 5, EventType: models.EventWalletDeposited, Payload: body},
+		URL:             receiver.URL,
+		Secret:          "secret",
+	}}, nil)
+	repo.EXPECT().MarkDelivered(int64(5), http.StatusNoContent).Return(nil)
+
+	worker := NewWorker(repo, receiver.Client(), logrus.New(), testOptions)
+	sent, err := worker.DeliverOnce()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.NoError(t, verifyErr)
+}
+
+// TestWorker_RetryAndDeadLetter проверяет расписание повторов и перевод доставки в DEAD после последней попытки
+func TestWorker_RetryAndDeadLetter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := mock.NewMockWebhookRepository(ctrl)
+	repo.EXPECT().ClaimDueDeliveries(testOptions.BatchSize, gomock.Any()).Return([]models.DueDelivery{
+		{WebhookDelivery: models.WebhookDelivery{ID: 1, Attempts: 0}, URL: receiver.URL, Secret: "secret"},
+		{WebhookDelivery: models.WebhookDelivery{ID: 2, Attempts: 2}, URL: receiver.URL, Secret: "secret"},
+	}, nil)
+	// Первая неудача: повтор через базовую задержку
+	repo.EXPECT().MarkFailed(int64(1), http.StatusInternalServerError, gomock.Any(), now.Add(10*time.Second), false).Return(nil)
+	// Третья неудача при MaxAttempts = 3: доставка переводится в DEAD
+	repo.EXPECT().MarkFailed(int64(2), http.StatusInternalServerError, gomock.Any(), gomock.Any(), true).Return(nil)
+
+	worker := NewWorker(repo, receiver.Client(), logrus.New(), testOptions)
+	worker.now = func() time.Time { return now }
+	_, err := worker.DeliverOnce()
+	assert.NoError(t, err)
+}
+
+// TestBackoff проверяет экспоненциальный рост задержки с ограничением сверху
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{4, time.Minute},
+		{10, time.Minute},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Backoff(tt.attempts, 10*time.Second, time.Minute))
+	}
+}
+
+// TestVerify проверяет отклонение подделанного тела и устаревшей подписи
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	body := []byte(`{"eventId":1}`)
+	header := Sign("secret", now, body)
+
+	assert.NoError(t, Verify("secret", header, body, now, time.Minute))
+	assert.ErrorIs(t, Verify("secret", header, []byte(`{"eventId":2}`), now, time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("other", header, body, now, time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", header, body, now.Add(time.Hour), time.Minute), ErrSignatureExpired)
+}
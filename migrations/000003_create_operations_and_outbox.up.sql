@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS wallet_operations (
+    operation_id BIGSERIAL PRIMARY KEY,
+    wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+    operation_type VARCHAR(32) NOT NULL,
+    amount NUMERIC(20, 2) NOT NULL,
+    balance_after NUMERIC(20, 2) NOT NULL,
+    wallet_version BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS wallet_operations_wallet_id_idx ON wallet_operations (wallet_id, operation_id);
+
+-- Балансы, накопленные до появления журнала операций, фиксируются как начальные
+INSERT INTO wallet_operations (wallet_id, operation_type, amount, balance_after, wallet_version)
+SELECT wallet_id, 'OPENING', balance, balance, version FROM wallets WHERE balance <> 0;
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    event_id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(64) NOT NULL,
+    wallet_id UUID NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS outbox_events_unpublished_idx ON outbox_events (event_id) WHERE published_at IS NULL;
\ No newline at end of file
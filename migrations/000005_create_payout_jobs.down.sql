@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS payout_rows;
+DROP TABLE IF EXISTS payout_jobs;
+DROP INDEX IF EXISTS wallet_operations_idempotency_key_idx;
+ALTER TABLE wallet_operations DROP COLUMN IF EXISTS idempotency_key;
\ No newline at end of file
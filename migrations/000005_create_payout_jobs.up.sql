@@ -0,0 +1,29 @@
+-- Ключ идемпотентности защищает от повторного выполнения операции, например строки выплаты после перезапуска
+ALTER TABLE wallet_operations ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(128);
+
+CREATE UNIQUE INDEX IF NOT EXISTS wallet_operations_idempotency_key_idx ON wallet_operations (idempotency_key) WHERE idempotency_key IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS payout_jobs (
+    job_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    status VARCHAR(16) NOT NULL DEFAULT 'RUNNING',
+    total_rows INT NOT NULL,
+    processed_rows INT NOT NULL DEFAULT 0,
+    failed_rows INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS payout_rows (
+    job_id UUID NOT NULL REFERENCES payout_jobs (job_id) ON DELETE CASCADE,
+    row_number INT NOT NULL,
+    wallet_id TEXT NOT NULL,
+    amount NUMERIC(20, 2),
+    status VARCHAR(16) NOT NULL DEFAULT 'PENDING',
+    error TEXT,
+    lease_until TIMESTAMPTZ,
+    processed_at TIMESTAMPTZ,
+    PRIMARY KEY (job_id, row_number)
+);
+
+CREATE INDEX IF NOT EXISTS payout_rows_pending_idx ON payout_rows (job_id, row_number) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS payout_rows_failed_idx ON payout_rows (job_id, row_number) WHERE status = 'FAILED';
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS wallet_pending_deposits;
\ No newline at end of file
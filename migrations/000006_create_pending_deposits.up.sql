@@ -0,0 +1,13 @@
+-- Буфер пополнений горячих кошельков. Пополнение записывается сюда без блокировки строки кошелька
+-- и периодически переносится в баланс и журнал операций
+CREATE TABLE IF NOT EXISTS wallet_pending_deposits (
+    deposit_id BIGSERIAL PRIMARY KEY,
+    wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+    amount NUMERIC(20, 2) NOT NULL,
+    idempotency_key VARCHAR(128),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS wallet_pending_deposits_wallet_id_idx ON wallet_pending_deposits (wallet_id, deposit_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS wallet_pending_deposits_idempotency_key_idx ON wallet_pending_deposits (idempotency_key) WHERE idempotency_key IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS audit_log;
+ALTER TABLE wallets DROP COLUMN IF EXISTS frozen;
\ No newline at end of file
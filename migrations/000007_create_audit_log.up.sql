@@ -0,0 +1,14 @@
+-- Операторы могут заморозить кошелек: операции с ним отклоняются, пока его не разморозят
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS frozen BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Журнал действий операторов. Записи только добавляются
+CREATE TABLE IF NOT EXISTS audit_log (
+    audit_id BIGSERIAL PRIMARY KEY,
+    actor TEXT NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    wallet_id UUID,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_wallet_id_idx ON audit_log (wallet_id, audit_id) WHERE wallet_id IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,16 @@
+DROP TRIGGER IF EXISTS audit_checkpoints_append_only ON audit_checkpoints;
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+DROP FUNCTION IF EXISTS audit_append_only();
+DROP TABLE IF EXISTS audit_checkpoints;
+DROP TABLE IF EXISTS audit_chain_head;
+ALTER TABLE wallet_pending_deposits
+    DROP COLUMN IF EXISTS request_id,
+    DROP COLUMN IF EXISTS actor;
+ALTER TABLE audit_log
+    DROP COLUMN IF EXISTS hash,
+    DROP COLUMN IF EXISTS prev_hash,
+    DROP COLUMN IF EXISTS balance_after,
+    DROP COLUMN IF EXISTS balance_before,
+    DROP COLUMN IF EXISTS operation_id,
+    DROP COLUMN IF EXISTS request_id,
+    DROP COLUMN IF EXISTS chain_seq;
\ No newline at end of file
@@ -0,0 +1,49 @@
+-- Журнал аудита становится цепочкой: каждая запись хранит хэш своего содержимого и хэш предыдущей записи.
+-- Записи, сделанные до появления цепочки, остаются без хэша и в проверку не входят
+ALTER TABLE audit_log
+    ADD COLUMN IF NOT EXISTS chain_seq BIGINT UNIQUE,
+    ADD COLUMN IF NOT EXISTS request_id TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS operation_id BIGINT,
+    ADD COLUMN IF NOT EXISTS balance_before NUMERIC(20, 2),
+    ADD COLUMN IF NOT EXISTS balance_after NUMERIC(20, 2),
+    ADD COLUMN IF NOT EXISTS prev_hash TEXT,
+    ADD COLUMN IF NOT EXISTS hash TEXT;
+
+-- Голова цепочки: номер и хэш последней записи. Блокировка этой строки упорядочивает добавление записей
+CREATE TABLE IF NOT EXISTS audit_chain_head (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    chain_seq BIGINT NOT NULL DEFAULT 0,
+    hash TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO audit_chain_head (id) VALUES (TRUE) ON CONFLICT DO NOTHING;
+
+-- Буферизованное пополнение попадает в журнал аудита при переносе в баланс, поэтому буфер хранит его источник
+ALTER TABLE wallet_pending_deposits
+    ADD COLUMN IF NOT EXISTS actor TEXT NOT NULL DEFAULT 'system',
+    ADD COLUMN IF NOT EXISTS request_id TEXT NOT NULL DEFAULT '';
+
+-- Подписанные контрольные точки цепочки
+CREATE TABLE IF NOT EXISTS audit_checkpoints (
+    checkpoint_id BIGSERIAL PRIMARY KEY,
+    chain_seq BIGINT NOT NULL UNIQUE,
+    hash TEXT NOT NULL,
+    key_id TEXT NOT NULL,
+    signature TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+-- Записи журнала аудита и контрольные точки нельзя изменить или удалить
+CREATE OR REPLACE FUNCTION audit_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_append_only();
+
+DROP TRIGGER IF EXISTS audit_checkpoints_append_only ON audit_checkpoints;
+CREATE TRIGGER audit_checkpoints_append_only BEFORE UPDATE OR DELETE ON audit_checkpoints
+    FOR EACH ROW EXECUTE FUNCTION audit_append_only();
\ No newline at end of file
@@ -0,0 +1,13 @@
+-- Снимки балансов кошельков на границы периодов. Баланс снимка включает все операции с created_at <= taken_at,
+-- поэтому баланс на любой момент вычисляется по ближайшему предыдущему снимку и операциям после него
+CREATE TABLE IF NOT EXISTS wallet_balance_snapshots (
+    wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+    taken_at TIMESTAMPTZ NOT NULL,
+    balance NUMERIC(20, 2) NOT NULL,
+    PRIMARY KEY (wallet_id, taken_at)
+);
+
+CREATE INDEX IF NOT EXISTS wallet_balance_snapshots_taken_at_idx ON wallet_balance_snapshots (taken_at);
+
+-- Операции кошелька за период между снимком и запрошенным моментом выбираются по времени
+CREATE INDEX IF NOT EXISTS wallet_operations_wallet_created_idx ON wallet_operations (wallet_id, created_at);
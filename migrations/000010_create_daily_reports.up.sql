@@ -0,0 +1,15 @@
+-- Дневные итоги кошельков за сутки UTC [day, day + 1): баланс на начало и конец суток, сумма и количество
+-- операций пополнения и списания. Строка записывается один раз после окончания суток и не изменяется
+CREATE TABLE IF NOT EXISTS wallet_daily_reports (
+    wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+    day DATE NOT NULL,
+    opening_balance NUMERIC(20, 2) NOT NULL,
+    closing_balance NUMERIC(20, 2) NOT NULL,
+    deposits NUMERIC(20, 2) NOT NULL,
+    withdrawals NUMERIC(20, 2) NOT NULL,
+    deposit_count INTEGER NOT NULL,
+    withdrawal_count INTEGER NOT NULL,
+    PRIMARY KEY (wallet_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS wallet_daily_reports_day_idx ON wallet_daily_reports (day);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS outbox_events_unpublished_wallet_idx;
+
+ALTER TABLE outbox_events
+    DROP COLUMN IF EXISTS next_attempt_at,
+    DROP COLUMN IF EXISTS attempts,
+    DROP COLUMN IF EXISTS claimed_until;
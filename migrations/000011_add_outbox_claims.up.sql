@@ -0,0 +1,9 @@
+-- События outbox захватываются экземпляром на время публикации (claimed_until) и публикуются вне транзакции.
+-- Событие, публикация которого не удалась, откладывается до next_attempt_at вместе с последующими событиями
+-- того же кошелька, не задерживая события других кошельков
+ALTER TABLE outbox_events
+    ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS outbox_events_unpublished_wallet_idx ON outbox_events (wallet_id, event_id) WHERE published_at IS NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS audit_log_unsealed_idx;
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_append_only();
+DROP FUNCTION IF EXISTS audit_log_seal_only();
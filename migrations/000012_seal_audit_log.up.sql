@@ -0,0 +1,24 @@
+-- Записи журнала аудита добавляются без номера в цепочке и хэшей, а в цепочку их выстраивает фоновый процесс
+-- в порядке audit_id. Транзакции, изменяющие балансы, больше не блокируют строку audit_chain_head.
+-- Единственное разрешенное изменение записи — однократное заполнение chain_seq, prev_hash и hash;
+-- содержимое записи по-прежнему изменить нельзя. Записи, сделанные до появления цепочки, тоже запечатываются
+CREATE OR REPLACE FUNCTION audit_log_seal_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND OLD.chain_seq IS NULL AND OLD.hash IS NULL
+        AND NEW.chain_seq IS NOT NULL AND NEW.prev_hash IS NOT NULL AND NEW.hash IS NOT NULL
+        AND (NEW.audit_id, NEW.actor, NEW.action, NEW.wallet_id, NEW.request_id, NEW.operation_id,
+             NEW.balance_before, NEW.balance_after, NEW.details, NEW.created_at)
+            IS NOT DISTINCT FROM
+            (OLD.audit_id, OLD.actor, OLD.action, OLD.wallet_id, OLD.request_id, OLD.operation_id,
+             OLD.balance_before, OLD.balance_after, OLD.details, OLD.created_at) THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_seal_only();
+
+CREATE INDEX IF NOT EXISTS audit_log_unsealed_idx ON audit_log (audit_id) WHERE chain_seq IS NULL;
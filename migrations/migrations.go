@@ -0,0 +1,15 @@
+// Package migrations содержит SQL-миграции, встроенные в исполняемый файл: миграции PostgreSQL
+// в корне каталога, миграции локальной базы SQLite — в каталоге sqlite
+package migrations
+
+import "embed"
+
+// Postgres — миграции PostgreSQL
+//
+//go:embed *.sql
+var Postgres embed.FS
+
+// SQLite — миграции SQLite, лежат в каталоге sqlite
+//
+//go:embed sqlite/*.sql
+var SQLite embed.FS
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS wallet_operations;
+DROP TABLE IF EXISTS wallets;
\ No newline at end of file
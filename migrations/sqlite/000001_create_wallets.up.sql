@@ -0,0 +1,20 @@
+-- Схема SQLite для автономных узлов. Суммы хранятся в копейках: в SQLite нет точного десятичного типа
+CREATE TABLE IF NOT EXISTS wallets (
+    wallet_id TEXT PRIMARY KEY,
+    balance INTEGER NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS wallet_operations (
+    operation_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    wallet_id TEXT NOT NULL REFERENCES wallets (wallet_id),
+    operation_type TEXT NOT NULL,
+    amount INTEGER NOT NULL,
+    balance_after INTEGER NOT NULL,
+    wallet_version INTEGER NOT NULL,
+    idempotency_key TEXT UNIQUE,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS wallet_operations_wallet_id_idx ON wallet_operations (wallet_id, operation_id);
\ No newline at end of file
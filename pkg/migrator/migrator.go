@@ -2,31 +2,163 @@ package migrator
 
 import (
 	"database/sql"
+	"errors"
+	"io/fs"
+	"os"
 
-	"github.com/golang-migrate/migrate"
-	"github.com/golang-migrate/migrate/database/postgres"
-	_ "github.com/golang-migrate/migrate/source/file"
+	"github.com/VadimBorzenkov/WalletAPI/migrations"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
 )
 
-// Запуск миграций базы данных
-func RunDatabaseMigrations(db *sql.DB) error {
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
+// Migrator управляет миграциями одной базы данных. Миграции встроены в исполняемый файл,
+// поэтому не зависят от рабочего каталога
+type Migrator struct {
+	m       *migrate.Migrate
+	source  source.Driver // Отдельный источник для перечисления миграций в Status
+	closeDB bool          // Закрывать ли в Close подключение к базе
+}
+
+// Status описывает состояние миграций базы
+type Status struct {
+	Version    uint // Версия последней примененной миграции; 0 — миграции не применялись
+	Dirty      bool // Миграция Version завершилась ошибкой, схему нужно исправить и выполнить force
+	Migrations []Migration
+}
+
+// Migration — миграция из набора, встроенного в исполняемый файл
+type Migration struct {
+	Version uint
+	Name    string
+	Applied bool
+}
+
+// Pending возвращает количество миграций, которые еще не применены
+func (s Status) Pending() int {
+	pending := 0
+	for _, migration := range s.Migrations {
+		if !migration.Applied {
+			pending++
+		}
+	}
+	return pending
+}
+
+// NewPostgres создает мигратор для базы PostgreSQL. Мигратор работает через database/sql поверх соединений пула
+// и держит одно соединение до вызова Close. Таблица версий создается в первой схеме search_path
+func NewPostgres(pool *pgxpool.Pool) (*Migrator, error) {
+	driver, err := pgx.WithInstance(stdlib.OpenDBFromPool(pool), &pgx.Config{})
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return newMigrator(migrations.Postgres, ".", "postgres", driver, true)
+}
 
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://migrations",
-		"postgres",
-		driver,
-	)
+// NewSQLite создает мигратор для локальной базы SQLite. Close не закрывает db
+func NewSQLite(db *sql.DB) (*Migrator, error) {
+	driver, err := sqlite3.WithInstance(db, &sqlite3.Config{})
 	if err != nil {
+		return nil, err
+	}
+	return newMigrator(migrations.SQLite, "sqlite", "sqlite3", driver, false)
+}
+
+func newMigrator(fsys fs.FS, dir, databaseName string, driver database.Driver, closeDB bool) (*Migrator, error) {
+	src, err := iofs.New(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	m, err := migrate.NewWithInstance("iofs", src, databaseName, driver)
+	if err != nil {
+		return nil, err
+	}
+	listing, err := iofs.New(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{m: m, source: listing, closeDB: closeDB}, nil
+}
+
+// Up применяет все миграции, которые еще не применены
+func (m *Migrator) Up() error {
+	if err := m.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
 		return err
 	}
+	return nil
+}
+
+// Down откатывает n последних примененных миграций
+func (m *Migrator) Down(n int) error {
+	if n <= 0 {
+		return errors.New("number of migrations to roll back must be positive")
+	}
+	return m.m.Steps(-n)
+}
+
+// Force записывает версию схемы без выполнения миграций и снимает признак ошибки. Применяется после ручного
+// исправления схемы, на которой миграция завершилась ошибкой. Версия -1 означает, что миграции не применялись
+func (m *Migrator) Force(version int) error {
+	return m.m.Force(version)
+}
+
+// Status возвращает версию схемы и список встроенных миграций с отметкой о применении
+func (m *Migrator) Status() (Status, error) {
+	var status Status
+	version, dirty, err := m.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return Status{}, err
+	}
+	status.Version, status.Dirty = version, dirty
 
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+	v, err := m.source.First()
+	for err == nil {
+		r, name, readErr := m.source.ReadUp(v)
+		if readErr != nil {
+			return Status{}, readErr
+		}
+		r.Close()
+		applied := v < status.Version || v == status.Version && !status.Dirty
+		status.Migrations = append(status.Migrations, Migration{Version: v, Name: name, Applied: applied})
+		v, err = m.source.Next(v)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return Status{}, err
+	}
+	return status, nil
+}
+
+// Close освобождает соединение мигратора
+func (m *Migrator) Close() error {
+	listingErr := m.source.Close()
+	if !m.closeDB {
+		return listingErr
+	}
+	sourceErr, dbErr := m.m.Close()
+	return errors.Join(listingErr, sourceErr, dbErr)
+}
+
+// Запуск миграций базы данных
+func RunDatabaseMigrations(pool *pgxpool.Pool) error {
+	m, err := NewPostgres(pool)
+	if err != nil {
 		return err
 	}
+	defer m.Close()
+	return m.Up()
+}
 
-	return nil
+// Запуск миграций локальной базы SQLite
+func RunSQLiteMigrations(db *sql.DB) error {
+	m, err := NewSQLite(db)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	return m.Up()
 }
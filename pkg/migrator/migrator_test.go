@@ -0,0 +1,89 @@
+package migrator
+
+import (
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/VadimBorzenkov/WalletAPI/internal/db"
+	"github.com/VadimBorzenkov/WalletAPI/migrations"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMigrator_SQLite проверяет команды мигратора на встроенных миграциях SQLite
+func TestMigrator_SQLite(t *testing.T) {
+	sqliteDB, err := db.OpenSQLite(filepath.Join(t.TempDir(), "wallets.db"))
+	require.NoError(t, err)
+	defer sqliteDB.Close()
+
+	m, err := NewSQLite(sqliteDB)
+	require.NoError(t, err)
+	defer m.Close()
+
+	status, err := m.Status()
+	require.NoError(t, err)
+	assert.Zero(t, status.Version)
+	require.NotEmpty(t, status.Migrations)
+	assert.Equal(t, len(status.Migrations), status.Pending())
+	assert.Equal(t, Migration{Version: 1, Name: "create_wallets"}, status.Migrations[0])
+	latest := status.Migrations[len(status.Migrations)-1].Version
+
+	require.NoError(t, m.Up())
+	require.NoError(t, m.Up(), "repeated up is a no-op")
+	status, err = m.Status()
+	require.NoError(t, err)
+	assert.Equal(t, latest, status.Version)
+	assert.Zero(t, status.Pending())
+	_, err = sqliteDB.Exec(`INSERT INTO wallets (wallet_id) VALUES ('a')`)
+	require.NoError(t, err)
+
+	require.NoError(t, m.Down(int(latest)))
+	status, err = m.Status()
+	require.NoError(t, err)
+	assert.Zero(t, status.Version)
+	_, err = sqliteDB.Exec(`INSERT INTO wallets (wallet_id) VALUES ('a')`)
+	assert.Error(t, err, "tables are dropped")
+	assert.Error(t, m.Down(0))
+
+	// force записывает версию без выполнения миграций и снимает признак ошибки
+	require.NoError(t, m.Force(int(latest)))
+	status, err = m.Status()
+	require.NoError(t, err)
+	assert.Equal(t, latest, status.Version)
+	assert.False(t, status.Dirty)
+	assert.Zero(t, status.Pending())
+
+	// Close мигратора SQLite не закрывает базу
+	require.NoError(t, m.Close())
+	assert.NoError(t, sqliteDB.Ping())
+}
+
+// TestEmbeddedMigrations проверяет, что миграции встроены в исполняемый файл и у каждой есть откат
+func TestEmbeddedMigrations(t *testing.T) {
+	tests := []struct {
+		name string // Набор миграций
+		fsys fs.FS  // Встроенные файлы
+		dir  string // Каталог миграций
+	}{
+		{"Postgres", migrations.Postgres, "."},
+		{"SQLite", migrations.SQLite, "sqlite"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := iofs.New(tt.fsys, tt.dir)
+			require.NoError(t, err)
+			defer src.Close()
+
+			v, err := src.First()
+			require.NoError(t, err)
+			for ; err == nil; v, err = src.Next(v) {
+				r, _, downErr := src.ReadDown(v)
+				require.NoError(t, downErr, "migration %d has no down file", v)
+				r.Close()
+			}
+		})
+	}
+}